@@ -5,7 +5,9 @@ go 1.24
 require (
 	github.com/lazyengs/lazynx/pkg/nxlsclient v0.1.0
 	github.com/muesli/reflow v0.3.0
+	github.com/sourcegraph/jsonrpc2 v0.2.0
 	go.lsp.dev/protocol v0.12.0
+	go.opentelemetry.io/otel v1.35.0
 	go.uber.org/zap v1.27.0
 )
 
@@ -24,7 +26,6 @@ require (
 	github.com/mitchellh/hashstructure/v2 v2.0.2 // indirect
 	github.com/segmentio/asm v1.1.3 // indirect
 	github.com/segmentio/encoding v0.3.4 // indirect
-	github.com/sourcegraph/jsonrpc2 v0.2.0 // indirect
 	github.com/spf13/pflag v1.0.6 // indirect
 	github.com/xo/terminfo v0.0.0-20220910002029-abceb7e1c41e // indirect
 	go.lsp.dev/jsonrpc2 v0.10.0 // indirect
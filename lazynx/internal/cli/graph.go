@@ -0,0 +1,137 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/lazyengs/lazynx/pkg/nxlsclient/export"
+	"github.com/lazyengs/lazynx/pkg/nxlsclient/taskgraph"
+	"github.com/spf13/cobra"
+)
+
+var graphFlags struct {
+	format   string
+	output   string
+	focus    []string
+	exclude  []string
+	depth    int
+	groupBy  string
+	external bool
+	tasks    []string
+}
+
+var graphCmd = &cobra.Command{
+	Use:   "graph [workspace-path]",
+	Short: "Export the project graph or a task graph",
+	Long: `Export the project graph of an Nx workspace as Graphviz DOT, Mermaid, GraphML or JSON.
+
+With --tasks, the task graph of the given targets is exported instead, for example
+--tasks app:build:production.`,
+	Example: `  lazynx graph --format mermaid --focus app --depth 2
+  lazynx graph --format dot --group-by directory | dot -Tsvg > graph.svg
+  lazynx graph --tasks app:build --format json`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runGraph,
+}
+
+func init() {
+	flags := graphCmd.Flags()
+	flags.StringVarP(&graphFlags.format, "format", "f", string(export.FormatDOT), "Output format: dot, mermaid, graphml or json")
+	flags.StringVarP(&graphFlags.output, "output", "o", "", "Write to this file instead of stdout")
+	flags.StringSliceVar(&graphFlags.focus, "focus", nil, "Only show these projects with their dependencies and dependents (globs and tag: allowed)")
+	flags.StringSliceVar(&graphFlags.exclude, "exclude", nil, "Hide these projects (globs and tag: allowed)")
+	flags.IntVar(&graphFlags.depth, "depth", 0, "Only show nodes this many edges away from the focus, 0 for no limit")
+	flags.StringVar(&graphFlags.groupBy, "group-by", "", "Group nodes by tag, directory or project")
+	flags.BoolVar(&graphFlags.external, "external", false, "Include external nodes such as npm packages")
+	flags.StringSliceVar(&graphFlags.tasks, "tasks", nil, "Export the task graph of these project:target[:configuration] targets")
+
+	rootCmd.AddCommand(graphCmd)
+}
+
+func runGraph(cmd *cobra.Command, args []string) error {
+	format, err := export.ParseFormat(graphFlags.format)
+	if err != nil {
+		return err
+	}
+	groupBy, err := export.ParseGroupBy(graphFlags.groupBy)
+	if err != nil {
+		return err
+	}
+
+	session, err := startHeadless(cmd.Context(), args)
+	if err != nil {
+		return err
+	}
+	defer session.close()
+
+	workspace, err := session.workspace(cmd.Context())
+	if err != nil {
+		return err
+	}
+
+	var roots []taskgraph.TaskID
+	for _, task := range graphFlags.tasks {
+		id, err := taskgraph.ResolveTaskID(task, workspace)
+		if err != nil {
+			return err
+		}
+		roots = append(roots, id)
+	}
+
+	diagram := export.FromProjectGraph(&workspace.ProjectGraph)
+	if len(roots) > 0 {
+		tasks, err := taskgraph.NewBuilder(workspace).Build(roots...)
+		if err != nil {
+			return fmt.Errorf("error building the task graph: %w", err)
+		}
+		diagram = export.FromTaskGraph(tasks, &workspace.ProjectGraph)
+	}
+
+	diagram, err = diagram.Apply(export.Options{
+		Focus:           graphFlags.focus,
+		Exclude:         graphFlags.exclude,
+		ExcludeExternal: !graphFlags.external,
+		Depth:           graphFlags.depth,
+		GroupBy:         groupBy,
+	})
+	if err != nil {
+		return err
+	}
+
+	session.logger.Infow("Exporting graph", "format", format, "nodes", len(diagram.Nodes), "edges", len(diagram.Edges))
+	if graphFlags.output == "" {
+		return export.Write(cmd.OutOrStdout(), diagram, format)
+	}
+	err = writeFile(graphFlags.output, func(w io.Writer) error {
+		return export.Write(w, diagram, format)
+	})
+	if err != nil {
+		return fmt.Errorf("error writing %s: %w", graphFlags.output, err)
+	}
+	return nil
+}
+
+// writeFile writes to a temporary file next to path and renames it over path once write
+// and Close succeeded, so a failed export never leaves a partial file behind.
+func writeFile(path string, write func(io.Writer) error) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+"-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := write(tmp); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(0o644); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
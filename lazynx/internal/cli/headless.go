@@ -0,0 +1,65 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/lazyengs/lazynx/internal/config"
+	"github.com/lazyengs/lazynx/internal/logs"
+	"github.com/lazyengs/lazynx/internal/nxls"
+	"github.com/lazyengs/lazynx/pkg/nxlsclient"
+	"github.com/lazyengs/lazynx/pkg/nxlsclient/commands"
+	nxtypes "github.com/lazyengs/lazynx/pkg/nxlsclient/nx-types"
+	"go.uber.org/zap"
+)
+
+// headlessSession is a started nxls client for subcommands that print a result and exit.
+type headlessSession struct {
+	client *nxlsclient.Client
+	logger *zap.SugaredLogger
+	config *config.Config
+	stop   func()
+}
+
+// startHeadless validates the workspace path in args, defaulting to the current
+// directory, and starts nxls for it.
+func startHeadless(ctx context.Context, args []string) (*headlessSession, error) {
+	config := config.LoadConfiguration()
+
+	logger, err := logs.SetupFileLogger(config.Logs, verbose)
+	if err != nil {
+		return nil, fmt.Errorf("error setting up logger: %w", err)
+	}
+
+	workspacePath := "./"
+	if len(args) > 0 {
+		workspacePath = args[0]
+	}
+	if err := validateWorkspacePath(workspacePath, logger); err != nil {
+		return nil, err
+	}
+
+	client, stop, err := nxls.StartHeadless(ctx, workspacePath, logger, config)
+	if err != nil {
+		return nil, fmt.Errorf("error starting the Nx language server: %w", err)
+	}
+
+	return &headlessSession{client: client, logger: logger, config: config, stop: stop}, nil
+}
+
+// workspace loads the workspace from nxls.
+func (s *headlessSession) workspace(ctx context.Context) (*nxtypes.NxWorkspace, error) {
+	workspace, err := s.client.Commander.SendWorkspaceRequest(ctx, &commands.WorkspaceRequestParams{})
+	if err != nil {
+		return nil, fmt.Errorf("error loading the workspace: %w", err)
+	}
+	if workspace == nil {
+		return nil, fmt.Errorf("error loading the workspace: empty response")
+	}
+	return workspace, nil
+}
+
+func (s *headlessSession) close() {
+	s.stop()
+	_ = s.logger.Sync()
+}
@@ -0,0 +1,92 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/lazyengs/lazynx/internal/nxls"
+	"github.com/lazyengs/lazynx/pkg/nxlsclient/boundaries"
+	"github.com/spf13/cobra"
+)
+
+// exitViolations is the exit status of lint-boundaries when a constraint is broken.
+const exitViolations = 2
+
+var lintBoundariesFlags struct {
+	json bool
+}
+
+var lintBoundariesCmd = &cobra.Command{
+	Use:   "lint-boundaries [workspace-path]",
+	Short: "Check module boundary constraints against the project graph",
+	Long: `Check the depConstraints of @nx/enforce-module-boundaries against the project tags
+and dependencies of an Nx workspace, without running eslint.
+
+Constraints come from depConstraints in ~/.lazynx/lazynx.json when set, otherwise from
+the workspace eslint config. The command exits with status 2 when a dependency breaks a
+constraint, so it can run in CI, and with status 1 when the check could not run.`,
+	Example: `  lazynx lint-boundaries
+  lazynx lint-boundaries ./my-workspace --json`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runLintBoundaries,
+}
+
+func init() {
+	lintBoundariesCmd.Flags().BoolVar(&lintBoundariesFlags.json, "json", false, "Print the violations as JSON")
+
+	rootCmd.AddCommand(lintBoundariesCmd)
+}
+
+func runLintBoundaries(cmd *cobra.Command, args []string) error {
+	session, err := startHeadless(cmd.Context(), args)
+	if err != nil {
+		return err
+	}
+	defer session.close()
+
+	constraints, source, err := nxls.LoadDepConstraints(session.client.NxWorkspacePath, session.config)
+	if err != nil {
+		return fmt.Errorf("error loading module boundary constraints: %w", err)
+	}
+
+	workspace, err := session.workspace(cmd.Context())
+	if err != nil {
+		return err
+	}
+
+	violations := boundaries.Check(&workspace.ProjectGraph, constraints)
+	session.logger.Infow("Checked module boundaries", "source", source, "constraints", len(constraints), "violations", len(violations))
+
+	out := cmd.OutOrStdout()
+	if lintBoundariesFlags.json {
+		if violations == nil {
+			violations = []boundaries.Violation{}
+		}
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(violations); err != nil {
+			return err
+		}
+	} else {
+		for _, v := range violations {
+			fmt.Fprintf(out, "%s → %s [%s]\n    %s\n", v.Dependency.Source, v.Dependency.Target, v.Kind, v.Message)
+		}
+		if len(violations) == 0 {
+			fmt.Fprintf(out, "No violations of %d constraints from %s\n", len(constraints), source)
+		}
+	}
+
+	if len(violations) > 0 {
+		// The violations are the output, usage would only bury them
+		cmd.SilenceUsage = true
+		noun := "violations"
+		if len(violations) == 1 {
+			noun = "violation"
+		}
+		return &exitError{
+			err:  fmt.Errorf("found %d module boundary %s (%s)", len(violations), noun, source),
+			code: exitViolations,
+		}
+	}
+	return nil
+}
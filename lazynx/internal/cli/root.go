@@ -1,6 +1,8 @@
 package cli
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -10,12 +12,17 @@ import (
 	"github.com/lazyengs/lazynx/internal/logs"
 	"github.com/lazyengs/lazynx/internal/nxls"
 	"github.com/lazyengs/lazynx/internal/tui"
+	"github.com/lazyengs/lazynx/pkg/nxlsclient/tracing"
 	"github.com/spf13/cobra"
+	"go.opentelemetry.io/otel"
 	"go.uber.org/zap"
 )
 
 var (
-	verbose bool
+	verbose   bool
+	traceFile string
+	// shutdownTracing flushes the spans of the command, set by setupTracing.
+	shutdownTracing = func() {}
 )
 
 // rootCmd represents the base command when called without any subcommands
@@ -26,12 +33,14 @@ var rootCmd = &cobra.Command{
 
 Provide the path to your Nx workspace as an argument, or you will be prompted
 to enter the workspace path with validation to ensure it contains nx.json.`,
-	Args: cobra.MaximumNArgs(1),
-	RunE: runLazyNX,
+	Args:              cobra.MaximumNArgs(1),
+	PersistentPreRunE: setupTracing,
+	RunE:              runLazyNX,
 }
 
 func init() {
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "Enable verbose logging")
+	rootCmd.PersistentFlags().StringVar(&traceFile, "trace-file", "", "Write OpenTelemetry spans as JSON lines to this file")
 }
 
 func runLazyNX(cmd *cobra.Command, args []string) error {
@@ -61,11 +70,11 @@ func runLazyNX(cmd *cobra.Command, args []string) error {
 	client := nxls.CreateNxlsclient(logger, config)
 
 	// Create and run the program
-	p := tui.Create(client, logger, workspacePath)
+	p := tui.Create(client, logger, workspacePath, config)
 
 	// Initialize the nxlsclient
 	go func() {
-		err := nxls.InitializeNxlsclient(cmd.Context(), client, workspacePath, p, logger)
+		err := nxls.InitializeNxlsclient(cmd.Context(), client, workspacePath, p, logger, config)
 		if err != nil {
 			logger.Errorw("Failed to initialize nxlsclient", "error", err)
 		}
@@ -145,10 +154,52 @@ func validateWorkspacePath(path string, logger *zap.SugaredLogger) error {
 	return nil
 }
 
+// setupTracing installs the tracer provider writing to --trace-file, or to the traceFile
+// of the configuration, for every command. Execute shuts it down once the command returned.
+func setupTracing(cmd *cobra.Command, args []string) error {
+	path := traceFile
+	if path == "" {
+		path = config.LoadConfiguration().TraceFile
+	}
+	if path == "" {
+		return nil
+	}
+
+	provider, err := tracing.NewFileTracerProvider(path, "lazynx")
+	if err != nil {
+		return fmt.Errorf("error setting up tracing: %w", err)
+	}
+	otel.SetTracerProvider(provider)
+	shutdownTracing = func() {
+		_ = provider.Shutdown(context.Background())
+	}
+	return nil
+}
+
+// exitError makes Execute exit with code instead of 1.
+type exitError struct {
+	err  error
+	code int
+}
+
+func (e *exitError) Error() string {
+	return e.err.Error()
+}
+
+func (e *exitError) Unwrap() error {
+	return e.err
+}
+
 // Execute adds all child commands to the root command and sets flags appropriately.
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
+	err := rootCmd.Execute()
+	shutdownTracing()
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		var exitErr *exitError
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.code)
+		}
 		os.Exit(1)
 	}
 }
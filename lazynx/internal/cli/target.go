@@ -0,0 +1,85 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+
+	nxtypes "github.com/lazyengs/lazynx/pkg/nxlsclient/nx-types"
+	"github.com/lazyengs/lazynx/pkg/nxlsclient/targetconfig"
+	"github.com/lazyengs/lazynx/pkg/nxlsclient/taskgraph"
+	"github.com/spf13/cobra"
+)
+
+var targetCmd = &cobra.Command{
+	Use:   "target <project:target[:configuration]> [workspace-path]",
+	Short: "Print the effective configuration of a target",
+	Long: `Print the configuration a target runs with as JSON: the target with nx.json
+targetDefaults merged in, tokens such as {projectRoot} replaced, and the options of the
+given configuration, or of the default one, overlaid.`,
+	Example: `  lazynx target app:build
+  lazynx target app:build:development ./my-workspace`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: runTarget,
+}
+
+func init() {
+	rootCmd.AddCommand(targetCmd)
+}
+
+// effectiveTarget is the output of the target command.
+type effectiveTarget struct {
+	Project        string                      `json:"project"`
+	Target         string                      `json:"target"`
+	Executor       string                      `json:"executor,omitempty"`
+	TargetDefaults string                      `json:"targetDefaults,omitempty"`
+	Configuration  string                      `json:"configuration,omitempty"`
+	Options        nxtypes.Options             `json:"options"`
+	Config         nxtypes.TargetConfiguration `json:"config"`
+}
+
+func runTarget(cmd *cobra.Command, args []string) error {
+	session, err := startHeadless(cmd.Context(), args[1:])
+	if err != nil {
+		return err
+	}
+	defer session.close()
+
+	workspace, err := session.workspace(cmd.Context())
+	if err != nil {
+		return err
+	}
+	id, err := taskgraph.ResolveTaskID(args[0], workspace)
+	if err != nil {
+		return err
+	}
+
+	target, err := targetconfig.NewResolver(workspace).Resolve(id.Project, id.Target)
+	if err != nil {
+		return err
+	}
+	configuration := target.Configuration(id.Configuration)
+	options, err := target.Options(configuration)
+	if err != nil {
+		return err
+	}
+	if options == nil {
+		options = nxtypes.Options{}
+	}
+
+	session.logger.Infow("Resolved target", "target", id.String(), "targetDefaults", target.DefaultsKey)
+	encoder := json.NewEncoder(cmd.OutOrStdout())
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(effectiveTarget{
+		Project:        target.Project,
+		Target:         target.Name,
+		Executor:       target.Executor(),
+		TargetDefaults: target.DefaultsKey,
+		Configuration:  configuration,
+		Options:        options,
+		Config:         target.Config,
+	}); err != nil {
+		return fmt.Errorf("error writing the target: %w", err)
+	}
+	return nil
+}
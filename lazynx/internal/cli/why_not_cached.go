@@ -0,0 +1,101 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/lazyengs/lazynx/internal/nxls"
+	"github.com/lazyengs/lazynx/pkg/nxlsclient/inputs"
+	"github.com/lazyengs/lazynx/pkg/nxlsclient/taskgraph"
+	"github.com/spf13/cobra"
+)
+
+var whyNotCachedFlags struct {
+	save bool
+}
+
+var whyNotCachedCmd = &cobra.Command{
+	Use:   "why-not-cached <project:target> [workspace-path]",
+	Short: "Explain why a target misses the cache",
+	Long: `Compare the inputs of a target with the snapshot recorded after its last successful
+run and list the files, package versions and env vars that changed since.
+
+lazynx records a snapshot when a task run from it succeeds. Use --save to record one
+from the command line, for example after running the target in CI or a terminal.`,
+	Example: `  lazynx why-not-cached app:build
+  lazynx why-not-cached app:build --save
+  lazynx why-not-cached app:test ./my-workspace`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: runWhyNotCached,
+}
+
+func init() {
+	whyNotCachedCmd.Flags().BoolVar(&whyNotCachedFlags.save, "save", false, "Record the current inputs instead of comparing with them")
+
+	rootCmd.AddCommand(whyNotCachedCmd)
+}
+
+func runWhyNotCached(cmd *cobra.Command, args []string) error {
+	session, err := startHeadless(cmd.Context(), args[1:])
+	if err != nil {
+		return err
+	}
+	defer session.close()
+
+	workspace, err := session.workspace(cmd.Context())
+	if err != nil {
+		return err
+	}
+	id, err := taskgraph.ResolveTaskID(args[0], workspace)
+	if err != nil {
+		return err
+	}
+	name := id.Project + ":" + id.Target
+
+	store := nxls.OpenInputStore(session.config)
+	workspacePath := session.client.NxWorkspacePath
+	var before *inputs.Snapshot
+	if !whyNotCachedFlags.save {
+		before, err = store.Load(workspacePath, id.Project, id.Target)
+		if errors.Is(err, inputs.ErrNoSnapshot) {
+			cmd.SilenceUsage = true
+			return fmt.Errorf("no snapshot of %s yet, record one with --save after a successful run", name)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	resolver, err := nxls.NewInputsResolver(cmd.Context(), workspace, workspacePath, session.logger)
+	if err != nil {
+		return err
+	}
+	after, err := resolver.Snapshot(id.Project, id.Target)
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	if whyNotCachedFlags.save {
+		if err := store.Save(workspacePath, after); err != nil {
+			return err
+		}
+		session.logger.Infow("Recorded target inputs", "target", name, "files", len(after.Files))
+		fmt.Fprintf(out, "Recorded %d files, %d packages and %d env vars of %s\n",
+			len(after.Files), len(after.ExternalDependencies), len(after.Env), name)
+		return nil
+	}
+
+	diff := inputs.Compare(before, after)
+	session.logger.Infow("Compared target inputs", "target", name, "changes", diff.Summary())
+	since := before.TakenAt.Local().Format("2006-01-02 15:04")
+	if diff.Empty() {
+		fmt.Fprintf(out, "No input of %s changed since %s\nRuntime inputs or the outputs of dependency tasks may still differ\n", name, since)
+		return nil
+	}
+	fmt.Fprintf(out, "Inputs of %s changed since %s: %s\n", name, since, diff.Summary())
+	for _, line := range diff.Lines() {
+		fmt.Fprintln(out, "  "+line)
+	}
+	return nil
+}
@@ -5,6 +5,8 @@ import (
 	"errors"
 	"os"
 	"path/filepath"
+
+	"github.com/lazyengs/lazynx/pkg/nxlsclient/boundaries"
 )
 
 const (
@@ -14,11 +16,44 @@ const (
 
 type Config struct {
 	Logs string `json:"logs"`
+	// DaemonPolicy decides whether the Nx daemon is stopped on exit: "never", "if-started" or "always".
+	DaemonPolicy string `json:"daemonPolicy"`
+	// Watchdog configures the nxls health checks. Zero values use the nxlsclient defaults.
+	Watchdog WatchdogConfig `json:"watchdog"`
+	// CI configures the Nx Cloud pipeline poller. Zero values use the cipe defaults.
+	CI CIConfig `json:"ci"`
+	// MetricsAddress, if set, serves nxls metrics on /metrics (Prometheus) and /debug/vars (expvar).
+	MetricsAddress string `json:"metricsAddress"`
+	// TraceFile, if set, receives an OpenTelemetry span per line for start-up phases and nxls requests.
+	TraceFile string `json:"traceFile"`
+	// AffectedBase is the git ref the affected view compares against.
+	AffectedBase string `json:"affectedBase"`
+	// CacheDir holds the workspace snapshots shown while nxls starts.
+	CacheDir string `json:"cacheDir"`
+	// DepConstraints replaces the module boundary constraints read from the workspace eslint config.
+	DepConstraints []boundaries.DepConstraint `json:"depConstraints"`
+}
+
+type WatchdogConfig struct {
+	Disabled              bool `json:"disabled"`
+	RestartOnUnresponsive bool `json:"restartOnUnresponsive"`
+	IntervalSeconds       int  `json:"intervalSeconds"`
+	ProbeTimeoutSeconds   int  `json:"probeTimeoutSeconds"`
+	MaxRSSMB              int  `json:"maxRssMb"`
+}
+
+type CIConfig struct {
+	Disabled              bool `json:"disabled"`
+	ActiveIntervalSeconds int  `json:"activeIntervalSeconds"`
+	IdleIntervalSeconds   int  `json:"idleIntervalSeconds"`
 }
 
 func new() *Config {
 	return &Config{
-		Logs: getDefaultLogFile(),
+		Logs:         getDefaultLogFile(),
+		DaemonPolicy: "if-started",
+		AffectedBase: "main",
+		CacheDir:     getDefaultCacheDir(),
 	}
 }
 
@@ -59,6 +94,48 @@ func (c *Config) overrideWith(target *Config) *Config {
 		if target.Logs != "" {
 			result.Logs = target.Logs
 		}
+		if target.DaemonPolicy != "" {
+			result.DaemonPolicy = target.DaemonPolicy
+		}
+		if target.AffectedBase != "" {
+			result.AffectedBase = target.AffectedBase
+		}
+		if target.CacheDir != "" {
+			result.CacheDir = target.CacheDir
+		}
+		if target.DepConstraints != nil {
+			result.DepConstraints = target.DepConstraints
+		}
+		if target.TraceFile != "" {
+			result.TraceFile = target.TraceFile
+		}
+		if target.MetricsAddress != "" {
+			result.MetricsAddress = target.MetricsAddress
+		}
+		if target.Watchdog.Disabled {
+			result.Watchdog.Disabled = true
+		}
+		if target.Watchdog.RestartOnUnresponsive {
+			result.Watchdog.RestartOnUnresponsive = true
+		}
+		if target.Watchdog.IntervalSeconds > 0 {
+			result.Watchdog.IntervalSeconds = target.Watchdog.IntervalSeconds
+		}
+		if target.Watchdog.ProbeTimeoutSeconds > 0 {
+			result.Watchdog.ProbeTimeoutSeconds = target.Watchdog.ProbeTimeoutSeconds
+		}
+		if target.Watchdog.MaxRSSMB > 0 {
+			result.Watchdog.MaxRSSMB = target.Watchdog.MaxRSSMB
+		}
+		if target.CI.Disabled {
+			result.CI.Disabled = true
+		}
+		if target.CI.ActiveIntervalSeconds > 0 {
+			result.CI.ActiveIntervalSeconds = target.CI.ActiveIntervalSeconds
+		}
+		if target.CI.IdleIntervalSeconds > 0 {
+			result.CI.IdleIntervalSeconds = target.CI.IdleIntervalSeconds
+		}
 	}
 
 	return &result
@@ -80,3 +157,8 @@ func getDefaultLogFile() string {
 	homeDir, _ := getHomeDir()
 	return filepath.Join(homeDir, AppName, "logs", "lazynx.log")
 }
+
+func getDefaultCacheDir() string {
+	homeDir, _ := getHomeDir()
+	return filepath.Join(homeDir, AppName, "cache")
+}
@@ -3,17 +3,54 @@ package nxls
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"expvar"
+	"net/http"
+	"os"
 	"path/filepath"
+	"slices"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea/v2"
 	"github.com/lazyengs/lazynx/internal/config"
 	"github.com/lazyengs/lazynx/internal/logs"
+	affectedmodel "github.com/lazyengs/lazynx/internal/tui/models/affected"
+	boundariesmodel "github.com/lazyengs/lazynx/internal/tui/models/boundaries"
+	cimodel "github.com/lazyengs/lazynx/internal/tui/models/ci"
+	foldersmodel "github.com/lazyengs/lazynx/internal/tui/models/folders"
+	generatemodel "github.com/lazyengs/lazynx/internal/tui/models/generate"
+	inputsmodel "github.com/lazyengs/lazynx/internal/tui/models/inputs"
+	tasksmodel "github.com/lazyengs/lazynx/internal/tui/models/tasks"
 	"github.com/lazyengs/lazynx/pkg/nxlsclient"
+	"github.com/lazyengs/lazynx/pkg/nxlsclient/affected"
+	"github.com/lazyengs/lazynx/pkg/nxlsclient/boundaries"
+	"github.com/lazyengs/lazynx/pkg/nxlsclient/cipe"
 	"github.com/lazyengs/lazynx/pkg/nxlsclient/commands"
+	"github.com/lazyengs/lazynx/pkg/nxlsclient/foldertree"
+	"github.com/lazyengs/lazynx/pkg/nxlsclient/generator"
+	"github.com/lazyengs/lazynx/pkg/nxlsclient/gitfiles"
+	"github.com/lazyengs/lazynx/pkg/nxlsclient/inputs"
+	"github.com/lazyengs/lazynx/pkg/nxlsclient/metrics"
+	nxtypes "github.com/lazyengs/lazynx/pkg/nxlsclient/nx-types"
+	"github.com/lazyengs/lazynx/pkg/nxlsclient/runner"
+	"github.com/lazyengs/lazynx/pkg/nxlsclient/snapshot"
+	"github.com/lazyengs/lazynx/pkg/nxlsclient/taskgraph"
 	"go.lsp.dev/protocol"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
 	"go.uber.org/zap"
 )
 
+var (
+	// ErrNotRunning is reported by commands that need nxls while it is not running.
+	ErrNotRunning = errors.New("the Nx language server is not running")
+	// ErrNoWorkspace is reported by commands that need the workspace before it loaded.
+	ErrNoWorkspace = errors.New("the workspace is not loaded yet")
+)
+
+// tracerName is the instrumentation name of the spans created by lazynx.
+const tracerName = "github.com/lazyengs/lazynx"
+
 func CreateNxlsclient(logger *zap.SugaredLogger, config *config.Config) *nxlsclient.Client {
 	// Setup separate logger for nxlsclient
 	nxlsclientLogFile := filepath.Join(filepath.Dir(config.Logs), "nxlsclient.log")
@@ -28,27 +65,625 @@ func CreateNxlsclient(logger *zap.SugaredLogger, config *config.Config) *nxlscli
 	client := nxlsclient.NewClientWithLogger(currentNxWorkspacePath, true, nxlsclientLogger)
 	logger.Infow("Created nxlsclient", "workspacePath", currentNxWorkspacePath)
 
+	daemonPolicy, err := nxlsclient.ParseDaemonPolicy(config.DaemonPolicy)
+	if err != nil {
+		logger.Warnw("Invalid daemonPolicy in configuration, using default", "error", err, "default", nxlsclient.DefaultDaemonPolicy)
+		daemonPolicy = nxlsclient.DefaultDaemonPolicy
+	}
+	client.DaemonPolicy = daemonPolicy
+
+	client.Metrics = metrics.NewRegistry()
+	if config.MetricsAddress != "" {
+		serveMetrics(config.MetricsAddress, client.Metrics, logger)
+	}
+
 	return client
 }
 
-func InitializeNxlsclient(ctx context.Context, client *nxlsclient.Client, workspacePath string, p *tea.Program, logger *zap.SugaredLogger) error {
-	// Update workspace path if different
-	if workspacePath != "" {
-		absPath, err := filepath.Abs(workspacePath)
+// serveMetrics exposes the client metrics over HTTP in the background.
+func serveMetrics(address string, registry *metrics.Registry, logger *zap.SugaredLogger) {
+	registry.PublishExpvar("nxls")
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", registry.Handler())
+	mux.Handle("/debug/vars", expvar.Handler())
+
+	go func() {
+		logger.Infow("Serving nxls metrics", "address", address)
+		if err := http.ListenAndServe(address, mux); err != nil {
+			logger.Errorw("Metrics server stopped", "address", address, "error", err)
+		}
+	}()
+}
+
+// InitializeNxlsclient runs nxls for workspacePath until ctx is done and sends p a
+// ClientMsg for every session. client only holds the settings of the session clients,
+// it is never started itself.
+func InitializeNxlsclient(ctx context.Context, client *nxlsclient.Client, workspacePath string, p *tea.Program, logger *zap.SugaredLogger, config *config.Config) error {
+	if workspacePath == "" {
+		workspacePath = client.NxWorkspacePath
+	}
+	absPath, err := filepath.Abs(workspacePath)
+	if err != nil {
+		return err
+	}
+	return runSessions(ctx, client, absPath, p, logger, config, healthThresholds(config.Watchdog), startClient)
+}
+
+// sender delivers messages to the TUI, it is a *tea.Program outside of tests.
+type sender interface {
+	Send(msg tea.Msg)
+}
+
+// startFunc starts a client and waits until it is initialized, like startClient.
+type startFunc func(ctx context.Context, client *nxlsclient.Client, p sender, logger *zap.SugaredLogger) (*commands.InitializeRequestResult, error)
+
+// ClientMsg hands the TUI the client of a new nxls session, which commands must use
+// from then on. The client of the previous session is stopped.
+type ClientMsg struct {
+	Client *nxlsclient.Client
+}
+
+// InitFailedMsg reports that nxls could not be started, for example because Node.js is
+// missing. Nothing works without it, so the TUI shows Err instead of the workspace.
+type InitFailedMsg struct {
+	Err error
+}
+
+// runSessions runs nxls sessions until ctx is done, restarting nxls when the watchdog
+// finds it unresponsive. Every session gets a fresh client copied from template, so a
+// restart never touches a client that requests still in flight are using.
+func runSessions(ctx context.Context, template *nxlsclient.Client, workspacePath string, p sender, logger *zap.SugaredLogger, config *config.Config, thresholds nxlsclient.HealthThresholds, start startFunc) error {
+	for {
+		client := newSessionClient(template, workspacePath)
+		sessionCtx, cancel := context.WithCancel(ctx)
+
+		startupCtx, startup := otel.Tracer(tracerName).Start(sessionCtx, "lazynx.startup")
+		res, err := start(startupCtx, client, p, logger)
 		if err != nil {
+			startup.RecordError(err)
+			startup.SetStatus(codes.Error, err.Error())
+			startup.End()
+			cancel()
+			p.Send(InitFailedMsg{Err: err})
 			return err
 		}
-		client.NxWorkspacePath = absPath
+		logger.Debugw("Received initialization result", "result", res)
+		p.Send(ClientMsg{Client: client})
+		p.Send(tea.Msg(res))
+
+		workspace := loadWorkspace(startupCtx, client, p, logger)
+		startup.End()
+
+		switch {
+		case config.CI.Disabled:
+		// A workspace that failed to load may still be connected, the poller backs off then
+		case workspace != nil && !cipe.Connected(workspace.NxJson, os.LookupEnv):
+			logger.Infow("Not polling CI pipelines, the workspace is not connected to Nx Cloud")
+			p.Send(cimodel.NotConnectedMsg{})
+		default:
+			poller := cipe.NewPoller(client.Commander.SendRecentCIPEDataRequest, pollIntervals(config.CI), func(update cipe.Update) {
+				logCIUpdate(logger, update)
+				p.Send(tea.Msg(update))
+			})
+			go poller.Run(sessionCtx)
+		}
+
+		if config.Watchdog.Disabled {
+			<-ctx.Done()
+			cancel()
+			return nil
+		}
+
+		// Health reports go to the TUI; unresponsive ones may also trigger a restart
+		restartCh := make(chan nxlsclient.HealthReport, 1)
+		watchdog := nxlsclient.NewWatchdog(client, thresholds, func(report nxlsclient.HealthReport) {
+			p.Send(tea.Msg(report))
+			if report.Status == nxlsclient.HealthStatusUnresponsive && config.Watchdog.RestartOnUnresponsive {
+				select {
+				case restartCh <- report:
+				default:
+				}
+			}
+		})
+		go watchdog.Run(sessionCtx)
+
+		select {
+		case <-ctx.Done():
+			cancel()
+			return nil
+		case report := <-restartCh:
+			logger.Warnw("nxls is unresponsive, restarting", "reasons", report.Reasons, "failures", report.ConsecutiveFailures)
+			client.Stop(sessionCtx)
+			cancel()
+		}
 	}
+}
+
+// newSessionClient returns an unstarted client with the settings of template.
+func newSessionClient(template *nxlsclient.Client, workspacePath string) *nxlsclient.Client {
+	client := nxlsclient.NewClientWithLogger(workspacePath, true, template.Logger)
+	client.DaemonPolicy = template.DaemonPolicy
+	client.ShutdownTimeouts = template.ShutdownTimeouts
+	client.Metrics = template.Metrics
+	client.TracerProvider = template.TracerProvider
+	return client
+}
+
+// WorkspaceMsg carries a workspace loaded from nxls, or from the snapshot of the last
+// session when Stale is set.
+type WorkspaceMsg struct {
+	Workspace *nxtypes.NxWorkspace
+	Err       error
+	Stale     bool
+	SavedAt   time.Time // SavedAt is when a stale workspace was saved.
+}
+
+// OpenSnapshotStore returns the store of workspace snapshots under the cache directory.
+func OpenSnapshotStore(config *config.Config) *snapshot.Store {
+	return snapshot.NewStore(filepath.Join(config.CacheDir, "workspaces"))
+}
+
+// LoadSnapshot returns a command that reports the snapshot saved by the last session as
+// a stale WorkspaceMsg. It reports nothing when there is no usable snapshot.
+func LoadSnapshot(store *snapshot.Store, workspacePath string, logger *zap.SugaredLogger) tea.Cmd {
+	return func() tea.Msg {
+		snap, err := store.Load(workspacePath)
+		if err != nil {
+			// A missing snapshot is the normal first start, anything else is worth a log line
+			if !errors.Is(err, snapshot.ErrNoSnapshot) {
+				logger.Infow("Not using the workspace snapshot", "reason", err)
+			}
+			return nil
+		}
+
+		logger.Infow("Loaded workspace snapshot", "savedAt", snap.SavedAt, "projects", len(snap.Workspace.ProjectGraph.Nodes))
+		return WorkspaceMsg{Workspace: snap.Workspace, Stale: true, SavedAt: snap.SavedAt}
+	}
+}
+
+// SaveSnapshot returns a command that saves workspace for the next session.
+func SaveSnapshot(store *snapshot.Store, workspacePath string, workspace *nxtypes.NxWorkspace, logger *zap.SugaredLogger) tea.Cmd {
+	return func() tea.Msg {
+		if err := store.Save(workspacePath, workspace); err != nil {
+			logger.Warnw("Failed to save the workspace snapshot", "error", err)
+		}
+		return nil
+	}
+}
 
+// loadWorkspace warms the nxls workspace cache so the first view does not wait for it,
+// and hands the snapshot to the TUI. It returns nil when the workspace failed to load.
+func loadWorkspace(ctx context.Context, client *nxlsclient.Client, p sender, logger *zap.SugaredLogger) *nxtypes.NxWorkspace {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "lazynx.loadWorkspace")
+	defer span.End()
+
+	msg := fetchWorkspace(ctx, client, logger)
+	if msg.Err != nil {
+		span.RecordError(msg.Err)
+		span.SetStatus(codes.Error, msg.Err.Error())
+		return nil
+	}
+	p.Send(msg)
+	return msg.Workspace
+}
+
+// ReloadWorkspace returns a command that loads the workspace again, for example after
+// nx/refreshWorkspace, and reports it as a WorkspaceMsg.
+func ReloadWorkspace(ctx context.Context, client *nxlsclient.Client, logger *zap.SugaredLogger) tea.Cmd {
+	return func() tea.Msg {
+		if client.Commander == nil {
+			return WorkspaceMsg{Err: ErrNotRunning}
+		}
+		return fetchWorkspace(ctx, client, logger)
+	}
+}
+
+func fetchWorkspace(ctx context.Context, client *nxlsclient.Client, logger *zap.SugaredLogger) WorkspaceMsg {
+	workspace, err := client.Commander.SendWorkspaceRequest(ctx, &commands.WorkspaceRequestParams{})
+	if err == nil && workspace == nil {
+		err = errors.New("empty workspace response")
+	}
+	if err != nil {
+		logger.Warnw("Failed to load workspace", "error", err)
+		return WorkspaceMsg{Err: err}
+	}
+
+	logger.Infow("Loaded workspace", "projects", len(workspace.ProjectGraph.Nodes), "nxVersion", workspace.NxVersion.Full)
+	return WorkspaceMsg{Workspace: workspace}
+}
+
+// ComputeAffected returns a command that computes the projects affected by the changes
+// since base and reports them as an affected.ResultMsg.
+func ComputeAffected(ctx context.Context, client *nxlsclient.Client, base string, logger *zap.SugaredLogger) tea.Cmd {
+	return func() tea.Msg {
+		if client.Commander == nil {
+			return affectedmodel.ResultMsg{Err: ErrNotRunning}
+		}
+
+		result, err := affected.Compute(ctx, client.Commander, client.NxWorkspacePath, affected.Options{Base: base})
+		if err != nil {
+			logger.Warnw("Failed to compute affected projects", "base", base, "error", err)
+			return affectedmodel.ResultMsg{Err: err}
+		}
+
+		logger.Debugw("Computed affected projects", "base", base, "projects", len(result.Projects), "files", len(result.ChangedFiles))
+		return affectedmodel.ResultMsg{Result: result}
+	}
+}
+
+// LoadDepConstraints returns the module boundary constraints of the lazynx configuration,
+// or else the ones of the workspace eslint config, with where they came from.
+func LoadDepConstraints(workspacePath string, config *config.Config) ([]boundaries.DepConstraint, string, error) {
+	if config.DepConstraints != nil {
+		return config.DepConstraints, "lazynx configuration", nil
+	}
+
+	constraints, source, err := boundaries.LoadESLintConstraints(workspacePath)
+	if err != nil {
+		return nil, source, err
+	}
+	if rel, err := filepath.Rel(workspacePath, source); err == nil {
+		source = rel
+	}
+	return constraints, source, nil
+}
+
+// CheckBoundaries returns a command that checks the module boundaries of workspace and
+// reports the violations as a boundaries.ResultMsg.
+func CheckBoundaries(workspace *nxtypes.NxWorkspace, workspacePath string, config *config.Config, logger *zap.SugaredLogger) tea.Cmd {
+	return func() tea.Msg {
+		if workspace == nil {
+			return boundariesmodel.ResultMsg{Err: ErrNoWorkspace}
+		}
+
+		constraints, source, err := LoadDepConstraints(workspacePath, config)
+		if err != nil {
+			logger.Warnw("Failed to load module boundary constraints", "error", err)
+			return boundariesmodel.ResultMsg{Source: source, Err: err}
+		}
+
+		violations := boundaries.Check(&workspace.ProjectGraph, constraints)
+		logger.Debugw("Checked module boundaries", "source", source, "constraints", len(constraints), "violations", len(violations))
+		return boundariesmodel.ResultMsg{Violations: violations, Source: source, Constraints: len(constraints)}
+	}
+}
+
+// LoadFolderTree returns a command that loads the project folder tree of the workspace
+// as a folders.ResultMsg.
+func LoadFolderTree(ctx context.Context, client *nxlsclient.Client, logger *zap.SugaredLogger) tea.Cmd {
+	return func() tea.Msg {
+		if client.Commander == nil {
+			return foldersmodel.ResultMsg{Err: ErrNotRunning}
+		}
+
+		result, err := client.Commander.SendProjectFolderTreeRequest(ctx)
+		if err != nil {
+			logger.Warnw("Failed to load the project folder tree", "error", err)
+			return foldersmodel.ResultMsg{Err: err}
+		}
+
+		tree := foldertree.New(result)
+		logger.Debugw("Loaded the project folder tree", "folders", tree.Len(), "projects", tree.ProjectCount())
+		return foldersmodel.ResultMsg{Tree: tree}
+	}
+}
+
+// OpenInputStore returns the store of target input snapshots under the cache directory.
+func OpenInputStore(config *config.Config) *inputs.Store {
+	return inputs.NewStore(filepath.Join(config.CacheDir, "inputs"))
+}
+
+// NewInputsResolver returns a resolver of the target inputs of workspace, matching
+// {workspaceRoot} filesets against the files of workspacePath.
+func NewInputsResolver(ctx context.Context, workspace *nxtypes.NxWorkspace, workspacePath string, logger *zap.SugaredLogger) (*inputs.Resolver, error) {
+	if workspace == nil {
+		return nil, ErrNoWorkspace
+	}
+
+	resolver, err := inputs.NewResolver(workspace)
+	if err != nil {
+		return nil, err
+	}
+	resolver.Root = workspacePath
+	// {workspaceRoot} filesets can match files outside every project
+	if files, err := gitfiles.List(ctx, workspacePath); err != nil {
+		logger.Warnw("Failed to list workspace files, {workspaceRoot} inputs only match project files", "error", err)
+	} else {
+		resolver.WorkspaceFiles = files
+	}
+	return resolver, nil
+}
+
+// ResolveInputs returns a command that expands the inputs of project:target into the
+// files, env vars and packages they cover, as an inputs.ResultMsg.
+func ResolveInputs(ctx context.Context, workspace *nxtypes.NxWorkspace, workspacePath, project, target string, logger *zap.SugaredLogger) tea.Cmd {
+	return func() tea.Msg {
+		resolver, err := NewInputsResolver(ctx, workspace, workspacePath, logger)
+		if err != nil {
+			return inputsmodel.ResultMsg{Err: err}
+		}
+
+		result, err := resolver.Resolve(project, target)
+		if err != nil {
+			logger.Warnw("Failed to resolve target inputs", "target", project+":"+target, "error", err)
+			return inputsmodel.ResultMsg{Err: err}
+		}
+		logger.Debugw("Resolved target inputs", "target", project+":"+target, "files", len(result.Files), "projects", len(result.Projects))
+		return inputsmodel.ResultMsg{Result: result}
+	}
+}
+
+// SaveInputSnapshot returns a command that records the current inputs of project:target,
+// as an inputs.SnapshotMsg.
+func SaveInputSnapshot(ctx context.Context, store *inputs.Store, workspace *nxtypes.NxWorkspace, workspacePath, project, target string, logger *zap.SugaredLogger) tea.Cmd {
+	return func() tea.Msg {
+		id := project + ":" + target
+		resolver, err := NewInputsResolver(ctx, workspace, workspacePath, logger)
+		if err != nil {
+			return inputsmodel.SnapshotMsg{Target: id, Err: err}
+		}
+		snap, err := resolver.Snapshot(project, target)
+		if err == nil {
+			err = store.Save(workspacePath, snap)
+		}
+		if err != nil {
+			logger.Warnw("Failed to record target inputs", "target", id, "error", err)
+		}
+		return inputsmodel.SnapshotMsg{Target: id, Err: err}
+	}
+}
+
+// SaveRunSnapshots returns a command that records the inputs of the tasks that succeeded
+// in a run, so a later cache miss can be explained. It reports nothing.
+func SaveRunSnapshots(ctx context.Context, store *inputs.Store, workspace *nxtypes.NxWorkspace, workspacePath string, tasks []runner.Task, logger *zap.SugaredLogger) tea.Cmd {
+	return func() tea.Msg {
+		var resolver *inputs.Resolver
+		for _, task := range tasks {
+			if task.Status != runner.TaskSucceeded && task.Status != runner.TaskCached {
+				continue
+			}
+			if resolver == nil {
+				var err error
+				if resolver, err = NewInputsResolver(ctx, workspace, workspacePath, logger); err != nil {
+					logger.Debugw("Not recording the inputs of the run", "reason", err)
+					return nil
+				}
+			}
+			id, err := taskgraph.ResolveTaskID(task.ID, workspace)
+			if err != nil {
+				continue
+			}
+
+			snap, err := resolver.Snapshot(id.Project, id.Target)
+			if err == nil {
+				err = store.Save(workspacePath, snap)
+			}
+			if err != nil {
+				logger.Warnw("Failed to record target inputs", "target", task.ID, "error", err)
+			}
+		}
+		return nil
+	}
+}
+
+// WhyNotCached returns a command that compares the inputs of project:target with its
+// last snapshot, as an inputs.DiffMsg.
+func WhyNotCached(ctx context.Context, store *inputs.Store, workspace *nxtypes.NxWorkspace, workspacePath, project, target string, logger *zap.SugaredLogger) tea.Cmd {
+	return func() tea.Msg {
+		id := project + ":" + target
+		before, err := store.Load(workspacePath, project, target)
+		if err != nil {
+			return inputsmodel.DiffMsg{Target: id, Err: err}
+		}
+		resolver, err := NewInputsResolver(ctx, workspace, workspacePath, logger)
+		if err != nil {
+			return inputsmodel.DiffMsg{Target: id, Err: err}
+		}
+		after, err := resolver.Snapshot(project, target)
+		if err != nil {
+			return inputsmodel.DiffMsg{Target: id, Err: err}
+		}
+
+		diff := inputs.Compare(before, after)
+		logger.Debugw("Compared target inputs", "target", id, "changes", diff.Summary())
+		return inputsmodel.DiffMsg{Target: id, Before: before, Diff: diff}
+	}
+}
+
+// LoadGenerators returns a command that lists the generators of the workspace as a
+// generate.GeneratorsMsg.
+func LoadGenerators(ctx context.Context, client *nxlsclient.Client, logger *zap.SugaredLogger) tea.Cmd {
+	return func() tea.Msg {
+		if client.Commander == nil {
+			return generatemodel.GeneratorsMsg{Err: ErrNotRunning}
+		}
+
+		generators, err := client.Commander.SendGeneratorsRequest(ctx, commands.GeneratorsRequestParams{})
+		if err != nil {
+			logger.Warnw("Failed to load generators", "error", err)
+			return generatemodel.GeneratorsMsg{Err: err}
+		}
+		return generatemodel.GeneratorsMsg{Generators: generators}
+	}
+}
+
+// LoadGeneratorOptions returns a command that loads the options of a generator as a
+// generate.OptionsMsg.
+func LoadGeneratorOptions(ctx context.Context, client *nxlsclient.Client, info nxtypes.GeneratorCollectionInfo, logger *zap.SugaredLogger) tea.Cmd {
+	return func() tea.Msg {
+		if client.Commander == nil {
+			return generatemodel.OptionsMsg{Err: ErrNotRunning}
+		}
+
+		options, err := client.Commander.SendGeneratorOptionsRequest(ctx, commands.GeneratorOptionsRequestParams{
+			Options: commands.GeneratorOptionsRequestOptions{
+				Collection: info.CollectionName,
+				Name:       info.Name,
+				Path:       info.SchemaPath,
+			},
+		})
+		if err != nil {
+			logger.Warnw("Failed to load generator options", "generator", info.CollectionName+":"+info.Name, "error", err)
+			return generatemodel.OptionsMsg{Generator: info, Err: err}
+		}
+		return generatemodel.OptionsMsg{Generator: info, Options: options}
+	}
+}
+
+// RunGenerator returns a command that runs a generator in the workspace, reporting a dry
+// run as a generate.PreviewMsg and a real run as a generate.AppliedMsg.
+func RunGenerator(ctx context.Context, workspacePath string, inv generator.Invocation, dryRun bool, logger *zap.SugaredLogger) tea.Cmd {
+	return func() tea.Msg {
+		runner := generator.NewRunner(workspacePath)
+		if !dryRun {
+			result, err := runner.Apply(ctx, inv)
+			logGeneratorRun(logger, inv, result, err)
+			return generatemodel.AppliedMsg{Result: result, Err: err}
+		}
+		result, err := runner.DryRun(ctx, inv)
+		logGeneratorRun(logger, inv, result, err)
+		return generatemodel.PreviewMsg{Result: result, Err: err}
+	}
+}
+
+func logGeneratorRun(logger *zap.SugaredLogger, inv generator.Invocation, result *generator.Result, err error) {
+	if err != nil {
+		logger.Warnw("Generator failed", "command", inv.String(), "error", err)
+		return
+	}
+	logger.Infow("Ran generator", "command", inv.String(), "dryRun", result.DryRun, "changes", len(result.Changes), "duration", result.Duration)
+}
+
+// tasksRefreshInterval caps how often the task list redraws while nx writes output.
+const tasksRefreshInterval = 100 * time.Millisecond
+
+// StartTasks returns a command that starts many in the workspace and reports it as a
+// tasks.StartedMsg. The tasks the workspace defines for the selected projects are
+// listed as pending until nx reports them.
+func StartTasks(ctx context.Context, workspacePath string, many runner.Many, workspace *nxtypes.NxWorkspace, logger *zap.SugaredLogger) tea.Cmd {
+	return func() tea.Msg {
+		tasks := runner.NewTasks(0)
+		tasks.Expect(expectedTasks(workspace, many)...)
+
+		run, err := runner.NewRunner(workspacePath).StartTasks(ctx, many.NxArgs(), tasks)
+		if err != nil {
+			logger.Warnw("Failed to start tasks", "args", many.NxArgs(), "error", err)
+			return tasksmodel.StartedMsg{Err: err}
+		}
+		logger.Infow("Started tasks", "args", run.Args, "pid", run.PID, "pty", run.PTY)
+		return tasksmodel.StartedMsg{Run: run, Tasks: tasks}
+	}
+}
+
+// RerunTask returns a command that runs task of tasks again with nx run, reporting the
+// new run as a tasks.StartedMsg.
+func RerunTask(ctx context.Context, workspacePath string, tasks *runner.Tasks, task runner.Task, logger *zap.SugaredLogger) tea.Cmd {
+	return func() tea.Msg {
+		tasks.Expect(task.ID)
+		run, err := runner.NewRunner(workspacePath).StartTasks(ctx, runner.TargetArgs(task.Target()), tasks)
+		if err != nil {
+			logger.Warnw("Failed to rerun task", "task", task.ID, "error", err)
+			return tasksmodel.StartedMsg{Err: err}
+		}
+		logger.Infow("Rerunning task", "task", task.ID, "pid", run.PID)
+		return tasksmodel.StartedMsg{Run: run, Tasks: tasks}
+	}
+}
+
+// WatchTasks returns a command that waits for the tasks of run to change, reported as a
+// tasks.ChangedMsg, or for run to finish, reported as a tasks.DoneMsg. The program
+// watches again after every change.
+func WatchTasks(run *runner.Run, tasks *runner.Tasks, logger *zap.SugaredLogger) tea.Cmd {
+	return func() tea.Msg {
+		select {
+		case <-tasks.Changed():
+			// Let a burst of output land before redrawing
+			time.Sleep(tasksRefreshInterval)
+			return tasksmodel.ChangedMsg{Run: run, Tasks: tasks}
+		case <-run.Done():
+			result, err := run.Wait()
+			logger.Infow("Tasks finished", "args", run.Args, "exitCode", result.ExitCode, "duration", result.Duration,
+				"canceled", result.Canceled, "failed", tasks.Failed())
+			return tasksmodel.DoneMsg{Run: run, Tasks: tasks, Result: result, Err: err}
+		}
+	}
+}
+
+// expectedTasks returns the tasks many runs according to the workspace, or nothing when
+// that depends on more than the project configuration: affected projects, project
+// patterns and configurations are resolved by nx.
+func expectedTasks(workspace *nxtypes.NxWorkspace, many runner.Many) []string {
+	if workspace == nil || many.Affected || many.Configuration != "" {
+		return nil
+	}
+
+	projects := many.Projects
+	if len(projects) == 0 {
+		for name := range workspace.ProjectGraph.Nodes {
+			projects = append(projects, name)
+		}
+		slices.Sort(projects)
+	}
+
+	var ids []string
+	for _, name := range projects {
+		node, ok := workspace.ProjectGraph.Nodes[name]
+		if !ok {
+			// A pattern such as tag:scope:shared or apps/*
+			return nil
+		}
+		if slices.Contains(many.Exclude, name) {
+			continue
+		}
+		for _, target := range many.Targets {
+			if _, ok := node.Data.Targets[target]; ok {
+				ids = append(ids, name+":"+target)
+			}
+		}
+	}
+	return ids
+}
+
+// StartHeadless starts a client for workspacePath without the TUI, for one-shot
+// subcommands. The returned stop function shuts the client down.
+func StartHeadless(ctx context.Context, workspacePath string, logger *zap.SugaredLogger, config *config.Config) (*nxlsclient.Client, func(), error) {
+	client := CreateNxlsclient(logger, config)
+	absPath, err := filepath.Abs(workspacePath)
+	if err != nil {
+		return nil, nil, err
+	}
+	client.NxWorkspacePath = absPath
+
+	sessionCtx, cancel := context.WithCancel(ctx)
+	if _, err := startClient(sessionCtx, client, nil, logger); err != nil {
+		cancel()
+		return nil, nil, err
+	}
+
+	stop := func() {
+		client.Stop(context.WithoutCancel(ctx))
+		cancel()
+	}
+	return client, stop, nil
+}
+
+// startClient starts the client in the background and waits until it is initialized
+// or fails to start. Refresh notifications are forwarded to p unless it is nil.
+func startClient(ctx context.Context, client *nxlsclient.Client, p sender, logger *zap.SugaredLogger) (*commands.InitializeRequestResult, error) {
 	// Channel for initialization result
 	ch := make(chan *commands.InitializeRequestResult)
+	// Channel for errors that prevent the client from ever initializing
+	errCh := make(chan error, 1)
 
-	client.OnNotification(commands.RefreshWorkspaceNotificationMethod, func(method string, params json.RawMessage) error {
-		logger.Debugw("Received refresh workspace notification", "method", method)
-		p.Send(tea.Msg(commands.RefreshWorkspaceNotificationMethod))
-		return nil
-	})
+	// Handlers are cleared on Stop, so register them for every session
+	if p != nil {
+		client.OnNotification(commands.RefreshWorkspaceNotificationMethod, func(method string, params json.RawMessage) error {
+			logger.Debugw("Received refresh workspace notification", "method", method)
+			p.Send(tea.Msg(commands.RefreshWorkspaceNotificationMethod))
+			return nil
+		})
+	}
 
 	// Start client in a goroutine
 	go func() {
@@ -68,13 +703,61 @@ func InitializeNxlsclient(ctx context.Context, client *nxlsclient.Client, worksp
 		err := client.Start(ctx, params, ch)
 		if err != nil {
 			logger.Errorw("Error starting client", "error", err)
+			errCh <- err
 		}
 	}()
 
-	res := <-ch
+	select {
+	case res, ok := <-ch:
+		if !ok || res == nil {
+			// Start closes the channel without a result when initialize fails
+			return nil, <-errCh
+		}
+		return res, nil
+	case err := <-errCh:
+		return nil, err
+	}
+}
 
-	logger.Debugw("Received initialization result", "result", res)
-	p.Send(tea.Msg(res))
+// healthThresholds converts the watchdog configuration into nxlsclient thresholds.
+func healthThresholds(cfg config.WatchdogConfig) nxlsclient.HealthThresholds {
+	thresholds := nxlsclient.DefaultHealthThresholds
+	if cfg.IntervalSeconds > 0 {
+		thresholds.Interval = time.Duration(cfg.IntervalSeconds) * time.Second
+	}
+	if cfg.ProbeTimeoutSeconds > 0 {
+		thresholds.ProbeTimeout = time.Duration(cfg.ProbeTimeoutSeconds) * time.Second
+	}
+	if cfg.MaxRSSMB > 0 {
+		thresholds.MaxRSSBytes = uint64(cfg.MaxRSSMB) << 20
+	}
+	return thresholds
+}
 
-	return nil
+func pollIntervals(cfg config.CIConfig) cipe.PollIntervals {
+	intervals := cipe.DefaultPollIntervals
+	if cfg.ActiveIntervalSeconds > 0 {
+		intervals.Active = time.Duration(cfg.ActiveIntervalSeconds) * time.Second
+	}
+	if cfg.IdleIntervalSeconds > 0 {
+		intervals.Idle = time.Duration(cfg.IdleIntervalSeconds) * time.Second
+	}
+	return intervals
+}
+
+func logCIUpdate(logger *zap.SugaredLogger, update cipe.Update) {
+	if update.Err != nil {
+		// Only the first failure in a row is worth the log, the next ones back off quietly
+		log := logger.Infow
+		if update.ConsecutiveFailures > 1 {
+			log = logger.Debugw
+		}
+		log("Failed to poll CI pipelines", "type", update.ErrorType(), "error", update.Err,
+			"failures", update.ConsecutiveFailures, "next", update.Next)
+		return
+	}
+	for _, event := range update.Events {
+		logger.Infow("CI pipeline changed", "event", event.String(), "url", event.Pipeline.CipeUrl)
+	}
+	logger.Debugw("Polled CI pipelines", "pipelines", len(update.Pipelines), "next", update.Next)
 }
@@ -0,0 +1,148 @@
+package nxls
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/lazyengs/lazynx/internal/config"
+	"github.com/lazyengs/lazynx/pkg/nxlsclient"
+	"github.com/lazyengs/lazynx/pkg/nxlsclient/commands"
+	"github.com/sourcegraph/jsonrpc2"
+	"go.uber.org/zap"
+)
+
+// fakeProgram records the messages sent to the TUI.
+type fakeProgram struct {
+	mu      sync.Mutex
+	clients []*nxlsclient.Client
+}
+
+func (p *fakeProgram) Send(msg tea.Msg) {
+	if msg, ok := msg.(ClientMsg); ok {
+		p.mu.Lock()
+		p.clients = append(p.clients, msg.Client)
+		p.mu.Unlock()
+	}
+}
+
+// client returns the client of the latest session and how many sessions started.
+func (p *fakeProgram) client() (*nxlsclient.Client, int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.clients) == 0 {
+		return nil, 0
+	}
+	return p.clients[len(p.clients)-1], len(p.clients)
+}
+
+// fakeStart connects clients to in-memory servers. The first server fails every
+// liveness probe so the watchdog restarts it, the others are healthy.
+func fakeStart() startFunc {
+	var mu sync.Mutex
+	sessions := 0
+
+	return func(ctx context.Context, client *nxlsclient.Client, p sender, logger *zap.SugaredLogger) (*commands.InitializeRequestResult, error) {
+		mu.Lock()
+		sessions++
+		unresponsive := sessions == 1
+		mu.Unlock()
+
+		clientSide, serverSide := net.Pipe()
+		handler := jsonrpc2.HandlerWithError(func(ctx context.Context, conn *jsonrpc2.Conn, req *jsonrpc2.Request) (interface{}, error) {
+			switch req.Method {
+			case commands.WorkspacePathRequestMethod:
+				if unresponsive {
+					return nil, errors.New("timed out")
+				}
+				return "/workspace", nil
+			case commands.WorkspaceRequestMethod:
+				// Keep requests in flight while the session restarts
+				time.Sleep(time.Millisecond)
+				return map[string]any{}, nil
+			}
+			return nil, nil
+		})
+		server := jsonrpc2.NewConn(ctx, jsonrpc2.NewBufferedStream(serverSide, jsonrpc2.VSCodeObjectCodec{}), jsonrpc2.AsyncHandler(handler))
+		conn := jsonrpc2.NewConn(ctx, jsonrpc2.NewBufferedStream(clientSide, jsonrpc2.VSCodeObjectCodec{}), jsonrpc2.HandlerWithError(func(ctx context.Context, conn *jsonrpc2.Conn, req *jsonrpc2.Request) (interface{}, error) {
+			return nil, nil
+		}))
+		go func() {
+			<-ctx.Done()
+			conn.Close()
+			server.Close()
+		}()
+
+		client.Commander = commands.NewCommander(conn, logger)
+		return &commands.InitializeRequestResult{}, nil
+	}
+}
+
+func TestRunSessionsRestartsWithRequestsInFlight(t *testing.T) {
+	logger := zap.NewNop().Sugar()
+	template := nxlsclient.NewClientWithLogger("/workspace", false, logger)
+	cfg := &config.Config{
+		CI:       config.CIConfig{Disabled: true},
+		Watchdog: config.WatchdogConfig{RestartOnUnresponsive: true},
+	}
+	thresholds := nxlsclient.HealthThresholds{
+		Interval:                   5 * time.Millisecond,
+		ProbeTimeout:               50 * time.Millisecond,
+		FailuresBeforeUnresponsive: 2,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p := &fakeProgram{}
+	done := make(chan error, 1)
+	go func() {
+		done <- runSessions(ctx, template, "/workspace", p, logger, cfg, thresholds, fakeStart())
+	}()
+
+	// Keep reloading the workspace with whatever client the TUI holds, like the
+	// refresh notifications do
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ctx.Err() == nil {
+				if client, _ := p.client(); client != nil {
+					ReloadWorkspace(ctx, client, logger)()
+				}
+			}
+		}()
+	}
+
+	deadline := time.After(5 * time.Second)
+	for {
+		if _, sessions := p.client(); sessions >= 2 {
+			break
+		}
+		select {
+		case <-deadline:
+			t.Fatal("nxls was not restarted")
+		case <-time.After(5 * time.Millisecond):
+		}
+	}
+
+	p.mu.Lock()
+	first, second := p.clients[0], p.clients[1]
+	p.mu.Unlock()
+	if first == second || first == template || second == template {
+		t.Fatal("sessions must not share a client")
+	}
+	if msg := ReloadWorkspace(ctx, second, logger)().(WorkspaceMsg); msg.Err != nil {
+		t.Fatalf("the new session failed to load the workspace: %v", msg.Err)
+	}
+
+	cancel()
+	wg.Wait()
+	if err := <-done; err != nil {
+		t.Fatalf("runSessions returned %v", err)
+	}
+}
@@ -0,0 +1,151 @@
+package components
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/lipgloss/v2"
+	"github.com/lazyengs/lazynx/pkg/nxlsclient/metrics"
+)
+
+// metricsRefreshInterval is how often the open debug panel re-reads the registry.
+const metricsRefreshInterval = time.Second
+
+// MetricsTickMsg asks the debug panel to refresh.
+type MetricsTickMsg struct{}
+
+type MetricsComponent struct {
+	registry *metrics.Registry
+	width    int
+	maxWidth int
+}
+
+func NewMetricsComponent(registry *metrics.Registry) *MetricsComponent {
+	return &MetricsComponent{
+		registry: registry,
+		maxWidth: 90,
+	}
+}
+
+func (c *MetricsComponent) Init() tea.Cmd {
+	return nil
+}
+
+// Tick schedules the next refresh of the panel.
+func (c *MetricsComponent) Tick() tea.Cmd {
+	return tea.Tick(metricsRefreshInterval, func(time.Time) tea.Msg {
+		return MetricsTickMsg{}
+	})
+}
+
+func (c *MetricsComponent) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		c.width = c.maxWidth
+		if msg.Width < c.maxWidth {
+			c.width = msg.Width - 4 // Leave margin for borders
+		}
+	}
+	return c, nil
+}
+
+func (c *MetricsComponent) renderContent() string {
+	if c.registry == nil {
+		return "Metrics are not enabled"
+	}
+
+	snap := c.registry.Snapshot()
+	headerStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#4ECDC4"))
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#888888"))
+	errStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#FF5722"))
+
+	var lines []string
+
+	lines = append(lines, headerStyle.Render("Requests"))
+	if len(snap.Requests) == 0 {
+		lines = append(lines, dimStyle.Render("  none yet"))
+	}
+	for _, m := range snap.Requests {
+		line := fmt.Sprintf("  %-34s %5d  p50 %-8s p95 %-8s %s",
+			m.Method,
+			m.Count,
+			formatDuration(m.LatencyQuantile(0.5)),
+			formatDuration(m.LatencyQuantile(0.95)),
+			formatBytes(m.ResponseBytes.Mean()),
+		)
+		if m.InFlight > 0 {
+			line += fmt.Sprintf("  %d in flight", m.InFlight)
+		}
+		if m.Errors > 0 {
+			line += errStyle.Render(fmt.Sprintf("  %d errors", m.Errors))
+		}
+		lines = append(lines, line)
+	}
+
+	lines = append(lines, "", headerStyle.Render("Notifications"))
+	if len(snap.Notifications) == 0 {
+		lines = append(lines, dimStyle.Render("  none yet"))
+	}
+	for _, m := range snap.Notifications {
+		lines = append(lines, fmt.Sprintf("  %-34s %5d  %6.1f/min  last %s ago",
+			m.Method,
+			m.Count,
+			m.RatePerMinute,
+			formatDuration(snap.TakenAt.Sub(m.LastSeen)),
+		))
+	}
+
+	lines = append(lines, "", dimStyle.Render("Since "+snap.Since.Format(time.TimeOnly)))
+
+	return lipgloss.NewStyle().Width(c.width).Render(strings.Join(lines, "\n"))
+}
+
+func (c *MetricsComponent) View() string {
+	baseStyle := lipgloss.NewStyle()
+
+	content := c.renderContent()
+	header := baseStyle.
+		Bold(true).
+		Width(lipgloss.Width(content)).
+		Foreground(lipgloss.Color("#4ECDC4")).
+		AlignHorizontal(lipgloss.Center).
+		Render("LazyNX - nxls Metrics")
+
+	return baseStyle.
+		Padding(1).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("#4ECDC4")).
+		Background(lipgloss.Color("#1a1a1a")).
+		Width(c.width).
+		Render(
+			lipgloss.JoinVertical(lipgloss.Center,
+				header,
+				"",
+				content,
+			),
+		)
+}
+
+func formatDuration(d time.Duration) string {
+	switch {
+	case d >= time.Second:
+		return d.Round(100 * time.Millisecond).String()
+	case d >= time.Millisecond:
+		return d.Round(time.Millisecond).String()
+	default:
+		return d.Round(time.Microsecond).String()
+	}
+}
+
+func formatBytes(b float64) string {
+	switch {
+	case b >= 1<<20:
+		return fmt.Sprintf("%.1f MiB", b/(1<<20))
+	case b >= 1<<10:
+		return fmt.Sprintf("%.1f KiB", b/(1<<10))
+	default:
+		return fmt.Sprintf("%.0f B", b)
+	}
+}
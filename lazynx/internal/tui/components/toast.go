@@ -0,0 +1,65 @@
+package components
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/lipgloss/v2"
+)
+
+// toastDuration is how long a toast stays on screen.
+const toastDuration = 5 * time.Second
+
+// ToastExpiredMsg hides the toast it was scheduled for.
+type ToastExpiredMsg struct {
+	id int
+}
+
+// ToastComponent shows a short-lived notification. A new toast replaces the current one.
+type ToastComponent struct {
+	text string
+	id   int
+}
+
+func NewToastComponent() *ToastComponent {
+	return &ToastComponent{}
+}
+
+// Show displays text and schedules its removal.
+func (c *ToastComponent) Show(text string) tea.Cmd {
+	c.id++
+	c.text = text
+	id := c.id
+	return tea.Tick(toastDuration, func(time.Time) tea.Msg {
+		return ToastExpiredMsg{id: id}
+	})
+}
+
+// Visible reports whether a toast is on screen.
+func (c *ToastComponent) Visible() bool {
+	return c.text != ""
+}
+
+func (c *ToastComponent) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	// Only the latest toast may clear itself
+	if msg, ok := msg.(ToastExpiredMsg); ok && msg.id == c.id {
+		c.text = ""
+	}
+	return c, nil
+}
+
+func (c *ToastComponent) Init() tea.Cmd {
+	return nil
+}
+
+func (c *ToastComponent) View() string {
+	if c.text == "" {
+		return ""
+	}
+	return lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("#4ECDC4")).
+		Foreground(lipgloss.Color("#FFF")).
+		Padding(0, 1).
+		Render(c.text)
+}
@@ -0,0 +1,181 @@
+package affected
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/lipgloss/v2"
+	nxaffected "github.com/lazyengs/lazynx/pkg/nxlsclient/affected"
+)
+
+// maxReasons caps the reasons listed for the selected project.
+const maxReasons = 8
+
+// ResultMsg carries the outcome of an affected computation.
+type ResultMsg struct {
+	Result *nxaffected.Result
+	Err    error
+}
+
+type Model struct {
+	base    string
+	width   int
+	height  int
+	loading bool
+	result  *nxaffected.Result
+	err     error
+	cursor  int
+	// highlighted are projects that changed in the last workspace refresh.
+	highlighted map[string]bool
+}
+
+func New(base string) Model {
+	return Model{
+		base:    base,
+		loading: true,
+	}
+}
+
+func (m Model) Init() tea.Cmd {
+	return nil
+}
+
+// Loading marks the model as waiting for a new result.
+func (m Model) Loading() Model {
+	m.loading = true
+	m.err = nil
+	return m
+}
+
+// Highlight marks projects that changed in the workspace, replacing earlier marks.
+func (m Model) Highlight(projects []string) Model {
+	m.highlighted = make(map[string]bool, len(projects))
+	for _, name := range projects {
+		m.highlighted[name] = true
+	}
+	return m
+}
+
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+	case ResultMsg:
+		m.loading = false
+		m.result = msg.Result
+		m.err = msg.Err
+		m.cursor = 0
+	case tea.KeyMsg:
+		if m.result == nil {
+			return m, nil
+		}
+		switch msg.String() {
+		case "up", "k":
+			if m.cursor > 0 {
+				m.cursor--
+			}
+		case "down", "j":
+			if m.cursor < len(m.result.Projects)-1 {
+				m.cursor++
+			}
+		}
+	}
+
+	return m, nil
+}
+
+func (m Model) View() string {
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("#4ECDC4"))
+	dimStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#888888"))
+
+	title := titleStyle.Render("Affected projects") + dimStyle.Render(" · base "+m.base)
+
+	var body string
+	switch {
+	case m.loading:
+		body = dimStyle.Render("Computing affected projects...")
+	case m.err != nil:
+		body = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#FF5722")).
+			Render("Error: " + m.err.Error())
+	case m.result == nil || len(m.result.Projects) == 0:
+		body = dimStyle.Render(fmt.Sprintf("No affected projects (%d changed files)", len(m.resultFiles())))
+	default:
+		body = m.renderProjects()
+	}
+
+	footer := dimStyle.Render("↑/↓ select · a recompute · esc back")
+
+	return lipgloss.NewStyle().
+		Width(m.width).
+		Height(m.height).
+		Padding(1, 2).
+		Render(lipgloss.JoinVertical(lipgloss.Left, title, "", body, "", footer))
+}
+
+func (m Model) resultFiles() []string {
+	if m.result == nil {
+		return nil
+	}
+	return m.result.ChangedFiles
+}
+
+func (m Model) renderProjects() string {
+	nameStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#FFF"))
+	selectedStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#4ECDC4"))
+	touchedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#FFC107"))
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#888888"))
+	changedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#C678DD"))
+
+	projects := m.result.Projects
+	summary := dimStyle.Render(fmt.Sprintf("%d projects · %d changed files", len(projects), len(m.result.ChangedFiles)))
+	if n := len(m.result.UnownedFiles); n > 0 {
+		summary += dimStyle.Render(fmt.Sprintf(" · %d outside any project", n))
+	}
+
+	// Keep the cursor visible, leaving room for the title, footer and expanded reasons
+	visible := max(m.height-8-maxReasons, 3)
+	start := 0
+	if m.cursor >= visible {
+		start = m.cursor - visible + 1
+	}
+	end := min(start+visible, len(projects))
+
+	lines := []string{summary, ""}
+	for i := start; i < end; i++ {
+		p := projects[i]
+		marker := "○"
+		if p.Touched() {
+			marker = touchedStyle.Render("●")
+		}
+
+		style := nameStyle
+		prefix := "  "
+		if i == m.cursor {
+			style = selectedStyle
+			prefix = "> "
+		}
+		line := prefix + marker + " " + style.Render(p.Name)
+		if m.highlighted[p.Name] {
+			line += " " + changedStyle.Render("(changed)")
+		}
+		lines = append(lines, line+"  "+dimStyle.Render(p.Reasons[0].String()))
+
+		if i == m.cursor {
+			for j, reason := range p.Reasons {
+				if j == maxReasons {
+					lines = append(lines, dimStyle.Render(fmt.Sprintf("      … %d more", len(p.Reasons)-maxReasons)))
+					break
+				}
+				lines = append(lines, dimStyle.Render("      "+reason.String()))
+			}
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,144 @@
+package boundaries
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/lipgloss/v2"
+	nxboundaries "github.com/lazyengs/lazynx/pkg/nxlsclient/boundaries"
+)
+
+// ResultMsg carries the outcome of a boundary check.
+type ResultMsg struct {
+	Violations []nxboundaries.Violation
+	// Source is where the constraints came from, a file or the lazynx configuration.
+	Source      string
+	Constraints int
+	Err         error
+}
+
+type Model struct {
+	width   int
+	height  int
+	loading bool
+	result  *ResultMsg
+	cursor  int
+}
+
+func New() Model {
+	return Model{loading: true}
+}
+
+func (m Model) Init() tea.Cmd {
+	return nil
+}
+
+// Loading marks the model as waiting for a new result.
+func (m Model) Loading() Model {
+	m.loading = true
+	return m
+}
+
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+	case ResultMsg:
+		m.loading = false
+		m.result = &msg
+		m.cursor = 0
+	case tea.KeyMsg:
+		if m.result == nil {
+			return m, nil
+		}
+		switch msg.String() {
+		case "up", "k":
+			if m.cursor > 0 {
+				m.cursor--
+			}
+		case "down", "j":
+			if m.cursor < len(m.result.Violations)-1 {
+				m.cursor++
+			}
+		}
+	}
+
+	return m, nil
+}
+
+func (m Model) View() string {
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("#4ECDC4"))
+	dimStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#888888"))
+
+	title := titleStyle.Render("Module boundaries")
+	if m.result != nil && m.result.Source != "" {
+		title += dimStyle.Render(" · " + m.result.Source)
+	}
+
+	var body string
+	switch {
+	case m.loading:
+		body = dimStyle.Render("Checking module boundaries...")
+	case m.result.Err != nil:
+		body = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#FF5722")).
+			Render("Error: " + m.result.Err.Error())
+	case len(m.result.Violations) == 0:
+		body = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#4ECDC4")).
+			Render(fmt.Sprintf("No violations of %d constraints", m.result.Constraints))
+	default:
+		body = m.renderViolations()
+	}
+
+	footer := dimStyle.Render("↑/↓ select · b recheck · esc back")
+
+	return lipgloss.NewStyle().
+		Width(m.width).
+		Height(m.height).
+		Padding(1, 2).
+		Render(lipgloss.JoinVertical(lipgloss.Left, title, "", body, "", footer))
+}
+
+func (m Model) renderViolations() string {
+	nameStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#FFF"))
+	selectedStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#4ECDC4"))
+	kindStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#FF5722"))
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#888888"))
+
+	violations := m.result.Violations
+	summary := kindStyle.Render(fmt.Sprintf("%d violations", len(violations))) +
+		dimStyle.Render(fmt.Sprintf(" of %d constraints", m.result.Constraints))
+
+	// Keep the cursor visible, leaving room for the title, footer and the message
+	visible := max(m.height-12, 3)
+	start := 0
+	if m.cursor >= visible {
+		start = m.cursor - visible + 1
+	}
+	end := min(start+visible, len(violations))
+
+	lines := []string{summary, ""}
+	for i := start; i < end; i++ {
+		v := violations[i]
+		style := nameStyle
+		prefix := "  "
+		if i == m.cursor {
+			style = selectedStyle
+			prefix = "> "
+		}
+		edge := v.Dependency.Source + " → " + v.Dependency.Target
+		lines = append(lines, prefix+style.Render(edge)+"  "+dimStyle.Render(string(v.Kind)))
+
+		if i == m.cursor {
+			lines = append(lines, dimStyle.PaddingLeft(4).Width(max(m.width-6, 20)).Render(v.Message))
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
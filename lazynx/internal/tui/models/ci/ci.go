@@ -0,0 +1,226 @@
+package ci
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/lipgloss/v2"
+	"github.com/lazyengs/lazynx/pkg/nxlsclient/cipe"
+	nxtypes "github.com/lazyengs/lazynx/pkg/nxlsclient/nx-types"
+)
+
+// NotConnectedMsg tells the panel that the workspace is not connected to Nx Cloud, so
+// nothing polls its pipelines.
+type NotConnectedMsg struct{}
+
+type Model struct {
+	width        int
+	height       int
+	disabled     bool
+	notConnected bool
+	update       cipe.Update
+	received     bool
+	cursor       int
+}
+
+// New creates the panel; disabled is set when the poller is turned off in the configuration.
+func New(disabled bool) Model {
+	return Model{disabled: disabled}
+}
+
+func (m Model) Init() tea.Cmd {
+	return nil
+}
+
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+	case NotConnectedMsg:
+		m.notConnected = true
+	case cipe.Update:
+		m.update = msg
+		m.received = true
+		m.cursor = min(m.cursor, max(len(msg.Pipelines)-1, 0))
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "up", "k":
+			if m.cursor > 0 {
+				m.cursor--
+			}
+		case "down", "j":
+			if m.cursor < len(m.update.Pipelines)-1 {
+				m.cursor++
+			}
+		}
+	}
+
+	return m, nil
+}
+
+// statusColors colors pipelines, run groups and runs by status.
+var statusColors = map[nxtypes.CIPEExecutionStatus]string{
+	nxtypes.CIPEStatusInProgress: "#FFC107",
+	nxtypes.CIPEStatusSucceeded:  "#4ECDC4",
+	nxtypes.CIPEStatusFailed:     "#FF5722",
+	nxtypes.CIPEStatusTimedOut:   "#FF5722",
+}
+
+var statusMarkers = map[nxtypes.CIPEExecutionStatus]string{
+	nxtypes.CIPEStatusNotStarted: "○",
+	nxtypes.CIPEStatusInProgress: "●",
+	nxtypes.CIPEStatusSucceeded:  "✔",
+	nxtypes.CIPEStatusFailed:     "✖",
+	nxtypes.CIPEStatusCanceled:   "⊘",
+	nxtypes.CIPEStatusTimedOut:   "✖",
+}
+
+func renderStatus(status nxtypes.CIPEExecutionStatus, text string) string {
+	color, ok := statusColors[status]
+	if !ok {
+		color = "#888888"
+	}
+	marker, ok := statusMarkers[status]
+	if !ok {
+		marker = "?"
+	}
+	return lipgloss.NewStyle().Foreground(lipgloss.Color(color)).Render(marker + " " + text)
+}
+
+func (m Model) View() string {
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("#4ECDC4"))
+	dimStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#888888"))
+
+	title := titleStyle.Render("CI pipelines")
+	if m.update.WorkspaceURL != "" {
+		title += dimStyle.Render(" · " + m.update.WorkspaceURL)
+	}
+
+	var body string
+	switch {
+	case m.disabled:
+		body = dimStyle.Render("CI polling is disabled in the lazynx configuration")
+	case m.notConnected:
+		body = dimStyle.Render("This workspace is not connected to Nx Cloud, run nx connect to see its CI pipelines")
+	case !m.received:
+		body = dimStyle.Render("Loading CI pipelines from Nx Cloud...")
+	case len(m.update.Pipelines) == 0 && m.update.Err == nil:
+		body = dimStyle.Render("No recent CI pipelines")
+	default:
+		body = m.renderPipelines()
+	}
+
+	if m.update.Err != nil {
+		body = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#FF5722")).
+			Width(max(m.width-4, 20)).
+			Render("Error: "+m.update.Err.Error()) + "\n" +
+			dimStyle.Render(errorHint(m.update)) + "\n\n" + body
+	}
+
+	footer := dimStyle.Render("↑/↓ select · esc back")
+	if m.received {
+		footer = dimStyle.Render(fmt.Sprintf("↑/↓ select · esc back · checked %s, next in %s",
+			m.update.CheckedAt.Format("15:04:05"), m.update.Next.Round(time.Second)))
+	}
+
+	return lipgloss.NewStyle().
+		Width(m.width).
+		Height(m.height).
+		Padding(1, 2).
+		Render(lipgloss.JoinVertical(lipgloss.Left, title, "", body, "", footer))
+}
+
+// errorHint suggests how to fix a failed poll.
+func errorHint(update cipe.Update) string {
+	if update.ErrorType() == nxtypes.CIPEErrorTypeAuthentication {
+		return "Run nx login to reconnect to Nx Cloud"
+	}
+	return fmt.Sprintf("Retrying in %s (%d failed attempts)", update.Next.Round(time.Second), update.ConsecutiveFailures)
+}
+
+func (m Model) renderPipelines() string {
+	nameStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#FFF"))
+	selectedStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#4ECDC4"))
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#888888"))
+
+	pipelines := m.update.Pipelines
+	visible := max(m.height-14, 3)
+	start := 0
+	if m.cursor >= visible {
+		start = m.cursor - visible + 1
+	}
+	end := min(start+visible, len(pipelines))
+
+	var lines []string
+	for i := start; i < end; i++ {
+		pipeline := pipelines[i]
+		style := nameStyle
+		prefix := "  "
+		if i == m.cursor {
+			style = selectedStyle
+			prefix = "> "
+		}
+
+		line := prefix + renderStatus(pipeline.Status, "") + style.Render(pipeline.Branch)
+		var details []string
+		if pipeline.CommitTitle != nil {
+			details = append(details, *pipeline.CommitTitle)
+		}
+		if pipeline.Author != nil {
+			details = append(details, *pipeline.Author)
+		}
+		details = append(details, age(pipeline.CreatedAt))
+		lines = append(lines, line+"  "+dimStyle.Render(strings.Join(details, " · ")))
+
+		if i == m.cursor {
+			lines = append(lines, m.renderRunGroups(pipeline)...)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (m Model) renderRunGroups(pipeline nxtypes.CIPEInfo) []string {
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#888888"))
+
+	lines := []string{dimStyle.Render("      " + pipeline.CipeUrl)}
+	for _, group := range pipeline.RunGroups {
+		line := "      " + renderStatus(group.Status, group.RunGroup)
+		if group.CiExecutionEnv != "" {
+			line += dimStyle.Render(" · " + group.CiExecutionEnv)
+		}
+		lines = append(lines, line)
+		for _, run := range group.Runs {
+			status := nxtypes.CIPEStatusNotStarted
+			if run.Status != nil {
+				status = *run.Status
+			}
+			text := run.Command
+			if run.NumTasks != nil && run.NumFailedTasks != nil && *run.NumFailedTasks > 0 {
+				text += fmt.Sprintf(" (%d/%d tasks failed)", *run.NumFailedTasks, *run.NumTasks)
+			}
+			lines = append(lines, "        "+renderStatus(status, text))
+		}
+	}
+	return lines
+}
+
+// age renders a pipeline creation time, in milliseconds since the epoch, relative to now.
+func age(createdAt int64) string {
+	d := time.Since(time.UnixMilli(createdAt))
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(d.Hours()))
+	}
+	return fmt.Sprintf("%dd ago", int(d.Hours()/24))
+}
@@ -0,0 +1,286 @@
+package folders
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/v2/textinput"
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/lipgloss/v2"
+	"github.com/lazyengs/lazynx/pkg/nxlsclient/foldertree"
+)
+
+// ResultMsg carries the project folder tree of the workspace.
+type ResultMsg struct {
+	Tree *foldertree.Tree
+	Err  error
+}
+
+type Model struct {
+	width   int
+	height  int
+	loading bool
+	tree    *foldertree.Tree
+	err     error
+	// expanded holds the directories whose children are shown.
+	expanded map[string]bool
+	cursor   int
+
+	input     textinput.Model
+	searching bool
+	query     string
+	// matches are the nodes matching query and their ancestors, nil without a query.
+	matches map[string]bool
+}
+
+func New() Model {
+	input := textinput.New()
+	input.Prompt = "/"
+	return Model{
+		loading:  true,
+		expanded: map[string]bool{},
+		input:    input,
+	}
+}
+
+func (m Model) Init() tea.Cmd {
+	return nil
+}
+
+// Loading marks the model as waiting for a new tree.
+func (m Model) Loading() Model {
+	m.loading = true
+	m.err = nil
+	return m
+}
+
+// Editing reports whether the search field has the focus, so keys must reach the model.
+func (m Model) Editing() bool {
+	return m.searching
+}
+
+// Back clears the search. ok is false when there is none, so the caller leaves the view.
+func (m Model) Back() (Model, bool) {
+	if m.query == "" {
+		return m, false
+	}
+	m = m.search("")
+	return m, true
+}
+
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+	case ResultMsg:
+		m.loading = false
+		m.tree = msg.Tree
+		m.err = msg.Err
+		if m.tree != nil && len(m.expanded) == 0 {
+			for _, root := range m.tree.Roots {
+				m.expanded[root.Dir] = true
+			}
+		}
+		m = m.search(m.query)
+	case tea.KeyMsg:
+		if m.searching {
+			return m.updateSearch(msg)
+		}
+		if m.tree == nil {
+			return m, nil
+		}
+		return m.updateTree(msg)
+	}
+
+	return m, nil
+}
+
+func (m Model) updateSearch(msg tea.KeyMsg) (Model, tea.Cmd) {
+	switch msg.String() {
+	case "enter":
+		m.searching = false
+		m.input.Blur()
+		return m, nil
+	case "esc":
+		m.searching = false
+		m.input.Blur()
+		return m.search(""), nil
+	}
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	return m.search(m.input.Value()), cmd
+}
+
+func (m Model) updateTree(msg tea.KeyMsg) (Model, tea.Cmd) {
+	rows := m.rows()
+	switch msg.String() {
+	case "up", "k":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case "down", "j":
+		if m.cursor < len(rows)-1 {
+			m.cursor++
+		}
+	case "enter":
+		if len(rows) > 0 && len(rows[m.cursor].Children) > 0 {
+			m.expanded[rows[m.cursor].Dir] = !m.expanded[rows[m.cursor].Dir]
+		}
+	case "right", "l":
+		if len(rows) > 0 && len(rows[m.cursor].Children) > 0 {
+			m.expanded[rows[m.cursor].Dir] = true
+		}
+	case "left", "h":
+		if len(rows) == 0 {
+			return m, nil
+		}
+		node := rows[m.cursor]
+		if m.expanded[node.Dir] && len(node.Children) > 0 {
+			m.expanded[node.Dir] = false
+		} else if node.Parent != nil {
+			// Jump to the parent, which is always listed above its children
+			for i := m.cursor - 1; i >= 0; i-- {
+				if rows[i] == node.Parent {
+					m.cursor = i
+					break
+				}
+			}
+		}
+	case "/":
+		m.searching = true
+		m.input.SetValue(m.query)
+		m.input.CursorEnd()
+		return m, m.input.Focus()
+	}
+	return m, nil
+}
+
+// search filters the tree to the nodes matching query and expands their ancestors.
+func (m Model) search(query string) Model {
+	m.query = strings.TrimSpace(query)
+	m.matches = nil
+	m.cursor = 0
+	if m.query == "" || m.tree == nil {
+		return m
+	}
+
+	m.matches = map[string]bool{}
+	for _, node := range m.tree.Search(m.query) {
+		m.matches[node.Dir] = true
+		for _, ancestor := range node.Ancestors() {
+			m.matches[ancestor.Dir] = true
+			m.expanded[ancestor.Dir] = true
+		}
+	}
+	return m
+}
+
+// rows returns the visible nodes, in tree order.
+func (m Model) rows() []*foldertree.Node {
+	var rows []*foldertree.Node
+	if m.tree == nil {
+		return rows
+	}
+	m.tree.Walk(func(n *foldertree.Node) bool {
+		if m.matches != nil && !m.matches[n.Dir] {
+			return false
+		}
+		rows = append(rows, n)
+		return m.expanded[n.Dir]
+	})
+	return rows
+}
+
+func (m Model) View() string {
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("#4ECDC4"))
+	dimStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#888888"))
+
+	title := titleStyle.Render("Project folders")
+	if m.tree != nil {
+		title += dimStyle.Render(fmt.Sprintf(" · %d projects", m.tree.ProjectCount()))
+	}
+
+	var body string
+	switch {
+	case m.loading:
+		body = dimStyle.Render("Loading the project folder tree...")
+	case m.err != nil:
+		body = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#FF5722")).
+			Render("Error: " + m.err.Error())
+	case m.tree == nil || len(m.tree.Roots) == 0:
+		body = dimStyle.Render("No project folders")
+	default:
+		body = m.renderTree()
+	}
+
+	var search string
+	if m.searching {
+		search = m.input.View()
+	} else if m.query != "" {
+		search = dimStyle.Render("/" + m.query)
+	}
+
+	footer := dimStyle.Render("↑/↓ select · ←/→ collapse/expand · / search · esc back")
+
+	return lipgloss.NewStyle().
+		Width(m.width).
+		Height(m.height).
+		Padding(1, 2).
+		Render(lipgloss.JoinVertical(lipgloss.Left, title, search, body, "", footer))
+}
+
+func (m Model) renderTree() string {
+	dirStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#FFF"))
+	projectStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#FFC107"))
+	selectedStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#4ECDC4"))
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#888888"))
+
+	rows := m.rows()
+	if len(rows) == 0 {
+		return dimStyle.Render("No folders match " + m.query)
+	}
+
+	visible := max(m.height-8, 3)
+	start := 0
+	if m.cursor >= visible {
+		start = m.cursor - visible + 1
+	}
+	end := min(start+visible, len(rows))
+
+	var lines []string
+	for i := start; i < end; i++ {
+		node := rows[i]
+		marker := " "
+		if len(node.Children) > 0 {
+			marker = "▸"
+			if m.expanded[node.Dir] {
+				marker = "▾"
+			}
+		}
+
+		style := dirStyle
+		if node.IsProject() {
+			style = projectStyle
+		}
+		prefix := "  "
+		if i == m.cursor {
+			style = selectedStyle
+			prefix = "> "
+		}
+
+		line := prefix + strings.Repeat("  ", node.Depth()) + marker + " " + style.Render(node.Name())
+		if node.IsProject() && node.ProjectName != node.Name() {
+			line += " " + projectStyle.Render(node.ProjectName)
+		}
+		if count := node.ProjectCount(); len(node.Children) > 0 && count > 0 {
+			line += dimStyle.Render(fmt.Sprintf("  %d projects", count))
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
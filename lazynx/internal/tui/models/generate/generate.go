@@ -0,0 +1,552 @@
+package generate
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/v2/textinput"
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/lipgloss/v2"
+	"github.com/lazyengs/lazynx/pkg/nxlsclient/generator"
+	nxtypes "github.com/lazyengs/lazynx/pkg/nxlsclient/nx-types"
+)
+
+// GeneratorsMsg carries the generators available in the workspace.
+type GeneratorsMsg struct {
+	Generators []nxtypes.GeneratorCollectionInfo
+	Err        error
+}
+
+// OptionsMsg carries the options of the generator picked from the list.
+type OptionsMsg struct {
+	Generator nxtypes.GeneratorCollectionInfo
+	Options   []nxtypes.Option
+	Err       error
+}
+
+// PreviewMsg carries the outcome of a dry run.
+type PreviewMsg struct {
+	Result *generator.Result
+	Err    error
+}
+
+// AppliedMsg carries the outcome of running the generator for real.
+type AppliedMsg struct {
+	Result *generator.Result
+	Err    error
+}
+
+// DryRunRequest asks the program to dry run an invocation.
+type DryRunRequest struct {
+	Invocation generator.Invocation
+}
+
+// ApplyRequest asks the program to run an invocation whose preview was confirmed.
+type ApplyRequest struct {
+	Invocation generator.Invocation
+}
+
+// OptionsRequest asks the program to load the options of a generator.
+type OptionsRequest struct {
+	Generator nxtypes.GeneratorCollectionInfo
+}
+
+type step int
+
+const (
+	listStep step = iota
+	formStep
+	previewStep
+	appliedStep
+)
+
+type Model struct {
+	width   int
+	height  int
+	step    step
+	loading bool
+	err     error
+
+	generators []nxtypes.GeneratorCollectionInfo
+	listCursor int
+
+	generator  nxtypes.GeneratorCollectionInfo
+	options    []nxtypes.Option
+	values     map[string]string
+	fieldErrs  map[string][]string
+	formCursor int
+	input      textinput.Model
+	editing    bool
+
+	invocation generator.Invocation
+	result     *generator.Result
+	cursor     int
+}
+
+func New() Model {
+	input := textinput.New()
+	input.Prompt = ""
+	return Model{loading: true, input: input}
+}
+
+func (m Model) Init() tea.Cmd {
+	return nil
+}
+
+// Loading marks the model as waiting for the generator list.
+func (m Model) Loading() Model {
+	m.step = listStep
+	m.loading = true
+	m.err = nil
+	return m
+}
+
+// Editing reports whether a text field has the focus, so keys must reach the model.
+func (m Model) Editing() bool {
+	return m.editing
+}
+
+// Back returns to the previous step. ok is false on the generator list, where going back
+// leaves the panel.
+func (m Model) Back() (Model, bool) {
+	switch m.step {
+	case formStep:
+		m.step = listStep
+		m.err = nil
+	case previewStep:
+		m.step = formStep
+		m.err = nil
+	case appliedStep:
+		m.step = listStep
+		m.err = nil
+	default:
+		return m, false
+	}
+	m.loading = false
+	return m, true
+}
+
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		m.input.SetWidth(max(msg.Width/2, 20))
+	case GeneratorsMsg:
+		m.loading = false
+		m.err = msg.Err
+		m.generators = msg.Generators
+		slices.SortFunc(m.generators, func(a, b nxtypes.GeneratorCollectionInfo) int {
+			return strings.Compare(a.CollectionName+":"+a.Name, b.CollectionName+":"+b.Name)
+		})
+		m.listCursor = min(m.listCursor, max(len(m.generators)-1, 0))
+	case OptionsMsg:
+		m.loading = false
+		m.err = msg.Err
+		if msg.Err == nil {
+			m.step = formStep
+			m.generator = msg.Generator
+			m.options = visibleOptions(msg.Options)
+			m.values = make(map[string]string)
+			m.fieldErrs = nil
+			m.formCursor = 0
+		}
+	case PreviewMsg:
+		m.loading = false
+		m.err = msg.Err
+		m.result = msg.Result
+		m.cursor = 0
+		if msg.Err == nil {
+			m.step = previewStep
+		}
+	case AppliedMsg:
+		m.loading = false
+		m.err = msg.Err
+		m.result = msg.Result
+		m.cursor = 0
+		if msg.Err == nil {
+			m.step = appliedStep
+		}
+	case tea.KeyMsg:
+		if m.loading {
+			return m, nil
+		}
+		switch m.step {
+		case listStep:
+			return m.updateList(msg)
+		case formStep:
+			return m.updateForm(msg)
+		case previewStep, appliedStep:
+			return m.updateChanges(msg)
+		}
+	default:
+		if m.editing {
+			var cmd tea.Cmd
+			m.input, cmd = m.input.Update(msg)
+			return m, cmd
+		}
+	}
+
+	return m, nil
+}
+
+func (m Model) updateList(msg tea.KeyMsg) (Model, tea.Cmd) {
+	switch msg.String() {
+	case "up", "k":
+		if m.listCursor > 0 {
+			m.listCursor--
+		}
+	case "down", "j":
+		if m.listCursor < len(m.generators)-1 {
+			m.listCursor++
+		}
+	case "enter":
+		if len(m.generators) > 0 {
+			m.loading = true
+			m.err = nil
+			info := m.generators[m.listCursor]
+			return m, func() tea.Msg { return OptionsRequest{Generator: info} }
+		}
+	}
+	return m, nil
+}
+
+func (m Model) updateForm(msg tea.KeyMsg) (Model, tea.Cmd) {
+	if m.editing {
+		switch msg.String() {
+		case "enter":
+			m.values[m.options[m.formCursor].Name] = strings.TrimSpace(m.input.Value())
+			m.editing = false
+			m.input.Blur()
+			m.fieldErrs = nil
+			return m, nil
+		case "esc":
+			m.editing = false
+			m.input.Blur()
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.input, cmd = m.input.Update(msg)
+		return m, cmd
+	}
+
+	switch msg.String() {
+	case "up", "k":
+		if m.formCursor > 0 {
+			m.formCursor--
+		}
+	case "down", "j":
+		if m.formCursor < len(m.options)-1 {
+			m.formCursor++
+		}
+	case "enter":
+		if len(m.options) > 0 {
+			m.editing = true
+			m.input.SetValue(m.values[m.options[m.formCursor].Name])
+			m.input.CursorEnd()
+			return m, m.input.Focus()
+		}
+	case "r":
+		values := m.typedValues()
+		if errs := generator.Validate(m.options, values); errs != nil {
+			m.fieldErrs = errs.ByField()
+			return m, nil
+		}
+		m.fieldErrs = nil
+		m.loading = true
+		m.err = nil
+		m.invocation = generator.NewInvocation(nxtypes.GeneratorSchema{
+			CollectionName: m.generator.CollectionName,
+			GeneratorName:  m.generator.Name,
+			Options:        m.options,
+		}, values)
+		inv := m.invocation
+		return m, func() tea.Msg { return DryRunRequest{Invocation: inv} }
+	}
+	return m, nil
+}
+
+func (m Model) updateChanges(msg tea.KeyMsg) (Model, tea.Cmd) {
+	count := 0
+	if m.result != nil {
+		count = len(m.result.Changes)
+	}
+	switch msg.String() {
+	case "up", "k":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case "down", "j":
+		if m.cursor < count-1 {
+			m.cursor++
+		}
+	case "y":
+		if m.step == previewStep {
+			m.loading = true
+			m.err = nil
+			inv := m.invocation
+			return m, func() tea.Msg { return ApplyRequest{Invocation: inv} }
+		}
+	case "n":
+		if m.step == previewStep {
+			m.step = formStep
+		}
+	}
+	return m, nil
+}
+
+// typedValues converts the form fields to values of the option types. Lists are entered
+// comma separated; other values stay strings, which Validate coerces.
+func (m Model) typedValues() map[string]any {
+	values := make(map[string]any)
+	for _, option := range m.options {
+		value, ok := m.values[option.Name]
+		if !ok || value == "" {
+			continue
+		}
+		if option.Type.Has("array") && !option.Type.Has("string") {
+			var items []string
+			for _, item := range strings.Split(value, ",") {
+				if item = strings.TrimSpace(item); item != "" {
+					items = append(items, item)
+				}
+			}
+			values[option.Name] = items
+			continue
+		}
+		values[option.Name] = value
+	}
+	return values
+}
+
+// visibleOptions drops hidden and internal options and puts required and important ones
+// first.
+func visibleOptions(options []nxtypes.Option) []nxtypes.Option {
+	var visible []nxtypes.Option
+	for _, option := range options {
+		if option.Hidden || option.XPriority == "internal" || (option.Visible != nil && !*option.Visible) {
+			continue
+		}
+		visible = append(visible, option)
+	}
+	rank := func(option nxtypes.Option) int {
+		switch {
+		case option.IsRequired:
+			return 0
+		case option.XPriority == "important":
+			return 1
+		}
+		return 2
+	}
+	slices.SortStableFunc(visible, func(a, b nxtypes.Option) int { return rank(a) - rank(b) })
+	return visible
+}
+
+func (m Model) View() string {
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("#4ECDC4"))
+	dimStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#888888"))
+
+	title := titleStyle.Render("Generate")
+	var body, footer string
+	switch m.step {
+	case listStep:
+		body = m.renderList()
+		footer = "↑/↓ select · enter options · esc back"
+	case formStep:
+		title += dimStyle.Render(" · " + m.generator.CollectionName + ":" + m.generator.Name)
+		body = m.renderForm()
+		footer = "↑/↓ select · enter edit · r preview · esc generators"
+		if m.editing {
+			footer = "enter save · esc cancel"
+		}
+	case previewStep:
+		title += dimStyle.Render(" · preview")
+		body = m.renderChanges("Dry run of " + m.invocation.String())
+		footer = "↑/↓ select · y apply · n edit options · esc back"
+	case appliedStep:
+		title += dimStyle.Render(" · done")
+		body = m.renderChanges(fmt.Sprintf("Ran %s in %s", m.invocation.String(), m.result.Duration.Round(100*time.Millisecond)))
+		footer = "esc generators"
+	}
+
+	switch {
+	case m.loading:
+		body = dimStyle.Render(m.loadingText())
+	case m.err != nil:
+		body = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#FF5722")).
+			Width(max(m.width-4, 20)).
+			Render("Error: "+m.err.Error()) + "\n\n" + body
+	}
+
+	return lipgloss.NewStyle().
+		Width(m.width).
+		Height(m.height).
+		Padding(1, 2).
+		Render(lipgloss.JoinVertical(lipgloss.Left, title, "", body, "", dimStyle.Render(footer)))
+}
+
+func (m Model) loadingText() string {
+	switch m.step {
+	case formStep:
+		return "Running " + m.invocation.String() + " --dry-run..."
+	case previewStep:
+		return "Running " + m.invocation.String() + "..."
+	}
+	if m.generators == nil {
+		return "Loading generators..."
+	}
+	return "Loading options..."
+}
+
+// window returns the range of count rows to show so cursor stays visible.
+func (m Model) window(cursor, count, reserved int) (int, int) {
+	visible := max(m.height-reserved, 3)
+	start := 0
+	if cursor >= visible {
+		start = cursor - visible + 1
+	}
+	return start, min(start+visible, count)
+}
+
+func (m Model) renderList() string {
+	nameStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#FFF"))
+	selectedStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#4ECDC4"))
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#888888"))
+
+	if len(m.generators) == 0 {
+		return dimStyle.Render("No generators found")
+	}
+
+	start, end := m.window(m.listCursor, len(m.generators), 8)
+	var lines []string
+	for i := start; i < end; i++ {
+		g := m.generators[i]
+		style := nameStyle
+		prefix := "  "
+		if i == m.listCursor {
+			style = selectedStyle
+			prefix = "> "
+		}
+		line := prefix + style.Render(g.CollectionName+":"+g.Name)
+		if g.Data != nil && g.Data.Description != "" {
+			line += "  " + dimStyle.Render(g.Data.Description)
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (m Model) renderForm() string {
+	nameStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#FFF"))
+	selectedStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#4ECDC4"))
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#888888"))
+	errorStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#FF5722"))
+	valueStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#FFC107"))
+
+	if len(m.options) == 0 {
+		return dimStyle.Render("This generator has no options, press r to preview")
+	}
+
+	start, end := m.window(m.formCursor, len(m.options), 10)
+	var lines []string
+	for i := start; i < end; i++ {
+		option := m.options[i]
+		style := nameStyle
+		prefix := "  "
+		if i == m.formCursor {
+			style = selectedStyle
+			prefix = "> "
+		}
+		name := option.Name
+		if option.IsRequired {
+			name += "*"
+		}
+
+		var value string
+		switch {
+		case i == m.formCursor && m.editing:
+			value = m.input.View()
+		case m.values[option.Name] != "":
+			value = valueStyle.Render(m.values[option.Name])
+		default:
+			value = dimStyle.Render(placeholder(option))
+		}
+		line := prefix + style.Render(name) + "  " + value
+		if errs := m.fieldErrs[option.Name]; len(errs) > 0 {
+			line += "  " + errorStyle.Render(strings.Join(errs, ", "))
+		}
+		lines = append(lines, line)
+
+		if i == m.formCursor && option.Description != "" {
+			lines = append(lines, dimStyle.PaddingLeft(4).Width(max(m.width-8, 20)).Render(option.Description))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// placeholder hints at the default or the allowed values of an empty field.
+func placeholder(option nxtypes.Option) string {
+	if values := option.EnumValues(); len(values) > 0 {
+		allowed := make([]string, len(values))
+		for i, v := range values {
+			allowed[i] = fmt.Sprint(v)
+		}
+		return strings.Join(allowed, " | ")
+	}
+	if option.Items != nil && len(option.Items.Allowed()) > 0 {
+		return strings.Join(option.Items.Allowed(), ", ")
+	}
+	if value, ok := option.DefaultValue(); ok {
+		return fmt.Sprintf("default %v", value)
+	}
+	return strings.Join(option.Type, " | ")
+}
+
+func (m Model) renderChanges(header string) string {
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#888888"))
+	styles := map[generator.ChangeType]lipgloss.Style{
+		generator.ChangeCreate: lipgloss.NewStyle().Foreground(lipgloss.Color("#4ECDC4")),
+		generator.ChangeUpdate: lipgloss.NewStyle().Foreground(lipgloss.Color("#FFC107")),
+		generator.ChangeDelete: lipgloss.NewStyle().Foreground(lipgloss.Color("#FF5722")),
+	}
+	markers := map[generator.ChangeType]string{
+		generator.ChangeCreate: "+",
+		generator.ChangeUpdate: "~",
+		generator.ChangeDelete: "-",
+	}
+
+	changes := m.result.Changes
+	counts := make(map[generator.ChangeType]int)
+	for _, change := range changes {
+		counts[change.Type]++
+	}
+	summary := fmt.Sprintf("%d created · %d updated · %d deleted",
+		counts[generator.ChangeCreate], counts[generator.ChangeUpdate], counts[generator.ChangeDelete])
+
+	lines := []string{
+		dimStyle.Width(max(m.width-4, 20)).Render(header),
+		dimStyle.Render(summary),
+		"",
+	}
+	if len(changes) == 0 {
+		lines = append(lines, dimStyle.Render("The generator changes no files"))
+	}
+
+	start, end := m.window(m.cursor, len(changes), 12)
+	for i := start; i < end; i++ {
+		change := changes[i]
+		prefix := "  "
+		if i == m.cursor {
+			prefix = "> "
+		}
+		style := styles[change.Type]
+		lines = append(lines, prefix+style.Render(markers[change.Type]+" "+string(change.Type)+" ")+change.Path)
+	}
+	return strings.Join(lines, "\n")
+}
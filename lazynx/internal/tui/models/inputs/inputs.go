@@ -0,0 +1,409 @@
+package inputs
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/v2/textinput"
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/lipgloss/v2"
+	nxinputs "github.com/lazyengs/lazynx/pkg/nxlsclient/inputs"
+	nxtypes "github.com/lazyengs/lazynx/pkg/nxlsclient/nx-types"
+)
+
+// ResolveRequest asks for the inputs of a target to be resolved.
+type ResolveRequest struct {
+	Project string
+	Target  string
+}
+
+// ResultMsg carries the resolved inputs of a target.
+type ResultMsg struct {
+	Result *nxinputs.Result
+	Err    error
+}
+
+// SnapshotRequest asks for the current inputs of a target to be recorded, to explain
+// later cache misses.
+type SnapshotRequest struct {
+	Project string
+	Target  string
+}
+
+// SnapshotMsg reports that the inputs of Target, a project:target, were recorded.
+type SnapshotMsg struct {
+	Target string
+	Err    error
+}
+
+// WhyNotCachedRequest asks for the inputs of a target to be compared with its last
+// snapshot.
+type WhyNotCachedRequest struct {
+	Project string
+	Target  string
+}
+
+// DiffMsg carries how the inputs of Target changed since Before was recorded. Err is
+// nxinputs.ErrNoSnapshot when nothing was recorded yet.
+type DiffMsg struct {
+	Target string
+	Before *nxinputs.Snapshot
+	Diff   *nxinputs.Diff
+	Err    error
+}
+
+type Model struct {
+	width  int
+	height int
+	// targets are all project:target pairs of the workspace, sorted.
+	targets []string
+	cursor  int
+
+	input     textinput.Model
+	searching bool
+	query     string
+
+	// open is the target whose inputs are shown, empty in the target list.
+	open    string
+	loading bool
+	result  *nxinputs.Result
+	err     error
+	scroll  int
+	// diff is how the inputs changed since the snapshot taken at since.
+	diff   *nxinputs.Diff
+	since  time.Time
+	status string
+}
+
+func New() Model {
+	input := textinput.New()
+	input.Prompt = "/"
+	return Model{input: input}
+}
+
+func (m Model) Init() tea.Cmd {
+	return nil
+}
+
+// SetWorkspace replaces the targets to pick from.
+func (m Model) SetWorkspace(workspace *nxtypes.NxWorkspace) Model {
+	m.targets = nil
+	if workspace != nil {
+		for name, node := range workspace.ProjectGraph.Nodes {
+			for target := range node.Data.Targets {
+				m.targets = append(m.targets, name+":"+target)
+			}
+		}
+		slices.Sort(m.targets)
+	}
+	m.cursor = min(m.cursor, max(len(m.visible())-1, 0))
+	return m
+}
+
+// Editing reports whether the search field has the focus, so keys must reach the model.
+func (m Model) Editing() bool {
+	return m.searching
+}
+
+// Back returns from the inputs of a target to the list, then clears the search. ok is
+// false when there is nothing to go back from.
+func (m Model) Back() (Model, bool) {
+	switch {
+	case m.open != "":
+		m.open = ""
+		m.result = nil
+		m.err = nil
+		m.diff = nil
+		m.status = ""
+	case m.query != "":
+		m.query = ""
+		m.cursor = 0
+	default:
+		return m, false
+	}
+	return m, true
+}
+
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+	case ResultMsg:
+		m.loading = false
+		m.result = msg.Result
+		m.err = msg.Err
+		m.scroll = 0
+	case SnapshotMsg:
+		if msg.Target != m.open {
+			return m, nil
+		}
+		m.status = "Recorded the inputs of " + msg.Target
+		if msg.Err != nil {
+			m.status = "Failed to record the inputs: " + msg.Err.Error()
+		}
+	case DiffMsg:
+		if msg.Target != m.open {
+			return m, nil
+		}
+		m.status = ""
+		m.diff = nil
+		switch {
+		case errors.Is(msg.Err, nxinputs.ErrNoSnapshot):
+			m.status = "No snapshot of " + msg.Target + " yet, press s after a successful run to record one"
+		case msg.Err != nil:
+			m.status = "Failed to compare the inputs: " + msg.Err.Error()
+		default:
+			m.diff = msg.Diff
+			m.since = msg.Before.TakenAt
+			m.scroll = 0
+		}
+	case tea.KeyMsg:
+		if m.searching {
+			return m.updateSearch(msg)
+		}
+		if m.open != "" {
+			return m.updateInputs(msg)
+		}
+		return m.updateList(msg)
+	}
+
+	return m, nil
+}
+
+func (m Model) updateSearch(msg tea.KeyMsg) (Model, tea.Cmd) {
+	switch msg.String() {
+	case "enter", "esc":
+		m.searching = false
+		m.input.Blur()
+		if msg.String() == "esc" {
+			m.query = ""
+		}
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	m.query = strings.TrimSpace(m.input.Value())
+	m.cursor = 0
+	return m, cmd
+}
+
+func (m Model) updateList(msg tea.KeyMsg) (Model, tea.Cmd) {
+	targets := m.visible()
+	switch msg.String() {
+	case "up", "k":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case "down", "j":
+		if m.cursor < len(targets)-1 {
+			m.cursor++
+		}
+	case "/":
+		m.searching = true
+		m.input.SetValue(m.query)
+		m.input.CursorEnd()
+		return m, m.input.Focus()
+	case "enter", "right", "l":
+		if len(targets) == 0 {
+			return m, nil
+		}
+		m.open = targets[m.cursor]
+		m.loading = true
+		m.result = nil
+		m.err = nil
+		m.diff = nil
+		m.status = ""
+		project, target, _ := strings.Cut(m.open, ":")
+		return m, func() tea.Msg { return ResolveRequest{Project: project, Target: target} }
+	}
+	return m, nil
+}
+
+func (m Model) updateInputs(msg tea.KeyMsg) (Model, tea.Cmd) {
+	switch msg.String() {
+	case "up", "k":
+		if m.scroll > 0 {
+			m.scroll--
+		}
+	case "down", "j":
+		if m.scroll < len(m.inputLines())-m.pageHeight() {
+			m.scroll++
+		}
+	case "s":
+		if m.result != nil {
+			m.status = "Recording the inputs..."
+			project, target, _ := strings.Cut(m.open, ":")
+			return m, func() tea.Msg { return SnapshotRequest{Project: project, Target: target} }
+		}
+	case "w":
+		if m.result != nil {
+			m.status = "Comparing with the last snapshot..."
+			project, target, _ := strings.Cut(m.open, ":")
+			return m, func() tea.Msg { return WhyNotCachedRequest{Project: project, Target: target} }
+		}
+	}
+	return m, nil
+}
+
+// visible returns the targets matching the search.
+func (m Model) visible() []string {
+	if m.query == "" {
+		return m.targets
+	}
+	var targets []string
+	for _, t := range m.targets {
+		if strings.Contains(strings.ToLower(t), strings.ToLower(m.query)) {
+			targets = append(targets, t)
+		}
+	}
+	return targets
+}
+
+// pageHeight is how many lines of a list fit between the title and the footer.
+func (m Model) pageHeight() int {
+	return max(m.height-8, 3)
+}
+
+func (m Model) View() string {
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("#4ECDC4"))
+	dimStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#888888"))
+
+	var title, body, footer string
+	if m.open == "" {
+		title = titleStyle.Render("Target inputs") + dimStyle.Render(" · which files affect the cache of a target")
+		body = m.renderTargets()
+		footer = dimStyle.Render("↑/↓ select · enter show inputs · / search · esc back")
+		if m.searching {
+			title += "\n" + m.input.View()
+		} else if m.query != "" {
+			title += "\n" + dimStyle.Render("/"+m.query)
+		}
+	} else {
+		title = titleStyle.Render("Inputs of " + m.open)
+		footer = dimStyle.Render("↑/↓ scroll · s record snapshot · w why not cached · esc back")
+		switch {
+		case m.loading:
+			body = dimStyle.Render("Resolving inputs...")
+		case m.err != nil:
+			body = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("#FF5722")).
+				Width(max(m.width-4, 20)).
+				Render("Error: " + m.err.Error())
+		default:
+			body = m.renderInputs()
+		}
+	}
+
+	content := []string{title, "", body, ""}
+	if m.open != "" && m.status != "" {
+		content = append(content, lipgloss.NewStyle().Foreground(lipgloss.Color("#FFC107")).Render(m.status))
+	}
+	content = append(content, footer)
+
+	return lipgloss.NewStyle().
+		Width(m.width).
+		Height(m.height).
+		Padding(1, 2).
+		Render(lipgloss.JoinVertical(lipgloss.Left, content...))
+}
+
+func (m Model) renderTargets() string {
+	nameStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#FFF"))
+	selectedStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#4ECDC4"))
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#888888"))
+
+	targets := m.visible()
+	if len(targets) == 0 {
+		return dimStyle.Render("No targets")
+	}
+
+	visible := m.pageHeight()
+	start := 0
+	if m.cursor >= visible {
+		start = m.cursor - visible + 1
+	}
+	end := min(start+visible, len(targets))
+
+	var lines []string
+	for i := start; i < end; i++ {
+		if i == m.cursor {
+			lines = append(lines, "> "+selectedStyle.Render(targets[i]))
+		} else {
+			lines = append(lines, "  "+nameStyle.Render(targets[i]))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// inputLines renders the resolved inputs, files grouped by project.
+func (m Model) inputLines() []string {
+	if m.result == nil {
+		return nil
+	}
+	headerStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#FFF"))
+	projectStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#FFC107"))
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#888888"))
+
+	r := m.result
+	lines := []string{dimStyle.Render(fmt.Sprintf("%d files in %d projects · %d env · %d runtime · %d packages",
+		len(r.Files), len(r.Projects), len(r.Env), len(r.Runtime), len(r.ExternalDependencies)))}
+
+	if m.diff != nil {
+		changedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#C678DD"))
+		lines = append(lines, "", headerStyle.Render("Changed since "+m.since.Local().Format("Jan 2 15:04"))+dimStyle.Render(" · "+m.diff.Summary()))
+		for _, line := range m.diff.Lines() {
+			lines = append(lines, "  "+changedStyle.Render(line))
+		}
+		if m.diff.Empty() {
+			lines = append(lines, dimStyle.Render("  Runtime inputs or dependency outputs may still differ"))
+		}
+	}
+
+	section := func(title string, values []string) {
+		if len(values) == 0 {
+			return
+		}
+		lines = append(lines, "", headerStyle.Render(title))
+		for _, v := range values {
+			lines = append(lines, "  "+v)
+		}
+	}
+
+	if len(r.Files) > 0 {
+		lines = append(lines, "", headerStyle.Render("Files"))
+		// Workspace files first, then each project's files
+		files := slices.Clone(r.Files)
+		slices.SortStableFunc(files, func(a, b nxinputs.File) int { return strings.Compare(a.Project, b.Project) })
+		owner := "\x00"
+		for _, f := range files {
+			if f.Project != owner {
+				owner = f.Project
+				name := owner
+				if name == "" {
+					name = "(workspace)"
+				}
+				lines = append(lines, "  "+projectStyle.Render(name))
+			}
+			lines = append(lines, "    "+f.Path)
+		}
+	}
+	section("Environment variables", r.Env)
+	section("Runtime commands", r.Runtime)
+	section("Packages", r.ExternalDependencies)
+	section("Outputs of dependency tasks", r.DependentOutputs)
+	return lines
+}
+
+func (m Model) renderInputs() string {
+	lines := m.inputLines()
+	start := min(m.scroll, max(len(lines)-m.pageHeight(), 0))
+	end := min(start+m.pageHeight(), len(lines))
+	return strings.Join(lines[start:end], "\n")
+}
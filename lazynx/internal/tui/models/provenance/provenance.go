@@ -0,0 +1,285 @@
+package provenance
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/lipgloss/v2"
+	"github.com/lazyengs/lazynx/internal/tui/utils"
+	nxtypes "github.com/lazyengs/lazynx/pkg/nxlsclient/nx-types"
+	nxprovenance "github.com/lazyengs/lazynx/pkg/nxlsclient/provenance"
+)
+
+// row is a target, or one of its fields, in the project view.
+type row struct {
+	target string
+	field  *nxprovenance.Field
+	source nxprovenance.Source
+	known  bool
+}
+
+// path is the full property path of the row, for locating it in its file.
+func (r row) path() []string {
+	path := []string{"targets", r.target}
+	if r.field != nil {
+		path = append(path, r.field.Path...)
+	}
+	return path
+}
+
+type Model struct {
+	workspacePath string
+	width         int
+	height        int
+	workspace     *nxtypes.NxWorkspace
+	provenance    *nxprovenance.Provenance
+	projects      []string
+	// project is the project whose targets are shown, empty in the project list.
+	project string
+	rows    []row
+	cursor  int
+	// listCursor is the cursor of the project list, kept while a project is open.
+	listCursor int
+	status     string
+}
+
+func New(workspacePath string) Model {
+	return Model{workspacePath: workspacePath}
+}
+
+func (m Model) Init() tea.Cmd {
+	return nil
+}
+
+// SetWorkspace replaces the workspace whose projects are explained, keeping the open
+// project when it still exists.
+func (m Model) SetWorkspace(workspace *nxtypes.NxWorkspace) Model {
+	if workspace == m.workspace {
+		return m
+	}
+
+	m.workspace = workspace
+	m.provenance = nxprovenance.New(workspace)
+	m.projects = nil
+	if workspace != nil {
+		for name := range workspace.ProjectGraph.Nodes {
+			m.projects = append(m.projects, name)
+		}
+		slices.Sort(m.projects)
+	}
+	m.listCursor = min(m.listCursor, max(len(m.projects)-1, 0))
+
+	if m.project != "" && slices.Contains(m.projects, m.project) {
+		m = m.open(m.project)
+	} else {
+		m.project = ""
+		m.cursor = m.listCursor
+	}
+	return m
+}
+
+// open shows the targets of project, every field annotated with its source.
+func (m Model) open(project string) Model {
+	m.project = project
+	m.rows = nil
+	for _, target := range m.provenance.Targets(project) {
+		source, known := m.provenance.Lookup(project, "targets", target)
+		m.rows = append(m.rows, row{target: target, source: source, known: known})
+		for _, field := range m.provenance.Target(project, target) {
+			m.rows = append(m.rows, row{target: target, field: &field, source: field.Source, known: field.Known})
+		}
+	}
+	m.cursor = min(m.cursor, max(len(m.rows)-1, 0))
+	return m
+}
+
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+	case utils.EditorClosedMsg:
+		m.status = ""
+		if msg.Err != nil {
+			m.status = "Editor failed: " + msg.Err.Error()
+		}
+	case tea.KeyMsg:
+		count := len(m.projects)
+		if m.project != "" {
+			count = len(m.rows)
+		}
+		switch msg.String() {
+		case "up", "k":
+			if m.cursor > 0 {
+				m.cursor--
+			}
+		case "down", "j":
+			if m.cursor < count-1 {
+				m.cursor++
+			}
+		case "enter", "right", "l":
+			if m.project == "" && count > 0 {
+				m.listCursor = m.cursor
+				m.cursor = 0
+				return m.open(m.projects[m.listCursor]), nil
+			}
+			if m.project != "" && count > 0 {
+				return m.openFile(m.rows[m.cursor])
+			}
+		case "o":
+			if m.project != "" && count > 0 {
+				return m.openFile(m.rows[m.cursor])
+			}
+		case "left", "h", "backspace":
+			if m.project != "" {
+				m.project = ""
+				m.cursor = m.listCursor
+				m.status = ""
+			}
+		}
+	}
+
+	return m, nil
+}
+
+// openFile opens the file that defined r in the editor, at the line defining it.
+func (m Model) openFile(r row) (Model, tea.Cmd) {
+	if !r.known {
+		m.status = "No source recorded for this field"
+		return m, nil
+	}
+	loc, err := nxprovenance.Locate(m.workspacePath, r.source, r.path()...)
+	if err != nil {
+		m.status = err.Error()
+		return m, nil
+	}
+	m.status = fmt.Sprintf("Opening %s:%d", r.source.File, loc.Line)
+	return m, utils.OpenInEditor(loc.File, loc.Line)
+}
+
+func (m Model) View() string {
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("#4ECDC4"))
+	dimStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#888888"))
+
+	title := titleStyle.Render("Configuration sources")
+	footer := dimStyle.Render("↑/↓ select · enter open project · esc back")
+	if m.project != "" {
+		title += dimStyle.Render(" · " + m.project)
+		footer = dimStyle.Render("↑/↓ select · enter/o open file · ← projects · esc back")
+	}
+
+	var body string
+	switch {
+	case m.workspace == nil:
+		body = dimStyle.Render("Waiting for the workspace...")
+	case m.workspace.SourceMaps == nil:
+		body = dimStyle.Render("This Nx version does not report configuration sources")
+	case m.project == "":
+		body = m.renderProjects()
+	default:
+		body = m.renderTargets()
+	}
+
+	content := []string{title, "", body, ""}
+	if m.status != "" {
+		content = append(content, lipgloss.NewStyle().Foreground(lipgloss.Color("#FFC107")).Render(m.status))
+	}
+	content = append(content, footer)
+
+	return lipgloss.NewStyle().
+		Width(m.width).
+		Height(m.height).
+		Padding(1, 2).
+		Render(lipgloss.JoinVertical(lipgloss.Left, content...))
+}
+
+// window returns the range of count rows to show so the cursor stays visible.
+func (m Model) window(count int) (int, int) {
+	visible := max(m.height-9, 3)
+	start := 0
+	if m.cursor >= visible {
+		start = m.cursor - visible + 1
+	}
+	return start, min(start+visible, count)
+}
+
+func (m Model) renderProjects() string {
+	nameStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#FFF"))
+	selectedStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#4ECDC4"))
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#888888"))
+
+	start, end := m.window(len(m.projects))
+	var lines []string
+	for i := start; i < end; i++ {
+		name := m.projects[i]
+		style := nameStyle
+		prefix := "  "
+		if i == m.cursor {
+			style = selectedStyle
+			prefix = "> "
+		}
+		files := m.provenance.Files(name)
+		lines = append(lines, prefix+style.Render(name)+"  "+dimStyle.Render(strings.Join(files, ", ")))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (m Model) renderTargets() string {
+	targetStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#FFF"))
+	fieldStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#CCCCCC"))
+	selectedStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#4ECDC4"))
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#888888"))
+	inferredStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#C678DD"))
+
+	if len(m.rows) == 0 {
+		return dimStyle.Render("No targets")
+	}
+
+	// Values are cut so the origin stays on the same line
+	valueWidth := max(m.width/3, 10)
+
+	start, end := m.window(len(m.rows))
+	var lines []string
+	for i := start; i < end; i++ {
+		r := m.rows[i]
+		prefix := "  "
+		if i == m.cursor {
+			prefix = "> "
+		}
+
+		var line string
+		if r.field == nil {
+			style := targetStyle
+			if i == m.cursor {
+				style = selectedStyle
+			}
+			line = prefix + style.Render(r.target)
+		} else {
+			style := fieldStyle
+			if i == m.cursor {
+				style = selectedStyle
+			}
+			value := r.field.Value
+			if runes := []rune(value); len(runes) > valueWidth {
+				value = string(runes[:valueWidth-1]) + "…"
+			}
+			line = prefix + "  " + style.Render(r.field.Name()) + dimStyle.Render(" = "+value)
+		}
+
+		origin := dimStyle.Render("unknown origin")
+		if r.known {
+			originStyle := dimStyle
+			if r.source.Inferred() {
+				originStyle = inferredStyle
+			}
+			origin = originStyle.Render(r.source.String())
+		}
+		lines = append(lines, line+"  "+origin)
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,516 @@
+package tasks
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/v2/textinput"
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/lipgloss/v2"
+	"github.com/lazyengs/lazynx/pkg/nxlsclient/runner"
+)
+
+// StartedMsg carries a run that was started, or why it could not start.
+type StartedMsg struct {
+	Run   *runner.Run
+	Tasks *runner.Tasks
+	Err   error
+}
+
+// ChangedMsg reports that the tasks of a run changed status or received output.
+type ChangedMsg struct {
+	Run   *runner.Run
+	Tasks *runner.Tasks
+}
+
+// DoneMsg carries the result of a run once nx exited.
+type DoneMsg struct {
+	Run    *runner.Run
+	Tasks  *runner.Tasks
+	Result runner.Result
+	Err    error
+}
+
+// StartRequest asks the program to start a run-many or affected command.
+type StartRequest struct {
+	Many runner.Many
+}
+
+// RerunRequest asks the program to run a task of tasks again.
+type RerunRequest struct {
+	Tasks *runner.Tasks
+	Task  runner.Task
+}
+
+type step int
+
+const (
+	formStep step = iota
+	listStep
+	logStep
+)
+
+// Form fields, in display order.
+const (
+	commandField  = "command"
+	targetsField  = "targets"
+	projectsField = "projects"
+	baseField     = "base"
+	parallelField = "parallel"
+)
+
+type Model struct {
+	width  int
+	height int
+	step   step
+	err    error
+
+	values     map[string]string
+	affected   bool
+	formCursor int
+	input      textinput.Model
+	editing    bool
+
+	run     *runner.Run
+	tasks   *runner.Tasks
+	list    []runner.Task
+	running bool
+	result  runner.Result
+	cursor  int
+	// scroll is how many lines the log is scrolled up from its end.
+	scroll int
+}
+
+func New(base string) Model {
+	input := textinput.New()
+	input.Prompt = ""
+	return Model{
+		values: map[string]string{baseField: base},
+		input:  input,
+	}
+}
+
+func (m Model) Init() tea.Cmd {
+	return nil
+}
+
+// Open shows the tasks of the last run, or the form when nothing ran yet.
+func (m Model) Open() Model {
+	if m.tasks == nil {
+		m.step = formStep
+	} else if m.step == formStep && m.running {
+		m.step = listStep
+	}
+	return m
+}
+
+// Editing reports whether a text field has the focus, so keys must reach the model.
+func (m Model) Editing() bool {
+	return m.editing
+}
+
+// Back returns to the previous step. ok is false on the form and the task list, where
+// going back leaves the panel; a run keeps going in the background.
+func (m Model) Back() (Model, bool) {
+	switch m.step {
+	case logStep:
+		m.step = listStep
+	case listStep:
+		if m.running {
+			return m, false
+		}
+		m.step = formStep
+		m.err = nil
+	default:
+		return m, false
+	}
+	return m, true
+}
+
+// Cancel interrupts the current run, if any.
+func (m Model) Cancel() {
+	if m.running && m.run != nil {
+		m.run.Cancel()
+	}
+}
+
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		m.input.SetWidth(max(msg.Width/2, 20))
+	case StartedMsg:
+		m.err = msg.Err
+		if msg.Err == nil {
+			m.step = listStep
+			m.run = msg.Run
+			m.tasks = msg.Tasks
+			m.list = msg.Tasks.List()
+			m.running = true
+			m.result = runner.Result{}
+			m.cursor = min(m.cursor, max(len(m.list)-1, 0))
+		}
+	case ChangedMsg:
+		if msg.Run == m.run {
+			m.list = msg.Tasks.List()
+		}
+	case DoneMsg:
+		if msg.Run == m.run {
+			m.list = msg.Tasks.List()
+			m.running = false
+			m.result = msg.Result
+		}
+	case tea.KeyMsg:
+		switch m.step {
+		case formStep:
+			return m.updateForm(msg)
+		case listStep:
+			return m.updateList(msg)
+		case logStep:
+			return m.updateLog(msg)
+		}
+	default:
+		if m.editing {
+			var cmd tea.Cmd
+			m.input, cmd = m.input.Update(msg)
+			return m, cmd
+		}
+	}
+
+	return m, nil
+}
+
+// fields returns the form fields of the selected command.
+func (m Model) fields() []string {
+	if m.affected {
+		return []string{commandField, targetsField, baseField, parallelField}
+	}
+	return []string{commandField, targetsField, projectsField, parallelField}
+}
+
+func (m Model) updateForm(msg tea.KeyMsg) (Model, tea.Cmd) {
+	fields := m.fields()
+	if m.editing {
+		switch msg.String() {
+		case "enter":
+			m.values[fields[m.formCursor]] = strings.TrimSpace(m.input.Value())
+			m.editing = false
+			m.input.Blur()
+			m.err = nil
+			return m, nil
+		case "esc":
+			m.editing = false
+			m.input.Blur()
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.input, cmd = m.input.Update(msg)
+		return m, cmd
+	}
+
+	switch msg.String() {
+	case "up", "k":
+		if m.formCursor > 0 {
+			m.formCursor--
+		}
+	case "down", "j":
+		if m.formCursor < len(fields)-1 {
+			m.formCursor++
+		}
+	case "enter":
+		if fields[m.formCursor] == commandField {
+			m.affected = !m.affected
+			return m, nil
+		}
+		m.editing = true
+		m.input.SetValue(m.values[fields[m.formCursor]])
+		m.input.CursorEnd()
+		return m, m.input.Focus()
+	case "r":
+		many, err := m.buildMany()
+		if err != nil {
+			m.err = err
+			return m, nil
+		}
+		m.err = nil
+		return m, func() tea.Msg { return StartRequest{Many: many} }
+	}
+	return m, nil
+}
+
+// buildMany turns the form into a command. Lists are entered comma separated.
+func (m Model) buildMany() (runner.Many, error) {
+	many := runner.Many{
+		Affected: m.affected,
+		Targets:  splitList(m.values[targetsField]),
+	}
+	if len(many.Targets) == 0 {
+		return many, errors.New("enter at least one target")
+	}
+	if m.affected {
+		many.Base = m.values[baseField]
+	} else {
+		many.Projects = splitList(m.values[projectsField])
+	}
+	if value := m.values[parallelField]; value != "" {
+		parallel, err := strconv.Atoi(value)
+		if err != nil || parallel < 1 {
+			return many, fmt.Errorf("parallel must be a positive number, got %q", value)
+		}
+		many.Parallel = parallel
+	}
+	return many, nil
+}
+
+func splitList(value string) []string {
+	return strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ' ' })
+}
+
+func (m Model) updateList(msg tea.KeyMsg) (Model, tea.Cmd) {
+	switch msg.String() {
+	case "up", "k":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case "down", "j":
+		if m.cursor < len(m.list)-1 {
+			m.cursor++
+		}
+	case "enter":
+		if len(m.list) > 0 {
+			m.step = logStep
+			m.scroll = 0
+		}
+	case "x":
+		m.Cancel()
+	case "r":
+		if !m.running && len(m.list) > 0 && m.list[m.cursor].Status == runner.TaskFailed {
+			request := RerunRequest{Tasks: m.tasks, Task: m.list[m.cursor]}
+			return m, func() tea.Msg { return request }
+		}
+	case "n":
+		if !m.running {
+			m.step = formStep
+			m.err = nil
+		}
+	}
+	return m, nil
+}
+
+func (m Model) updateLog(msg tea.KeyMsg) (Model, tea.Cmd) {
+	page := m.logHeight()
+	maxScroll := max(m.list[m.cursor].Output.Len()-page, 0)
+	switch msg.String() {
+	case "up", "k":
+		m.scroll = min(m.scroll+1, maxScroll)
+	case "down", "j":
+		m.scroll = max(m.scroll-1, 0)
+	case "pgup":
+		m.scroll = min(m.scroll+page, maxScroll)
+	case "pgdown":
+		m.scroll = max(m.scroll-page, 0)
+	case "r":
+		if task := m.list[m.cursor]; !m.running && task.Status == runner.TaskFailed {
+			request := RerunRequest{Tasks: m.tasks, Task: task}
+			return m, func() tea.Msg { return request }
+		}
+	}
+	return m, nil
+}
+
+func (m Model) View() string {
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("#4ECDC4"))
+	dimStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#888888"))
+
+	title := titleStyle.Render("Tasks")
+	var body, footer string
+	switch m.step {
+	case formStep:
+		body = m.renderForm()
+		footer = "↑/↓ select · enter edit · r run · esc back"
+		if m.editing {
+			footer = "enter save · esc cancel"
+		}
+	case listStep:
+		title += dimStyle.Render(" · nx " + strings.Join(m.run.Args, " "))
+		body = m.renderList()
+		footer = "↑/↓ select · enter log · x cancel · esc back"
+		if !m.running {
+			footer = "↑/↓ select · enter log · r rerun failed · n new run · esc back"
+		}
+	case logStep:
+		task := m.list[m.cursor]
+		title += dimStyle.Render(" · " + task.ID + " · " + statusText(task))
+		body = m.renderLog(task)
+		footer = "↑/↓ scroll · esc tasks"
+		if !m.running && task.Status == runner.TaskFailed {
+			footer = "↑/↓ scroll · r rerun · esc tasks"
+		}
+	}
+
+	if m.err != nil {
+		body = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#FF5722")).
+			Width(max(m.width-4, 20)).
+			Render("Error: "+m.err.Error()) + "\n\n" + body
+	}
+
+	return lipgloss.NewStyle().
+		Width(m.width).
+		Height(m.height).
+		Padding(1, 2).
+		Render(lipgloss.JoinVertical(lipgloss.Left, title, "", body, "", dimStyle.Render(footer)))
+}
+
+func (m Model) renderForm() string {
+	nameStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#FFF"))
+	selectedStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#4ECDC4"))
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#888888"))
+	valueStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#FFC107"))
+
+	placeholders := map[string]string{
+		targetsField:  "lint, test",
+		projectsField: "all projects",
+		baseField:     "main",
+		parallelField: "nx default",
+	}
+
+	var lines []string
+	for i, field := range m.fields() {
+		style := nameStyle
+		prefix := "  "
+		if i == m.formCursor {
+			style = selectedStyle
+			prefix = "> "
+		}
+
+		var value string
+		switch {
+		case field == commandField && m.affected:
+			value = valueStyle.Render("affected") + dimStyle.Render(" (enter for run-many)")
+		case field == commandField:
+			value = valueStyle.Render("run-many") + dimStyle.Render(" (enter for affected)")
+		case i == m.formCursor && m.editing:
+			value = m.input.View()
+		case m.values[field] != "":
+			value = valueStyle.Render(m.values[field])
+		default:
+			value = dimStyle.Render(placeholders[field])
+		}
+		lines = append(lines, prefix+style.Render(fmt.Sprintf("%-9s", field))+"  "+value)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// statusStyles colors task markers by status.
+var statusStyles = map[runner.TaskStatus]lipgloss.Style{
+	runner.TaskRunning:   lipgloss.NewStyle().Foreground(lipgloss.Color("#FFC107")),
+	runner.TaskSucceeded: lipgloss.NewStyle().Foreground(lipgloss.Color("#4ECDC4")),
+	runner.TaskCached:    lipgloss.NewStyle().Foreground(lipgloss.Color("#4ECDC4")),
+	runner.TaskFailed:    lipgloss.NewStyle().Foreground(lipgloss.Color("#FF5722")),
+}
+
+var statusMarkers = map[runner.TaskStatus]string{
+	runner.TaskPending:   "·",
+	runner.TaskRunning:   "●",
+	runner.TaskFinished:  "○",
+	runner.TaskSucceeded: "✔",
+	runner.TaskCached:    "✔",
+	runner.TaskFailed:    "✖",
+	runner.TaskSkipped:   "-",
+	runner.TaskCanceled:  "⊘",
+}
+
+func statusText(task runner.Task) string {
+	if task.Cache != "" {
+		return string(task.Status) + ", " + task.Cache
+	}
+	return string(task.Status)
+}
+
+func (m Model) renderList() string {
+	nameStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#FFF"))
+	selectedStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#4ECDC4"))
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#888888"))
+
+	counts := make(map[runner.TaskStatus]int)
+	for _, task := range m.list {
+		counts[task.Status]++
+	}
+	summary := fmt.Sprintf("%d tasks · %d succeeded · %d cached · %d failed",
+		len(m.list), counts[runner.TaskSucceeded], counts[runner.TaskCached], counts[runner.TaskFailed])
+	switch {
+	case m.running:
+		summary = "Running · " + summary
+	case m.result.Canceled:
+		summary = fmt.Sprintf("Canceled after %s · %s", m.result.Duration.Round(100*time.Millisecond), summary)
+	default:
+		summary = fmt.Sprintf("Exited with code %d in %s · %s", m.result.ExitCode, m.result.Duration.Round(100*time.Millisecond), summary)
+	}
+
+	lines := []string{dimStyle.Render(summary), ""}
+	if len(m.list) == 0 {
+		lines = append(lines, dimStyle.Render("Waiting for nx to report tasks..."))
+	}
+
+	visible := max(m.height-10, 3)
+	start := 0
+	if m.cursor >= visible {
+		start = m.cursor - visible + 1
+	}
+	end := min(start+visible, len(m.list))
+	for i := start; i < end; i++ {
+		task := m.list[i]
+		style := nameStyle
+		prefix := "  "
+		if i == m.cursor {
+			style = selectedStyle
+			prefix = "> "
+		}
+		markerStyle, ok := statusStyles[task.Status]
+		if !ok {
+			markerStyle = dimStyle
+		}
+		lines = append(lines, prefix+markerStyle.Render(statusMarkers[task.Status])+" "+style.Render(task.ID)+"  "+dimStyle.Render(statusText(task)))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// logHeight is the number of log lines that fit below the title and above the footer.
+func (m Model) logHeight() int {
+	return max(m.height-8, 3)
+}
+
+// renderLog shows the end of the task output, scrolled up by m.scroll lines.
+func (m Model) renderLog(task runner.Task) string {
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#888888"))
+
+	lines := task.Output.Lines()
+	if len(lines) == 0 {
+		if task.Status == runner.TaskPending {
+			return dimStyle.Render("The task did not start yet")
+		}
+		return dimStyle.Render("The task wrote no output")
+	}
+
+	visible := m.logHeight()
+	end := max(len(lines)-m.scroll, min(visible, len(lines)))
+	start := max(end-visible, 0)
+	text := make([]string, 0, end-start)
+	for _, line := range lines[start:end] {
+		text = append(text, line.Text)
+	}
+
+	var header string
+	if dropped := task.Output.Dropped(); dropped > 0 && start == 0 {
+		header = dimStyle.Render(fmt.Sprintf("… %d earlier lines dropped", dropped)) + "\n"
+	}
+	return header + lipgloss.NewStyle().MaxWidth(max(m.width-4, 20)).Render(strings.Join(text, "\n"))
+}
@@ -1,14 +1,21 @@
 package welcome
 
 import (
+	"fmt"
+	"time"
+
 	tea "github.com/charmbracelet/bubbletea/v2"
 	"github.com/charmbracelet/lipgloss/v2"
+	nxtypes "github.com/lazyengs/lazynx/pkg/nxlsclient/nx-types"
 )
 
 type Model struct {
 	workspacePath string
 	width         int
 	height        int
+	workspace     *nxtypes.NxWorkspace
+	// staleSince is when the shown workspace was saved, zero once it comes from nxls.
+	staleSince time.Time
 }
 
 func New(workspacePath string) Model {
@@ -21,6 +28,14 @@ func New(workspacePath string) Model {
 	}
 }
 
+// SetWorkspace shows a summary of workspace. A non-zero staleSince marks it as a
+// snapshot saved at that time rather than a live response.
+func (m Model) SetWorkspace(workspace *nxtypes.NxWorkspace, staleSince time.Time) Model {
+	m.workspace = workspace
+	m.staleSince = staleSince
+	return m
+}
+
 func (m Model) Init() tea.Cmd {
 	return nil
 }
@@ -68,6 +83,8 @@ func (m Model) View() string {
 		Align(lipgloss.Center).
 		Render("Workspace: " + m.workspacePath)
 
+	summary := m.renderSummary()
+
 	instructions := lipgloss.NewStyle().
 		Foreground(lipgloss.Color("#888888")).
 		Align(lipgloss.Center).
@@ -82,6 +99,7 @@ func (m Model) View() string {
 		"",
 		"",
 		workspaceInfo,
+		summary,
 		"",
 		instructions,
 	)
@@ -96,3 +114,27 @@ func (m Model) View() string {
 
 	return container
 }
+
+// renderSummary describes the loaded workspace, with a badge while it is a snapshot.
+func (m Model) renderSummary() string {
+	if m.workspace == nil {
+		return ""
+	}
+
+	line := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#888888")).
+		Render(fmt.Sprintf("%d projects · Nx %s", len(m.workspace.ProjectGraph.Nodes), m.workspace.NxVersion.Full))
+	if m.staleSince.IsZero() {
+		return line
+	}
+
+	badge := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#000")).
+		Background(lipgloss.Color("#FFC107")).
+		Padding(0, 1).
+		Render("stale")
+	age := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#888888")).
+		Render(" saved " + m.staleSince.Local().Format("Jan 2 15:04") + ", refreshing...")
+	return line + "  " + badge + age
+}
@@ -1,17 +1,37 @@
 package tui
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
 	"github.com/charmbracelet/bubbles/v2/key"
 	"github.com/charmbracelet/bubbles/v2/spinner"
 	tea "github.com/charmbracelet/bubbletea/v2"
 	"github.com/charmbracelet/lipgloss/v2"
 
+	"github.com/lazyengs/lazynx/internal/config"
+	"github.com/lazyengs/lazynx/internal/nxls"
 	"github.com/lazyengs/lazynx/internal/tui/components"
 	"github.com/lazyengs/lazynx/internal/tui/layout"
+	"github.com/lazyengs/lazynx/internal/tui/models/affected"
+	"github.com/lazyengs/lazynx/internal/tui/models/boundaries"
+	"github.com/lazyengs/lazynx/internal/tui/models/ci"
+	"github.com/lazyengs/lazynx/internal/tui/models/folders"
+	"github.com/lazyengs/lazynx/internal/tui/models/generate"
+	"github.com/lazyengs/lazynx/internal/tui/models/inputs"
+	"github.com/lazyengs/lazynx/internal/tui/models/provenance"
+	"github.com/lazyengs/lazynx/internal/tui/models/tasks"
 	"github.com/lazyengs/lazynx/internal/tui/models/welcome"
 	"github.com/lazyengs/lazynx/internal/tui/utils"
 	"github.com/lazyengs/lazynx/pkg/nxlsclient"
+	"github.com/lazyengs/lazynx/pkg/nxlsclient/cipe"
 	"github.com/lazyengs/lazynx/pkg/nxlsclient/commands"
+	"github.com/lazyengs/lazynx/pkg/nxlsclient/diff"
+	nxinputs "github.com/lazyengs/lazynx/pkg/nxlsclient/inputs"
+	nxtypes "github.com/lazyengs/lazynx/pkg/nxlsclient/nx-types"
+	"github.com/lazyengs/lazynx/pkg/nxlsclient/snapshot"
 	"go.uber.org/zap"
 )
 
@@ -20,15 +40,33 @@ type activeView uint
 const (
 	spinnerView activeView = iota // Initial loading state
 	welcomeView
+	affectedView
+	boundariesView
+	provenanceView
+	generateView
+	tasksView
+	ciView
+	foldersView
+	inputsView
 )
 
 type keyMap struct {
-	Up    key.Binding
-	Down  key.Binding
-	Left  key.Binding
-	Right key.Binding
-	Help  key.Binding
-	Quit  key.Binding
+	Up         key.Binding
+	Down       key.Binding
+	Left       key.Binding
+	Right      key.Binding
+	Help       key.Binding
+	Metrics    key.Binding
+	Affected   key.Binding
+	Boundaries key.Binding
+	Provenance key.Binding
+	Generate   key.Binding
+	Tasks      key.Binding
+	CI         key.Binding
+	Folders    key.Binding
+	Inputs     key.Binding
+	Back       key.Binding
+	Quit       key.Binding
 }
 
 var globalKeys = keyMap{
@@ -52,6 +90,46 @@ var globalKeys = keyMap{
 		key.WithKeys("?"),
 		key.WithHelp("?", "toggle help"),
 	),
+	Metrics: key.NewBinding(
+		key.WithKeys("D"),
+		key.WithHelp("D", "toggle nxls metrics"),
+	),
+	Affected: key.NewBinding(
+		key.WithKeys("a"),
+		key.WithHelp("a", "show affected projects"),
+	),
+	Boundaries: key.NewBinding(
+		key.WithKeys("b"),
+		key.WithHelp("b", "check module boundaries"),
+	),
+	Provenance: key.NewBinding(
+		key.WithKeys("p"),
+		key.WithHelp("p", "show configuration sources"),
+	),
+	Generate: key.NewBinding(
+		key.WithKeys("g"),
+		key.WithHelp("g", "run a generator"),
+	),
+	Tasks: key.NewBinding(
+		key.WithKeys("t"),
+		key.WithHelp("t", "run tasks"),
+	),
+	CI: key.NewBinding(
+		key.WithKeys("c"),
+		key.WithHelp("c", "show CI pipelines"),
+	),
+	Folders: key.NewBinding(
+		key.WithKeys("f"),
+		key.WithHelp("f", "browse project folders"),
+	),
+	Inputs: key.NewBinding(
+		key.WithKeys("i"),
+		key.WithHelp("i", "show target inputs"),
+	),
+	Back: key.NewBinding(
+		key.WithKeys("esc"),
+		key.WithHelp("esc", "go back"),
+	),
 	Quit: key.NewBinding(
 		key.WithKeys("q", "ctrl+c"),
 		key.WithHelp("q/ctrl+c", "quit"),
@@ -63,13 +141,63 @@ func getKeysForView(view activeView) []key.Binding {
 	case welcomeView:
 		// For welcome view, show most relevant keys
 		return []key.Binding{
+			globalKeys.Affected,
+			globalKeys.Boundaries,
+			globalKeys.Provenance,
+			globalKeys.Generate,
+			globalKeys.Tasks,
+			globalKeys.CI,
+			globalKeys.Folders,
+			globalKeys.Inputs,
+			globalKeys.Help,
+			globalKeys.Metrics,
+			globalKeys.Quit,
+		}
+	case affectedView:
+		return []key.Binding{
+			globalKeys.Up,
+			globalKeys.Down,
+			globalKeys.Affected,
+			globalKeys.Back,
+			globalKeys.Help,
+			globalKeys.Metrics,
+			globalKeys.Quit,
+		}
+	case boundariesView:
+		return []key.Binding{
+			globalKeys.Up,
+			globalKeys.Down,
+			globalKeys.Boundaries,
+			globalKeys.Back,
+			globalKeys.Help,
+			globalKeys.Metrics,
+			globalKeys.Quit,
+		}
+	case generateView, tasksView, ciView, inputsView:
+		return []key.Binding{
+			globalKeys.Up,
+			globalKeys.Down,
+			globalKeys.Back,
+			globalKeys.Help,
+			globalKeys.Metrics,
+			globalKeys.Quit,
+		}
+	case provenanceView, foldersView:
+		return []key.Binding{
+			globalKeys.Up,
+			globalKeys.Down,
+			globalKeys.Left,
+			globalKeys.Right,
+			globalKeys.Back,
 			globalKeys.Help,
+			globalKeys.Metrics,
 			globalKeys.Quit,
 		}
 	case spinnerView:
 		// For spinner view, show minimal keys
 		return []key.Binding{
 			globalKeys.Help,
+			globalKeys.Metrics,
 			globalKeys.Quit,
 		}
 	default:
@@ -79,21 +207,45 @@ func getKeysForView(view activeView) []key.Binding {
 }
 
 type ProgramModel struct {
-	welcomeModel welcome.Model
-	spinnerModel spinner.Model
-	activeView   activeView
+	welcomeModel    welcome.Model
+	affectedModel   affected.Model
+	boundariesModel boundaries.Model
+	provenanceModel provenance.Model
+	generateModel   generate.Model
+	tasksModel      tasks.Model
+	ciModel         ci.Model
+	foldersModel    folders.Model
+	inputsModel     inputs.Model
+	spinnerModel    spinner.Model
+	activeView      activeView
 
 	showHelp      bool
 	helpComponent *components.HelpComponent
 
+	showMetrics      bool
+	metricsComponent *components.MetricsComponent
+
+	toastComponent *components.ToastComponent
+
 	viewport      tea.WindowSizeMsg
 	client        *nxlsclient.Client
 	logger        *zap.SugaredLogger
 	errorMsg      string
+	initErr       error
+	health        nxlsclient.HealthReport
 	workspacePath string
+	affectedBase  string
+	// workspace is the last snapshot, compared with the next one on refresh.
+	workspace *nxtypes.NxWorkspace
+	// stale is set while workspace comes from the previous session.
+	stale     bool
+	snapshots *snapshot.Store
+	// inputSnapshots records target inputs after successful runs, to explain cache misses.
+	inputSnapshots *nxinputs.Store
+	config         *config.Config
 }
 
-func createProgram(client *nxlsclient.Client, logger *zap.SugaredLogger, workspacePath string) ProgramModel {
+func createProgram(client *nxlsclient.Client, logger *zap.SugaredLogger, workspacePath string, config *config.Config) ProgramModel {
 	s := spinner.New()
 	s.Spinner = spinner.Dot
 	s.Style = lipgloss.NewStyle().Foreground(lipgloss.Color("205"))
@@ -101,13 +253,27 @@ func createProgram(client *nxlsclient.Client, logger *zap.SugaredLogger, workspa
 	helpComp := components.NewHelpComponent()
 
 	return ProgramModel{
-		welcomeModel:  welcome.New(workspacePath),
-		spinnerModel:  s,
-		helpComponent: helpComp,
-		client:        client,
-		activeView:    spinnerView,
-		logger:        logger,
-		workspacePath: workspacePath,
+		welcomeModel:     welcome.New(workspacePath),
+		affectedModel:    affected.New(config.AffectedBase),
+		boundariesModel:  boundaries.New(),
+		provenanceModel:  provenance.New(workspacePath),
+		generateModel:    generate.New(),
+		tasksModel:       tasks.New(config.AffectedBase),
+		ciModel:          ci.New(config.CI.Disabled),
+		foldersModel:     folders.New(),
+		inputsModel:      inputs.New(),
+		spinnerModel:     s,
+		helpComponent:    helpComp,
+		metricsComponent: components.NewMetricsComponent(client.Metrics),
+		toastComponent:   components.NewToastComponent(),
+		client:           client,
+		activeView:       spinnerView,
+		logger:           logger,
+		workspacePath:    workspacePath,
+		affectedBase:     config.AffectedBase,
+		snapshots:        nxls.OpenSnapshotStore(config),
+		inputSnapshots:   nxls.OpenInputStore(config),
+		config:           config,
 	}
 }
 
@@ -117,6 +283,7 @@ func (m ProgramModel) Init() tea.Cmd {
 		m.welcomeModel.Init(),
 		m.spinnerModel.Tick,
 		m.helpComponent.Init(),
+		nxls.LoadSnapshot(m.snapshots, m.workspacePath, m.logger),
 	)
 }
 
@@ -130,10 +297,46 @@ func (m ProgramModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		helpModel, helpCmd := m.helpComponent.Update(msg)
 		m.helpComponent = helpModel.(*components.HelpComponent)
 		cmds = append(cmds, helpCmd)
+		metricsModel, metricsCmd := m.metricsComponent.Update(msg)
+		m.metricsComponent = metricsModel.(*components.MetricsComponent)
+		cmds = append(cmds, metricsCmd)
 		m.welcomeModel, cmd = m.welcomeModel.Update(msg)
 		cmds = append(cmds, cmd)
+		m.affectedModel, cmd = m.affectedModel.Update(msg)
+		cmds = append(cmds, cmd)
+		m.boundariesModel, cmd = m.boundariesModel.Update(msg)
+		cmds = append(cmds, cmd)
+		m.provenanceModel, cmd = m.provenanceModel.Update(msg)
+		cmds = append(cmds, cmd)
+		m.generateModel, cmd = m.generateModel.Update(msg)
+		cmds = append(cmds, cmd)
+		m.tasksModel, cmd = m.tasksModel.Update(msg)
+		cmds = append(cmds, cmd)
+		m.ciModel, cmd = m.ciModel.Update(msg)
+		cmds = append(cmds, cmd)
+		m.foldersModel, cmd = m.foldersModel.Update(msg)
+		cmds = append(cmds, cmd)
+		m.inputsModel, cmd = m.inputsModel.Update(msg)
+		cmds = append(cmds, cmd)
 
 	case tea.KeyMsg:
+		// Text fields take every key, global bindings included
+		if m.activeView == generateView && m.generateModel.Editing() {
+			m.generateModel, cmd = m.generateModel.Update(msg)
+			return m, cmd
+		}
+		if m.activeView == tasksView && m.tasksModel.Editing() {
+			m.tasksModel, cmd = m.tasksModel.Update(msg)
+			return m, cmd
+		}
+		if m.activeView == foldersView && m.foldersModel.Editing() {
+			m.foldersModel, cmd = m.foldersModel.Update(msg)
+			return m, cmd
+		}
+		if m.activeView == inputsView && m.inputsModel.Editing() {
+			m.inputsModel, cmd = m.inputsModel.Update(msg)
+			return m, cmd
+		}
 		switch {
 		case key.Matches(msg, globalKeys.Help):
 			m.showHelp = !m.showHelp
@@ -142,7 +345,74 @@ func (m ProgramModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.helpComponent.SetBindings(getKeysForView(m.activeView))
 			}
 			return m, nil
+		case key.Matches(msg, globalKeys.Metrics):
+			m.showMetrics = !m.showMetrics
+			if m.showMetrics {
+				return m, m.metricsComponent.Tick()
+			}
+			return m, nil
+		case key.Matches(msg, globalKeys.Affected) && (m.activeView == welcomeView || m.activeView == affectedView):
+			// Entering the view or pressing a again recomputes against the current changes
+			m.activeView = affectedView
+			m.affectedModel = m.affectedModel.Loading()
+			return m, nxls.ComputeAffected(context.Background(), m.client, m.affectedBase, m.logger)
+		case key.Matches(msg, globalKeys.Boundaries) && (m.activeView == welcomeView || m.activeView == boundariesView):
+			m.activeView = boundariesView
+			m.boundariesModel = m.boundariesModel.Loading()
+			return m, nxls.CheckBoundaries(m.workspace, m.workspacePath, m.config, m.logger)
+		case key.Matches(msg, globalKeys.Provenance) && m.activeView == welcomeView:
+			m.activeView = provenanceView
+			m.provenanceModel = m.provenanceModel.SetWorkspace(m.workspace)
+			return m, nil
+		case key.Matches(msg, globalKeys.Generate) && m.activeView == welcomeView:
+			m.activeView = generateView
+			m.generateModel = m.generateModel.Loading()
+			return m, nxls.LoadGenerators(context.Background(), m.client, m.logger)
+		case key.Matches(msg, globalKeys.Tasks) && m.activeView == welcomeView:
+			m.activeView = tasksView
+			m.tasksModel = m.tasksModel.Open()
+			return m, nil
+		case key.Matches(msg, globalKeys.CI) && m.activeView == welcomeView:
+			m.activeView = ciView
+			return m, nil
+		case key.Matches(msg, globalKeys.Folders) && m.activeView == welcomeView:
+			m.activeView = foldersView
+			m.foldersModel = m.foldersModel.Loading()
+			return m, nxls.LoadFolderTree(context.Background(), m.client, m.logger)
+		case key.Matches(msg, globalKeys.Inputs) && m.activeView == welcomeView:
+			m.activeView = inputsView
+			m.inputsModel = m.inputsModel.SetWorkspace(m.workspace)
+			return m, nil
+		case key.Matches(msg, globalKeys.Back) && m.activeView == inputsView:
+			var handled bool
+			if m.inputsModel, handled = m.inputsModel.Back(); !handled {
+				m.activeView = welcomeView
+			}
+			return m, nil
+		case key.Matches(msg, globalKeys.Back) && m.activeView == foldersView:
+			var handled bool
+			if m.foldersModel, handled = m.foldersModel.Back(); !handled {
+				m.activeView = welcomeView
+			}
+			return m, nil
+		case key.Matches(msg, globalKeys.Back) && m.activeView == tasksView:
+			var handled bool
+			if m.tasksModel, handled = m.tasksModel.Back(); !handled {
+				m.activeView = welcomeView
+			}
+			return m, nil
+		case key.Matches(msg, globalKeys.Back) && m.activeView == generateView:
+			var handled bool
+			if m.generateModel, handled = m.generateModel.Back(); !handled {
+				m.activeView = welcomeView
+			}
+			return m, nil
+		case key.Matches(msg, globalKeys.Back) && (m.activeView == affectedView || m.activeView == boundariesView || m.activeView == provenanceView || m.activeView == ciView):
+			m.activeView = welcomeView
+			return m, nil
 		case key.Matches(msg, globalKeys.Quit):
+			// Nx runs in its own session, so it would outlive lazynx
+			m.tasksModel.Cancel()
 			return m, tea.Quit
 		default:
 			// Reset error state on any key press
@@ -151,10 +421,134 @@ func (m ProgramModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return m, nil
 			}
 		}
+	case nxls.ClientMsg:
+		m.client = msg.Client
+		return m, nil
 	case *commands.InitializeRequestResult:
 		// Initialization completed successfully
 		m.activeView = welcomeView
 		return m, nil
+	case components.MetricsTickMsg:
+		// Keep refreshing only while the panel is open
+		if m.showMetrics {
+			return m, m.metricsComponent.Tick()
+		}
+		return m, nil
+	case string:
+		if msg == commands.RefreshWorkspaceNotificationMethod {
+			return m, nxls.ReloadWorkspace(context.Background(), m.client, m.logger)
+		}
+	case nxls.WorkspaceMsg:
+		if msg.Err != nil {
+			return m, nil
+		}
+		if msg.Stale {
+			// The live workspace wins if it arrived first
+			if m.workspace != nil {
+				return m, nil
+			}
+			m.workspace = msg.Workspace
+			m.stale = true
+			m.provenanceModel = m.provenanceModel.SetWorkspace(msg.Workspace)
+			m.welcomeModel = m.welcomeModel.SetWorkspace(msg.Workspace, msg.SavedAt)
+			if m.activeView == spinnerView && m.initErr == nil {
+				m.activeView = welcomeView
+			}
+			return m, nil
+		}
+
+		cmds = append(cmds, nxls.SaveSnapshot(m.snapshots, m.workspacePath, msg.Workspace, m.logger))
+		if m.workspace != nil {
+			changes := diff.Workspaces(m.workspace, msg.Workspace)
+			if !changes.Empty() {
+				title := "Workspace changed: "
+				if m.stale {
+					title = "Changed since last session: "
+				}
+				m.logger.Infow("Workspace changed", "summary", changes.Summary(), "sinceLastSession", m.stale)
+				m.affectedModel = m.affectedModel.Highlight(changes.Touched())
+				cmds = append(cmds, m.toastComponent.Show(title+changes.Summary()))
+			}
+		}
+		m.workspace = msg.Workspace
+		m.stale = false
+		m.provenanceModel = m.provenanceModel.SetWorkspace(msg.Workspace)
+		m.welcomeModel = m.welcomeModel.SetWorkspace(msg.Workspace, time.Time{})
+		return m, tea.Batch(cmds...)
+	case components.ToastExpiredMsg:
+		m.toastComponent.Update(msg)
+		return m, nil
+	case affected.ResultMsg:
+		m.affectedModel, cmd = m.affectedModel.Update(msg)
+		return m, cmd
+	case boundaries.ResultMsg:
+		m.boundariesModel, cmd = m.boundariesModel.Update(msg)
+		return m, cmd
+	case folders.ResultMsg:
+		m.foldersModel, cmd = m.foldersModel.Update(msg)
+		return m, cmd
+	case inputs.ResolveRequest:
+		return m, nxls.ResolveInputs(context.Background(), m.workspace, m.workspacePath, msg.Project, msg.Target, m.logger)
+	case inputs.SnapshotRequest:
+		return m, nxls.SaveInputSnapshot(context.Background(), m.inputSnapshots, m.workspace, m.workspacePath, msg.Project, msg.Target, m.logger)
+	case inputs.WhyNotCachedRequest:
+		return m, nxls.WhyNotCached(context.Background(), m.inputSnapshots, m.workspace, m.workspacePath, msg.Project, msg.Target, m.logger)
+	case inputs.SnapshotMsg, inputs.DiffMsg:
+		m.inputsModel, cmd = m.inputsModel.Update(msg)
+		return m, cmd
+	case inputs.ResultMsg:
+		m.inputsModel, cmd = m.inputsModel.Update(msg)
+		return m, cmd
+	case generate.OptionsRequest:
+		return m, nxls.LoadGeneratorOptions(context.Background(), m.client, msg.Generator, m.logger)
+	case generate.DryRunRequest:
+		return m, nxls.RunGenerator(context.Background(), m.client.NxWorkspacePath, msg.Invocation, true, m.logger)
+	case generate.ApplyRequest:
+		return m, nxls.RunGenerator(context.Background(), m.client.NxWorkspacePath, msg.Invocation, false, m.logger)
+	case generate.AppliedMsg:
+		m.generateModel, cmd = m.generateModel.Update(msg)
+		if msg.Err == nil {
+			return m, tea.Batch(cmd, m.toastComponent.Show(fmt.Sprintf("Generated %d file changes", len(msg.Result.Changes))))
+		}
+		return m, cmd
+	case generate.GeneratorsMsg, generate.OptionsMsg, generate.PreviewMsg:
+		m.generateModel, cmd = m.generateModel.Update(msg)
+		return m, cmd
+	case tasks.StartRequest:
+		return m, nxls.StartTasks(context.Background(), m.client.NxWorkspacePath, msg.Many, m.workspace, m.logger)
+	case tasks.RerunRequest:
+		return m, nxls.RerunTask(context.Background(), m.client.NxWorkspacePath, msg.Tasks, msg.Task, m.logger)
+	case tasks.StartedMsg:
+		m.tasksModel, cmd = m.tasksModel.Update(msg)
+		if msg.Err != nil {
+			return m, cmd
+		}
+		return m, tea.Batch(cmd, nxls.WatchTasks(msg.Run, msg.Tasks, m.logger))
+	case tasks.ChangedMsg:
+		m.tasksModel, cmd = m.tasksModel.Update(msg)
+		return m, tea.Batch(cmd, nxls.WatchTasks(msg.Run, msg.Tasks, m.logger))
+	case tasks.DoneMsg:
+		m.tasksModel, cmd = m.tasksModel.Update(msg)
+		return m, tea.Batch(cmd, m.toastComponent.Show(tasksSummary(msg)),
+			nxls.SaveRunSnapshots(context.Background(), m.inputSnapshots, m.workspace, m.workspacePath, msg.Tasks.List(), m.logger))
+	case cipe.Update:
+		m.ciModel, cmd = m.ciModel.Update(msg)
+		if len(msg.Events) > 0 {
+			return m, tea.Batch(cmd, m.toastComponent.Show(ciSummary(msg.Events)))
+		}
+		return m, cmd
+	case ci.NotConnectedMsg:
+		m.ciModel, cmd = m.ciModel.Update(msg)
+		return m, cmd
+	case nxlsclient.HealthReport:
+		m.health = msg
+		return m, nil
+	case nxls.InitFailedMsg:
+		// The client failed before it could initialize, keep the spinner screen
+		// but replace the spinner with the reason, even over a stale snapshot
+		m.initErr = msg.Err
+		m.activeView = spinnerView
+		return m, nil
 	}
 
 	if m.activeView == welcomeView {
@@ -162,6 +556,46 @@ func (m ProgramModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		cmds = append(cmds, cmd)
 	}
 
+	if m.activeView == affectedView {
+		m.affectedModel, cmd = m.affectedModel.Update(msg)
+		cmds = append(cmds, cmd)
+	}
+
+	if m.activeView == boundariesView {
+		m.boundariesModel, cmd = m.boundariesModel.Update(msg)
+		cmds = append(cmds, cmd)
+	}
+
+	if m.activeView == generateView {
+		m.generateModel, cmd = m.generateModel.Update(msg)
+		cmds = append(cmds, cmd)
+	}
+
+	if m.activeView == tasksView {
+		m.tasksModel, cmd = m.tasksModel.Update(msg)
+		cmds = append(cmds, cmd)
+	}
+
+	if m.activeView == ciView {
+		m.ciModel, cmd = m.ciModel.Update(msg)
+		cmds = append(cmds, cmd)
+	}
+
+	if m.activeView == foldersView {
+		m.foldersModel, cmd = m.foldersModel.Update(msg)
+		cmds = append(cmds, cmd)
+	}
+
+	if m.activeView == inputsView {
+		m.inputsModel, cmd = m.inputsModel.Update(msg)
+		cmds = append(cmds, cmd)
+	}
+
+	if m.activeView == provenanceView {
+		m.provenanceModel, cmd = m.provenanceModel.Update(msg)
+		cmds = append(cmds, cmd)
+	}
+
 	if m.activeView == spinnerView {
 		m.spinnerModel, cmd = m.spinnerModel.Update(msg)
 		cmds = append(cmds, cmd)
@@ -183,8 +617,41 @@ func (m ProgramModel) View() string {
 			lipgloss.Center,
 			content,
 			"",
+			renderHealth(m.health),
 			helpFooter,
 		)
+	} else if m.activeView == affectedView {
+		baseView = m.affectedModel.View()
+	} else if m.activeView == boundariesView {
+		baseView = m.boundariesModel.View()
+	} else if m.activeView == provenanceView {
+		baseView = m.provenanceModel.View()
+	} else if m.activeView == generateView {
+		baseView = m.generateModel.View()
+	} else if m.activeView == tasksView {
+		baseView = m.tasksModel.View()
+	} else if m.activeView == ciView {
+		baseView = m.ciModel.View()
+	} else if m.activeView == foldersView {
+		baseView = m.foldersModel.View()
+	} else if m.activeView == inputsView {
+		baseView = m.inputsModel.View()
+	} else if m.activeView == spinnerView && m.initErr != nil {
+		baseView = lipgloss.JoinVertical(
+			lipgloss.Center,
+			"",
+			lipgloss.NewStyle().
+				Foreground(lipgloss.Color("#FF5722")).
+				Render("Failed to start the Nx language server"),
+			"",
+			lipgloss.NewStyle().
+				Foreground(lipgloss.Color("#FFF")).
+				Render(m.initErr.Error()),
+			"",
+			lipgloss.NewStyle().
+				Foreground(lipgloss.Color("#888888")).
+				Render(initErrorHint(m.initErr)),
+		)
 	} else if m.activeView == spinnerView {
 		baseView = lipgloss.JoinVertical(
 			lipgloss.Center,
@@ -226,49 +693,131 @@ func (m ProgramModel) View() string {
 
 	// If help is shown, create a true overlay that preserves the background
 	if m.showHelp {
-		// Ensure base view fills the entire viewport
-		styledBaseView := lipgloss.NewStyle().
-			Width(m.viewport.Width).
-			Height(m.viewport.Height).
-			Render(baseView)
+		return m.renderOverlay(baseView, m.helpComponent.View(), "Press ? again to close help")
+	}
 
-		// Render the help modal content
-		modal := m.helpComponent.View()
+	if m.showMetrics {
+		return m.renderOverlay(baseView, m.metricsComponent.View(), "Press D again to close metrics")
+	}
 
-		// Add instruction text below the modal
-		instructions := lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#888888")).
-			AlignHorizontal(lipgloss.Center).
-			Render("Press ? again to close help")
+	if m.toastComponent.Visible() && baseView != "" {
+		toast := m.toastComponent.View()
+		x := max(m.viewport.Width-lipgloss.Width(toast)-1, 0)
+		return layout.PlaceOverlay(x, 1, toast, baseView)
+	}
 
-		modalContent := lipgloss.JoinVertical(
-			lipgloss.Center,
-			modal,
-			"",
-			instructions,
-		)
+	return baseView
+}
+
+// renderOverlay centers a modal with closing instructions on top of the base view.
+func (m ProgramModel) renderOverlay(baseView, modal, instructionText string) string {
+	// Ensure base view fills the entire viewport
+	styledBaseView := lipgloss.NewStyle().
+		Width(m.viewport.Width).
+		Height(m.viewport.Height).
+		Render(baseView)
+
+	// Add instruction text below the modal
+	instructions := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#888888")).
+		AlignHorizontal(lipgloss.Center).
+		Render(instructionText)
+
+	modalContent := lipgloss.JoinVertical(
+		lipgloss.Center,
+		modal,
+		"",
+		instructions,
+	)
 
-		// Calculate center position for the modal
-		modalWidth := lipgloss.Width(modalContent)
-		modalHeight := lipgloss.Height(modalContent)
+	// Calculate center position for the modal
+	modalWidth := lipgloss.Width(modalContent)
+	modalHeight := lipgloss.Height(modalContent)
 
-		// Center the modal on the screen
-		x := (m.viewport.Width - modalWidth) / 2
-		y := (m.viewport.Height - modalHeight) / 2
+	// Center the modal on the screen
+	x := (m.viewport.Width - modalWidth) / 2
+	y := (m.viewport.Height - modalHeight) / 2
 
-		// Use PlaceOverlay to place the modal on top of the base view
-		// This will preserve the background while showing the modal on top
-		overlay := layout.PlaceOverlay(x, y, modalContent, styledBaseView)
+	// Use PlaceOverlay to place the modal on top of the base view
+	// This will preserve the background while showing the modal on top
+	return layout.PlaceOverlay(x, y, modalContent, styledBaseView)
+}
 
-		return overlay
+// renderHealth renders the last nxls health report as a single status line.
+func renderHealth(report nxlsclient.HealthReport) string {
+	color := "#888888"
+	switch report.Status {
+	case nxlsclient.HealthStatusHealthy:
+		color = "#4ECDC4"
+	case nxlsclient.HealthStatusDegraded:
+		color = "#FFC107"
+	case nxlsclient.HealthStatusUnresponsive:
+		color = "#FF5722"
+	case "":
+		report.Status = nxlsclient.HealthStatusUnknown
 	}
 
-	return baseView
+	line := "nxls: " + string(report.Status)
+	if report.Stats != nil {
+		line += fmt.Sprintf(" · %d MiB · %.0f%% cpu", report.Stats.RSSBytes>>20, report.Stats.CPUPercent)
+	}
+	if len(report.Reasons) > 0 {
+		line += " · " + report.Reasons[0]
+	}
+
+	return lipgloss.NewStyle().
+		Foreground(lipgloss.Color(color)).
+		Render(line)
+}
+
+// tasksSummary describes a finished run in a toast.
+func tasksSummary(msg tasks.DoneMsg) string {
+	failed := len(msg.Tasks.Failed())
+	switch {
+	case msg.Result.Canceled:
+		return "Tasks canceled"
+	case failed > 0:
+		return fmt.Sprintf("%d of %d tasks failed", failed, len(msg.Tasks.List()))
+	case !msg.Result.Success():
+		return fmt.Sprintf("nx exited with code %d", msg.Result.ExitCode)
+	}
+	return fmt.Sprintf("%d tasks succeeded", len(msg.Tasks.List()))
+}
+
+// ciSummary describes pipeline changes in a toast, failures first.
+func ciSummary(events []cipe.Event) string {
+	event := events[len(events)-1]
+	for _, e := range events {
+		if e.Failed() {
+			event = e
+			break
+		}
+	}
+	text := "CI: " + event.String()
+	if len(events) > 1 {
+		text += fmt.Sprintf(" (+%d more)", len(events)-1)
+	}
+	return text
+}
+
+// initErrorHint returns a suggestion for the user based on why initialization failed.
+func initErrorHint(err error) string {
+	var runtimeErr *nxlsclient.NodeRuntimeError
+	if errors.As(err, &runtimeErr) {
+		switch runtimeErr.Reason {
+		case nxlsclient.NodeRuntimeNotFound:
+			return "Install Node.js " + runtimeErr.Required.String() + " or later and make sure it is on your PATH. Press q to quit"
+		case nxlsclient.NodeRuntimeTooOld:
+			return "Switch to Node.js " + runtimeErr.Required.String() + " or later (nvm, volta or .nvmrc). Press q to quit"
+		}
+	}
+
+	return "Check the logs for details. Press q to quit"
 }
 
-func Create(client *nxlsclient.Client, logger *zap.SugaredLogger, workspacePath string) *tea.Program {
+func Create(client *nxlsclient.Client, logger *zap.SugaredLogger, workspacePath string, config *config.Config) *tea.Program {
 	return tea.NewProgram(
-		createProgram(client, logger, workspacePath),
+		createProgram(client, logger, workspacePath, config),
 		tea.WithAltScreen(),
 		tea.WithKeyboardEnhancements(tea.WithUniformKeyLayout),
 		tea.WithGraphemeClustering())
@@ -0,0 +1,42 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+)
+
+// EditorClosedMsg is sent when the editor opened by OpenInEditor exits.
+type EditorClosedMsg struct {
+	Err error
+}
+
+// OpenInEditor suspends the program and opens file at line in $VISUAL or $EDITOR,
+// falling back to vi.
+func OpenInEditor(file string, line int) tea.Cmd {
+	// EDITOR may carry flags, such as "code --wait"
+	fields := strings.Fields(os.Getenv("VISUAL"))
+	if len(fields) == 0 {
+		fields = strings.Fields(os.Getenv("EDITOR"))
+	}
+	if len(fields) == 0 {
+		fields = []string{"vi"}
+	}
+	args := fields[1:]
+	switch filepath.Base(fields[0]) {
+	case "code", "code-insiders", "cursor", "codium":
+		args = append(args, "-g", fmt.Sprintf("%s:%d", file, line))
+	case "zed", "subl":
+		args = append(args, fmt.Sprintf("%s:%d", file, line))
+	default:
+		args = append(args, fmt.Sprintf("+%d", line), file)
+	}
+
+	return tea.ExecProcess(exec.Command(fields[0], args...), func(err error) tea.Msg {
+		return EditorClosedMsg{Err: err}
+	})
+}
@@ -0,0 +1,245 @@
+package affected
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/lazyengs/lazynx/pkg/nxlsclient/commands"
+	"github.com/lazyengs/lazynx/pkg/nxlsclient/gitfiles"
+	"github.com/lazyengs/lazynx/pkg/nxlsclient/graph"
+	nxtypes "github.com/lazyengs/lazynx/pkg/nxlsclient/nx-types"
+)
+
+// ReasonKind tells why a project is affected.
+type ReasonKind string
+
+const (
+	// ReasonTouched means a file owned by the project changed.
+	ReasonTouched ReasonKind = "touched"
+	// ReasonDependency means an affected project is a direct dependency of the project.
+	ReasonDependency ReasonKind = "dependency"
+)
+
+// Reason is a single cause for a project being affected.
+type Reason struct {
+	Kind       ReasonKind `json:"kind"`
+	File       string     `json:"file,omitempty"`       // File is the changed file for ReasonTouched.
+	Dependency string     `json:"dependency,omitempty"` // Dependency is the affected dependency for ReasonDependency.
+}
+
+func (r Reason) String() string {
+	if r.Kind == ReasonTouched {
+		return "touched file " + r.File
+	}
+	return "depends on " + r.Dependency
+}
+
+// Project is an affected project with every reason found for it.
+type Project struct {
+	Name    string   `json:"name"`
+	Reasons []Reason `json:"reasons"`
+}
+
+// Touched reports whether one of the project's own files changed.
+func (p Project) Touched() bool {
+	return len(p.Reasons) > 0 && p.Reasons[0].Kind == ReasonTouched
+}
+
+// Result is the outcome of an affected computation.
+type Result struct {
+	Projects     []Project `json:"projects"`               // Projects are sorted by name.
+	ChangedFiles []string  `json:"changedFiles"`           // ChangedFiles are the inputs, sorted.
+	UnownedFiles []string  `json:"unownedFiles,omitempty"` // UnownedFiles changed but belong to no project.
+}
+
+// Names returns the names of the affected projects.
+func (r *Result) Names() []string {
+	names := make([]string, len(r.Projects))
+	for i, p := range r.Projects {
+		names[i] = p.Name
+	}
+	return names
+}
+
+// Project returns the affected project called name.
+func (r *Result) Project(name string) (Project, bool) {
+	i := sort.Search(len(r.Projects), func(i int) bool { return r.Projects[i].Name >= name })
+	if i < len(r.Projects) && r.Projects[i].Name == name {
+		return r.Projects[i], true
+	}
+	return Project{}, false
+}
+
+// Resolver maps changed files to affected projects.
+type Resolver struct {
+	graph *graph.Graph
+	// owners maps files to the projects that own them.
+	owners map[string][]string
+	// roots are the project roots, longest first, for files missing from the file maps.
+	roots []projectRoot
+}
+
+type projectRoot struct {
+	root    string
+	project string
+}
+
+// NewResolver builds a Resolver from a workspace. File ownership comes from the
+// workspace ProjectFileMap and from sourceMap, the result of
+// SendSourceMapFilesToProjectsMapRequest, which may be nil. Files found in neither are
+// attributed to the project with the longest matching root.
+func NewResolver(workspace *nxtypes.NxWorkspace, sourceMap map[string][]string) *Resolver {
+	r := &Resolver{
+		graph:  graph.New(&workspace.ProjectGraph).WithoutExternal(),
+		owners: make(map[string][]string),
+	}
+
+	if workspace.ProjectFileMap != nil {
+		for project, files := range *workspace.ProjectFileMap {
+			for _, f := range files {
+				r.addOwner(f.File, project)
+			}
+		}
+	}
+	for file, projects := range sourceMap {
+		for _, project := range projects {
+			r.addOwner(file, project)
+		}
+	}
+
+	for name, node := range workspace.ProjectGraph.Nodes {
+		r.roots = append(r.roots, projectRoot{root: cleanRoot(node.Data.Root), project: name})
+	}
+	sort.Slice(r.roots, func(i, j int) bool {
+		if len(r.roots[i].root) != len(r.roots[j].root) {
+			return len(r.roots[i].root) > len(r.roots[j].root)
+		}
+		return r.roots[i].project < r.roots[j].project
+	})
+
+	return r
+}
+
+func (r *Resolver) addOwner(file, project string) {
+	file = path.Clean(file)
+	for _, existing := range r.owners[file] {
+		if existing == project {
+			return
+		}
+	}
+	r.owners[file] = append(r.owners[file], project)
+}
+
+// cleanRoot normalises a project root, mapping the workspace root to "".
+func cleanRoot(root string) string {
+	root = path.Clean(strings.TrimPrefix(root, "./"))
+	if root == "." {
+		return ""
+	}
+	return root
+}
+
+// Owners returns the projects owning file, sorted by name.
+func (r *Resolver) Owners(file string) []string {
+	file = path.Clean(file)
+	if owners, ok := r.owners[file]; ok {
+		result := append([]string(nil), owners...)
+		sort.Strings(result)
+		return result
+	}
+
+	for _, pr := range r.roots {
+		if pr.root == "" || file == pr.root || strings.HasPrefix(file, pr.root+"/") {
+			return []string{pr.project}
+		}
+	}
+	return nil
+}
+
+// Affected returns the projects owning a changed file and, transitively, every project
+// depending on them.
+func (r *Resolver) Affected(changedFiles []string) *Result {
+	result := &Result{ChangedFiles: slices.Compact(slices.Sorted(slices.Values(changedFiles)))}
+	reasons := make(map[string][]Reason)
+
+	var queue []string
+	for _, file := range result.ChangedFiles {
+		owners := r.Owners(file)
+		if len(owners) == 0 {
+			result.UnownedFiles = append(result.UnownedFiles, file)
+			continue
+		}
+		for _, project := range owners {
+			if _, seen := reasons[project]; !seen {
+				queue = append(queue, project)
+			}
+			reasons[project] = append(reasons[project], Reason{Kind: ReasonTouched, File: file})
+		}
+	}
+
+	// Every affected project affects its dependents; each edge is recorded once
+	for len(queue) > 0 {
+		project := queue[0]
+		queue = queue[1:]
+		for _, dependent := range r.graph.Dependents(project) {
+			if _, seen := reasons[dependent]; !seen {
+				queue = append(queue, dependent)
+			}
+			reasons[dependent] = append(reasons[dependent], Reason{Kind: ReasonDependency, Dependency: project})
+		}
+	}
+
+	for name, list := range reasons {
+		sort.SliceStable(list, func(i, j int) bool {
+			if list[i].Kind != list[j].Kind {
+				return list[i].Kind == ReasonTouched
+			}
+			return list[i].File+list[i].Dependency < list[j].File+list[j].Dependency
+		})
+		result.Projects = append(result.Projects, Project{Name: name, Reasons: list})
+	}
+	sort.Slice(result.Projects, func(i, j int) bool { return result.Projects[i].Name < result.Projects[j].Name })
+
+	return result
+}
+
+// Options selects the changes to compute affected projects for.
+type Options struct {
+	Base  string   // Base is the git ref to compare against, such as "main".
+	Head  string   // Head is the git ref with the changes. Empty means the working tree.
+	Files []string // Files, when not empty, are used instead of asking git.
+}
+
+// Compute loads the workspace through commander and returns the projects affected by
+// the changes described in opts.
+func Compute(ctx context.Context, commander *commands.Commander, workspacePath string, opts Options) (*Result, error) {
+	files := opts.Files
+	if len(files) == 0 {
+		var err error
+		files, err = gitfiles.Changed(ctx, workspacePath, opts.Base, opts.Head)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list changed files: %w", err)
+		}
+	}
+
+	workspace, err := commander.SendWorkspaceRequest(ctx, &commands.WorkspaceRequestParams{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load workspace: %w", err)
+	}
+	if workspace == nil {
+		return nil, fmt.Errorf("failed to load workspace: empty response")
+	}
+
+	// The source map only adds ownership of configuration files, so failures are not fatal
+	sourceMap, err := commander.SendSourceMapFilesToProjectsMapRequest(ctx)
+	if err != nil {
+		commander.Logger.Debugw("Failed to load source map, using the project file map only", "error", err)
+		sourceMap = nil
+	}
+
+	return NewResolver(workspace, sourceMap).Affected(files), nil
+}
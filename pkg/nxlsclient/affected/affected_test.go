@@ -0,0 +1,103 @@
+package affected
+
+import (
+	"testing"
+
+	nxtypes "github.com/lazyengs/lazynx/pkg/nxlsclient/nx-types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func projectNode(name, root string) nxtypes.ProjectGraphProjectNode {
+	node := nxtypes.ProjectGraphProjectNode{Name: name, Type: "lib"}
+	node.Data.Root = root
+	return node
+}
+
+// newTestWorkspace builds app -> feature -> ui -> utils with e2e -> app.
+func newTestWorkspace() *nxtypes.NxWorkspace {
+	fileMap := nxtypes.ProjectFileMap{
+		"ui":    {{File: "libs/ui/src/button.ts"}},
+		"utils": {{File: "libs/utils/src/index.ts"}},
+	}
+	return &nxtypes.NxWorkspace{
+		ProjectGraph: nxtypes.ProjectGraph{
+			Nodes: map[string]nxtypes.ProjectGraphProjectNode{
+				"app":     projectNode("app", "apps/app"),
+				"e2e":     projectNode("e2e", "apps/app-e2e"),
+				"feature": projectNode("feature", "libs/feature"),
+				"ui":      projectNode("ui", "libs/ui"),
+				"utils":   projectNode("utils", "libs/utils"),
+			},
+			Dependencies: map[string][]nxtypes.ProjectGraphDependency{
+				"app":     {{Source: "app", Target: "feature", Type: "static"}, {Source: "app", Target: "npm:react", Type: "static"}},
+				"feature": {{Source: "feature", Target: "ui", Type: "static"}},
+				"ui":      {{Source: "ui", Target: "utils", Type: "static"}},
+				"e2e":     {{Source: "e2e", Target: "app", Type: "implicit"}},
+			},
+		},
+		ProjectFileMap: &fileMap,
+	}
+}
+
+func TestOwners(t *testing.T) {
+	r := NewResolver(newTestWorkspace(), map[string][]string{
+		"libs/ui/project.json": {"ui"},
+		"tsconfig.base.json":   {"ui", "utils"},
+	})
+
+	tests := []struct {
+		file string
+		want []string
+	}{
+		{"libs/ui/src/button.ts", []string{"ui"}},
+		{"./libs/ui/src/button.ts", []string{"ui"}},
+		{"libs/ui/project.json", []string{"ui"}},
+		{"tsconfig.base.json", []string{"ui", "utils"}},
+		// Not in any file map, falls back to the longest root
+		{"apps/app-e2e/src/deleted.ts", []string{"e2e"}},
+		{"apps/app/src/main.ts", []string{"app"}},
+		{"apps/application/main.ts", nil},
+		{"README.md", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			assert.Equal(t, tt.want, r.Owners(tt.file))
+		})
+	}
+}
+
+func TestAffected(t *testing.T) {
+	r := NewResolver(newTestWorkspace(), nil)
+
+	result := r.Affected([]string{"libs/ui/src/button.ts", "README.md", "libs/ui/src/button.ts"})
+
+	assert.Equal(t, []string{"app", "e2e", "feature", "ui"}, result.Names())
+	assert.Equal(t, []string{"README.md", "libs/ui/src/button.ts"}, result.ChangedFiles)
+	assert.Equal(t, []string{"README.md"}, result.UnownedFiles)
+
+	ui, ok := result.Project("ui")
+	require.True(t, ok)
+	assert.True(t, ui.Touched())
+	assert.Equal(t, "touched file libs/ui/src/button.ts", ui.Reasons[0].String())
+
+	e2e, ok := result.Project("e2e")
+	require.True(t, ok)
+	assert.False(t, e2e.Touched())
+	assert.Equal(t, []Reason{{Kind: ReasonDependency, Dependency: "app"}}, e2e.Reasons)
+
+	_, ok = result.Project("utils")
+	assert.False(t, ok)
+}
+
+func TestAffectedTouchedAndDependent(t *testing.T) {
+	r := NewResolver(newTestWorkspace(), nil)
+
+	result := r.Affected([]string{"libs/utils/src/index.ts", "apps/app/src/main.ts"})
+
+	app, ok := result.Project("app")
+	require.True(t, ok)
+	assert.Equal(t, []string{"touched file apps/app/src/main.ts", "depends on feature"}, []string{app.Reasons[0].String(), app.Reasons[1].String()})
+	assert.Equal(t, []string{"app", "e2e", "feature", "ui", "utils"}, result.Names())
+}
@@ -0,0 +1,28 @@
+/*
+Package affected computes the projects affected by a set of changed files.
+
+Changed files come from git, using the same merge-base comparison as `nx affected`, or
+from an explicit list. They are mapped to their owning projects with the workspace
+ProjectFileMap and the nx/sourceMapFilesToProjectsMap request, then expanded through the
+dependents in the project graph. Every affected project carries the reasons it was
+affected, such as "touched file libs/ui/src/button.ts" or "depends on ui".
+
+# Usage
+
+	result, err := affected.Compute(ctx, client.Commander, client.NxWorkspacePath, affected.Options{
+		Base: "main",
+	})
+	if err != nil {
+		// Handle error
+	}
+
+	for _, project := range result.Projects {
+		fmt.Printf("%s: %s\n", project.Name, project.Reasons[0])
+	}
+
+When the workspace is already loaded, NewResolver avoids the extra requests:
+
+	resolver := affected.NewResolver(workspace, nil)
+	result := resolver.Affected([]string{"libs/ui/src/button.ts"})
+*/
+package affected
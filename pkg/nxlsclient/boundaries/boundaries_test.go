@@ -0,0 +1,262 @@
+package boundaries
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	nxtypes "github.com/lazyengs/lazynx/pkg/nxlsclient/nx-types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestGraph builds a project graph from project tags and dependencies given as
+// source -> targets. Targets starting with "npm:" become external nodes.
+func newTestGraph(tags map[string][]string, deps map[string][]string) *nxtypes.ProjectGraph {
+	pg := &nxtypes.ProjectGraph{
+		Nodes:         make(map[string]nxtypes.ProjectGraphProjectNode),
+		ExternalNodes: make(map[string]nxtypes.ProjectGraphExternalNode),
+		Dependencies:  make(map[string][]nxtypes.ProjectGraphDependency),
+	}
+	for name, projectTags := range tags {
+		node := nxtypes.ProjectGraphProjectNode{Name: name, Type: "lib"}
+		node.Data.Tags = projectTags
+		pg.Nodes[name] = node
+	}
+	for source, targets := range deps {
+		for _, target := range targets {
+			if _, ok := pg.Nodes[target]; !ok {
+				external := nxtypes.ProjectGraphExternalNode{Name: target, Type: "npm"}
+				external.Data.PackageName = target[len("npm:"):]
+				pg.ExternalNodes[target] = external
+			}
+			pg.Dependencies[source] = append(pg.Dependencies[source], nxtypes.ProjectGraphDependency{Source: source, Target: target, Type: "static"})
+		}
+	}
+	return pg
+}
+
+func TestCheckOnlyDependOn(t *testing.T) {
+	pg := newTestGraph(map[string][]string{
+		"app":   {"type:app"},
+		"feat":  {"type:feature"},
+		"ui":    {"type:ui"},
+		"other": {"type:app"},
+	}, map[string][]string{
+		"app":  {"feat", "ui"},
+		"feat": {"ui", "other"},
+	})
+
+	violations := Check(pg, []DepConstraint{
+		{SourceTag: "type:app", OnlyDependOnLibsWithTags: []string{"type:feature", "type:ui"}},
+		{SourceTag: "type:feature", OnlyDependOnLibsWithTags: []string{"type:ui"}},
+		{SourceTag: "type:ui", OnlyDependOnLibsWithTags: []string{"type:ui"}},
+	})
+
+	require.Len(t, violations, 1)
+	v := violations[0]
+	assert.Equal(t, ViolationOnlyDependOn, v.Kind)
+	assert.Equal(t, nxtypes.ProjectGraphDependency{Source: "feat", Target: "other", Type: "static"}, v.Dependency)
+	assert.Equal(t, "type:feature", v.Constraint.SourceTag)
+	assert.Equal(t, `feat -> other: A project tagged with "type:feature" can only depend on libs tagged with "type:ui"`, v.String())
+}
+
+func TestDepConstraintJSON(t *testing.T) {
+	// An empty list forbids everything, unlike a missing one, so it must survive a round trip
+	data, err := json.Marshal(DepConstraint{SourceTag: "type:util", OnlyDependOnLibsWithTags: []string{}})
+	require.NoError(t, err)
+
+	var constraint DepConstraint
+	require.NoError(t, json.Unmarshal(data, &constraint))
+	assert.NotNil(t, constraint.OnlyDependOnLibsWithTags)
+	assert.Empty(t, constraint.OnlyDependOnLibsWithTags)
+	assert.Nil(t, constraint.AllowedExternalImports)
+}
+
+func TestCheckNotDependOnTransitive(t *testing.T) {
+	pg := newTestGraph(map[string][]string{
+		"web":    {"platform:web"},
+		"shared": {"platform:any"},
+		"utils":  {"platform:any"},
+		"fs":     {"platform:node"},
+	}, map[string][]string{
+		"web":    {"shared"},
+		"shared": {"utils"},
+		"utils":  {"fs"},
+	})
+
+	violations := Check(pg, []DepConstraint{
+		{SourceTag: "platform:web", NotDependOnLibsWithTags: []string{"platform:node"}},
+		{SourceTag: "platform:any", NotDependOnLibsWithTags: []string{"platform:web"}},
+	})
+
+	require.Len(t, violations, 1)
+	v := violations[0]
+	assert.Equal(t, ViolationNotDependOn, v.Kind)
+	assert.Equal(t, "shared", v.Dependency.Target)
+	assert.Equal(t, []string{"web", "shared", "utils", "fs"}, v.Path)
+	assert.Contains(t, v.Message, "Violation detected in: web -> shared -> utils -> fs")
+}
+
+func TestCheckAllSourceTagsAndPatterns(t *testing.T) {
+	pg := newTestGraph(map[string][]string{
+		"admin-feat": {"scope:admin", "type:feature"},
+		"admin-ui":   {"scope:admin", "type:ui"},
+		"shop-ui":    {"scope:shop", "type:ui"},
+		"shared-ui":  {"scope:shared", "type:ui"},
+	}, map[string][]string{
+		"admin-feat": {"admin-ui", "shop-ui", "shared-ui"},
+	})
+
+	violations := Check(pg, []DepConstraint{
+		{AllSourceTags: []string{"scope:admin", "type:feature"}, OnlyDependOnLibsWithTags: []string{"/^scope:(admin|shared)$/"}},
+		{SourceTag: "scope:*", OnlyDependOnLibsWithTags: []string{"*"}},
+	})
+
+	require.Len(t, violations, 1)
+	assert.Equal(t, "shop-ui", violations[0].Dependency.Target)
+	assert.Contains(t, violations[0].Message, `"scope:admin" and "type:feature"`)
+}
+
+func TestCheckNoMatchingConstraint(t *testing.T) {
+	pg := newTestGraph(map[string][]string{
+		"app":  nil,
+		"ui":   {"type:ui"},
+		"feat": {"type:feature"},
+	}, map[string][]string{
+		"app":  {"ui", "npm:react"},
+		"feat": {"ui"},
+	})
+
+	violations := Check(pg, []DepConstraint{{SourceTag: "type:feature", OnlyDependOnLibsWithTags: []string{"type:ui"}}})
+
+	require.Len(t, violations, 1)
+	assert.Equal(t, ViolationNoMatchingConstraint, violations[0].Kind)
+	assert.Equal(t, "ui", violations[0].Dependency.Target)
+	assert.Nil(t, violations[0].Constraint)
+
+	assert.Empty(t, Check(pg, nil))
+}
+
+func TestCheckExternalImports(t *testing.T) {
+	pg := newTestGraph(map[string][]string{
+		"web":  {"platform:web"},
+		"api":  {"platform:node"},
+		"core": {"platform:core"},
+	}, map[string][]string{
+		"web":  {"npm:react", "npm:@nestjs/core"},
+		"api":  {"npm:@nestjs/core", "npm:express"},
+		"core": {"npm:lodash"},
+	})
+
+	violations := Check(pg, []DepConstraint{
+		{SourceTag: "platform:web", BannedExternalImports: []string{"@nestjs/*"}},
+		{SourceTag: "platform:node", AllowedExternalImports: []string{"@nestjs/*"}},
+		{SourceTag: "platform:core", AllowedExternalImports: []string{"lodash"}},
+	})
+
+	require.Len(t, violations, 2)
+	assert.Equal(t, ViolationNotAllowedImport, violations[0].Kind)
+	assert.Equal(t, "npm:express", violations[0].Dependency.Target)
+	assert.Equal(t, ViolationBannedImport, violations[1].Kind)
+	assert.Equal(t, "npm:@nestjs/core", violations[1].Dependency.Target)
+	assert.Equal(t, `web -> npm:@nestjs/core: A project tagged with "platform:web" is not allowed to import "@nestjs/core"`, violations[1].String())
+}
+
+func TestLoadESLintConstraints(t *testing.T) {
+	tests := []struct {
+		name   string
+		file   string
+		config string
+	}{
+		{
+			name: "eslintrc",
+			file: ".eslintrc.json",
+			config: `{
+  // Workspace lint rules
+  "root": true,
+  "overrides": [
+    {
+      "files": ["*.ts"],
+      "rules": {
+        "@nx/enforce-module-boundaries": [
+          "error",
+          {
+            "enforceBuildableLibDependency": true,
+            "depConstraints": [
+              { "sourceTag": "type:app", "onlyDependOnLibsWithTags": ["type:feature", "type:ui"] },
+              { "sourceTag": "type:ui", "notDependOnLibsWithTags": ["type:feature"], },
+            ]
+          }
+        ]
+      }
+    }
+  ]
+}`,
+		},
+		{
+			name: "flat config",
+			file: "eslint.config.mjs",
+			config: `import nx from '@nx/eslint-plugin';
+
+export default [
+  ...nx.configs['flat/base'],
+  {
+    files: ['**/*.ts'],
+    rules: {
+      '@nx/enforce-module-boundaries': [
+        'error',
+        {
+          allow: ['^.*/eslint(\\.base)?\\.config\\.[cm]?js$'],
+          depConstraints: [
+            /* Apps compose features and UI */
+            { sourceTag: 'type:app', onlyDependOnLibsWithTags: ['type:feature', "type:ui"] },
+            {
+              sourceTag: 'type:ui', // leaf libraries
+              notDependOnLibsWithTags: ['type:feature'],
+            },
+          ],
+        },
+      ],
+    },
+  },
+];
+`,
+		},
+	}
+
+	expected := []DepConstraint{
+		{SourceTag: "type:app", OnlyDependOnLibsWithTags: []string{"type:feature", "type:ui"}},
+		{SourceTag: "type:ui", NotDependOnLibsWithTags: []string{"type:feature"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, tt.file)
+			require.NoError(t, os.WriteFile(path, []byte(tt.config), 0o644))
+
+			constraints, source, err := LoadESLintConstraints(dir)
+			require.NoError(t, err)
+			assert.Equal(t, path, source)
+			assert.Equal(t, expected, constraints)
+		})
+	}
+}
+
+func TestLoadESLintConstraintsErrors(t *testing.T) {
+	dir := t.TempDir()
+	_, _, err := LoadESLintConstraints(dir)
+	assert.ErrorIs(t, err, ErrNoConstraints)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".eslintrc.json"), []byte(`{"rules": {}}`), 0o644))
+	_, _, err = LoadESLintConstraints(dir)
+	assert.ErrorIs(t, err, ErrNoConstraints)
+
+	// Constraints built from variables cannot be read without running the config
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "eslint.config.js"), []byte(`module.exports = [{ rules: { '@nx/enforce-module-boundaries': ['error', { depConstraints: [...shared] }] } }];`), 0o644))
+	_, _, err = LoadESLintConstraints(dir)
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, ErrNoConstraints)
+}
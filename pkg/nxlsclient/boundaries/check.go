@@ -0,0 +1,171 @@
+package boundaries
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/lazyengs/lazynx/pkg/nxlsclient/graph"
+	nxtypes "github.com/lazyengs/lazynx/pkg/nxlsclient/nx-types"
+)
+
+// ViolationKind names the rule a dependency breaks.
+type ViolationKind string
+
+const (
+	ViolationOnlyDependOn         ViolationKind = "onlyDependOnLibsWithTags"
+	ViolationNotDependOn          ViolationKind = "notDependOnLibsWithTags"
+	ViolationBannedImport         ViolationKind = "bannedExternalImports"
+	ViolationNotAllowedImport     ViolationKind = "allowedExternalImports"
+	ViolationNoMatchingConstraint ViolationKind = "noMatchingConstraint"
+)
+
+// Violation is a dependency edge that breaks a constraint.
+type Violation struct {
+	Kind ViolationKind `json:"kind"`
+	// Dependency is the offending edge of the project graph.
+	Dependency nxtypes.ProjectGraphDependency `json:"dependency"`
+	// Path leads from the source to the project with a forbidden tag, through Dependency,
+	// for ViolationNotDependOn.
+	Path []string `json:"path,omitempty"`
+	// Constraint is the broken constraint, nil for ViolationNoMatchingConstraint.
+	Constraint *DepConstraint `json:"constraint,omitempty"`
+	Message    string         `json:"message"`
+}
+
+func (v Violation) String() string {
+	return fmt.Sprintf("%s -> %s: %s", v.Dependency.Source, v.Dependency.Target, v.Message)
+}
+
+// Check evaluates constraints against the tags and the static and dynamic dependencies of
+// the project graph, the ones the lint rule sees as imports. Violations are sorted by
+// source, target and constraint order. No constraints means no violations.
+func Check(pg *nxtypes.ProjectGraph, constraints []DepConstraint) []Violation {
+	if len(constraints) == 0 {
+		return nil
+	}
+
+	g := graph.New(pg).Filter(nxtypes.DependencyTypeStatic, nxtypes.DependencyTypeDynamic)
+	c := &checker{pg: pg, g: g}
+
+	var violations []Violation
+	for _, source := range g.Projects() {
+		tags := pg.Nodes[source].Data.Tags
+
+		var matching []*DepConstraint
+		for i := range constraints {
+			if constraints[i].appliesTo(tags) {
+				matching = append(matching, &constraints[i])
+			}
+		}
+
+		for _, dep := range g.DependencyEdges(source) {
+			if dep.Target == source {
+				continue
+			}
+			if len(matching) == 0 {
+				if !g.IsExternal(dep.Target) {
+					violations = append(violations, Violation{
+						Kind:       ViolationNoMatchingConstraint,
+						Dependency: dep,
+						Message:    "A project without tags matching at least one constraint cannot depend on any libraries",
+					})
+				}
+				continue
+			}
+			for _, constraint := range matching {
+				if v, ok := c.check(dep, constraint); ok {
+					violations = append(violations, v)
+				}
+			}
+		}
+	}
+
+	return violations
+}
+
+type checker struct {
+	pg *nxtypes.ProjectGraph
+	g  *graph.Graph
+}
+
+func (c *checker) check(dep nxtypes.ProjectGraphDependency, constraint *DepConstraint) (Violation, bool) {
+	v := Violation{Dependency: dep, Constraint: constraint}
+
+	if c.g.IsExternal(dep.Target) {
+		pkg := c.packageName(dep.Target)
+		if slices.ContainsFunc(constraint.BannedExternalImports, func(p string) bool { return matchPattern(p, pkg) }) {
+			v.Kind = ViolationBannedImport
+			v.Message = fmt.Sprintf("A project tagged with %s is not allowed to import %q", constraint.Source(), pkg)
+			return v, true
+		}
+		if constraint.AllowedExternalImports != nil && !slices.ContainsFunc(constraint.AllowedExternalImports, func(p string) bool { return matchPattern(p, pkg) }) {
+			v.Kind = ViolationNotAllowedImport
+			v.Message = fmt.Sprintf("A project tagged with %s is not allowed to import %q", constraint.Source(), pkg)
+			return v, true
+		}
+		return v, false
+	}
+
+	targetTags := c.pg.Nodes[dep.Target].Data.Tags
+	if constraint.OnlyDependOnLibsWithTags != nil &&
+		!slices.ContainsFunc(constraint.OnlyDependOnLibsWithTags, func(p string) bool { return anyTagMatches(p, targetTags) }) {
+		v.Kind = ViolationOnlyDependOn
+		v.Message = fmt.Sprintf("A project tagged with %s can only depend on libs tagged with %s", constraint.Source(), quoteAll(constraint.OnlyDependOnLibsWithTags, ", "))
+		return v, true
+	}
+
+	if len(constraint.NotDependOnLibsWithTags) > 0 {
+		if path := c.forbiddenPath(dep, constraint.NotDependOnLibsWithTags); path != nil {
+			v.Kind = ViolationNotDependOn
+			v.Path = path
+			v.Message = fmt.Sprintf("A project tagged with %s can not depend on libs tagged with %s", constraint.Source(), quoteAll(constraint.NotDependOnLibsWithTags, ", "))
+			if len(path) > 2 {
+				v.Message += ". Violation detected in: " + strings.Join(path, " -> ")
+			}
+			return v, true
+		}
+	}
+
+	return v, false
+}
+
+// forbiddenPath returns the shortest path from dep.Source through dep.Target to a
+// project having one of the forbidden tags, or nil.
+func (c *checker) forbiddenPath(dep nxtypes.ProjectGraphDependency, forbidden []string) []string {
+	hasForbiddenTag := func(project string) bool {
+		tags := c.pg.Nodes[project].Data.Tags
+		return slices.ContainsFunc(forbidden, func(p string) bool { return p != "*" && anyTagMatches(p, tags) })
+	}
+
+	previous := map[string]string{dep.Target: dep.Source}
+	queue := []string{dep.Target}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		if hasForbiddenTag(current) {
+			path := []string{current}
+			for n := current; n != dep.Source; {
+				n = previous[n]
+				path = append(path, n)
+			}
+			slices.Reverse(path)
+			return path
+		}
+		for _, next := range c.g.Dependencies(current) {
+			if _, seen := previous[next]; !seen && next != dep.Source && !c.g.IsExternal(next) {
+				previous[next] = current
+				queue = append(queue, next)
+			}
+		}
+	}
+	return nil
+}
+
+// packageName returns the npm package of an external node.
+func (c *checker) packageName(node string) string {
+	if external, ok := c.pg.ExternalNodes[node]; ok && external.Data.PackageName != "" {
+		return external.Data.PackageName
+	}
+	return strings.TrimPrefix(node, "npm:")
+}
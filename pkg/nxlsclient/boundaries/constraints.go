@@ -0,0 +1,84 @@
+package boundaries
+
+import (
+	"regexp"
+	"strings"
+)
+
+// DepConstraint is an entry of the depConstraints option of @nx/enforce-module-boundaries.
+type DepConstraint struct {
+	// SourceTag selects the projects the constraint applies to. "*" selects every project.
+	SourceTag string `json:"sourceTag,omitempty"`
+	// AllSourceTags selects the projects having all of these tags.
+	AllSourceTags []string `json:"allSourceTags,omitempty"`
+	// OnlyDependOnLibsWithTags, when set, lists the tags a direct dependency must have one of.
+	// An empty list forbids every dependency, so it is not omitted from JSON.
+	OnlyDependOnLibsWithTags []string `json:"onlyDependOnLibsWithTags"`
+	// NotDependOnLibsWithTags lists tags no dependency, direct or transitive, may have.
+	NotDependOnLibsWithTags []string `json:"notDependOnLibsWithTags,omitempty"`
+	// AllowedExternalImports, when set, lists the only packages that may be imported. Like
+	// OnlyDependOnLibsWithTags, an empty list is kept in JSON.
+	AllowedExternalImports []string `json:"allowedExternalImports"`
+	// BannedExternalImports lists packages that may not be imported.
+	BannedExternalImports []string `json:"bannedExternalImports,omitempty"`
+}
+
+// Source describes which projects the constraint applies to, such as `"type:feature"`.
+func (c DepConstraint) Source() string {
+	if len(c.AllSourceTags) > 0 {
+		return quoteAll(c.AllSourceTags, " and ")
+	}
+	return `"` + c.SourceTag + `"`
+}
+
+// appliesTo reports whether the constraint selects a project with tags.
+func (c DepConstraint) appliesTo(tags []string) bool {
+	if len(c.AllSourceTags) > 0 {
+		for _, required := range c.AllSourceTags {
+			if !anyTagMatches(required, tags) {
+				return false
+			}
+		}
+		return true
+	}
+	return c.SourceTag != "" && anyTagMatches(c.SourceTag, tags)
+}
+
+// anyTagMatches reports whether pattern matches one of tags. "*" matches projects
+// without tags too.
+func anyTagMatches(pattern string, tags []string) bool {
+	if pattern == "*" {
+		return true
+	}
+	for _, tag := range tags {
+		if matchPattern(pattern, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchPattern matches a tag or package name the way the lint rule does: "/regex/",
+// globs with "*", or exact values.
+func matchPattern(pattern, value string) bool {
+	if len(pattern) > 2 && strings.HasPrefix(pattern, "/") && strings.HasSuffix(pattern, "/") {
+		re, err := regexp.Compile(pattern[1 : len(pattern)-1])
+		return err == nil && re.MatchString(value)
+	}
+	if strings.Contains(pattern, "*") {
+		parts := strings.Split(pattern, "*")
+		for i, part := range parts {
+			parts[i] = regexp.QuoteMeta(part)
+		}
+		return regexp.MustCompile("^" + strings.Join(parts, ".*") + "$").MatchString(value)
+	}
+	return pattern == value
+}
+
+func quoteAll(values []string, separator string) string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = `"` + v + `"`
+	}
+	return strings.Join(quoted, separator)
+}
@@ -0,0 +1,25 @@
+/*
+Package boundaries checks module boundary constraints against a project graph.
+
+The constraints are the depConstraints of the @nx/enforce-module-boundaries lint rule:
+each selects projects by tag and restricts the tags of the projects they depend on and
+the npm packages they import. Check evaluates them against the project tags and the
+static and dynamic dependencies nxls reports, so the boundaries of a whole workspace are
+checked without running eslint on every file.
+
+# Usage
+
+	constraints, source, err := boundaries.LoadESLintConstraints(workspacePath)
+	if err != nil {
+		return err
+	}
+
+	for _, v := range boundaries.Check(&workspace.ProjectGraph, constraints) {
+		fmt.Printf("%s (%s)\n", v, source)
+	}
+
+Tags and package names in constraints may be exact values, globs with "*", or regular
+expressions written as "/pattern/". notDependOnLibsWithTags is checked transitively and
+violations carry the path to the project with the forbidden tag.
+*/
+package boundaries
@@ -0,0 +1,302 @@
+package boundaries
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ruleNames are the names the module boundaries rule has been published under.
+var ruleNames = []string{"@nx/enforce-module-boundaries", "@nrwl/nx/enforce-module-boundaries"}
+
+// eslintConfigFiles are looked up in this order in the workspace root.
+var eslintConfigFiles = []string{
+	"eslint.config.js",
+	"eslint.config.mjs",
+	"eslint.config.cjs",
+	"eslint.config.ts",
+	".eslintrc.json",
+	".eslintrc",
+}
+
+// ErrNoConstraints is returned when no eslint configuration declares depConstraints.
+var ErrNoConstraints = errors.New("no depConstraints found in the eslint configuration")
+
+// LoadESLintConstraints reads the depConstraints of the module boundaries rule from the
+// root eslint configuration of a workspace and returns them with the file they came from.
+//
+// .eslintrc files are parsed as JSON with comments. Flat configs are JavaScript, so the
+// depConstraints array literal is extracted from the source instead of evaluating it;
+// constraints built with variables or function calls cannot be read this way.
+func LoadESLintConstraints(workspacePath string) ([]DepConstraint, string, error) {
+	for _, name := range eslintConfigFiles {
+		path := filepath.Join(workspacePath, name)
+		data, err := os.ReadFile(path)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, path, fmt.Errorf("failed to read %s: %w", name, err)
+		}
+
+		var constraints []DepConstraint
+		if strings.HasPrefix(name, ".eslintrc") {
+			constraints, err = parseESLintRC(data)
+		} else {
+			constraints, err = parseFlatConfig(data)
+		}
+		if errors.Is(err, ErrNoConstraints) {
+			continue
+		}
+		if err != nil {
+			return nil, path, fmt.Errorf("failed to read depConstraints from %s: %w", name, err)
+		}
+		return constraints, path, nil
+	}
+
+	return nil, "", ErrNoConstraints
+}
+
+// parseESLintRC finds the rule in the rules and overrides of an .eslintrc file.
+func parseESLintRC(data []byte) ([]DepConstraint, error) {
+	normalized, err := jsToJSON(string(data))
+	if err != nil {
+		return nil, err
+	}
+
+	type rules map[string]json.RawMessage
+	var config struct {
+		Rules     rules `json:"rules"`
+		Overrides []struct {
+			Rules rules `json:"rules"`
+		} `json:"overrides"`
+	}
+	if err := json.Unmarshal([]byte(normalized), &config); err != nil {
+		return nil, err
+	}
+
+	all := []rules{config.Rules}
+	for _, override := range config.Overrides {
+		all = append(all, override.Rules)
+	}
+	for _, r := range all {
+		for _, name := range ruleNames {
+			if setting, ok := r[name]; ok {
+				if constraints, found := ruleConstraints(setting); found {
+					return constraints, nil
+				}
+			}
+		}
+	}
+	return nil, ErrNoConstraints
+}
+
+// ruleConstraints reads the depConstraints of a ["error", { ... }] rule setting.
+func ruleConstraints(setting json.RawMessage) ([]DepConstraint, bool) {
+	var entries []json.RawMessage
+	if err := json.Unmarshal(setting, &entries); err != nil || len(entries) < 2 {
+		return nil, false
+	}
+	var options struct {
+		DepConstraints []DepConstraint `json:"depConstraints"`
+	}
+	if err := json.Unmarshal(entries[1], &options); err != nil || options.DepConstraints == nil {
+		return nil, false
+	}
+	return options.DepConstraints, true
+}
+
+// parseFlatConfig extracts the first depConstraints array literal of a flat config.
+func parseFlatConfig(data []byte) ([]DepConstraint, error) {
+	src := string(data)
+	for offset := 0; ; {
+		i := strings.Index(src[offset:], "depConstraints")
+		if i < 0 {
+			return nil, ErrNoConstraints
+		}
+		start := offset + i + len("depConstraints")
+		offset = start
+
+		// Expect `: [` or `": [` after the key
+		rest := strings.TrimLeft(src[start:], "'\" \t\r\n")
+		if !strings.HasPrefix(rest, ":") {
+			continue
+		}
+		rest = strings.TrimLeft(rest[1:], " \t\r\n")
+		if !strings.HasPrefix(rest, "[") {
+			continue
+		}
+
+		literal, err := balanced(rest)
+		if err != nil {
+			return nil, err
+		}
+		normalized, err := jsToJSON(literal)
+		if err != nil {
+			return nil, err
+		}
+		var constraints []DepConstraint
+		if err := json.Unmarshal([]byte(normalized), &constraints); err != nil {
+			return nil, err
+		}
+		return constraints, nil
+	}
+}
+
+// balanced returns the bracketed expression at the start of src, skipping strings and
+// comments.
+func balanced(src string) (string, error) {
+	depth := 0
+	for i := 0; i < len(src); i++ {
+		switch c := src[i]; {
+		case c == '"' || c == '\'' || c == '`':
+			end, err := stringEnd(src, i)
+			if err != nil {
+				return "", err
+			}
+			i = end
+		case strings.HasPrefix(src[i:], "//"):
+			i += strings.IndexByte(src[i:]+"\n", '\n')
+		case strings.HasPrefix(src[i:], "/*"):
+			end := strings.Index(src[i+2:], "*/")
+			if end < 0 {
+				return "", errors.New("unterminated comment")
+			}
+			i += end + 3
+		case c == '[' || c == '{':
+			depth++
+		case c == ']' || c == '}':
+			depth--
+			if depth == 0 {
+				return src[:i+1], nil
+			}
+		}
+	}
+	return "", errors.New("unterminated array")
+}
+
+// stringEnd returns the index of the quote closing the string starting at start.
+func stringEnd(src string, start int) (int, error) {
+	quote := src[start]
+	for i := start + 1; i < len(src); i++ {
+		switch src[i] {
+		case '\\':
+			i++
+		case quote:
+			return i, nil
+		}
+	}
+	return 0, errors.New("unterminated string")
+}
+
+// jsToJSON converts a JavaScript object or array literal, or JSON with comments, into
+// JSON: comments are dropped, keys quoted, single-quoted strings converted and trailing
+// commas removed. Identifiers other than true, false and null are rejected.
+func jsToJSON(src string) (string, error) {
+	var out strings.Builder
+	for i := 0; i < len(src); i++ {
+		c := src[i]
+		switch {
+		case c == '"' || c == '\'' || c == '`':
+			end, err := stringEnd(src, i)
+			if err != nil {
+				return "", err
+			}
+			value := src[i+1 : end]
+			if c == '`' && strings.Contains(value, "${") {
+				return "", errors.New("template literals with expressions are not supported")
+			}
+			out.WriteString(quoteJS(value, c))
+			i = end
+		case strings.HasPrefix(src[i:], "//"):
+			i += strings.IndexByte(src[i:]+"\n", '\n')
+		case strings.HasPrefix(src[i:], "/*"):
+			end := strings.Index(src[i+2:], "*/")
+			if end < 0 {
+				return "", errors.New("unterminated comment")
+			}
+			i += end + 3
+		case c == ',':
+			// Drop trailing commas
+			next := strings.TrimLeft(stripComments(src[i+1:]), " \t\r\n")
+			if !strings.HasPrefix(next, "]") && !strings.HasPrefix(next, "}") {
+				out.WriteByte(c)
+			}
+		case isIdentStart(c):
+			end := i
+			for end < len(src) && isIdentPart(src[end]) {
+				end++
+			}
+			ident := src[i:end]
+			rest := strings.TrimLeft(src[end:], " \t\r\n")
+			switch {
+			case strings.HasPrefix(rest, ":"):
+				out.WriteString(`"` + ident + `"`)
+			case ident == "true" || ident == "false" || ident == "null":
+				out.WriteString(ident)
+			default:
+				return "", fmt.Errorf("unsupported expression %q", ident)
+			}
+			i = end - 1
+		default:
+			out.WriteByte(c)
+		}
+	}
+	return out.String(), nil
+}
+
+// stripComments removes a leading run of comments and whitespace.
+func stripComments(src string) string {
+	for {
+		trimmed := strings.TrimLeft(src, " \t\r\n")
+		switch {
+		case strings.HasPrefix(trimmed, "//"):
+			src = trimmed[strings.IndexByte(trimmed+"\n", '\n'):]
+		case strings.HasPrefix(trimmed, "/*"):
+			end := strings.Index(trimmed, "*/")
+			if end < 0 {
+				return trimmed
+			}
+			src = trimmed[end+2:]
+		default:
+			return trimmed
+		}
+	}
+}
+
+// quoteJS converts the body of a JavaScript string into a JSON string.
+func quoteJS(body string, quote byte) string {
+	var value strings.Builder
+	for i := 0; i < len(body); i++ {
+		if body[i] == '\\' && i+1 < len(body) {
+			i++
+			switch body[i] {
+			case 'n':
+				value.WriteByte('\n')
+			case 't':
+				value.WriteByte('\t')
+			case 'r':
+				value.WriteByte('\r')
+			default:
+				// \' \" \\ \/ and anything else stand for the character itself
+				value.WriteByte(body[i])
+			}
+			continue
+		}
+		value.WriteByte(body[i])
+	}
+
+	data, _ := json.Marshal(value.String())
+	return string(data)
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || c == '$' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isIdentPart(c byte) bool {
+	return isIdentStart(c) || (c >= '0' && c <= '9')
+}
@@ -0,0 +1,162 @@
+package cipe
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/lazyengs/lazynx/pkg/nxlsclient/commands"
+	nxtypes "github.com/lazyengs/lazynx/pkg/nxlsclient/nx-types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func status(s nxtypes.CIPEExecutionStatus) *nxtypes.CIPEExecutionStatus {
+	return &s
+}
+
+func pipeline(id, branch string, s nxtypes.CIPEExecutionStatus, runs ...nxtypes.CIPERun) nxtypes.CIPEInfo {
+	return nxtypes.CIPEInfo{
+		CiPipelineExecutionId: id,
+		Branch:                branch,
+		Status:                s,
+		RunGroups:             []nxtypes.CIPERunGroup{{RunGroup: "main", Status: s, Runs: runs}},
+	}
+}
+
+func TestConnected(t *testing.T) {
+	noEnv := func(string) (string, bool) { return "", false }
+	token := func(key string) (string, bool) { return "secret", key == "NX_CLOUD_ACCESS_TOKEN" }
+
+	var nxJson nxtypes.NxJsonConfiguration
+	require.NoError(t, json.Unmarshal([]byte(`{"nxCloudId": "abc"}`), &nxJson))
+	assert.True(t, Connected(nxJson, noEnv))
+
+	var legacy nxtypes.NxJsonConfiguration
+	require.NoError(t, json.Unmarshal([]byte(`{"tasksRunnerOptions": {"default": {"runner": "nx-cloud", "options": {"accessToken": "abc"}}}}`), &legacy))
+	assert.True(t, Connected(legacy, noEnv))
+
+	assert.False(t, Connected(nxtypes.NxJsonConfiguration{}, noEnv))
+	assert.True(t, Connected(nxtypes.NxJsonConfiguration{}, token))
+
+	never := true
+	assert.False(t, Connected(nxtypes.NxJsonConfiguration{NeverConnectToCloud: &never}, token))
+}
+
+func TestDiff(t *testing.T) {
+	failedTasks := 2
+	lint := nxtypes.CIPERun{Command: "nx affected -t lint", Status: status(nxtypes.CIPEStatusSucceeded)}
+	test := nxtypes.CIPERun{Command: "nx affected -t test", Status: status(nxtypes.CIPEStatusFailed), NumFailedTasks: &failedTasks}
+
+	prev := []nxtypes.CIPEInfo{
+		pipeline("1", "feat/ui", nxtypes.CIPEStatusInProgress, lint),
+		pipeline("2", "main", nxtypes.CIPEStatusSucceeded),
+		pipeline("3", "old", nxtypes.CIPEStatusSucceeded),
+	}
+	next := []nxtypes.CIPEInfo{
+		pipeline("4", "fix/api", nxtypes.CIPEStatusNotStarted),
+		pipeline("1", "feat/ui", nxtypes.CIPEStatusFailed, lint, test),
+		pipeline("2", "main", nxtypes.CIPEStatusSucceeded),
+	}
+
+	events := Diff(prev, next)
+	require.Len(t, events, 2)
+	assert.Equal(t, EventStarted, events[0].Kind)
+	assert.Equal(t, "pipeline for branch fix/api started, NOT_STARTED", events[0].String())
+	assert.False(t, events[0].Failed())
+
+	assert.Equal(t, EventStatusChanged, events[1].Kind)
+	assert.Equal(t, []nxtypes.CIPERun{test}, events[1].FailedRuns)
+	assert.Equal(t, "pipeline for branch feat/ui moved IN_PROGRESS → FAILED: failed nx affected -t test", events[1].String())
+	assert.True(t, events[1].Failed())
+}
+
+// fakeFetch returns the queued results one by one.
+type fakeFetch struct {
+	results []*commands.RecentCIPEDataResult
+	errs    []error
+}
+
+func (f *fakeFetch) fetch(ctx context.Context) (*commands.RecentCIPEDataResult, error) {
+	result, err := f.results[0], f.errs[0]
+	f.results, f.errs = f.results[1:], f.errs[1:]
+	return result, err
+}
+
+func (f *fakeFetch) add(result *commands.RecentCIPEDataResult, err error) {
+	f.results = append(f.results, result)
+	f.errs = append(f.errs, err)
+}
+
+func TestPoll(t *testing.T) {
+	intervals := PollIntervals{Active: time.Second, Idle: time.Minute, MaxBackoff: 5 * time.Second}
+	running := &commands.RecentCIPEDataResult{
+		Info:         []nxtypes.CIPEInfo{pipeline("1", "feat/ui", nxtypes.CIPEStatusInProgress)},
+		WorkspaceUrl: "https://cloud.nx.app/orgs/acme/workspaces/web",
+	}
+	done := &commands.RecentCIPEDataResult{Info: []nxtypes.CIPEInfo{pipeline("1", "feat/ui", nxtypes.CIPEStatusSucceeded)}}
+
+	f := &fakeFetch{}
+	f.add(running, nil)
+	f.add(nil, errors.New("connection closed"))
+	f.add(nil, errors.New("connection closed"))
+	f.add(&commands.RecentCIPEDataResult{Error: &nxtypes.CIPEInfoError{Type: nxtypes.CIPEErrorTypeNetwork, Message: "timeout"}}, nil)
+	f.add(&commands.RecentCIPEDataResult{Error: &nxtypes.CIPEInfoError{Type: nxtypes.CIPEErrorTypeAuthentication, Message: "log in"}}, nil)
+	f.add(done, nil)
+
+	p := NewPoller(f.fetch, intervals, nil)
+	ctx := context.Background()
+
+	update := p.Poll(ctx)
+	require.NoError(t, update.Err)
+	assert.Empty(t, update.Events)
+	assert.Equal(t, time.Second, update.Next)
+	assert.Equal(t, running.WorkspaceUrl, update.WorkspaceURL)
+
+	// Failures back off exponentially and keep the last pipelines
+	update = p.Poll(ctx)
+	assert.Equal(t, nxtypes.CIPEErrorTypeNetwork, update.ErrorType())
+	assert.Equal(t, time.Second, update.Next)
+	assert.Equal(t, running.Info, update.Pipelines)
+	update = p.Poll(ctx)
+	assert.Equal(t, 2*time.Second, update.Next)
+	update = p.Poll(ctx)
+	assert.Equal(t, 3, update.ConsecutiveFailures)
+	assert.Equal(t, 4*time.Second, update.Next)
+	assert.EqualError(t, update.Err, "network error: timeout")
+
+	update = p.Poll(ctx)
+	assert.Equal(t, nxtypes.CIPEErrorTypeAuthentication, update.ErrorType())
+	assert.Equal(t, 5*time.Second, update.Next)
+
+	// The events compare with the last successful poll
+	update = p.Poll(ctx)
+	require.NoError(t, update.Err)
+	require.Len(t, update.Events, 1)
+	assert.Equal(t, nxtypes.CIPEStatusInProgress, update.Events[0].From)
+	assert.Equal(t, nxtypes.CIPEStatusSucceeded, update.Events[0].To)
+	assert.Equal(t, time.Minute, update.Next)
+	assert.Equal(t, update, p.Last())
+}
+
+func TestRunRefresh(t *testing.T) {
+	result := &commands.RecentCIPEDataResult{Info: []nxtypes.CIPEInfo{pipeline("1", "main", nxtypes.CIPEStatusSucceeded)}}
+	polls := make(chan Update)
+	p := NewPoller(func(context.Context) (*commands.RecentCIPEDataResult, error) { return result, nil },
+		PollIntervals{Idle: time.Hour}, func(update Update) { polls <- update })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go p.Run(ctx)
+
+	<-polls
+	p.Refresh()
+	select {
+	case update := <-polls:
+		assert.Equal(t, time.Hour, update.Next)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Refresh did not trigger a poll")
+	}
+}
@@ -0,0 +1,32 @@
+/*
+Package cipe watches the Nx Cloud CI pipeline executions (CIPEs) of a workspace.
+
+nx/recentCIPEData returns the recent pipelines of the branches the user works on, with
+their run groups and runs. A Poller asks for them again on an interval that adapts to
+what it sees: often while a pipeline is running, rarely once all are finished, and with
+an exponential backoff while Nx Cloud cannot be reached. Authentication errors wait the
+longest, since they need the user to log in again.
+
+Every update carries the events between the last two successful polls, such as a
+pipeline moving from IN_PROGRESS to FAILED together with the runs that failed.
+
+Workspaces that are not connected to Nx Cloud have no pipelines, check Connected before
+polling them.
+
+# Usage
+
+	if !cipe.Connected(workspace.NxJson, os.LookupEnv) {
+		return
+	}
+	poller := cipe.NewPoller(client.Commander.SendRecentCIPEDataRequest, cipe.DefaultPollIntervals, func(update cipe.Update) {
+		if update.Err != nil {
+			log.Printf("CI status unavailable (%s), retrying in %s", update.ErrorType(), update.Next)
+			return
+		}
+		for _, event := range update.Events {
+			fmt.Println(event) // "pipeline for branch feat/ui moved IN_PROGRESS → FAILED: failed nx affected -t test"
+		}
+	})
+	go poller.Run(ctx)
+*/
+package cipe
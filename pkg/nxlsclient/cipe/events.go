@@ -0,0 +1,103 @@
+package cipe
+
+import (
+	"fmt"
+	"strings"
+
+	nxtypes "github.com/lazyengs/lazynx/pkg/nxlsclient/nx-types"
+)
+
+// EventKind tells what happened to a pipeline between two polls.
+type EventKind string
+
+const (
+	// EventStarted means a pipeline appeared that the previous poll did not return.
+	EventStarted EventKind = "started"
+	// EventStatusChanged means the status of a pipeline changed.
+	EventStatusChanged EventKind = "status-changed"
+)
+
+// Event is a change of a CI pipeline execution.
+type Event struct {
+	Kind     EventKind
+	Pipeline nxtypes.CIPEInfo
+	// From is the previous status, empty for EventStarted.
+	From nxtypes.CIPEExecutionStatus
+	To   nxtypes.CIPEExecutionStatus
+	// FailedRuns are the runs of the pipeline that failed.
+	FailedRuns []nxtypes.CIPERun
+}
+
+func (e Event) String() string {
+	var s string
+	if e.Kind == EventStarted {
+		s = fmt.Sprintf("pipeline for branch %s started, %s", e.Pipeline.Branch, e.To)
+	} else {
+		s = fmt.Sprintf("pipeline for branch %s moved %s → %s", e.Pipeline.Branch, e.From, e.To)
+	}
+	if len(e.FailedRuns) > 0 {
+		commands := make([]string, len(e.FailedRuns))
+		for i, run := range e.FailedRuns {
+			commands[i] = run.Command
+		}
+		s += ": failed " + strings.Join(commands, ", ")
+	}
+	return s
+}
+
+// Failed reports whether the pipeline ended in failure, cancellation or a timeout.
+func (e Event) Failed() bool {
+	switch e.To {
+	case nxtypes.CIPEStatusFailed, nxtypes.CIPEStatusCanceled, nxtypes.CIPEStatusTimedOut:
+		return true
+	}
+	return false
+}
+
+// Active reports whether status is a pipeline that did not finish yet.
+func Active(status nxtypes.CIPEExecutionStatus) bool {
+	return status == nxtypes.CIPEStatusNotStarted || status == nxtypes.CIPEStatusInProgress
+}
+
+// FailedRuns returns the runs of pipeline that failed or have failed tasks, in run
+// group order.
+func FailedRuns(pipeline nxtypes.CIPEInfo) []nxtypes.CIPERun {
+	var runs []nxtypes.CIPERun
+	for _, group := range pipeline.RunGroups {
+		for _, run := range group.Runs {
+			failed := run.Status != nil && *run.Status == nxtypes.CIPEStatusFailed
+			if failed || (run.NumFailedTasks != nil && *run.NumFailedTasks > 0) {
+				runs = append(runs, run)
+			}
+		}
+	}
+	return runs
+}
+
+// Diff returns the events that turn prev into next, in the order of next. Pipelines are
+// matched by execution id; pipelines missing from next fell out of the recent list and
+// are not reported.
+func Diff(prev, next []nxtypes.CIPEInfo) []Event {
+	previous := make(map[string]nxtypes.CIPEInfo, len(prev))
+	for _, pipeline := range prev {
+		previous[pipeline.CiPipelineExecutionId] = pipeline
+	}
+
+	var events []Event
+	for _, pipeline := range next {
+		old, ok := previous[pipeline.CiPipelineExecutionId]
+		switch {
+		case !ok:
+			events = append(events, Event{Kind: EventStarted, Pipeline: pipeline, To: pipeline.Status, FailedRuns: FailedRuns(pipeline)})
+		case old.Status != pipeline.Status:
+			events = append(events, Event{
+				Kind:       EventStatusChanged,
+				Pipeline:   pipeline,
+				From:       old.Status,
+				To:         pipeline.Status,
+				FailedRuns: FailedRuns(pipeline),
+			})
+		}
+	}
+	return events
+}
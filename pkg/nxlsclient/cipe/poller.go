@@ -0,0 +1,226 @@
+package cipe
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/lazyengs/lazynx/pkg/nxlsclient/commands"
+	nxtypes "github.com/lazyengs/lazynx/pkg/nxlsclient/nx-types"
+)
+
+// PollIntervals configures how often the poller asks for recent pipelines.
+type PollIntervals struct {
+	Active     time.Duration // Active is the interval while a pipeline is running.
+	Idle       time.Duration // Idle is the interval while every pipeline is finished.
+	MaxBackoff time.Duration // MaxBackoff caps the interval after failed polls.
+}
+
+// DefaultPollIntervals suit a developer watching the pipelines of their branches.
+var DefaultPollIntervals = PollIntervals{
+	Active:     15 * time.Second,
+	Idle:       2 * time.Minute,
+	MaxBackoff: 10 * time.Minute,
+}
+
+// withDefaults fills unset intervals from DefaultPollIntervals.
+func (i PollIntervals) withDefaults() PollIntervals {
+	if i.Active <= 0 {
+		i.Active = DefaultPollIntervals.Active
+	}
+	if i.Idle <= 0 {
+		i.Idle = DefaultPollIntervals.Idle
+	}
+	if i.MaxBackoff <= 0 {
+		i.MaxBackoff = DefaultPollIntervals.MaxBackoff
+	}
+	return i
+}
+
+// FetchFunc returns the recent pipelines, such as Commander.SendRecentCIPEDataRequest.
+type FetchFunc func(ctx context.Context) (*commands.RecentCIPEDataResult, error)
+
+// Update is the outcome of a single poll.
+type Update struct {
+	// Pipelines are the recent pipelines of the last successful poll.
+	Pipelines    []nxtypes.CIPEInfo
+	WorkspaceURL string
+	// Events are the changes since the previous successful poll. The first poll reports
+	// no events, because every pipeline would be new.
+	Events    []Event
+	CheckedAt time.Time
+	// Next is the time until the next poll.
+	Next time.Duration
+	// Err is why the poll failed. Errors reported by Nx Cloud are *nxtypes.CIPEInfoError;
+	// anything else failed to reach nxls and is treated like a network error.
+	Err error
+	// ConsecutiveFailures counts failed polls in a row, including this one.
+	ConsecutiveFailures int
+}
+
+// ErrorType returns the type of Err, empty when the poll succeeded.
+func (u Update) ErrorType() nxtypes.CIPEErrorType {
+	var infoErr *nxtypes.CIPEInfoError
+	switch {
+	case u.Err == nil:
+		return ""
+	case errors.As(u.Err, &infoErr):
+		return infoErr.Type
+	}
+	return nxtypes.CIPEErrorTypeNetwork
+}
+
+// Connected reports whether a workspace is connected to Nx Cloud, through nxCloudId or
+// an access token in nx.json, its legacy tasksRunnerOptions or NX_CLOUD_ACCESS_TOKEN.
+// Polling a workspace that is not connected only ever fails.
+func Connected(nxJson nxtypes.NxJsonConfiguration, lookupEnv func(key string) (string, bool)) bool {
+	if nxJson.NeverConnectToCloud != nil && *nxJson.NeverConnectToCloud {
+		return false
+	}
+	if token, ok := lookupEnv("NX_CLOUD_ACCESS_TOKEN"); ok && token != "" {
+		return true
+	}
+	if nonEmpty(nxJson.NxCloudId) || nonEmpty(nxJson.NxCloudAccessToken) {
+		return true
+	}
+	for _, runner := range nxJson.TasksRunnerOptions {
+		if token, ok := runner.Options.GetString("accessToken"); ok && token != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func nonEmpty(s *string) bool {
+	return s != nil && *s != ""
+}
+
+// Poller refreshes the recent CI pipeline executions of the workspace and reports how
+// they changed.
+type Poller struct {
+	fetch     FetchFunc
+	intervals PollIntervals
+	onUpdate  func(Update)
+	refresh   chan struct{}
+
+	mu        sync.Mutex
+	last      Update
+	pipelines []nxtypes.CIPEInfo
+	polled    bool
+	failures  int
+}
+
+// NewPoller creates a Poller fetching pipelines with fetch. onUpdate, if not nil, is
+// called after every poll from the goroutine running Run.
+func NewPoller(fetch FetchFunc, intervals PollIntervals, onUpdate func(Update)) *Poller {
+	return &Poller{
+		fetch:     fetch,
+		intervals: intervals.withDefaults(),
+		onUpdate:  onUpdate,
+		refresh:   make(chan struct{}, 1),
+	}
+}
+
+// Last returns the most recent update.
+func (p *Poller) Last() Update {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.last
+}
+
+// Refresh makes Run poll right away instead of waiting for the next interval.
+func (p *Poller) Refresh() {
+	select {
+	case p.refresh <- struct{}{}:
+	default:
+	}
+}
+
+// Run polls until ctx is done, starting right away and then after the interval each
+// update asks for.
+func (p *Poller) Run(ctx context.Context) {
+	for {
+		update := p.Poll(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if p.onUpdate != nil {
+			p.onUpdate(update)
+		}
+
+		timer := time.NewTimer(update.Next)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-p.refresh:
+			timer.Stop()
+		case <-timer.C:
+		}
+	}
+}
+
+// Poll fetches the recent pipelines once, diffs them with the previous poll and records
+// the update.
+func (p *Poller) Poll(ctx context.Context) Update {
+	update := Update{CheckedAt: time.Now()}
+
+	result, err := p.fetch(ctx)
+	switch {
+	case err != nil:
+		update.Err = err
+	case result == nil:
+		update.Err = &nxtypes.CIPEInfoError{Type: nxtypes.CIPEErrorTypeOther, Message: "no CI pipeline data"}
+	case result.Error != nil:
+		update.Err = result.Error
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if update.Err != nil {
+		p.failures++
+		// Keep showing what the last successful poll returned
+		update.Pipelines = p.last.Pipelines
+		update.WorkspaceURL = p.last.WorkspaceURL
+	} else {
+		p.failures = 0
+		update.Pipelines = result.Info
+		update.WorkspaceURL = result.WorkspaceUrl
+		if p.polled {
+			update.Events = Diff(p.pipelines, result.Info)
+		}
+		p.pipelines = result.Info
+		p.polled = true
+	}
+	update.ConsecutiveFailures = p.failures
+	update.Next = p.next(update)
+	p.last = update
+	return update
+}
+
+// next returns the interval until the poll after update. Failures back off
+// exponentially from the active interval; authentication errors wait the longest,
+// since they only go away once the user logs in again.
+func (p *Poller) next(update Update) time.Duration {
+	if update.Err != nil {
+		if update.ErrorType() == nxtypes.CIPEErrorTypeAuthentication {
+			return p.intervals.MaxBackoff
+		}
+		backoff := p.intervals.Active
+		for i := 1; i < update.ConsecutiveFailures && backoff < p.intervals.MaxBackoff; i++ {
+			backoff *= 2
+		}
+		return min(backoff, p.intervals.MaxBackoff)
+	}
+
+	active := slices.ContainsFunc(update.Pipelines, func(pipeline nxtypes.CIPEInfo) bool {
+		return Active(pipeline.Status)
+	})
+	if active {
+		return p.intervals.Active
+	}
+	return p.intervals.Idle
+}
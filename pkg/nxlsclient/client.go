@@ -2,21 +2,39 @@ package nxlsclient
 
 import (
 	"context"
+	"os/exec"
 
 	"github.com/lazyengs/lazynx/pkg/nxlsclient/commands"
+	"github.com/lazyengs/lazynx/pkg/nxlsclient/metrics"
 	"github.com/sourcegraph/jsonrpc2"
 	"go.lsp.dev/protocol"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 )
 
 type Client struct {
 	Logger               *zap.SugaredLogger
 	conn                 *jsonrpc2.Conn
+	tempDir              string
 	serverDir            string
 	NxWorkspacePath      string
 	isVerbose            bool
 	Commander            *commands.Commander
 	notificationListener *notificationListener
+	runtime              *NodeRuntime
+	process              *exec.Cmd
+	processDone          chan struct{}
+	serverPid            int
+	daemonDetected       bool
+	daemonWasRunning     bool
+	// ShutdownTimeouts bounds each step of Stop. Zero values fall back to DefaultShutdownTimeouts.
+	ShutdownTimeouts ShutdownTimeouts
+	// DaemonPolicy decides whether Stop shuts down the Nx daemon. Empty means DefaultDaemonPolicy.
+	DaemonPolicy DaemonPolicy
+	// Metrics, if set before Start, records request and notification metrics.
+	Metrics *metrics.Registry
+	// TracerProvider creates the lifecycle and request spans. Nil means the global OpenTelemetry provider.
+	TracerProvider trace.TracerProvider
 }
 
 // NewClient creates a new Client struct instance with the given nxWorkspacePath and verbosity level.
@@ -34,6 +52,8 @@ func NewClient(nxWorkspacePath string, verbose bool) *Client {
 		NxWorkspacePath:      nxWorkspacePath,
 		isVerbose:            verbose,
 		notificationListener: newNotificationListener(),
+		ShutdownTimeouts:     DefaultShutdownTimeouts,
+		DaemonPolicy:         DefaultDaemonPolicy,
 	}
 }
 
@@ -46,6 +66,8 @@ func NewClientWithLogger(nxWorkspacePath string, verbose bool, logger *zap.Sugar
 		NxWorkspacePath:      nxWorkspacePath,
 		isVerbose:            verbose,
 		notificationListener: newNotificationListener(),
+		ShutdownTimeouts:     DefaultShutdownTimeouts,
+		DaemonPolicy:         DefaultDaemonPolicy,
 	}
 }
 
@@ -53,19 +75,49 @@ func NewClientWithLogger(nxWorkspacePath string, verbose bool, logger *zap.Sugar
 func (c *Client) Start(ctx context.Context, initParams *protocol.InitializeParams, ch chan *commands.InitializeRequestResult) error {
 	c.Logger.Debugw("Starting client")
 
-	err := c.unpackServer()
+	// The startup span covers the phases up to initialize, not the lifetime of the client
+	startupCtx, startup := c.startSpan(ctx, "nxls.start")
+
+	err := c.tracePhase(startupCtx, "nxls.resolveRuntime", func(ctx context.Context) error {
+		runtime, err := c.resolveNodeRuntime(ctx)
+		c.runtime = runtime
+		return err
+	})
 	if err != nil {
+		endSpan(startup, err)
+		return err
+	}
+
+	if removed := c.ReapStaleServers(); len(removed) > 0 {
+		c.Logger.Infow("Removed stale server directories", "dirs", removed)
+	}
+
+	err = c.tracePhase(startupCtx, "nxls.unpack", func(context.Context) error {
+		return c.unpackServer()
+	})
+	if err != nil {
+		endSpan(startup, err)
 		c.Stop(ctx)
 		return err
 	}
-	err = c.installDependencies(ctx)
+	err = c.tracePhase(startupCtx, "nxls.installDependencies", c.installDependencies)
 	if err != nil {
+		endSpan(startup, err)
 		c.Stop(ctx)
 		return err
 	}
 
-	rwc, err := c.startNxls(ctx)
+	c.detectDaemon()
+
+	var rwc *ReadWriteCloser
+	err = c.tracePhase(startupCtx, "nxls.spawn", func(context.Context) error {
+		var err error
+		// The process must live as long as ctx, the span only scopes the spawn itself
+		rwc, err = c.startNxls(ctx)
+		return err
+	})
 	if err != nil {
+		endSpan(startup, err)
 		c.Stop(ctx)
 		return err
 	}
@@ -73,8 +125,16 @@ func (c *Client) Start(ctx context.Context, initParams *protocol.InitializeParam
 	c.connectToLSPServer(ctx, rwc)
 
 	c.Commander = commands.NewCommander(c.conn, c.Logger)
+	c.Commander.SetTracerProvider(c.tracerProvider())
+	if c.Metrics != nil {
+		c.Commander.SetObserver(c.Metrics)
+	}
 
-	initResponse, err := c.Commander.SendInitializeRequest(ctx, initParams)
+	initResponse, err := c.Commander.SendInitializeRequest(startupCtx, initParams)
+	if initResponse != nil {
+		c.serverPid = initResponse.Pid
+	}
+	endSpan(startup, err)
 
 	ch <- initResponse
 
@@ -93,6 +153,9 @@ func (c *Client) Start(ctx context.Context, initParams *protocol.InitializeParam
 func (c *Client) Stop(ctx context.Context) {
 	c.Logger.Debugw("Stopping client")
 
+	ctx, span := c.startSpan(ctx, "nxls.stop")
+	defer span.End()
+
 	// Clear all notification handlers
 	if c.notificationListener != nil {
 		c.notificationListener.clearHandlers()
@@ -100,6 +163,7 @@ func (c *Client) Stop(ctx context.Context) {
 
 	err := c.stopNxls(ctx)
 	if err != nil {
+		span.RecordError(err)
 		c.Logger.Errorw("An error occurred while stopping nxls", "error", err.Error())
 	}
 
@@ -2,16 +2,35 @@ package commands
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/sourcegraph/jsonrpc2"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 )
 
+// TracerName is the instrumentation name of the spans created by a Commander.
+const TracerName = "github.com/lazyengs/lazynx/pkg/nxlsclient/commands"
+
+// RequestObserver is notified about every request sent by a Commander.
+type RequestObserver interface {
+	// RequestStarted is called before the request is sent.
+	RequestStarted(method string)
+	// RequestFinished is called once the request completed, with the size of the raw response.
+	RequestFinished(method string, duration time.Duration, responseBytes int, err error)
+}
+
 // Commander is responsible for sending requests and notifications via JSON-RPC.
 type Commander struct {
-	Logger *zap.SugaredLogger // Logger is used to log messages.
-	conn   *jsonrpc2.Conn     // conn is the JSON-RPC connection.
+	Logger   *zap.SugaredLogger // Logger is used to log messages.
+	conn     *jsonrpc2.Conn     // conn is the JSON-RPC connection.
+	observer RequestObserver    // observer receives request metrics, if set.
+	tracer   trace.Tracer       // tracer creates a span for every request and notification.
 }
 
 // NewCommander creates a new Commander instance.
@@ -19,31 +38,67 @@ func NewCommander(conn *jsonrpc2.Conn, logger *zap.SugaredLogger) *Commander {
 	return &Commander{
 		Logger: logger,
 		conn:   conn,
+		tracer: otel.Tracer(TracerName),
 	}
 }
 
+// SetTracerProvider makes the Commander create its spans with provider instead of the
+// global OpenTelemetry tracer provider.
+func (c *Commander) SetTracerProvider(provider trace.TracerProvider) {
+	c.tracer = provider.Tracer(TracerName)
+}
+
+// SetObserver registers an observer that is notified about every request.
+func (c *Commander) SetObserver(observer RequestObserver) {
+	c.observer = observer
+}
+
 // sendRequest sends a JSON-RPC request and stores the result in the provided result parameter.
-func (c *Commander) sendRequest(ctx context.Context, method string, params any, result any) error {
+func (c *Commander) sendRequest(ctx context.Context, method string, params any, result any) (err error) {
 	c.Logger.Debugw("Sending request", "method", method, "params", params)
 
+	ctx, span := c.startSpan(ctx, method)
+	defer func() { endSpan(span, err) }()
+
 	// Check connection state before making the call
 	if c.conn == nil {
 		return fmt.Errorf("connection is nil")
 	}
 
-	if err := c.conn.Call(ctx, method, params, result); err != nil {
+	// Decode in two steps so the response size can be reported
+	var raw json.RawMessage
+	if c.observer != nil {
+		c.observer.RequestStarted(method)
+		start := time.Now()
+		defer func() {
+			c.observer.RequestFinished(method, time.Since(start), len(raw), err)
+		}()
+	}
+
+	if err := c.conn.Call(ctx, method, params, &raw); err != nil {
 		c.Logger.Warnw("Request failed", "method", method, "error", err)
 		return fmt.Errorf("an error occurred while executing the request: %w", err)
 	}
 
+	span.SetAttributes(attribute.Int("rpc.response.size", len(raw)))
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, result); err != nil {
+			c.Logger.Warnw("Failed to decode response", "method", method, "error", err)
+			return fmt.Errorf("an error occurred while decoding the response: %w", err)
+		}
+	}
+
 	c.Logger.Debugw("Request successful", "method", method)
 	return nil
 }
 
 // sendNotification sends a JSON-RPC notification.
-func (c *Commander) sendNotification(ctx context.Context, method string, params any) error {
+func (c *Commander) sendNotification(ctx context.Context, method string, params any) (err error) {
 	c.Logger.Debugw("Sending notification", "method", method, "params", params)
 
+	ctx, span := c.startSpan(ctx, method)
+	defer func() { endSpan(span, err) }()
+
 	// Check connection state before making the call
 	if c.conn == nil {
 		return fmt.Errorf("connection is nil")
@@ -57,3 +112,27 @@ func (c *Commander) sendNotification(ctx context.Context, method string, params
 	c.Logger.Debugw("Notification sent successfully", "method", method)
 	return nil
 }
+
+// startSpan starts a client span for a JSON-RPC call, as a child of any span in ctx.
+func (c *Commander) startSpan(ctx context.Context, method string) (context.Context, trace.Span) {
+	tracer := c.tracer
+	if tracer == nil {
+		tracer = otel.Tracer(TracerName)
+	}
+	return tracer.Start(ctx, method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("rpc.system", "jsonrpc"),
+			attribute.String("rpc.method", method),
+		),
+	)
+}
+
+// endSpan records err, if any, and ends the span.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
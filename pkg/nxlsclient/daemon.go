@@ -0,0 +1,96 @@
+package nxlsclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// DaemonPolicy decides whether Stop shuts down the Nx daemon.
+type DaemonPolicy string
+
+const (
+	// DaemonPolicyNever leaves the Nx daemon running.
+	DaemonPolicyNever DaemonPolicy = "never"
+	// DaemonPolicyIfStarted stops the daemon only when none was running before Start.
+	DaemonPolicyIfStarted DaemonPolicy = "if-started"
+	// DaemonPolicyAlways stops the daemon on every Stop.
+	DaemonPolicyAlways DaemonPolicy = "always"
+)
+
+// DefaultDaemonPolicy is used by clients created with NewClient and NewClientWithLogger.
+const DefaultDaemonPolicy = DaemonPolicyIfStarted
+
+// ParseDaemonPolicy validates a policy name. An empty string yields DefaultDaemonPolicy.
+func ParseDaemonPolicy(s string) (DaemonPolicy, error) {
+	switch p := DaemonPolicy(s); p {
+	case "":
+		return DefaultDaemonPolicy, nil
+	case DaemonPolicyNever, DaemonPolicyIfStarted, DaemonPolicyAlways:
+		return p, nil
+	default:
+		return "", fmt.Errorf("unknown daemon policy %q, expected %q, %q or %q", s, DaemonPolicyNever, DaemonPolicyIfStarted, DaemonPolicyAlways)
+	}
+}
+
+// daemonServerProcessFiles lists where Nx records the running daemon, newest layout first.
+func daemonServerProcessFiles(workspacePath string) []string {
+	var files []string
+	if dir := os.Getenv("NX_WORKSPACE_DATA_DIRECTORY"); dir != "" {
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(workspacePath, dir)
+		}
+		files = append(files, filepath.Join(dir, "d", "server-process.json"))
+	}
+
+	return append(files,
+		// Nx 19 and later
+		filepath.Join(workspacePath, ".nx", "workspace-data", "d", "server-process.json"),
+		// Nx 16 to 18
+		filepath.Join(workspacePath, "node_modules", ".cache", "nx", "d", "server-process.json"),
+	)
+}
+
+// isDaemonRunning reports whether an Nx daemon is serving the workspace.
+func isDaemonRunning(workspacePath string) bool {
+	for _, file := range daemonServerProcessFiles(workspacePath) {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+
+		var info struct {
+			ProcessID int `json:"processId"`
+		}
+		if err := json.Unmarshal(data, &info); err != nil {
+			continue
+		}
+
+		return processAlive(info.ProcessID)
+	}
+
+	// Nx removes the file when the daemon stops, so its absence means no daemon
+	return false
+}
+
+// detectDaemon records whether an Nx daemon was already running before nxls started.
+func (c *Client) detectDaemon() {
+	running := isDaemonRunning(c.NxWorkspacePath)
+	c.daemonWasRunning = running
+	c.daemonDetected = true
+	c.Logger.Debugw("Detected Nx daemon state", "running", running, "policy", c.DaemonPolicy)
+}
+
+// shouldStopDaemon applies DaemonPolicy to decide whether Stop shuts down the Nx daemon.
+func (c *Client) shouldStopDaemon() bool {
+	switch c.DaemonPolicy {
+	case DaemonPolicyNever:
+		return false
+	case DaemonPolicyAlways:
+		return true
+	default:
+		// Without detection nxls never started, so neither did a daemon
+		return c.daemonDetected && !c.daemonWasRunning
+	}
+}
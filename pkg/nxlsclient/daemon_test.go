@@ -0,0 +1,105 @@
+package nxlsclient
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func writeServerProcessFile(t *testing.T, workspace string, pid int) {
+	t.Helper()
+
+	dir := filepath.Join(workspace, ".nx", "workspace-data", "d")
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	content := `{"processId": ` + strconv.Itoa(pid) + `, "daemonVersion": "20.0.0"}`
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "server-process.json"), []byte(content), 0644))
+}
+
+func TestParseDaemonPolicy(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected DaemonPolicy
+		wantErr  bool
+	}{
+		{input: "", expected: DefaultDaemonPolicy},
+		{input: "never", expected: DaemonPolicyNever},
+		{input: "if-started", expected: DaemonPolicyIfStarted},
+		{input: "always", expected: DaemonPolicyAlways},
+		{input: "sometimes", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			policy, err := ParseDaemonPolicy(tt.input)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, policy)
+		})
+	}
+}
+
+func TestIsDaemonRunning(t *testing.T) {
+	t.Setenv("NX_WORKSPACE_DATA_DIRECTORY", "")
+
+	t.Run("NoMetadata", func(t *testing.T) {
+		assert.False(t, isDaemonRunning(t.TempDir()))
+	})
+
+	t.Run("LiveProcess", func(t *testing.T) {
+		workspace := t.TempDir()
+		writeServerProcessFile(t, workspace, os.Getpid())
+		assert.True(t, isDaemonRunning(workspace))
+	})
+
+	t.Run("DeadProcess", func(t *testing.T) {
+		dead := exec.Command("true")
+		require.NoError(t, dead.Run())
+
+		workspace := t.TempDir()
+		writeServerProcessFile(t, workspace, dead.Process.Pid)
+		assert.False(t, isDaemonRunning(workspace))
+	})
+}
+
+func TestShouldStopDaemon(t *testing.T) {
+	t.Setenv("NX_WORKSPACE_DATA_DIRECTORY", "")
+	logger, _ := zap.NewDevelopment()
+
+	running := t.TempDir()
+	writeServerProcessFile(t, running, os.Getpid())
+	idle := t.TempDir()
+
+	tests := []struct {
+		name      string
+		policy    DaemonPolicy
+		workspace string
+		detect    bool
+		expected  bool
+	}{
+		{name: "NeverWithoutDaemon", policy: DaemonPolicyNever, workspace: idle, detect: true, expected: false},
+		{name: "AlwaysWithDaemon", policy: DaemonPolicyAlways, workspace: running, detect: true, expected: true},
+		{name: "IfStartedWithDaemon", policy: DaemonPolicyIfStarted, workspace: running, detect: true, expected: false},
+		{name: "IfStartedWithoutDaemon", policy: DaemonPolicyIfStarted, workspace: idle, detect: true, expected: true},
+		{name: "IfStartedNeverDetected", policy: DaemonPolicyIfStarted, workspace: idle, detect: false, expected: false},
+		{name: "EmptyPolicyDefaults", policy: "", workspace: running, detect: true, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &Client{Logger: logger.Sugar(), NxWorkspacePath: tt.workspace, DaemonPolicy: tt.policy}
+			if tt.detect {
+				client.detectDaemon()
+			}
+			assert.Equal(t, tt.expected, client.shouldStopDaemon())
+		})
+	}
+}
@@ -0,0 +1,310 @@
+package diff
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/lazyengs/lazynx/pkg/nxlsclient/graph"
+	nxtypes "github.com/lazyengs/lazynx/pkg/nxlsclient/nx-types"
+)
+
+// Diff lists the changes between two project graphs. Lists are sorted and empty when
+// nothing changed.
+type Diff struct {
+	AddedProjects       []string                         `json:"addedProjects,omitempty"`
+	RemovedProjects     []string                         `json:"removedProjects,omitempty"`
+	AddedDependencies   []nxtypes.ProjectGraphDependency `json:"addedDependencies,omitempty"`
+	RemovedDependencies []nxtypes.ProjectGraphDependency `json:"removedDependencies,omitempty"`
+	// ChangedProjects are the projects present in both graphs whose targets or tags changed.
+	ChangedProjects []ProjectDiff `json:"changedProjects,omitempty"`
+}
+
+// ProjectDiff lists the changes of a project present in both graphs.
+type ProjectDiff struct {
+	Name           string       `json:"name"`
+	AddedTargets   []string     `json:"addedTargets,omitempty"`
+	RemovedTargets []string     `json:"removedTargets,omitempty"`
+	ChangedTargets []TargetDiff `json:"changedTargets,omitempty"`
+	AddedTags      []string     `json:"addedTags,omitempty"`
+	RemovedTags    []string     `json:"removedTags,omitempty"`
+}
+
+// TargetDiff lists the changes of a target present in both graphs.
+type TargetDiff struct {
+	Name string `json:"name"`
+	// Fields are the changed target fields other than options, such as "executor",
+	// "dependsOn" or "configurations.production".
+	Fields  []string       `json:"fields,omitempty"`
+	Options []OptionChange `json:"options,omitempty"`
+}
+
+// OptionChange is a changed executor option. Old is nil for added options and New for
+// removed ones.
+type OptionChange struct {
+	Key string          `json:"key"`
+	Old json.RawMessage `json:"old,omitempty"`
+	New json.RawMessage `json:"new,omitempty"`
+}
+
+// Workspaces compares the project graphs of two workspace snapshots. Either may be nil.
+func Workspaces(before, after *nxtypes.NxWorkspace) *Diff {
+	var oldGraph, newGraph *nxtypes.ProjectGraph
+	if before != nil {
+		oldGraph = &before.ProjectGraph
+	}
+	if after != nil {
+		newGraph = &after.ProjectGraph
+	}
+	return ProjectGraphs(oldGraph, newGraph)
+}
+
+// ProjectGraphs compares two project graphs. Either may be nil.
+func ProjectGraphs(before, after *nxtypes.ProjectGraph) *Diff {
+	if before == nil {
+		before = &nxtypes.ProjectGraph{}
+	}
+	if after == nil {
+		after = &nxtypes.ProjectGraph{}
+	}
+
+	d := &Diff{}
+	for name, node := range after.Nodes {
+		oldNode, ok := before.Nodes[name]
+		if !ok {
+			d.AddedProjects = append(d.AddedProjects, name)
+			continue
+		}
+		if changes := diffProject(name, &oldNode.Data.ProjectConfiguration, &node.Data.ProjectConfiguration); changes != nil {
+			d.ChangedProjects = append(d.ChangedProjects, *changes)
+		}
+	}
+	for name := range before.Nodes {
+		if _, ok := after.Nodes[name]; !ok {
+			d.RemovedProjects = append(d.RemovedProjects, name)
+		}
+	}
+	sort.Strings(d.AddedProjects)
+	sort.Strings(d.RemovedProjects)
+	sort.Slice(d.ChangedProjects, func(i, j int) bool { return d.ChangedProjects[i].Name < d.ChangedProjects[j].Name })
+
+	oldEdges, newEdges := edges(before), edges(after)
+	for _, e := range newEdges.list {
+		if !oldEdges.set[e] {
+			d.AddedDependencies = append(d.AddedDependencies, e)
+		}
+	}
+	for _, e := range oldEdges.list {
+		if !newEdges.set[e] {
+			d.RemovedDependencies = append(d.RemovedDependencies, e)
+		}
+	}
+
+	return d
+}
+
+type edgeSet struct {
+	list []nxtypes.ProjectGraphDependency // list is sorted by source, target and type.
+	set  map[nxtypes.ProjectGraphDependency]bool
+}
+
+// edges returns the deduplicated edges of pg.
+func edges(pg *nxtypes.ProjectGraph) edgeSet {
+	g := graph.New(pg)
+	result := edgeSet{set: make(map[nxtypes.ProjectGraphDependency]bool)}
+	for _, name := range g.Nodes() {
+		for _, e := range g.DependencyEdges(name) {
+			result.list = append(result.list, e)
+			result.set[e] = true
+		}
+	}
+	return result
+}
+
+func diffProject(name string, before, after *nxtypes.ProjectConfiguration) *ProjectDiff {
+	p := &ProjectDiff{Name: name}
+
+	p.AddedTags, p.RemovedTags = setDiff(before.Tags, after.Tags)
+	p.AddedTargets, p.RemovedTargets = setDiff(keys(before.Targets), keys(after.Targets))
+
+	for _, target := range keys(after.Targets) {
+		oldTarget, ok := before.Targets[target]
+		if !ok {
+			continue
+		}
+		if changes := diffTarget(target, oldTarget, after.Targets[target]); changes != nil {
+			p.ChangedTargets = append(p.ChangedTargets, *changes)
+		}
+	}
+
+	if p.AddedTags == nil && p.RemovedTags == nil && p.AddedTargets == nil && p.RemovedTargets == nil && p.ChangedTargets == nil {
+		return nil
+	}
+	return p
+}
+
+func diffTarget(name string, before, after nxtypes.TargetConfiguration) *TargetDiff {
+	t := &TargetDiff{Name: name}
+
+	oldFields, newFields := fields(before), fields(after)
+	for _, field := range union(keys(oldFields), keys(newFields)) {
+		switch field {
+		case "options":
+			t.Options = diffOptions(before.Options, after.Options)
+		case "configurations":
+			for _, configuration := range union(keys(before.Configurations), keys(after.Configurations)) {
+				if !equalJSON(marshal(before.Configurations[configuration]), marshal(after.Configurations[configuration])) {
+					t.Fields = append(t.Fields, "configurations."+configuration)
+				}
+			}
+		default:
+			if !equalJSON(oldFields[field], newFields[field]) {
+				t.Fields = append(t.Fields, field)
+			}
+		}
+	}
+
+	if t.Fields == nil && t.Options == nil {
+		return nil
+	}
+	return t
+}
+
+func diffOptions(before, after nxtypes.Options) []OptionChange {
+	var changes []OptionChange
+	for _, key := range union(keys(before), keys(after)) {
+		if !equalJSON(before[key], after[key]) {
+			changes = append(changes, OptionChange{Key: key, Old: before[key], New: after[key]})
+		}
+	}
+	return changes
+}
+
+// fields splits a target into its JSON fields, so every field is compared the way it
+// is written in project.json.
+func fields(target nxtypes.TargetConfiguration) map[string]json.RawMessage {
+	var result map[string]json.RawMessage
+	_ = json.Unmarshal(marshal(target), &result)
+	return result
+}
+
+func marshal(v any) json.RawMessage {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+// equalJSON compares two JSON values ignoring formatting and key order. Missing values
+// only equal missing values and null.
+func equalJSON(a, b json.RawMessage) bool {
+	if bytes.Equal(a, b) {
+		return true
+	}
+	return bytes.Equal(canonical(a), canonical(b))
+}
+
+func canonical(data json.RawMessage) []byte {
+	if len(data) == 0 {
+		return []byte("null")
+	}
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		return data
+	}
+	// Maps are marshaled with sorted keys
+	return marshal(v)
+}
+
+func keys[V any](m map[string]V) []string {
+	result := make([]string, 0, len(m))
+	for k := range m {
+		result = append(result, k)
+	}
+	sort.Strings(result)
+	return result
+}
+
+func union(a, b []string) []string {
+	result := slices.Concat(a, b)
+	sort.Strings(result)
+	return slices.Compact(result)
+}
+
+// setDiff returns the values only in after and the values only in before, sorted.
+func setDiff(before, after []string) (added, removed []string) {
+	for _, v := range after {
+		if !slices.Contains(before, v) && !slices.Contains(added, v) {
+			added = append(added, v)
+		}
+	}
+	for _, v := range before {
+		if !slices.Contains(after, v) && !slices.Contains(removed, v) {
+			removed = append(removed, v)
+		}
+	}
+	sort.Strings(added)
+	sort.Strings(removed)
+	return added, removed
+}
+
+// Empty reports whether the graphs are the same.
+func (d *Diff) Empty() bool {
+	return len(d.AddedProjects) == 0 && len(d.RemovedProjects) == 0 &&
+		len(d.AddedDependencies) == 0 && len(d.RemovedDependencies) == 0 &&
+		len(d.ChangedProjects) == 0
+}
+
+// Summary describes the diff in one line, such as "+1 project, 3 new deps, 2 projects changed".
+func (d *Diff) Summary() string {
+	if d.Empty() {
+		return "no changes"
+	}
+
+	var parts []string
+	add := func(n int, format, singular, plural string) {
+		switch n {
+		case 0:
+		case 1:
+			parts = append(parts, fmt.Sprintf(format, n, singular))
+		default:
+			parts = append(parts, fmt.Sprintf(format, n, plural))
+		}
+	}
+	add(len(d.AddedProjects), "+%d %s", "project", "projects")
+	add(len(d.RemovedProjects), "-%d %s", "project", "projects")
+	add(len(d.AddedDependencies), "%d new %s", "dep", "deps")
+	add(len(d.RemovedDependencies), "%d removed %s", "dep", "deps")
+	add(len(d.ChangedProjects), "%d %s changed", "project", "projects")
+	return strings.Join(parts, ", ")
+}
+
+// Touched returns the projects of the new graph that were added, changed, or gained or
+// lost a dependency, sorted. These are the rows worth highlighting.
+func (d *Diff) Touched() []string {
+	var names []string
+	names = append(names, d.AddedProjects...)
+	for _, p := range d.ChangedProjects {
+		names = append(names, p.Name)
+	}
+	for _, e := range slices.Concat(d.AddedDependencies, d.RemovedDependencies) {
+		if !slices.Contains(d.RemovedProjects, e.Source) {
+			names = append(names, e.Source)
+		}
+	}
+	return union(names, nil)
+}
+
+// Project returns the changes of a project present in both graphs.
+func (d *Diff) Project(name string) (ProjectDiff, bool) {
+	for _, p := range d.ChangedProjects {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return ProjectDiff{}, false
+}
@@ -0,0 +1,109 @@
+package diff
+
+import (
+	"encoding/json"
+	"testing"
+
+	nxtypes "github.com/lazyengs/lazynx/pkg/nxlsclient/nx-types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestGraph builds a project graph from project configurations given as JSON and
+// dependencies given as source -> targets.
+func newTestGraph(t *testing.T, projects map[string]string, deps map[string][]string) *nxtypes.ProjectGraph {
+	t.Helper()
+
+	pg := &nxtypes.ProjectGraph{
+		Nodes:        make(map[string]nxtypes.ProjectGraphProjectNode),
+		Dependencies: make(map[string][]nxtypes.ProjectGraphDependency),
+	}
+	for name, config := range projects {
+		node := nxtypes.ProjectGraphProjectNode{Name: name, Type: "lib"}
+		require.NoError(t, json.Unmarshal([]byte(config), &node.Data.ProjectConfiguration))
+		pg.Nodes[name] = node
+	}
+	for source, targets := range deps {
+		for _, target := range targets {
+			pg.Dependencies[source] = append(pg.Dependencies[source], nxtypes.ProjectGraphDependency{Source: source, Target: target, Type: "static"})
+		}
+	}
+	return pg
+}
+
+func TestProjectGraphs(t *testing.T) {
+	before := newTestGraph(t, map[string]string{
+		"app":    `{"root":"apps/app","tags":["scope:app"],"targets":{"build":{"executor":"@nx/vite:build","options":{"outDir":"dist/app","sourcemap":true}},"lint":{}}}`,
+		"ui":     `{"root":"libs/ui","tags":["scope:shared"],"targets":{"build":{"dependsOn":["^build"]}}}`,
+		"legacy": `{"root":"libs/legacy"}`,
+	}, map[string][]string{
+		"app": {"ui", "legacy"},
+	})
+	after := newTestGraph(t, map[string]string{
+		// Reformatted options are not a change
+		"app":   `{"root":"apps/app","tags":["scope:app","type:app"],"targets":{"build":{"options":{"sourcemap":false,"outDir":"dist/app","minify":true},"executor":"@nx/vite:build","configurations":{"production":{}}},"test":{}}}`,
+		"ui":    `{"root":"libs/ui","tags":["scope:shared"],"targets":{"build":{"dependsOn":["^build"]}}}`,
+		"utils": `{"root":"libs/utils"}`,
+	}, map[string][]string{
+		"app": {"ui", "npm:react"},
+		"ui":  {"utils"},
+	})
+
+	d := ProjectGraphs(before, after)
+
+	assert.Equal(t, []string{"utils"}, d.AddedProjects)
+	assert.Equal(t, []string{"legacy"}, d.RemovedProjects)
+	assert.Equal(t, []nxtypes.ProjectGraphDependency{
+		{Source: "app", Target: "npm:react", Type: "static"},
+		{Source: "ui", Target: "utils", Type: "static"},
+	}, d.AddedDependencies)
+	assert.Equal(t, []nxtypes.ProjectGraphDependency{{Source: "app", Target: "legacy", Type: "static"}}, d.RemovedDependencies)
+
+	require.Len(t, d.ChangedProjects, 1)
+	app, ok := d.Project("app")
+	require.True(t, ok)
+	assert.Equal(t, []string{"type:app"}, app.AddedTags)
+	assert.Nil(t, app.RemovedTags)
+	assert.Equal(t, []string{"test"}, app.AddedTargets)
+	assert.Equal(t, []string{"lint"}, app.RemovedTargets)
+	assert.Equal(t, []TargetDiff{{
+		Name:   "build",
+		Fields: []string{"configurations.production"},
+		Options: []OptionChange{
+			{Key: "minify", New: json.RawMessage(`true`)},
+			{Key: "sourcemap", Old: json.RawMessage(`true`), New: json.RawMessage(`false`)},
+		},
+	}}, app.ChangedTargets)
+
+	_, ok = d.Project("ui")
+	assert.False(t, ok)
+
+	assert.Equal(t, "+1 project, -1 project, 2 new deps, 1 removed dep, 1 project changed", d.Summary())
+	assert.Equal(t, []string{"app", "ui", "utils"}, d.Touched())
+}
+
+func TestTargetFields(t *testing.T) {
+	before := newTestGraph(t, map[string]string{
+		"app": `{"root":"apps/app","targets":{"build":{"dependsOn":["^build"],"cache":true,"outputs":["{options.outDir}"]}}}`,
+	}, nil)
+	after := newTestGraph(t, map[string]string{
+		"app": `{"root":"apps/app","targets":{"build":{"dependsOn":[{"target":"build","dependencies":true}],"outputs":["{options.outDir}"]}}}`,
+	}, nil)
+
+	app, ok := ProjectGraphs(before, after).Project("app")
+	require.True(t, ok)
+	assert.Equal(t, []string{"cache", "dependsOn"}, app.ChangedTargets[0].Fields)
+}
+
+func TestEmpty(t *testing.T) {
+	pg := newTestGraph(t, map[string]string{"app": `{"root":"apps/app"}`}, map[string][]string{"app": {"npm:react"}})
+
+	d := ProjectGraphs(pg, pg)
+	assert.True(t, d.Empty())
+	assert.Equal(t, "no changes", d.Summary())
+	assert.Empty(t, d.Touched())
+
+	// A missing snapshot compares as an empty graph
+	d = Workspaces(nil, &nxtypes.NxWorkspace{ProjectGraph: *pg})
+	assert.Equal(t, "+1 project, 1 new dep", d.Summary())
+}
@@ -0,0 +1,31 @@
+/*
+Package diff compares two snapshots of a workspace project graph.
+
+nxls sends nx/refreshWorkspace whenever the workspace changes, but not what changed. Keeping
+the previous nx/workspace response and comparing it with the new one tells which projects
+were added or removed, which dependencies appeared or disappeared, and which projects had
+targets added, removed or reconfigured, or tags changed.
+
+# Usage
+
+	before, err := client.Commander.SendWorkspaceRequest(ctx, &commands.WorkspaceRequestParams{})
+	// ... nx/refreshWorkspace ...
+	after, err := client.Commander.SendWorkspaceRequest(ctx, &commands.WorkspaceRequestParams{})
+
+	changes := diff.Workspaces(before, after)
+	if !changes.Empty() {
+		fmt.Println("workspace changed:", changes.Summary()) // "+1 project, 3 new deps"
+	}
+
+	for _, project := range changes.ChangedProjects {
+		for _, target := range project.ChangedTargets {
+			for _, option := range target.Options {
+				fmt.Printf("%s:%s %s: %s -> %s\n", project.Name, target.Name, option.Key, option.Old, option.New)
+			}
+		}
+	}
+
+Targets are compared field by field as they appear in project.json, ignoring formatting and
+key order; options and configurations are compared key by key.
+*/
+package diff
@@ -0,0 +1,140 @@
+package export
+
+import (
+	"encoding/json"
+	"slices"
+	"sort"
+
+	"github.com/lazyengs/lazynx/pkg/nxlsclient/graph"
+	nxtypes "github.com/lazyengs/lazynx/pkg/nxlsclient/nx-types"
+	"github.com/lazyengs/lazynx/pkg/nxlsclient/taskgraph"
+)
+
+// Node is a project, an external package or a task.
+type Node struct {
+	ID       string   `json:"id"`
+	Type     string   `json:"type,omitempty"`    // Type is the nx project type, the external node type or "task".
+	Project  string   `json:"project,omitempty"` // Project is the project of a task node.
+	Root     string   `json:"root,omitempty"`
+	Tags     []string `json:"tags,omitempty"`
+	External bool     `json:"external,omitempty"`
+	Group    string   `json:"group,omitempty"` // Group is set by Options.GroupBy.
+}
+
+// Edge is a dependency from Source to Target.
+type Edge struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+	Type   string `json:"type,omitempty"`  // Type is the dependency type, or "task" for task edges.
+	Label  string `json:"label,omitempty"` // Label is the dependsOn rule of task edges.
+}
+
+// Diagram is a graph normalized for export: nodes are sorted by id and edges by source,
+// target and type, so the same graph always produces the same output.
+type Diagram struct {
+	Nodes []Node `json:"nodes"`
+	Edges []Edge `json:"edges"`
+}
+
+// FromProjectGraph converts a project graph, external nodes included.
+func FromProjectGraph(pg *nxtypes.ProjectGraph) *Diagram {
+	g := graph.New(pg)
+	d := &Diagram{}
+
+	for _, name := range g.Nodes() {
+		node := Node{ID: name, External: g.IsExternal(name)}
+		if project, ok := pg.Nodes[name]; ok {
+			node.Type = project.Type
+			node.Root = project.Data.Root
+			node.Tags = sortedTags(project.Data.Tags)
+		} else if external, ok := pg.ExternalNodes[name]; ok {
+			node.Type = external.Type
+		}
+		d.Nodes = append(d.Nodes, node)
+
+		for _, dep := range g.DependencyEdges(name) {
+			d.Edges = append(d.Edges, Edge{Source: dep.Source, Target: dep.Target, Type: dep.Type})
+		}
+	}
+
+	d.normalize()
+	return d
+}
+
+// FromTaskGraph converts a task graph. projects, which may be nil, provides the roots and
+// tags of the tasks' projects.
+func FromTaskGraph(tasks *taskgraph.Graph, projects *nxtypes.ProjectGraph) *Diagram {
+	d := &Diagram{}
+
+	for _, id := range tasks.IDs() {
+		node := Node{ID: id.String(), Type: "task", Project: id.Project}
+		if projects != nil {
+			if project, ok := projects.Nodes[id.Project]; ok {
+				node.Root = project.Data.Root
+				node.Tags = sortedTags(project.Data.Tags)
+			}
+		}
+		d.Nodes = append(d.Nodes, node)
+
+		for _, e := range tasks.Dependencies(id) {
+			d.Edges = append(d.Edges, Edge{Source: e.From.String(), Target: e.To.String(), Type: "task", Label: ruleLabel(e.Rule)})
+		}
+	}
+
+	d.normalize()
+	return d
+}
+
+func ruleLabel(rule nxtypes.TargetDependency) string {
+	if rule.Shorthand != "" {
+		return rule.Shorthand
+	}
+	data, err := json.Marshal(rule)
+	if err != nil {
+		return rule.Target
+	}
+	return string(data)
+}
+
+func sortedTags(tags []string) []string {
+	if len(tags) == 0 {
+		return nil
+	}
+	tags = slices.Clone(tags)
+	sort.Strings(tags)
+	return tags
+}
+
+// normalize sorts nodes and edges and makes empty lists marshal as [].
+func (d *Diagram) normalize() {
+	if d.Nodes == nil {
+		d.Nodes = []Node{}
+	}
+	if d.Edges == nil {
+		d.Edges = []Edge{}
+	}
+
+	sort.Slice(d.Nodes, func(i, j int) bool { return d.Nodes[i].ID < d.Nodes[j].ID })
+	sort.Slice(d.Edges, func(i, j int) bool {
+		a, b := d.Edges[i], d.Edges[j]
+		if a.Source != b.Source {
+			return a.Source < b.Source
+		}
+		if a.Target != b.Target {
+			return a.Target < b.Target
+		}
+		return a.Type < b.Type
+	})
+}
+
+// Groups returns the distinct non-empty node groups, sorted.
+func (d *Diagram) Groups() []string {
+	var groups []string
+	for _, n := range d.Nodes {
+		if n.Group != "" && !slices.Contains(groups, n.Group) {
+			groups = append(groups, n.Group)
+		}
+	}
+	sort.Strings(groups)
+	return groups
+}
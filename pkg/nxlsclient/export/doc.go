@@ -0,0 +1,34 @@
+/*
+Package export writes project graphs and task graphs as Graphviz DOT, Mermaid, GraphML or
+normalized JSON, for architecture diagrams in design documents and pull requests.
+
+A graph is first converted into a Diagram, which can then be narrowed to the neighbourhood of
+some nodes, stripped of excluded nodes, limited in depth and grouped by tag or directory.
+
+# Usage
+
+	workspace, err := client.Commander.SendWorkspaceRequest(ctx, &commands.WorkspaceRequestParams{})
+	if err != nil {
+		// Handle error
+	}
+
+	diagram, err := export.FromProjectGraph(&workspace.ProjectGraph).Apply(export.Options{
+		Focus:           []string{"app"},
+		Exclude:         []string{"tag:scope:legacy"},
+		ExcludeExternal: true,
+		Depth:           2,
+		GroupBy:         export.GroupByDirectory,
+	})
+	if err != nil {
+		// Handle error
+	}
+
+	err = export.Write(os.Stdout, diagram, export.FormatMermaid)
+
+Task graphs from the taskgraph package are converted with FromTaskGraph; their edges carry
+the dependsOn rule as a label.
+
+Nodes are sorted by id and edges by source and target, so exporting the same graph twice
+produces identical output that diffs cleanly.
+*/
+package export
@@ -0,0 +1,154 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/bradleyjkemp/cupaloy/v2"
+	nxtypes "github.com/lazyengs/lazynx/pkg/nxlsclient/nx-types"
+	"github.com/lazyengs/lazynx/pkg/nxlsclient/taskgraph"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func projectNode(name, kind, root string, tags ...string) nxtypes.ProjectGraphProjectNode {
+	node := nxtypes.ProjectGraphProjectNode{Name: name, Type: kind}
+	node.Data.Root = root
+	node.Data.Tags = tags
+	return node
+}
+
+// newTestGraph builds:
+//
+//	e2e -> app (implicit)
+//	app -> feature -> ui -> utils
+//	app -> ui (dynamic)
+//	ui -> npm:@types/react
+func newTestGraph() *nxtypes.ProjectGraph {
+	return &nxtypes.ProjectGraph{
+		Nodes: map[string]nxtypes.ProjectGraphProjectNode{
+			"e2e":     projectNode("e2e", "e2e", "apps/app-e2e"),
+			"app":     projectNode("app", "app", "apps/app", "scope:app"),
+			"feature": projectNode("feature", "lib", "libs/shop/feature", "scope:shop", "type:feature"),
+			"ui":      projectNode("ui", "lib", "libs/shared/ui", "type:ui", "scope:shared"),
+			"utils":   projectNode("utils", "lib", "libs/shared/utils", "scope:shared"),
+		},
+		ExternalNodes: map[string]nxtypes.ProjectGraphExternalNode{
+			"npm:@types/react": {Name: "npm:@types/react", Type: "npm"},
+		},
+		Dependencies: map[string][]nxtypes.ProjectGraphDependency{
+			"e2e":     {{Source: "e2e", Target: "app", Type: "implicit"}},
+			"app":     {{Source: "app", Target: "feature", Type: "static"}, {Source: "app", Target: "ui", Type: "dynamic"}},
+			"feature": {{Source: "feature", Target: "ui", Type: "static"}},
+			"ui":      {{Source: "ui", Target: "utils", Type: "static"}, {Source: "ui", Target: "npm:@types/react", Type: "static"}},
+		},
+	}
+}
+
+func nodeIDs(d *Diagram) []string {
+	var ids []string
+	for _, n := range d.Nodes {
+		ids = append(ids, n.ID)
+	}
+	return ids
+}
+
+func TestFromProjectGraph(t *testing.T) {
+	d := FromProjectGraph(newTestGraph())
+
+	assert.Equal(t, []string{"app", "e2e", "feature", "npm:@types/react", "ui", "utils"}, nodeIDs(d))
+	assert.True(t, d.Nodes[3].External)
+	assert.Equal(t, []string{"scope:shared", "type:ui"}, d.Nodes[4].Tags)
+	assert.Equal(t, Edge{Source: "app", Target: "feature", Type: "static"}, d.Edges[0])
+	assert.Len(t, d.Edges, 6)
+}
+
+func TestApply(t *testing.T) {
+	d := FromProjectGraph(newTestGraph())
+
+	tests := []struct {
+		name string
+		opts Options
+		want []string
+	}{
+		{"no options", Options{}, []string{"app", "e2e", "feature", "npm:@types/react", "ui", "utils"}},
+		{"exclude external", Options{ExcludeExternal: true}, []string{"app", "e2e", "feature", "ui", "utils"}},
+		{"exclude glob", Options{Exclude: []string{"npm:*"}}, []string{"app", "e2e", "feature", "ui", "utils"}},
+		{"exclude tag", Options{Exclude: []string{"tag:scope:shared"}}, []string{"app", "e2e", "feature", "npm:@types/react"}},
+		{"focus", Options{Focus: []string{"feature"}, ExcludeExternal: true}, []string{"app", "e2e", "feature", "ui", "utils"}},
+		{"focus with depth", Options{Focus: []string{"feature"}, Depth: 1}, []string{"app", "feature", "ui"}},
+		{"focus tag", Options{Focus: []string{"tag:scope:app"}, Depth: 1}, []string{"app", "e2e", "feature", "ui"}},
+		{"depth from top", Options{Depth: 2}, []string{"app", "e2e", "feature", "ui"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := d.Apply(tt.opts)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, nodeIDs(result))
+			for _, e := range result.Edges {
+				assert.Contains(t, tt.want, e.Source)
+				assert.Contains(t, tt.want, e.Target)
+			}
+		})
+	}
+
+	_, err := d.Apply(Options{Focus: []string{"missing"}})
+	assert.EqualError(t, err, `no node matches focus "missing"`)
+}
+
+func TestGroups(t *testing.T) {
+	d := FromProjectGraph(newTestGraph())
+
+	byDirectory, err := d.Apply(Options{GroupBy: GroupByDirectory})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"apps", "libs/shared", "libs/shop"}, byDirectory.Groups())
+
+	byTag, err := d.Apply(Options{GroupBy: GroupByTag})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"scope:app", "scope:shared", "scope:shop"}, byTag.Groups())
+
+	_, err = ParseGroupBy("owner")
+	assert.Error(t, err)
+}
+
+func TestFromTaskGraph(t *testing.T) {
+	workspace := &nxtypes.NxWorkspace{ProjectGraph: *newTestGraph()}
+	for name, node := range workspace.ProjectGraph.Nodes {
+		node.Data.Targets = map[string]nxtypes.TargetConfiguration{"build": {}}
+		workspace.ProjectGraph.Nodes[name] = node
+	}
+	require.NoError(t, json.Unmarshal([]byte(`{"build":{"dependsOn":["^build"]}}`), &workspace.NxJson.TargetDefaults))
+
+	tasks, err := taskgraph.NewBuilder(workspace).Build(taskgraph.TaskID{Project: "feature", Target: "build"})
+	require.NoError(t, err)
+
+	d, err := FromTaskGraph(tasks, &workspace.ProjectGraph).Apply(Options{GroupBy: GroupByProject})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"feature:build", "ui:build", "utils:build"}, nodeIDs(d))
+	assert.Equal(t, Edge{Source: "feature:build", Target: "ui:build", Type: "task", Label: "^build"}, d.Edges[0])
+	assert.Equal(t, "libs/shared/ui", d.Nodes[1].Root)
+	assert.Equal(t, "ui", d.Nodes[1].Group)
+}
+
+func TestWrite(t *testing.T) {
+	snapshotter := cupaloy.New(cupaloy.SnapshotSubdirectory("testdata/snapshots"))
+
+	d, err := FromProjectGraph(newTestGraph()).Apply(Options{GroupBy: GroupByDirectory})
+	require.NoError(t, err)
+
+	for _, format := range Formats {
+		t.Run(string(format), func(t *testing.T) {
+			var first, second bytes.Buffer
+			require.NoError(t, Write(&first, d, format))
+			require.NoError(t, Write(&second, d, format))
+
+			assert.Equal(t, first.String(), second.String(), "output must be stable")
+			snapshotter.SnapshotT(t, first.String())
+		})
+	}
+
+	_, err = ParseFormat("svg")
+	assert.Error(t, err)
+}
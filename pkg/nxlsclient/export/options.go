@@ -0,0 +1,191 @@
+package export
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/lazyengs/lazynx/pkg/nxlsclient/internal/glob"
+)
+
+// GroupBy selects how nodes are clustered.
+type GroupBy string
+
+const (
+	// GroupByNone does not group nodes.
+	GroupByNone GroupBy = ""
+	// GroupByTag groups nodes by their first tag, alphabetically.
+	GroupByTag GroupBy = "tag"
+	// GroupByDirectory groups nodes by the parent directory of their root, such as "libs/shared".
+	GroupByDirectory GroupBy = "directory"
+	// GroupByProject groups task nodes by project.
+	GroupByProject GroupBy = "project"
+)
+
+// ParseGroupBy validates a grouping name. The empty string and "none" mean GroupByNone.
+func ParseGroupBy(s string) (GroupBy, error) {
+	switch GroupBy(s) {
+	case GroupByNone, "none":
+		return GroupByNone, nil
+	case GroupByTag, GroupByDirectory, GroupByProject:
+		return GroupBy(s), nil
+	default:
+		return "", fmt.Errorf("invalid grouping %q, expected one of: none, tag, directory, project", s)
+	}
+}
+
+// Options narrow and arrange a Diagram before it is written.
+type Options struct {
+	// Focus keeps the matching nodes with their dependencies and dependents.
+	Focus []string
+	// Exclude removes the matching nodes.
+	Exclude []string
+	// ExcludeExternal removes external nodes such as npm packages.
+	ExcludeExternal bool
+	// Depth limits how many edges away from the focused nodes, or from the nodes nothing
+	// depends on when there is no focus, nodes are kept. Zero means no limit.
+	Depth   int
+	GroupBy GroupBy
+}
+
+// Apply returns a copy of d narrowed and grouped according to opts. Patterns in Focus and
+// Exclude match node ids, may be globs and may select tags with "tag:". It fails when a
+// focus pattern matches no node.
+func (d *Diagram) Apply(opts Options) (*Diagram, error) {
+	keep := make(map[string]bool, len(d.Nodes))
+	for _, n := range d.Nodes {
+		if opts.ExcludeExternal && n.External {
+			continue
+		}
+		if matchesAny(n, opts.Exclude) {
+			continue
+		}
+		keep[n.ID] = true
+	}
+
+	deps := make(map[string][]string)
+	dependents := make(map[string][]string)
+	for _, e := range d.Edges {
+		if keep[e.Source] && keep[e.Target] {
+			deps[e.Source] = append(deps[e.Source], e.Target)
+			dependents[e.Target] = append(dependents[e.Target], e.Source)
+		}
+	}
+
+	var starts []string
+	if len(opts.Focus) > 0 {
+		for _, pattern := range opts.Focus {
+			found := false
+			for _, n := range d.Nodes {
+				if keep[n.ID] && matches(n, pattern) {
+					starts = append(starts, n.ID)
+					found = true
+				}
+			}
+			if !found {
+				return nil, fmt.Errorf("no node matches focus %q", pattern)
+			}
+		}
+	} else if opts.Depth > 0 {
+		for _, n := range d.Nodes {
+			if keep[n.ID] && len(dependents[n.ID]) == 0 {
+				starts = append(starts, n.ID)
+			}
+		}
+	}
+
+	if starts != nil {
+		reached := walk(starts, deps, opts.Depth)
+		// Focus shows what the nodes depend on and what depends on them
+		if len(opts.Focus) > 0 {
+			for id := range walk(starts, dependents, opts.Depth) {
+				reached[id] = true
+			}
+		}
+		keep = reached
+	}
+
+	result := &Diagram{}
+	for _, n := range d.Nodes {
+		if !keep[n.ID] {
+			continue
+		}
+		n.Group = group(n, opts.GroupBy)
+		result.Nodes = append(result.Nodes, n)
+	}
+	for _, e := range d.Edges {
+		if keep[e.Source] && keep[e.Target] {
+			result.Edges = append(result.Edges, e)
+		}
+	}
+
+	result.normalize()
+	return result, nil
+}
+
+// walk returns the nodes reachable from starts in at most depth steps, or any number of
+// steps when depth is zero.
+func walk(starts []string, next map[string][]string, depth int) map[string]bool {
+	reached := make(map[string]bool)
+	frontier := starts
+	for _, id := range starts {
+		reached[id] = true
+	}
+
+	for step := 1; len(frontier) > 0 && (depth <= 0 || step <= depth); step++ {
+		var following []string
+		for _, id := range frontier {
+			for _, n := range next[id] {
+				if !reached[n] {
+					reached[n] = true
+					following = append(following, n)
+				}
+			}
+		}
+		frontier = following
+	}
+	return reached
+}
+
+func group(n Node, by GroupBy) string {
+	switch by {
+	case GroupByTag:
+		if len(n.Tags) > 0 {
+			return n.Tags[0]
+		}
+	case GroupByDirectory:
+		if n.Root != "" {
+			if dir := path.Dir(strings.TrimSuffix(n.Root, "/")); dir != "." {
+				return dir
+			}
+		}
+	case GroupByProject:
+		if n.Project != "" {
+			return n.Project
+		}
+		return n.ID
+	}
+	return ""
+}
+
+func matchesAny(n Node, patterns []string) bool {
+	for _, pattern := range patterns {
+		if matches(n, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// matches reports whether pattern selects n, by id or with "tag:" by tag.
+func matches(n Node, pattern string) bool {
+	if tag, ok := strings.CutPrefix(pattern, "tag:"); ok {
+		for _, t := range n.Tags {
+			if glob.Match(tag, t) {
+				return true
+			}
+		}
+		return false
+	}
+	return glob.Match(pattern, n.ID)
+}
@@ -0,0 +1,267 @@
+package export
+
+import (
+	"bufio"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Format is an output format.
+type Format string
+
+const (
+	FormatDOT     Format = "dot"
+	FormatMermaid Format = "mermaid"
+	FormatGraphML Format = "graphml"
+	FormatJSON    Format = "json"
+)
+
+// Formats lists the supported formats.
+var Formats = []Format{FormatDOT, FormatMermaid, FormatGraphML, FormatJSON}
+
+// ParseFormat validates a format name.
+func ParseFormat(s string) (Format, error) {
+	for _, f := range Formats {
+		if string(f) == s {
+			return f, nil
+		}
+	}
+	return "", fmt.Errorf("invalid format %q, expected one of: dot, mermaid, graphml, json", s)
+}
+
+// Write writes d to w in the given format.
+func Write(w io.Writer, d *Diagram, format Format) error {
+	switch format {
+	case FormatDOT:
+		return WriteDOT(w, d)
+	case FormatMermaid:
+		return WriteMermaid(w, d)
+	case FormatGraphML:
+		return WriteGraphML(w, d)
+	case FormatJSON:
+		return WriteJSON(w, d)
+	default:
+		return fmt.Errorf("invalid format %q", format)
+	}
+}
+
+// WriteJSON writes d as indented JSON.
+func WriteJSON(w io.Writer, d *Diagram) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(d)
+}
+
+// WriteDOT writes d as a Graphviz digraph. Groups become clusters, external nodes are
+// ellipses, dynamic dependencies are dashed and implicit ones dotted.
+func WriteDOT(w io.Writer, d *Diagram) error {
+	b := bufio.NewWriter(w)
+
+	fmt.Fprintln(b, "digraph nx {")
+	fmt.Fprintln(b, "  rankdir=LR;")
+	fmt.Fprintln(b, "  node [shape=box];")
+
+	writeNode := func(indent string, n Node) {
+		if n.External {
+			fmt.Fprintf(b, "%s%s [shape=ellipse];\n", indent, dotQuote(n.ID))
+		} else {
+			fmt.Fprintf(b, "%s%s;\n", indent, dotQuote(n.ID))
+		}
+	}
+
+	for i, g := range d.Groups() {
+		fmt.Fprintf(b, "  subgraph cluster_%d {\n", i)
+		fmt.Fprintf(b, "    label=%s;\n", dotQuote(g))
+		for _, n := range d.Nodes {
+			if n.Group == g {
+				writeNode("    ", n)
+			}
+		}
+		fmt.Fprintln(b, "  }")
+	}
+	for _, n := range d.Nodes {
+		if n.Group == "" {
+			writeNode("  ", n)
+		}
+	}
+
+	for _, e := range d.Edges {
+		var attrs []string
+		switch e.Type {
+		case "dynamic":
+			attrs = append(attrs, "style=dashed")
+		case "implicit":
+			attrs = append(attrs, "style=dotted")
+		}
+		if e.Label != "" {
+			attrs = append(attrs, "label="+dotQuote(e.Label))
+		}
+
+		if len(attrs) > 0 {
+			fmt.Fprintf(b, "  %s -> %s [%s];\n", dotQuote(e.Source), dotQuote(e.Target), strings.Join(attrs, ", "))
+		} else {
+			fmt.Fprintf(b, "  %s -> %s;\n", dotQuote(e.Source), dotQuote(e.Target))
+		}
+	}
+
+	fmt.Fprintln(b, "}")
+	return b.Flush()
+}
+
+func dotQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// WriteMermaid writes d as a Mermaid flowchart. Node ids are replaced with n0, n1, ...
+// since Mermaid ids cannot hold most characters of project names; names become labels.
+func WriteMermaid(w io.Writer, d *Diagram) error {
+	b := bufio.NewWriter(w)
+
+	ids := make(map[string]string, len(d.Nodes))
+	for i, n := range d.Nodes {
+		ids[n.ID] = fmt.Sprintf("n%d", i)
+	}
+
+	fmt.Fprintln(b, "flowchart LR")
+
+	writeNode := func(indent string, n Node) {
+		if n.External {
+			fmt.Fprintf(b, "%s%s([%s])\n", indent, ids[n.ID], mermaidQuote(n.ID))
+		} else {
+			fmt.Fprintf(b, "%s%s[%s]\n", indent, ids[n.ID], mermaidQuote(n.ID))
+		}
+	}
+
+	for i, g := range d.Groups() {
+		fmt.Fprintf(b, "  subgraph g%d[%s]\n", i, mermaidQuote(g))
+		for _, n := range d.Nodes {
+			if n.Group == g {
+				writeNode("    ", n)
+			}
+		}
+		fmt.Fprintln(b, "  end")
+	}
+	for _, n := range d.Nodes {
+		if n.Group == "" {
+			writeNode("  ", n)
+		}
+	}
+
+	for _, e := range d.Edges {
+		arrow := "-->"
+		if e.Type == "dynamic" || e.Type == "implicit" {
+			arrow = "-.->"
+		}
+
+		label := e.Label
+		if e.Type == "implicit" {
+			label = "implicit"
+		}
+		if label != "" {
+			arrow += "|" + mermaidQuote(label) + "|"
+		}
+
+		fmt.Fprintf(b, "  %s %s %s\n", ids[e.Source], arrow, ids[e.Target])
+	}
+
+	return b.Flush()
+}
+
+func mermaidQuote(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, "#quot;") + `"`
+}
+
+type graphML struct {
+	XMLName xml.Name     `xml:"graphml"`
+	Xmlns   string       `xml:"xmlns,attr"`
+	Keys    []graphMLKey `xml:"key"`
+	Graph   struct {
+		ID          string        `xml:"id,attr"`
+		EdgeDefault string        `xml:"edgedefault,attr"`
+		Nodes       []graphMLNode `xml:"node"`
+		Edges       []graphMLEdge `xml:"edge"`
+	} `xml:"graph"`
+}
+
+type graphMLKey struct {
+	ID   string `xml:"id,attr"`
+	For  string `xml:"for,attr"`
+	Name string `xml:"attr.name,attr"`
+	Type string `xml:"attr.type,attr"`
+}
+
+type graphMLData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+type graphMLNode struct {
+	ID   string        `xml:"id,attr"`
+	Data []graphMLData `xml:"data"`
+}
+
+type graphMLEdge struct {
+	ID     string        `xml:"id,attr"`
+	Source string        `xml:"source,attr"`
+	Target string        `xml:"target,attr"`
+	Data   []graphMLData `xml:"data"`
+}
+
+// WriteGraphML writes d as GraphML. Node fields become data keys, tags are comma separated.
+func WriteGraphML(w io.Writer, d *Diagram) error {
+	doc := graphML{
+		Xmlns: "http://graphml.graphdrawing.org/xmlns",
+		Keys: []graphMLKey{
+			{ID: "type", For: "node", Name: "type", Type: "string"},
+			{ID: "project", For: "node", Name: "project", Type: "string"},
+			{ID: "root", For: "node", Name: "root", Type: "string"},
+			{ID: "tags", For: "node", Name: "tags", Type: "string"},
+			{ID: "external", For: "node", Name: "external", Type: "boolean"},
+			{ID: "group", For: "node", Name: "group", Type: "string"},
+			{ID: "edgeType", For: "edge", Name: "type", Type: "string"},
+			{ID: "label", For: "edge", Name: "label", Type: "string"},
+		},
+	}
+	doc.Graph.ID = "nx"
+	doc.Graph.EdgeDefault = "directed"
+
+	data := func(list []graphMLData, key, value string) []graphMLData {
+		if value == "" {
+			return list
+		}
+		return append(list, graphMLData{Key: key, Value: value})
+	}
+
+	for _, n := range d.Nodes {
+		node := graphMLNode{ID: n.ID}
+		node.Data = data(node.Data, "type", n.Type)
+		node.Data = data(node.Data, "project", n.Project)
+		node.Data = data(node.Data, "root", n.Root)
+		node.Data = data(node.Data, "tags", strings.Join(n.Tags, ","))
+		if n.External {
+			node.Data = data(node.Data, "external", "true")
+		}
+		node.Data = data(node.Data, "group", n.Group)
+		doc.Graph.Nodes = append(doc.Graph.Nodes, node)
+	}
+	for i, e := range d.Edges {
+		edge := graphMLEdge{ID: fmt.Sprintf("e%d", i), Source: e.Source, Target: e.Target}
+		edge.Data = data(edge.Data, "edgeType", e.Type)
+		edge.Data = data(edge.Data, "label", e.Label)
+		doc.Graph.Edges = append(doc.Graph.Edges, edge)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,30 @@
+/*
+Package foldertree turns the nx/projectFolderTree response into a directory tree.
+
+The language server serializes the tree as a flat list of directories whose children are
+directory strings. A Tree links them into nodes with parents and children, so consumers can
+render the workspace like a file explorer, with each project at its root.
+
+# Usage
+
+	result, err := client.Commander.SendProjectFolderTreeRequest(ctx)
+	if err != nil {
+		// Handle error
+	}
+
+	tree := foldertree.New(result)
+
+	// Render the directories with their project counts
+	tree.Walk(func(n *foldertree.Node) bool {
+		fmt.Printf("%s%s (%d)\n", strings.Repeat("  ", n.Depth()), n.Name(), n.ProjectCount())
+		return true
+	})
+
+	// Find a directory, the project owning a file, or anything matching a query
+	libs, ok := tree.Lookup("libs")
+	owner := tree.Nearest("libs/ui/src/button.tsx")
+	matches := tree.Search("ui")
+
+Children and roots are sorted by directory, so walks are deterministic.
+*/
+package foldertree
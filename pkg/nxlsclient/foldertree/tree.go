@@ -0,0 +1,243 @@
+package foldertree
+
+import (
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/lazyengs/lazynx/pkg/nxlsclient/commands"
+	nxtypes "github.com/lazyengs/lazynx/pkg/nxlsclient/nx-types"
+)
+
+// Node is a directory of the workspace that is, or contains, a project root.
+type Node struct {
+	// Dir is the path of the directory relative to the workspace root.
+	Dir string
+	// ProjectName is the project rooted at Dir, empty for intermediate directories.
+	ProjectName string
+	Project     *nxtypes.ProjectGraphProjectNode
+	Parent      *Node
+	// Children are sorted by directory name.
+	Children []*Node
+
+	projects int
+}
+
+// Name returns the last element of Dir.
+func (n *Node) Name() string {
+	return path.Base(n.Dir)
+}
+
+// IsProject reports whether a project is rooted at the node.
+func (n *Node) IsProject() bool {
+	return n.ProjectName != ""
+}
+
+// ProjectCount returns the number of projects rooted at the node or below it.
+func (n *Node) ProjectCount() int {
+	return n.projects
+}
+
+// Depth returns the number of ancestors of the node.
+func (n *Node) Depth() int {
+	depth := 0
+	for p := n.Parent; p != nil; p = p.Parent {
+		depth++
+	}
+	return depth
+}
+
+// Ancestors returns the ancestors of the node, from its root down to its parent.
+func (n *Node) Ancestors() []*Node {
+	var ancestors []*Node
+	for p := n.Parent; p != nil; p = p.Parent {
+		ancestors = append(ancestors, p)
+	}
+	for i, j := 0, len(ancestors)-1; i < j; i, j = i+1, j-1 {
+		ancestors[i], ancestors[j] = ancestors[j], ancestors[i]
+	}
+	return ancestors
+}
+
+// Walk visits the node and its descendants depth first. Returning false from fn skips
+// the children of the visited node.
+func (n *Node) Walk(fn func(*Node) bool) {
+	if !fn(n) {
+		return
+	}
+	for _, child := range n.Children {
+		child.Walk(fn)
+	}
+}
+
+// Tree is the project folder tree of a workspace.
+type Tree struct {
+	// Roots are the top-level directories, sorted by directory name.
+	Roots []*Node
+	nodes map[string]*Node
+}
+
+// New builds a Tree from an nx/projectFolderTree result. Children missing from the
+// serialized map are added as intermediate directories.
+func New(result *commands.ProjectFolderTreeResult) *Tree {
+	t := &Tree{nodes: map[string]*Node{}}
+	if result == nil {
+		return t
+	}
+
+	for _, entry := range result.SerializedTreeMap {
+		t.add(entry.Node)
+	}
+	for _, root := range result.Roots {
+		t.add(root)
+	}
+
+	// Link children after every node exists, so the order of the map does not matter
+	for _, entry := range result.SerializedTreeMap {
+		t.link(entry.Node)
+	}
+	for _, root := range result.Roots {
+		t.link(root)
+	}
+
+	for _, node := range t.nodes {
+		if node.Parent == nil {
+			t.Roots = append(t.Roots, node)
+		}
+		sortNodes(node.Children)
+	}
+	sortNodes(t.Roots)
+	for _, root := range t.Roots {
+		countProjects(root)
+	}
+	return t
+}
+
+func (t *Tree) add(tn nxtypes.TreeNode) *Node {
+	dir := Clean(tn.Dir)
+	node, ok := t.nodes[dir]
+	if !ok {
+		node = &Node{Dir: dir}
+		t.nodes[dir] = node
+	}
+	if tn.ProjectName != "" {
+		node.ProjectName = tn.ProjectName
+	}
+	if tn.ProjectConfiguration != nil {
+		node.Project = tn.ProjectConfiguration
+	}
+	return node
+}
+
+func (t *Tree) link(tn nxtypes.TreeNode) {
+	parent := t.nodes[Clean(tn.Dir)]
+	for _, dir := range tn.Children {
+		child := t.add(nxtypes.TreeNode{Dir: dir})
+		// A node keeps its first parent, which also breaks cycles
+		if child.Parent != nil || child == parent || isAncestor(child, parent) {
+			continue
+		}
+		child.Parent = parent
+		parent.Children = append(parent.Children, child)
+	}
+}
+
+func isAncestor(node, of *Node) bool {
+	for p := of.Parent; p != nil; p = p.Parent {
+		if p == node {
+			return true
+		}
+	}
+	return false
+}
+
+func countProjects(n *Node) int {
+	n.projects = 0
+	if n.IsProject() {
+		n.projects = 1
+	}
+	for _, child := range n.Children {
+		n.projects += countProjects(child)
+	}
+	return n.projects
+}
+
+func sortNodes(nodes []*Node) {
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].Dir < nodes[j].Dir })
+}
+
+// Clean normalizes a workspace relative directory the way the tree stores it.
+func Clean(dir string) string {
+	dir = strings.TrimPrefix(path.Clean(strings.ReplaceAll(dir, "\\", "/")), "/")
+	if dir == "" {
+		return "."
+	}
+	return dir
+}
+
+// Lookup returns the node of dir.
+func (t *Tree) Lookup(dir string) (*Node, bool) {
+	node, ok := t.nodes[Clean(dir)]
+	return node, ok
+}
+
+// Nearest returns the deepest node containing file, such as the project owning a
+// source file, or nil when no node does.
+func (t *Tree) Nearest(file string) *Node {
+	for dir := Clean(file); ; dir = path.Dir(dir) {
+		if node, ok := t.nodes[dir]; ok {
+			return node
+		}
+		if dir == "." {
+			return nil
+		}
+	}
+}
+
+// Walk visits every node depth first, roots in order. Returning false from fn skips the
+// children of the visited node.
+func (t *Tree) Walk(fn func(*Node) bool) {
+	for _, root := range t.Roots {
+		root.Walk(fn)
+	}
+}
+
+// Search returns the nodes whose directory or project name contains query, ignoring
+// case, in walk order.
+func (t *Tree) Search(query string) []*Node {
+	query = strings.ToLower(query)
+	var matches []*Node
+	t.Walk(func(n *Node) bool {
+		if strings.Contains(strings.ToLower(n.Dir), query) || strings.Contains(strings.ToLower(n.ProjectName), query) {
+			matches = append(matches, n)
+		}
+		return true
+	})
+	return matches
+}
+
+// Projects returns the nodes with a project, in walk order.
+func (t *Tree) Projects() []*Node {
+	var projects []*Node
+	t.Walk(func(n *Node) bool {
+		if n.IsProject() {
+			projects = append(projects, n)
+		}
+		return true
+	})
+	return projects
+}
+
+// ProjectCount returns the number of projects in the tree.
+func (t *Tree) ProjectCount() int {
+	count := 0
+	for _, root := range t.Roots {
+		count += root.ProjectCount()
+	}
+	return count
+}
+
+// Len returns the number of nodes in the tree.
+func (t *Tree) Len() int {
+	return len(t.nodes)
+}
@@ -0,0 +1,111 @@
+package foldertree
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/lazyengs/lazynx/pkg/nxlsclient/commands"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// The serialized map lists children before their parents and leaves out libs/shared.
+const folderTree = `{
+	"serializedTreeMap": [
+		{"dir": "apps/web", "node": {"dir": "apps/web", "projectName": "web", "projectConfiguration": {"type": "app", "name": "web", "data": {"root": "apps/web"}}, "children": ["apps/web/e2e"]}},
+		{"dir": "apps/web/e2e", "node": {"dir": "apps/web/e2e", "projectName": "web-e2e", "children": []}},
+		{"dir": "apps", "node": {"dir": "apps", "children": ["apps/web", "apps/api"]}},
+		{"dir": "apps/api", "node": {"dir": "apps/api", "projectName": "api", "children": []}},
+		{"dir": "libs", "node": {"dir": "libs", "children": ["libs/ui", "libs/shared"]}},
+		{"dir": "libs/ui", "node": {"dir": "libs/ui", "projectName": "ui", "children": []}}
+	],
+	"roots": [
+		{"dir": "apps", "children": ["apps/web", "apps/api"]},
+		{"dir": "libs", "children": ["libs/ui", "libs/shared"]}
+	]
+}`
+
+func newTree(t *testing.T) *Tree {
+	t.Helper()
+	var result commands.ProjectFolderTreeResult
+	require.NoError(t, json.Unmarshal([]byte(folderTree), &result))
+	return New(&result)
+}
+
+func dirs(nodes []*Node) []string {
+	out := make([]string, len(nodes))
+	for i, n := range nodes {
+		out[i] = n.Dir
+	}
+	return out
+}
+
+func TestNew(t *testing.T) {
+	tree := newTree(t)
+
+	assert.Equal(t, []string{"apps", "libs"}, dirs(tree.Roots))
+	assert.Equal(t, 7, tree.Len())
+	assert.Equal(t, 4, tree.ProjectCount())
+
+	apps, ok := tree.Lookup("apps")
+	require.True(t, ok)
+	assert.Equal(t, []string{"apps/api", "apps/web"}, dirs(apps.Children))
+	assert.Equal(t, 3, apps.ProjectCount())
+	assert.False(t, apps.IsProject())
+
+	e2e, ok := tree.Lookup("./apps/web/e2e/")
+	require.True(t, ok)
+	assert.Equal(t, "e2e", e2e.Name())
+	assert.Equal(t, "web-e2e", e2e.ProjectName)
+	assert.Equal(t, 2, e2e.Depth())
+	assert.Equal(t, []string{"apps", "apps/web"}, dirs(e2e.Ancestors()))
+
+	web := e2e.Parent
+	require.NotNil(t, web.Project)
+	assert.Equal(t, "apps/web", web.Project.Data.Root)
+	assert.Equal(t, 2, web.ProjectCount())
+
+	shared, ok := tree.Lookup("libs/shared")
+	require.True(t, ok)
+	assert.Equal(t, "libs", shared.Parent.Dir)
+	assert.Equal(t, 0, shared.ProjectCount())
+
+	_, ok = tree.Lookup("tools")
+	assert.False(t, ok)
+}
+
+func TestWalkAndSearch(t *testing.T) {
+	tree := newTree(t)
+
+	var visited []string
+	tree.Walk(func(n *Node) bool {
+		visited = append(visited, n.Dir)
+		return n.Dir != "apps/web"
+	})
+	assert.Equal(t, []string{"apps", "apps/api", "apps/web", "libs", "libs/shared", "libs/ui"}, visited)
+
+	assert.Equal(t, []string{"apps/web", "apps/web/e2e"}, dirs(tree.Search("WEB")))
+	assert.Equal(t, []string{"apps/web/e2e"}, dirs(tree.Search("web-e2e")))
+	assert.Equal(t, []string{"apps/api", "apps/web", "apps/web/e2e", "libs/ui"}, dirs(tree.Projects()))
+}
+
+func TestNearest(t *testing.T) {
+	tree := newTree(t)
+
+	assert.Equal(t, "apps/web/e2e", tree.Nearest("apps/web/e2e/src/app.cy.ts").Dir)
+	assert.Equal(t, "apps/web", tree.Nearest("apps/web/src/main.ts").Dir)
+	assert.Equal(t, "libs", tree.Nearest("libs/README.md").Dir)
+	assert.Nil(t, tree.Nearest("package.json"))
+}
+
+func TestNewCycle(t *testing.T) {
+	var result commands.ProjectFolderTreeResult
+	require.NoError(t, json.Unmarshal([]byte(`{"serializedTreeMap": [
+		{"dir": "a", "node": {"dir": "a", "children": ["a/b"]}},
+		{"dir": "a/b", "node": {"dir": "a/b", "projectName": "b", "children": ["a"]}}
+	]}`), &result))
+
+	tree := New(&result)
+	assert.Equal(t, []string{"a"}, dirs(tree.Roots))
+	assert.Equal(t, 1, tree.ProjectCount())
+}
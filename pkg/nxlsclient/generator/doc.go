@@ -0,0 +1,48 @@
+/*
+Package generator validates and runs Nx generators.
+
+SendGeneratorOptionsRequest describes the options of a generator with the JSON Schema
+subset Nx uses. Validate checks the values a user entered against those options, so a
+form can point at the offending fields instead of waiting for nx generate to fail.
+NewInvocation turns the values into an nx generate command line, which a Runner runs
+with --dry-run to preview the files it would change, and again to apply them.
+
+# Usage
+
+	options, err := client.Commander.SendGeneratorOptionsRequest(ctx, commands.GeneratorOptionsRequestParams{
+		Options: commands.GeneratorOptionsRequestOptions{Collection: "@nx/react", Name: "library"},
+	})
+	if err != nil {
+		return err
+	}
+
+	// values are what the user entered in a form, keyed by option name
+	values := map[string]any{
+		"name":      "ui",
+		"directory": "libs/ui",
+		"bundler":   "webpack",
+	}
+	if errs := generator.Validate(options, values); len(errs) > 0 {
+		for field, messages := range errs.ByField() {
+			form.SetError(field, strings.Join(messages, ", "))
+		}
+		return errs
+	}
+
+	schema := nxtypes.GeneratorSchema{CollectionName: "@nx/react", GeneratorName: "library", Options: options}
+	inv := generator.NewInvocation(schema, values)
+	runner := generator.NewRunner(workspacePath)
+
+	preview, err := runner.DryRun(ctx, inv)
+	if err != nil {
+		return err
+	}
+	for _, change := range preview.Changes {
+		fmt.Println(change) // "CREATE libs/ui/src/index.ts"
+	}
+
+	if confirmed {
+		result, err := runner.Apply(ctx, inv)
+	}
+*/
+package generator
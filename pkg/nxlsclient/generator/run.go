@@ -0,0 +1,215 @@
+package generator
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	nxtypes "github.com/lazyengs/lazynx/pkg/nxlsclient/nx-types"
+	"github.com/lazyengs/lazynx/pkg/nxlsclient/runner"
+)
+
+// ChangeType is what a generator does to a file.
+type ChangeType string
+
+const (
+	ChangeCreate ChangeType = "CREATE"
+	ChangeUpdate ChangeType = "UPDATE"
+	ChangeDelete ChangeType = "DELETE"
+)
+
+// FileChange is a file a generator creates, updates or deletes.
+type FileChange struct {
+	Type ChangeType `json:"type"`
+	// Path is relative to the workspace root.
+	Path string `json:"path"`
+	// Size is the size in bytes reported by older Nx versions, -1 when not reported.
+	Size int `json:"size"`
+}
+
+func (c FileChange) String() string {
+	return string(c.Type) + " " + c.Path
+}
+
+// Invocation is an nx generate command line.
+type Invocation struct {
+	// Generator is collection:name, such as "@nx/react:library".
+	Generator string
+	// Options are the --name=value arguments, in option order.
+	Options []string
+}
+
+// NewInvocation builds the invocation of the generator described by schema with values,
+// keyed by option name. Values are passed as named options in the order of
+// schema.Options, then values without an option in name order. Empty values are
+// skipped; lists repeat the option for each item and objects are passed as JSON.
+func NewInvocation(schema nxtypes.GeneratorSchema, values map[string]any) Invocation {
+	inv := Invocation{Generator: schema.CollectionName + ":" + schema.GeneratorName}
+
+	var names []string
+	for _, option := range schema.Options {
+		if _, ok := values[option.Name]; ok {
+			names = append(names, option.Name)
+		}
+	}
+	var extra []string
+	for name := range values {
+		if !slices.Contains(names, name) {
+			extra = append(extra, name)
+		}
+	}
+	slices.Sort(extra)
+
+	for _, name := range append(names, extra...) {
+		inv.Options = append(inv.Options, formatOption(name, values[name])...)
+	}
+	return inv
+}
+
+// formatOption returns the arguments passing value for the option name.
+func formatOption(name string, value any) []string {
+	switch v := normalize(value).(type) {
+	case nil:
+		return nil
+	case string:
+		if v == "" {
+			return nil
+		}
+		return []string{"--" + name + "=" + v}
+	case bool:
+		return []string{"--" + name + "=" + strconv.FormatBool(v)}
+	case float64:
+		return []string{"--" + name + "=" + strconv.FormatFloat(v, 'f', -1, 64)}
+	case []any:
+		var args []string
+		for _, item := range v {
+			args = append(args, formatOption(name, item)...)
+		}
+		return args
+	default:
+		data, _ := json.Marshal(v)
+		return []string{"--" + name + "=" + string(data)}
+	}
+}
+
+// Args returns the nx arguments, without the nx command itself. Generators never prompt,
+// so options left out use their defaults.
+func (inv Invocation) Args(dryRun bool) []string {
+	args := append([]string{"generate", inv.Generator}, inv.Options...)
+	args = append(args, "--no-interactive")
+	if dryRun {
+		args = append(args, "--dry-run")
+	}
+	return args
+}
+
+func (inv Invocation) String() string {
+	return "nx " + strings.Join(inv.Args(false), " ")
+}
+
+// Result is the outcome of running a generator.
+type Result struct {
+	Invocation Invocation
+	DryRun     bool
+	Changes    []FileChange
+	// Output is the combined output of nx, without colors.
+	Output   string
+	Duration time.Duration
+}
+
+// Runner runs generators in a workspace. It starts nx like runner.Runner does, with the
+// workspace package manager and in a process group of its own, so cancelling a run stops
+// everything nx started.
+type Runner struct {
+	runner.Runner
+}
+
+// NewRunner creates a runner for the workspace at workspacePath.
+func NewRunner(workspacePath string) *Runner {
+	r := &Runner{Runner: *runner.NewRunner(workspacePath)}
+	// The output is parsed rather than shown, a pseudo-terminal would only mix up stderr
+	r.PTY = false
+	return r
+}
+
+// DryRun runs the generator with --dry-run and returns the changes it would make.
+func (r *Runner) DryRun(ctx context.Context, inv Invocation) (*Result, error) {
+	return r.run(ctx, inv, true)
+}
+
+// Apply runs the generator, usually after its DryRun was confirmed, and returns the
+// changes it made.
+func (r *Runner) Apply(ctx context.Context, inv Invocation) (*Result, error) {
+	return r.run(ctx, inv, false)
+}
+
+func (r *Runner) run(ctx context.Context, inv Invocation, dryRun bool) (*Result, error) {
+	nx := r.Runner
+	nx.Env = slices.Clone(r.Env)
+	if nx.Env == nil {
+		nx.Env = os.Environ()
+	}
+	// Nx colors its output when it thinks it writes to a terminal
+	nx.Env = append(nx.Env, "FORCE_COLOR=0", "NX_INTERACTIVE=false")
+
+	var output strings.Builder
+	run, err := nx.Start(ctx, inv.Args(dryRun), func(line runner.Line) {
+		output.WriteString(line.Text)
+		output.WriteByte('\n')
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to run %s: %w", inv.Generator, err)
+	}
+
+	exit, err := run.Wait()
+	result := &Result{
+		Invocation: inv,
+		DryRun:     dryRun,
+		Output:     runner.StripANSI(output.String()),
+		Duration:   exit.Duration,
+	}
+	result.Changes = ParseChanges(result.Output)
+
+	if err != nil {
+		return result, fmt.Errorf("failed to run %s: %w: %s", inv.Generator, err, lastLines(result.Output, 5))
+	}
+	return result, nil
+}
+
+var changePattern = regexp.MustCompile(`^\s*(CREATE|UPDATE|DELETE)\s+(.+?)(?:\s+\((\d+) bytes\))?\s*$`)
+
+// ParseChanges reads the CREATE, UPDATE and DELETE lines nx generate prints, in order.
+func ParseChanges(output string) []FileChange {
+	var changes []FileChange
+	scanner := bufio.NewScanner(strings.NewReader(runner.StripANSI(output)))
+	for scanner.Scan() {
+		match := changePattern.FindStringSubmatch(scanner.Text())
+		if match == nil {
+			continue
+		}
+		size := -1
+		if match[3] != "" {
+			size, _ = strconv.Atoi(match[3])
+		}
+		changes = append(changes, FileChange{Type: ChangeType(match[1]), Path: match[2], Size: size})
+	}
+	return changes
+}
+
+// lastLines returns the last n non-empty lines of s, where nx reports why it failed.
+func lastLines(s string, n int) string {
+	var lines []string
+	for _, line := range strings.Split(s, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines[max(len(lines)-n, 0):], "\n")
+}
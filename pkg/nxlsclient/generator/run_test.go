@@ -0,0 +1,112 @@
+package generator
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	nxtypes "github.com/lazyengs/lazynx/pkg/nxlsclient/nx-types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewInvocation(t *testing.T) {
+	schema := nxtypes.GeneratorSchema{
+		CollectionName: "@nx/react",
+		GeneratorName:  "library",
+		Options:        loadOptions(t),
+	}
+
+	inv := NewInvocation(schema, map[string]any{
+		"verbose":   true,
+		"tags":      []string{"scope:shared", "type:ui"},
+		"port":      4200,
+		"directory": "libs/ui",
+		"name":      "ui",
+		"bundler":   "",
+		"setup":     map[string]any{"entry": "src/index.ts"},
+	})
+
+	assert.Equal(t, "@nx/react:library", inv.Generator)
+	assert.Equal(t, []string{
+		"--name=ui",
+		"--directory=libs/ui",
+		"--port=4200",
+		"--tags=scope:shared",
+		"--tags=type:ui",
+		`--setup={"entry":"src/index.ts"}`,
+		"--verbose=true",
+	}, inv.Options)
+	assert.Equal(t, []string{"generate", "@nx/react:library", "--name=ui", "--no-interactive", "--dry-run"},
+		Invocation{Generator: "@nx/react:library", Options: []string{"--name=ui"}}.Args(true))
+}
+
+func TestParseChanges(t *testing.T) {
+	output := "\x1b[32mCREATE\x1b[39m libs/ui/project.json\n" +
+		"CREATE libs/ui/src/index.ts (28 bytes)\n" +
+		"\x1b[33mUPDATE\x1b[39m tsconfig.base.json\n" +
+		"DELETE libs/old/README.md\n" +
+		"\n" +
+		"NOTE: The \"dryRun\" flag means no changes were made.\n"
+
+	assert.Equal(t, []FileChange{
+		{Type: ChangeCreate, Path: "libs/ui/project.json", Size: -1},
+		{Type: ChangeCreate, Path: "libs/ui/src/index.ts", Size: 28},
+		{Type: ChangeUpdate, Path: "tsconfig.base.json", Size: -1},
+		{Type: ChangeDelete, Path: "libs/old/README.md", Size: -1},
+	}, ParseChanges(output))
+}
+
+func TestRunner(t *testing.T) {
+	dir := t.TempDir()
+	// A fake nx that records its arguments and prints what a generator would do
+	script := filepath.Join(dir, "nx")
+	require.NoError(t, os.WriteFile(script, []byte(`#!/bin/sh
+echo "$@" > args.txt
+if [ "$3" = "--hang" ]; then
+  sleep 30 &
+  wait
+fi
+if [ "$3" = "--fail" ]; then
+  echo "NX   Cannot find generator" >&2
+  exit 1
+fi
+echo "CREATE libs/ui/src/index.ts"
+echo "UPDATE nx.json"
+`), 0o755))
+
+	runner := NewRunner(dir)
+	runner.Command = []string{"sh", script}
+	inv := Invocation{Generator: "@nx/react:library", Options: []string{"--name=ui"}}
+
+	result, err := runner.DryRun(context.Background(), inv)
+	require.NoError(t, err)
+	assert.True(t, result.DryRun)
+	assert.Equal(t, []FileChange{
+		{Type: ChangeCreate, Path: "libs/ui/src/index.ts", Size: -1},
+		{Type: ChangeUpdate, Path: "nx.json", Size: -1},
+	}, result.Changes)
+	args, err := os.ReadFile(filepath.Join(dir, "args.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "generate @nx/react:library --name=ui --no-interactive --dry-run\n", string(args))
+
+	result, err = runner.Apply(context.Background(), inv)
+	require.NoError(t, err)
+	assert.False(t, result.DryRun)
+	args, err = os.ReadFile(filepath.Join(dir, "args.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "generate @nx/react:library --name=ui --no-interactive\n", string(args))
+
+	_, err = runner.DryRun(context.Background(), Invocation{Generator: "missing", Options: []string{"--fail"}})
+	assert.ErrorContains(t, err, "Cannot find generator")
+
+	// Cancelling interrupts nx and what it started, instead of waiting for the sleep
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err = runner.DryRun(ctx, Invocation{Generator: "slow", Options: []string{"--hang"}})
+	assert.Error(t, err)
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
@@ -0,0 +1,401 @@
+package generator
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	nxtypes "github.com/lazyengs/lazynx/pkg/nxlsclient/nx-types"
+)
+
+// FieldError is a problem with the value of one option.
+type FieldError struct {
+	// Field is the option name.
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func (e FieldError) Error() string {
+	return e.Field + ": " + e.Message
+}
+
+// ValidationErrors lists the problems found by Validate, in option order.
+type ValidationErrors []FieldError
+
+func (e ValidationErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+// ByField groups the messages by option name.
+func (e ValidationErrors) ByField() map[string][]string {
+	fields := make(map[string][]string, len(e))
+	for _, err := range e {
+		fields[err.Field] = append(fields[err.Field], err.Message)
+	}
+	return fields
+}
+
+// Validate checks values, keyed by option name or alias, against the options of a
+// generator. It returns nil when the values can be passed to nx.
+//
+// Values may come from a form as strings: they are accepted for number, integer and
+// boolean options when they parse as such, the way the Nx command line coerces them.
+// Required options without a value are only reported when Nx cannot fill them from a
+// default or from the project or working directory. $ref and format are not checked.
+func Validate(options []nxtypes.Option, values map[string]any) ValidationErrors {
+	var errs ValidationErrors
+
+	names := make(map[string]string)
+	for _, option := range options {
+		for _, name := range optionNames(option) {
+			names[name] = option.Name
+		}
+	}
+	for key := range values {
+		if _, ok := names[key]; !ok {
+			errs = append(errs, FieldError{Field: key, Message: "unknown option"})
+		}
+	}
+	slices.SortFunc(errs, func(a, b FieldError) int { return strings.Compare(a.Field, b.Field) })
+
+	for _, option := range options {
+		value, set := lookup(option, values)
+		if !set || isEmpty(value) {
+			if option.IsRequired && !canDefault(option) {
+				errs = append(errs, FieldError{Field: option.Name, Message: "is required"})
+			}
+			continue
+		}
+
+		for _, message := range checkOption(option, value) {
+			errs = append(errs, FieldError{Field: option.Name, Message: message})
+		}
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// optionNames returns the names an option can be given as.
+func optionNames(option nxtypes.Option) []string {
+	names := []string{option.Name}
+	if option.OriginalName != "" {
+		names = append(names, option.OriginalName)
+	}
+	if option.Alias != "" {
+		names = append(names, option.Alias)
+	}
+	return append(names, option.Aliases...)
+}
+
+// lookup returns the value of option, given by name or alias.
+func lookup(option nxtypes.Option, values map[string]any) (any, bool) {
+	for _, name := range optionNames(option) {
+		if value, ok := values[name]; ok {
+			return value, true
+		}
+	}
+	return nil, false
+}
+
+func isEmpty(value any) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case string:
+		return v == ""
+	case []any:
+		return len(v) == 0
+	case []string:
+		return len(v) == 0
+	}
+	return false
+}
+
+// canDefault reports whether Nx fills a missing value by itself.
+func canDefault(option nxtypes.Option) bool {
+	if len(option.Default) > 0 {
+		return true
+	}
+	if source := option.DefaultSource; source != nil {
+		return source.Source == "projectName" || source.Source == "workingDirectory"
+	}
+	return false
+}
+
+// checkOption validates a value against an option, including the allowed items of array
+// options, which Option.Items describes instead of the schema.
+func checkOption(option nxtypes.Option, value any) []string {
+	schema := option.PropertyDescription
+	if option.Items != nil {
+		if allowed := option.Items.Allowed(); len(allowed) > 0 {
+			schema.Items = &nxtypes.PropertyDescription{Type: nxtypes.SchemaType{"string"}, Enum: encodeAll(allowed)}
+		}
+	}
+	return check(schema, normalize(value))
+}
+
+// check validates value against schema, returning every problem found.
+func check(schema nxtypes.PropertyDescription, value any) []string {
+	if len(schema.Type) > 0 {
+		coerced, ok := coerce(schema.Type, value)
+		if !ok {
+			return []string{fmt.Sprintf("must be %s", describeTypes(schema.Type))}
+		}
+		value = coerced
+	}
+
+	var problems []string
+	if len(schema.Enum) > 0 && !inEnum(schema.Enum, value) {
+		problems = append(problems, "must be one of "+describeEnum(schema.Enum))
+	}
+
+	switch v := value.(type) {
+	case string:
+		problems = append(problems, checkString(schema, v)...)
+	case float64:
+		problems = append(problems, checkNumber(schema, v)...)
+	case []any:
+		problems = append(problems, checkArray(schema, v)...)
+	case map[string]any:
+		problems = append(problems, checkObject(schema, v)...)
+	}
+
+	if len(schema.OneOf) > 0 {
+		matches := 0
+		for _, alternative := range schema.OneOf {
+			if len(check(alternative, value)) == 0 {
+				matches++
+			}
+		}
+		if matches != 1 {
+			problems = append(problems, fmt.Sprintf("must match exactly one of %d alternatives, matches %d", len(schema.OneOf), matches))
+		}
+	}
+	if len(schema.AnyOf) > 0 && !slices.ContainsFunc(schema.AnyOf, func(alternative nxtypes.PropertyDescription) bool {
+		return len(check(alternative, value)) == 0
+	}) {
+		problems = append(problems, fmt.Sprintf("must match one of %d alternatives", len(schema.AnyOf)))
+	}
+	for _, required := range schema.AllOf {
+		problems = append(problems, check(required, value)...)
+	}
+
+	return problems
+}
+
+func checkString(schema nxtypes.PropertyDescription, value string) []string {
+	var problems []string
+	length := len([]rune(value))
+	if schema.MinLength != nil && length < *schema.MinLength {
+		problems = append(problems, fmt.Sprintf("must be at least %d characters", *schema.MinLength))
+	}
+	if schema.MaxLength != nil && length > *schema.MaxLength {
+		problems = append(problems, fmt.Sprintf("must be at most %d characters", *schema.MaxLength))
+	}
+	if schema.Pattern != "" {
+		re, err := regexp.Compile(schema.Pattern)
+		if err == nil && !re.MatchString(value) {
+			problems = append(problems, fmt.Sprintf("must match %s", schema.Pattern))
+		}
+	}
+	return problems
+}
+
+func checkNumber(schema nxtypes.PropertyDescription, value float64) []string {
+	var problems []string
+	if schema.Type.Has("integer") && !schema.Type.Has("number") && value != math.Trunc(value) {
+		problems = append(problems, "must be an integer")
+	}
+	if schema.Minimum != nil && value < *schema.Minimum {
+		problems = append(problems, "must be at least "+formatNumber(*schema.Minimum))
+	}
+	if schema.ExclusiveMinimum != nil && value <= *schema.ExclusiveMinimum {
+		problems = append(problems, "must be greater than "+formatNumber(*schema.ExclusiveMinimum))
+	}
+	if schema.Maximum != nil && value > *schema.Maximum {
+		problems = append(problems, "must be at most "+formatNumber(*schema.Maximum))
+	}
+	if schema.ExclusiveMaximum != nil && value >= *schema.ExclusiveMaximum {
+		problems = append(problems, "must be less than "+formatNumber(*schema.ExclusiveMaximum))
+	}
+	if schema.MultipleOf != nil && *schema.MultipleOf > 0 {
+		if q := value / *schema.MultipleOf; math.Abs(q-math.Round(q)) > 1e-9 {
+			problems = append(problems, "must be a multiple of "+formatNumber(*schema.MultipleOf))
+		}
+	}
+	return problems
+}
+
+func checkArray(schema nxtypes.PropertyDescription, items []any) []string {
+	var problems []string
+	if schema.MinItems != nil && len(items) < *schema.MinItems {
+		problems = append(problems, fmt.Sprintf("must have at least %d items", *schema.MinItems))
+	}
+	if schema.MaxItems != nil && len(items) > *schema.MaxItems {
+		problems = append(problems, fmt.Sprintf("must have at most %d items", *schema.MaxItems))
+	}
+	if schema.Items != nil {
+		for i, item := range items {
+			for _, problem := range check(*schema.Items, item) {
+				problems = append(problems, fmt.Sprintf("item %d %s", i+1, problem))
+			}
+		}
+	}
+	return problems
+}
+
+func checkObject(schema nxtypes.PropertyDescription, object map[string]any) []string {
+	var problems []string
+	for _, key := range schema.Required {
+		if _, ok := object[key]; !ok {
+			problems = append(problems, fmt.Sprintf("%s is required", key))
+		}
+	}
+
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	for _, key := range keys {
+		property, known := schema.Properties[key]
+		if !known {
+			if schema.AdditionalProperties == nil || schema.AdditionalProperties.Schema == nil {
+				if schema.AdditionalProperties != nil && !schema.AdditionalProperties.Allowed {
+					problems = append(problems, fmt.Sprintf("%s is not allowed", key))
+				}
+				continue
+			}
+			property = *schema.AdditionalProperties.Schema
+		}
+		for _, problem := range check(property, object[key]) {
+			problems = append(problems, key+" "+problem)
+		}
+	}
+	return problems
+}
+
+// normalize converts a value to the types encoding/json decodes into, so []string and
+// ints can be passed as well.
+func normalize(value any) any {
+	switch value.(type) {
+	case string, float64, bool, nil:
+		return value
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return value
+	}
+	var normalized any
+	if err := json.Unmarshal(data, &normalized); err != nil {
+		return value
+	}
+	return normalized
+}
+
+// coerce returns value as one of types, parsing strings for numbers and booleans.
+func coerce(types nxtypes.SchemaType, value any) (any, bool) {
+	for _, t := range types {
+		switch v := value.(type) {
+		case string:
+			switch t {
+			case "string":
+				return v, true
+			case "number", "integer":
+				if n, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err == nil {
+					return n, true
+				}
+			case "boolean":
+				if b, err := strconv.ParseBool(v); err == nil {
+					return b, true
+				}
+			case "array":
+				// A single value given for a list
+				return []any{v}, true
+			}
+		case float64:
+			if t == "number" || (t == "integer" && v == math.Trunc(v)) {
+				return v, true
+			}
+		case bool:
+			if t == "boolean" {
+				return v, true
+			}
+		case []any:
+			if t == "array" {
+				return v, true
+			}
+		case map[string]any:
+			if t == "object" {
+				return v, true
+			}
+		case nil:
+			if t == "null" {
+				return v, true
+			}
+		}
+	}
+	return nil, false
+}
+
+func inEnum(enum []json.RawMessage, value any) bool {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return false
+	}
+	for _, raw := range enum {
+		var allowed any
+		if err := json.Unmarshal(raw, &allowed); err != nil {
+			continue
+		}
+		// Compare encodings of decoded values so formatting does not matter
+		if canonical, err := json.Marshal(allowed); err == nil && string(canonical) == string(encoded) {
+			return true
+		}
+	}
+	return false
+}
+
+func encodeAll(values []string) []json.RawMessage {
+	encoded := make([]json.RawMessage, len(values))
+	for i, value := range values {
+		encoded[i], _ = json.Marshal(value)
+	}
+	return encoded
+}
+
+func describeTypes(types nxtypes.SchemaType) string {
+	described := make([]string, len(types))
+	for i, t := range types {
+		switch t {
+		case "array", "object", "integer":
+			described[i] = "an " + t
+		default:
+			described[i] = "a " + t
+		}
+	}
+	return strings.Join(described, " or ")
+}
+
+func describeEnum(enum []json.RawMessage) string {
+	values := make([]string, len(enum))
+	for i, raw := range enum {
+		values[i] = string(raw)
+	}
+	return strings.Join(values, ", ")
+}
+
+func formatNumber(n float64) string {
+	return strconv.FormatFloat(n, 'f', -1, 64)
+}
@@ -0,0 +1,139 @@
+package generator
+
+import (
+	"encoding/json"
+	"testing"
+
+	nxtypes "github.com/lazyengs/lazynx/pkg/nxlsclient/nx-types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testOptions is shaped like the nx/generatorOptions response for @nx/react:library.
+const testOptions = `[
+	{"name": "name", "type": "string", "isRequired": true, "aliases": [], "$default": {"$source": "argv", "index": 0}, "pattern": "^[a-zA-Z][^:]*$", "x-prompt": "What name would you like to use for the library?"},
+	{"name": "directory", "type": "string", "isRequired": false, "aliases": ["dir"], "alias": "d"},
+	{"name": "project", "type": "string", "isRequired": true, "aliases": [], "$default": {"$source": "projectName"}},
+	{"name": "bundler", "type": "string", "isRequired": false, "aliases": [], "enum": ["none", "vite", "rollup"], "default": "none",
+		"x-prompt": {"message": "Which bundler?", "type": "list", "items": [{"value": "none", "label": "None"}, "vite", "rollup"]}},
+	{"name": "port", "type": "integer", "isRequired": false, "aliases": [], "minimum": 1024, "maximum": 65535},
+	{"name": "tags", "type": ["string", "array"], "isRequired": false, "aliases": [], "maxLength": 20},
+	{"name": "linters", "type": "array", "isRequired": false, "aliases": [], "items": {"type": "string", "enum": ["eslint", "biome"]}},
+	{"name": "strict", "type": "boolean", "isRequired": false, "aliases": [], "x-deprecated": "Use --typecheck instead."},
+	{"name": "style", "isRequired": false, "aliases": [], "oneOf": [{"type": "string", "enum": ["css", "scss"]}, {"type": "boolean", "enum": [false]}]},
+	{"name": "setup", "type": "object", "isRequired": false, "aliases": [], "required": ["entry"],
+		"properties": {"entry": {"type": "string"}}, "additionalProperties": false}
+]`
+
+func loadOptions(t *testing.T) []nxtypes.Option {
+	t.Helper()
+
+	var options []nxtypes.Option
+	require.NoError(t, json.Unmarshal([]byte(testOptions), &options))
+	return options
+}
+
+func TestDecodeOptions(t *testing.T) {
+	options := loadOptions(t)
+
+	name := options[0]
+	assert.Equal(t, nxtypes.SchemaType{"string"}, name.Type)
+	assert.Equal(t, "argv", name.DefaultSource.Source)
+	assert.Equal(t, 0, *name.DefaultSource.Index)
+	assert.True(t, name.XPrompt.Shorthand)
+
+	bundler := options[3]
+	value, ok := bundler.DefaultValue()
+	assert.True(t, ok)
+	assert.Equal(t, "none", value)
+	assert.Equal(t, []any{"none", "vite", "rollup"}, bundler.EnumValues())
+	assert.Equal(t, "list", bundler.XPrompt.Type)
+	assert.Equal(t, nxtypes.PromptItem{Value: "none", Label: "None"}, bundler.XPrompt.Items[0])
+	assert.Equal(t, "vite", bundler.XPrompt.Items[1].Value)
+
+	assert.Equal(t, 1024.0, *options[4].Minimum)
+	assert.True(t, options[5].Type.Has("array"))
+	assert.Equal(t, []string{"eslint", "biome"}, options[6].Items.Allowed())
+	assert.Equal(t, "Use --typecheck instead.", options[7].XDeprecated.Message)
+	assert.Len(t, options[8].OneOf, 2)
+	assert.False(t, options[9].AdditionalProperties.Allowed)
+
+	// The decoded options encode back to the same JSON
+	out, err := json.Marshal(options)
+	require.NoError(t, err)
+	assert.JSONEq(t, testOptions, string(out))
+}
+
+func TestValidate(t *testing.T) {
+	options := loadOptions(t)
+
+	tests := []struct {
+		name   string
+		values map[string]any
+		errs   map[string][]string
+	}{
+		{
+			name: "valid values",
+			values: map[string]any{
+				"name":    "ui",
+				"dir":     "libs/ui",
+				"bundler": "vite",
+				"port":    "4200",
+				"tags":    []string{"scope:shared"},
+				"linters": []string{"eslint"},
+				"strict":  "true",
+				"style":   false,
+				"setup":   map[string]any{"entry": "src/index.ts"},
+			},
+		},
+		{
+			name:   "missing required name",
+			values: map[string]any{"name": ""},
+			errs:   map[string][]string{"name": {"is required"}},
+		},
+		{
+			name: "invalid values",
+			values: map[string]any{
+				"name":    "1:ui",
+				"bundler": "webpack",
+				"port":    80.5,
+				"tags":    "a-tag-that-is-far-too-long",
+				"linters": []any{"eslint", "tslint"},
+				"strict":  "maybe",
+				"style":   "less",
+				"setup":   map[string]any{"outDir": "dist"},
+				"unknown": 1,
+			},
+			errs: map[string][]string{
+				"unknown": {"unknown option"},
+				"name":    {"must match ^[a-zA-Z][^:]*$"},
+				"bundler": {`must be one of "none", "vite", "rollup"`},
+				"port":    {"must be an integer"},
+				"tags":    {"must be at most 20 characters"},
+				"linters": {`item 2 must be one of "eslint", "biome"`},
+				"strict":  {"must be a boolean"},
+				"style":   {"must match exactly one of 2 alternatives, matches 0"},
+				"setup":   {"entry is required", "outDir is not allowed"},
+			},
+		},
+		{
+			name:   "out of range",
+			values: map[string]any{"name": "ui", "port": 70000},
+			errs:   map[string][]string{"port": {"must be at most 65535"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := Validate(options, tt.values)
+			if tt.errs == nil {
+				assert.Nil(t, errs)
+				return
+			}
+			assert.Equal(t, tt.errs, errs.ByField())
+		})
+	}
+
+	errs := Validate(options, map[string]any{"port": true})
+	assert.EqualError(t, errs, "name: is required; port: must be an integer")
+}
@@ -0,0 +1,19 @@
+/*
+Package gitfiles lists the files of an Nx workspace with git: the files changed since a
+base ref, compared the way `nx affected` does, and every file git does not ignore.
+
+# Usage
+
+	changed, err := gitfiles.Changed(ctx, workspacePath, "main", "")
+	if err != nil {
+		// Handle error, such as an unknown base ref
+	}
+
+	files, err := gitfiles.List(ctx, workspacePath)
+	if err != nil {
+		// Handle error, such as a workspace outside a git repository
+	}
+
+Paths are relative to the workspace, sorted and without duplicates.
+*/
+package gitfiles
@@ -0,0 +1,94 @@
+package gitfiles
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+)
+
+// Changed lists the files changed between base and head, relative to workspacePath, the
+// way `nx affected` does: the diff starts at the merge base of base and head. When head
+// is empty the working tree is compared instead, including untracked files.
+func Changed(ctx context.Context, workspacePath, base, head string) ([]string, error) {
+	if base == "" {
+		return nil, fmt.Errorf("a base ref is required")
+	}
+
+	mergeBaseHead := head
+	if mergeBaseHead == "" {
+		mergeBaseHead = "HEAD"
+	}
+	mergeBase, err := git(ctx, workspacePath, "merge-base", base, mergeBaseHead)
+	if err != nil {
+		return nil, err
+	}
+
+	diffArgs := []string{"diff", "--name-only", "--no-renames", "--relative", strings.TrimSpace(mergeBase)}
+	if head != "" {
+		diffArgs = append(diffArgs, head)
+	}
+	diff, err := git(ctx, workspacePath, diffArgs...)
+	if err != nil {
+		return nil, err
+	}
+	files := splitLines(diff)
+
+	if head == "" {
+		untracked, err := git(ctx, workspacePath, "ls-files", "--others", "--exclude-standard")
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, splitLines(untracked)...)
+	}
+
+	return dedupe(files), nil
+}
+
+// List lists the tracked and untracked files of workspacePath, relative to it, leaving
+// out ignored files.
+func List(ctx context.Context, workspacePath string) ([]string, error) {
+	out, err := git(ctx, workspacePath, "ls-files", "--cached", "--others", "--exclude-standard")
+	if err != nil {
+		return nil, err
+	}
+	return dedupe(splitLines(out)), nil
+}
+
+// git runs a git command in dir and returns its standard output.
+func git(ctx context.Context, dir string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to run git %s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return string(out), nil
+}
+
+func splitLines(s string) []string {
+	var lines []string
+	for _, line := range strings.Split(s, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// dedupe sorts files and removes duplicates.
+func dedupe(files []string) []string {
+	sort.Strings(files)
+	result := files[:0]
+	for i, f := range files {
+		if i == 0 || f != files[i-1] {
+			result = append(result, f)
+		}
+	}
+	return result
+}
@@ -0,0 +1,86 @@
+package gitfiles
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func runGit(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+		"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com",
+	)
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(out))
+}
+
+func writeFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+}
+
+func TestChanged(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	dir := t.TempDir()
+	runGit(t, dir, "init", "-q", "-b", "main")
+	writeFile(t, dir, "libs/ui/src/button.ts", "v1")
+	writeFile(t, dir, "libs/utils/src/index.ts", "v1")
+	runGit(t, dir, "add", "-A")
+	runGit(t, dir, "commit", "-q", "-m", "initial")
+
+	runGit(t, dir, "checkout", "-q", "-b", "feature")
+	writeFile(t, dir, "libs/ui/src/button.ts", "v2")
+	runGit(t, dir, "commit", "-q", "-am", "change ui")
+
+	// Changes on main after branching are not part of the feature diff
+	runGit(t, dir, "checkout", "-q", "main")
+	writeFile(t, dir, "libs/utils/src/index.ts", "v2")
+	runGit(t, dir, "commit", "-q", "-am", "change utils")
+	runGit(t, dir, "checkout", "-q", "feature")
+
+	files, err := Changed(context.Background(), dir, "main", "feature")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"libs/ui/src/button.ts"}, files)
+
+	// Without head, the working tree and untracked files are included
+	writeFile(t, dir, "libs/ui/src/new.ts", "new")
+	files, err = Changed(context.Background(), dir, "main", "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"libs/ui/src/button.ts", "libs/ui/src/new.ts"}, files)
+
+	_, err = Changed(context.Background(), dir, "missing-ref", "")
+	assert.Error(t, err)
+}
+
+func TestList(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	dir := t.TempDir()
+	runGit(t, dir, "init", "-q", "-b", "main")
+	writeFile(t, dir, "libs/ui/src/button.ts", "v1")
+	runGit(t, dir, "add", "-A")
+	runGit(t, dir, "commit", "-q", "-m", "initial")
+	writeFile(t, dir, "libs/ui/src/new.ts", "new")
+	writeFile(t, dir, ".gitignore", "dist\n")
+	writeFile(t, dir, "dist/main.js", "built")
+
+	files, err := List(context.Background(), dir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{".gitignore", "libs/ui/src/button.ts", "libs/ui/src/new.ts"}, files)
+}
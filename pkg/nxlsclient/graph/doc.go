@@ -0,0 +1,38 @@
+/*
+Package graph provides queries over an nxtypes.ProjectGraph.
+
+A Graph indexes the project graph of an nx/workspace response once, in both directions, so consumers
+no longer need to hand-roll traversals over the Nodes, ExternalNodes and Dependencies maps.
+
+# Usage
+
+	workspace, err := client.Commander.SendWorkspaceRequest(ctx, &commands.WorkspaceRequestParams{})
+	if err != nil {
+		// Handle error
+	}
+
+	g := graph.New(&workspace.ProjectGraph).WithoutExternal()
+
+	// Direct and transitive relations
+	deps := g.Dependencies("app")
+	affected := g.TransitiveDependents("shared-ui")
+
+	// Why does app depend on utils?
+	path := g.ShortestPath("app", "utils")
+
+	// Build order, failing on cycles
+	order, err := g.TopologicalOrder()
+	var cycleErr *graph.CycleError
+	if errors.As(err, &cycleErr) {
+		for _, cycle := range cycleErr.Cycles {
+			fmt.Println(strings.Join(cycle, " -> "))
+		}
+	}
+
+	// Only consider imports, ignoring implicit dependencies
+	static := g.Filter(nxtypes.DependencyTypeStatic, nxtypes.DependencyTypeDynamic)
+
+All methods return names sorted alphabetically so results are deterministic. Graphs are
+immutable; Filter and WithoutExternal return new graphs.
+*/
+package graph
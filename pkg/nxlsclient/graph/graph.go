@@ -0,0 +1,269 @@
+package graph
+
+import (
+	"slices"
+	"sort"
+
+	nxtypes "github.com/lazyengs/lazynx/pkg/nxlsclient/nx-types"
+)
+
+// Graph is an immutable, indexed view of an nxtypes.ProjectGraph.
+type Graph struct {
+	projects   map[string]bool // projects holds workspace projects; other nodes are external.
+	nodes      []string
+	deps       map[string][]nxtypes.ProjectGraphDependency
+	dependents map[string][]nxtypes.ProjectGraphDependency
+}
+
+// New indexes pg. Dependencies on nodes missing from Nodes and ExternalNodes are kept,
+// their targets are treated as external nodes.
+func New(pg *nxtypes.ProjectGraph) *Graph {
+	g := &Graph{
+		projects:   make(map[string]bool),
+		deps:       make(map[string][]nxtypes.ProjectGraphDependency),
+		dependents: make(map[string][]nxtypes.ProjectGraphDependency),
+	}
+	if pg == nil {
+		return g
+	}
+
+	seen := make(map[string]bool)
+	addNode := func(name string) {
+		if !seen[name] {
+			seen[name] = true
+			g.nodes = append(g.nodes, name)
+		}
+	}
+
+	for name := range pg.Nodes {
+		g.projects[name] = true
+		addNode(name)
+	}
+	for name := range pg.ExternalNodes {
+		addNode(name)
+	}
+
+	var edges []nxtypes.ProjectGraphDependency
+	for source, list := range pg.Dependencies {
+		addNode(source)
+		for _, dep := range list {
+			// Nx keys dependencies by source, so trust the key over the payload
+			dep.Source = source
+			addNode(dep.Target)
+			edges = append(edges, dep)
+		}
+	}
+
+	sort.Strings(g.nodes)
+	g.addEdges(edges)
+	return g
+}
+
+// addEdges indexes edges, dropping duplicates of the same source, target and type.
+func (g *Graph) addEdges(edges []nxtypes.ProjectGraphDependency) {
+	type edgeKey struct{ source, target, kind string }
+	seen := make(map[edgeKey]bool)
+
+	for _, dep := range edges {
+		key := edgeKey{dep.Source, dep.Target, dep.Type}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		g.deps[dep.Source] = append(g.deps[dep.Source], dep)
+		g.dependents[dep.Target] = append(g.dependents[dep.Target], dep)
+	}
+
+	for _, list := range g.deps {
+		sortEdges(list, func(d nxtypes.ProjectGraphDependency) string { return d.Target })
+	}
+	for _, list := range g.dependents {
+		sortEdges(list, func(d nxtypes.ProjectGraphDependency) string { return d.Source })
+	}
+}
+
+func sortEdges(list []nxtypes.ProjectGraphDependency, by func(nxtypes.ProjectGraphDependency) string) {
+	sort.Slice(list, func(i, j int) bool {
+		if by(list[i]) != by(list[j]) {
+			return by(list[i]) < by(list[j])
+		}
+		return list[i].Type < list[j].Type
+	})
+}
+
+// subgraph returns a graph with the same nodes and only the edges accepted by keep.
+func (g *Graph) subgraph(keep func(nxtypes.ProjectGraphDependency) bool) *Graph {
+	sub := &Graph{
+		projects:   g.projects,
+		nodes:      g.nodes,
+		deps:       make(map[string][]nxtypes.ProjectGraphDependency),
+		dependents: make(map[string][]nxtypes.ProjectGraphDependency),
+	}
+
+	var edges []nxtypes.ProjectGraphDependency
+	for _, name := range g.nodes {
+		for _, dep := range g.deps[name] {
+			if keep(dep) {
+				edges = append(edges, dep)
+			}
+		}
+	}
+	sub.addEdges(edges)
+	return sub
+}
+
+// Filter returns a graph that only keeps dependencies of the given types.
+func (g *Graph) Filter(types ...nxtypes.DependencyType) *Graph {
+	return g.subgraph(func(dep nxtypes.ProjectGraphDependency) bool {
+		return slices.Contains(types, nxtypes.DependencyType(dep.Type))
+	})
+}
+
+// WithoutExternal returns a graph without dependencies on external nodes such as npm packages.
+func (g *Graph) WithoutExternal() *Graph {
+	return g.subgraph(func(dep nxtypes.ProjectGraphDependency) bool {
+		return g.projects[dep.Source] && g.projects[dep.Target]
+	})
+}
+
+// Nodes returns every node, workspace projects and external nodes alike, sorted by name.
+func (g *Graph) Nodes() []string {
+	return slices.Clone(g.nodes)
+}
+
+// Projects returns the workspace projects sorted by name.
+func (g *Graph) Projects() []string {
+	projects := make([]string, 0, len(g.projects))
+	for _, name := range g.nodes {
+		if g.projects[name] {
+			projects = append(projects, name)
+		}
+	}
+	return projects
+}
+
+// HasNode reports whether name is a node of the graph.
+func (g *Graph) HasNode(name string) bool {
+	_, found := slices.BinarySearch(g.nodes, name)
+	return found
+}
+
+// IsExternal reports whether name is a node that is not a workspace project.
+func (g *Graph) IsExternal(name string) bool {
+	return g.HasNode(name) && !g.projects[name]
+}
+
+// DependencyEdges returns the dependencies declared by name.
+func (g *Graph) DependencyEdges(name string) []nxtypes.ProjectGraphDependency {
+	return slices.Clone(g.deps[name])
+}
+
+// DependentEdges returns the dependencies pointing at name.
+func (g *Graph) DependentEdges(name string) []nxtypes.ProjectGraphDependency {
+	return slices.Clone(g.dependents[name])
+}
+
+// Dependencies returns the nodes name depends on directly.
+func (g *Graph) Dependencies(name string) []string {
+	return uniqueEnds(g.deps[name], func(d nxtypes.ProjectGraphDependency) string { return d.Target })
+}
+
+// Dependents returns the nodes that depend on name directly.
+func (g *Graph) Dependents(name string) []string {
+	return uniqueEnds(g.dependents[name], func(d nxtypes.ProjectGraphDependency) string { return d.Source })
+}
+
+// uniqueEnds returns the distinct edge ends of a sorted edge list.
+func uniqueEnds(list []nxtypes.ProjectGraphDependency, end func(nxtypes.ProjectGraphDependency) string) []string {
+	names := make([]string, 0, len(list))
+	for _, dep := range list {
+		if n := end(dep); len(names) == 0 || names[len(names)-1] != n {
+			names = append(names, n)
+		}
+	}
+	return names
+}
+
+// TransitiveDependencies returns every node reachable from name, sorted by name. name
+// itself is only included when it is part of a cycle.
+func (g *Graph) TransitiveDependencies(name string) []string {
+	return g.reachable(name, g.Dependencies)
+}
+
+// TransitiveDependents returns every node that reaches name, sorted by name. name
+// itself is only included when it is part of a cycle.
+func (g *Graph) TransitiveDependents(name string) []string {
+	return g.reachable(name, g.Dependents)
+}
+
+func (g *Graph) reachable(name string, next func(string) []string) []string {
+	visited := make(map[string]bool)
+	queue := next(name)
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		if visited[current] {
+			continue
+		}
+		visited[current] = true
+		queue = append(queue, next(current)...)
+	}
+
+	result := make([]string, 0, len(visited))
+	for n := range visited {
+		result = append(result, n)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// ReverseIndex maps every node to the nodes that depend on it directly. Nodes without
+// dependents map to an empty slice.
+func (g *Graph) ReverseIndex() map[string][]string {
+	index := make(map[string][]string, len(g.nodes))
+	for _, name := range g.nodes {
+		index[name] = g.Dependents(name)
+	}
+	return index
+}
+
+// ShortestPath returns the shortest dependency chain from one node to another, both
+// included, or nil when to is not reachable from from.
+func (g *Graph) ShortestPath(from, to string) []string {
+	if !g.HasNode(from) || !g.HasNode(to) {
+		return nil
+	}
+	if from == to {
+		return []string{from}
+	}
+
+	previous := map[string]string{from: ""}
+	queue := []string{from}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, dep := range g.Dependencies(current) {
+			if _, seen := previous[dep]; seen {
+				continue
+			}
+			previous[dep] = current
+			if dep == to {
+				return buildPath(previous, from, to)
+			}
+			queue = append(queue, dep)
+		}
+	}
+
+	return nil
+}
+
+// buildPath walks the BFS predecessors back from to.
+func buildPath(previous map[string]string, from, to string) []string {
+	path := []string{to}
+	for current := to; current != from; {
+		current = previous[current]
+		path = append(path, current)
+	}
+	slices.Reverse(path)
+	return path
+}
@@ -0,0 +1,178 @@
+package graph
+
+import (
+	"errors"
+	"testing"
+
+	nxtypes "github.com/lazyengs/lazynx/pkg/nxlsclient/nx-types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func dep(source, target string, kind nxtypes.DependencyType) nxtypes.ProjectGraphDependency {
+	return nxtypes.ProjectGraphDependency{Source: source, Target: target, Type: string(kind)}
+}
+
+// newTestGraph builds:
+//
+//	app -> feature -> ui -> utils
+//	app -> utils (implicit)
+//	e2e -> app (implicit)
+//	ui -> npm:react
+func newTestGraph(extra ...nxtypes.ProjectGraphDependency) *nxtypes.ProjectGraph {
+	pg := &nxtypes.ProjectGraph{
+		Nodes: map[string]nxtypes.ProjectGraphProjectNode{
+			"app":     {Name: "app", Type: "app"},
+			"feature": {Name: "feature", Type: "lib"},
+			"ui":      {Name: "ui", Type: "lib"},
+			"utils":   {Name: "utils", Type: "lib"},
+			"e2e":     {Name: "e2e", Type: "e2e"},
+		},
+		ExternalNodes: map[string]nxtypes.ProjectGraphExternalNode{
+			"npm:react": {Name: "npm:react", Type: "npm"},
+		},
+		Dependencies: map[string][]nxtypes.ProjectGraphDependency{
+			"app": {
+				dep("app", "feature", nxtypes.DependencyTypeStatic),
+				dep("app", "utils", nxtypes.DependencyTypeImplicit),
+				// Duplicates are dropped
+				dep("app", "feature", nxtypes.DependencyTypeStatic),
+			},
+			"feature": {dep("feature", "ui", nxtypes.DependencyTypeDynamic)},
+			"ui": {
+				dep("ui", "utils", nxtypes.DependencyTypeStatic),
+				dep("ui", "npm:react", nxtypes.DependencyTypeStatic),
+			},
+			"e2e": {dep("e2e", "app", nxtypes.DependencyTypeImplicit)},
+		},
+	}
+	for _, d := range extra {
+		pg.Dependencies[d.Source] = append(pg.Dependencies[d.Source], d)
+	}
+	return pg
+}
+
+func TestNodes(t *testing.T) {
+	g := New(newTestGraph())
+
+	assert.Equal(t, []string{"app", "e2e", "feature", "npm:react", "ui", "utils"}, g.Nodes())
+	assert.Equal(t, []string{"app", "e2e", "feature", "ui", "utils"}, g.Projects())
+	assert.True(t, g.IsExternal("npm:react"))
+	assert.False(t, g.IsExternal("ui"))
+	assert.False(t, g.HasNode("missing"))
+}
+
+func TestDirectAndTransitive(t *testing.T) {
+	g := New(newTestGraph())
+
+	tests := []struct {
+		name string
+		got  []string
+		want []string
+	}{
+		{"dependencies", g.Dependencies("app"), []string{"feature", "utils"}},
+		{"dependents", g.Dependents("utils"), []string{"app", "ui"}},
+		{"no dependencies", g.Dependencies("utils"), []string{}},
+		{"transitive dependencies", g.TransitiveDependencies("app"), []string{"feature", "npm:react", "ui", "utils"}},
+		{"transitive dependents", g.TransitiveDependents("utils"), []string{"app", "e2e", "feature", "ui"}},
+		{"unknown node", g.TransitiveDependents("missing"), []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.got)
+		})
+	}
+
+	assert.Len(t, g.DependencyEdges("app"), 2)
+	assert.Equal(t, "implicit", g.DependentEdges("app")[0].Type)
+}
+
+func TestReverseIndex(t *testing.T) {
+	index := New(newTestGraph()).ReverseIndex()
+
+	assert.Equal(t, []string{"app", "ui"}, index["utils"])
+	assert.Equal(t, []string{"ui"}, index["npm:react"])
+	assert.Empty(t, index["e2e"])
+	assert.Len(t, index, 6)
+}
+
+func TestFilter(t *testing.T) {
+	g := New(newTestGraph())
+
+	static := g.Filter(nxtypes.DependencyTypeStatic)
+	assert.Equal(t, []string{"feature"}, static.Dependencies("app"))
+	assert.Empty(t, static.Dependencies("feature"))
+	// Nodes are kept even when they lose all edges
+	assert.True(t, static.HasNode("e2e"))
+
+	internal := g.WithoutExternal()
+	assert.Equal(t, []string{"utils"}, internal.Dependencies("ui"))
+	assert.Empty(t, internal.Dependents("npm:react"))
+
+	// The original graph is unchanged
+	assert.Equal(t, []string{"feature", "utils"}, g.Dependencies("app"))
+}
+
+func TestShortestPath(t *testing.T) {
+	g := New(newTestGraph())
+
+	assert.Equal(t, []string{"app", "utils"}, g.ShortestPath("app", "utils"))
+	assert.Equal(t, []string{"e2e", "app", "feature", "ui", "npm:react"}, g.ShortestPath("e2e", "npm:react"))
+	assert.Equal(t, []string{"ui"}, g.ShortestPath("ui", "ui"))
+	assert.Nil(t, g.ShortestPath("utils", "app"))
+	assert.Nil(t, g.ShortestPath("app", "missing"))
+
+	// Without the implicit edge the path goes through the libraries
+	static := g.Filter(nxtypes.DependencyTypeStatic, nxtypes.DependencyTypeDynamic)
+	assert.Equal(t, []string{"app", "feature", "ui", "utils"}, static.ShortestPath("app", "utils"))
+}
+
+func TestTopologicalOrder(t *testing.T) {
+	g := New(newTestGraph())
+
+	layers, err := g.Layers()
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{
+		{"npm:react", "utils"},
+		{"ui"},
+		{"feature"},
+		{"app"},
+		{"e2e"},
+	}, layers)
+
+	order, err := g.TopologicalOrder()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"npm:react", "utils", "ui", "feature", "app", "e2e"}, order)
+}
+
+func TestCycles(t *testing.T) {
+	g := New(newTestGraph(
+		dep("utils", "feature", nxtypes.DependencyTypeStatic),
+		dep("e2e", "e2e", nxtypes.DependencyTypeImplicit),
+	))
+
+	assert.Equal(t, [][]string{
+		{"e2e", "e2e"},
+		{"feature", "ui", "utils", "feature"},
+	}, g.Cycles())
+
+	assert.Contains(t, g.TransitiveDependencies("ui"), "ui")
+
+	_, err := g.TopologicalOrder()
+	var cycleErr *CycleError
+	require.True(t, errors.As(err, &cycleErr))
+	assert.Len(t, cycleErr.Cycles, 2)
+	assert.EqualError(t, err, "project graph contains 2 cycle(s): e2e -> e2e; feature -> ui -> utils -> feature")
+
+	assert.Empty(t, New(newTestGraph()).Cycles())
+}
+
+func TestNilGraph(t *testing.T) {
+	g := New(nil)
+
+	assert.Empty(t, g.Nodes())
+	order, err := g.TopologicalOrder()
+	require.NoError(t, err)
+	assert.Empty(t, order)
+}
@@ -0,0 +1,173 @@
+package graph
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// CycleError is returned when an ordering is requested for a graph with cycles.
+type CycleError struct {
+	Cycles [][]string // Cycles holds one path per cycle, starting and ending with the same node.
+}
+
+func (e *CycleError) Error() string {
+	paths := make([]string, len(e.Cycles))
+	for i, cycle := range e.Cycles {
+		paths[i] = strings.Join(cycle, " -> ")
+	}
+	return fmt.Sprintf("project graph contains %d cycle(s): %s", len(e.Cycles), strings.Join(paths, "; "))
+}
+
+// Layers groups the nodes so that every node only depends on nodes in earlier layers.
+// Nodes within a layer are independent of each other and sorted by name. It returns a
+// *CycleError when the graph has cycles.
+func (g *Graph) Layers() ([][]string, error) {
+	if cycles := g.Cycles(); len(cycles) > 0 {
+		return nil, &CycleError{Cycles: cycles}
+	}
+
+	remaining := make(map[string]int, len(g.nodes))
+	var ready []string
+	for _, name := range g.nodes {
+		remaining[name] = len(g.Dependencies(name))
+		if remaining[name] == 0 {
+			ready = append(ready, name)
+		}
+	}
+
+	var layers [][]string
+	for len(ready) > 0 {
+		layers = append(layers, ready)
+		var next []string
+		for _, name := range ready {
+			for _, dependent := range g.Dependents(name) {
+				remaining[dependent]--
+				if remaining[dependent] == 0 {
+					next = append(next, dependent)
+				}
+			}
+		}
+		sort.Strings(next)
+		ready = next
+	}
+
+	return layers, nil
+}
+
+// TopologicalOrder returns every node after all of its dependencies, which is the order
+// in which projects can be built. It returns a *CycleError when the graph has cycles.
+func (g *Graph) TopologicalOrder() ([]string, error) {
+	layers, err := g.Layers()
+	if err != nil {
+		return nil, err
+	}
+	return slices.Concat(layers...), nil
+}
+
+// Cycles returns one cycle path per strongly connected component, including nodes that
+// depend on themselves. Each path starts and ends with the alphabetically first node of
+// its component. Paths are sorted by their first node.
+func (g *Graph) Cycles() [][]string {
+	var cycles [][]string
+	for _, component := range g.stronglyConnectedComponents() {
+		start := component[0]
+		if len(component) == 1 && !slices.Contains(g.Dependencies(start), start) {
+			continue
+		}
+		cycles = append(cycles, g.cycleThrough(start, component))
+	}
+
+	sort.Slice(cycles, func(i, j int) bool { return cycles[i][0] < cycles[j][0] })
+	return cycles
+}
+
+// cycleThrough returns the shortest path from start back to itself inside component.
+func (g *Graph) cycleThrough(start string, component []string) []string {
+	inComponent := make(map[string]bool, len(component))
+	for _, name := range component {
+		inComponent[name] = true
+	}
+
+	previous := make(map[string]string)
+	queue := []string{start}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, dep := range g.Dependencies(current) {
+			if !inComponent[dep] {
+				continue
+			}
+			if dep == start {
+				path := []string{start}
+				for n := current; n != start; n = previous[n] {
+					path = append(path, n)
+				}
+				path = append(path, start)
+				slices.Reverse(path)
+				return path
+			}
+			if _, seen := previous[dep]; !seen {
+				previous[dep] = current
+				queue = append(queue, dep)
+			}
+		}
+	}
+
+	// Unreachable for a real component, keep the start so callers still see the node
+	return []string{start, start}
+}
+
+// stronglyConnectedComponents runs Tarjan's algorithm. Each component is sorted by name.
+func (g *Graph) stronglyConnectedComponents() [][]string {
+	var (
+		index      int
+		stack      []string
+		onStack    = make(map[string]bool)
+		indices    = make(map[string]int)
+		lowLinks   = make(map[string]int)
+		components [][]string
+		visit      func(string)
+	)
+
+	visit = func(name string) {
+		indices[name] = index
+		lowLinks[name] = index
+		index++
+		stack = append(stack, name)
+		onStack[name] = true
+
+		for _, dep := range g.Dependencies(name) {
+			if _, visited := indices[dep]; !visited {
+				visit(dep)
+				lowLinks[name] = min(lowLinks[name], lowLinks[dep])
+			} else if onStack[dep] {
+				lowLinks[name] = min(lowLinks[name], indices[dep])
+			}
+		}
+
+		if lowLinks[name] == indices[name] {
+			var component []string
+			for {
+				top := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onStack[top] = false
+				component = append(component, top)
+				if top == name {
+					break
+				}
+			}
+			sort.Strings(component)
+			components = append(components, component)
+		}
+	}
+
+	for _, name := range g.nodes {
+		if _, visited := indices[name]; !visited {
+			visit(name)
+		}
+	}
+
+	return components
+}
@@ -0,0 +1,219 @@
+package nxlsclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// HealthStatus summarises the state of the nxls server.
+type HealthStatus string
+
+const (
+	// HealthStatusUnknown means no check has run yet or the client is not started.
+	HealthStatusUnknown HealthStatus = "unknown"
+	// HealthStatusHealthy means the server answered the probe quickly and is within resource limits.
+	HealthStatusHealthy HealthStatus = "healthy"
+	// HealthStatusDegraded means the server answered slowly, exceeded a resource limit or missed a probe.
+	HealthStatusDegraded HealthStatus = "degraded"
+	// HealthStatusUnresponsive means the server missed FailuresBeforeUnresponsive probes in a row.
+	HealthStatusUnresponsive HealthStatus = "unresponsive"
+)
+
+// HealthThresholds configures the watchdog probes and limits.
+type HealthThresholds struct {
+	Interval                   time.Duration // Interval is the time between probes.
+	ProbeTimeout               time.Duration // ProbeTimeout bounds a single nx/workspacePath probe.
+	SlowProbe                  time.Duration // SlowProbe marks the server degraded when a probe takes longer.
+	FailuresBeforeUnresponsive int           // FailuresBeforeUnresponsive is the number of consecutive failed probes before the server is unresponsive.
+	MaxRSSBytes                uint64        // MaxRSSBytes marks the server degraded above this RSS. Zero disables the limit.
+	MaxCPUPercent              float64       // MaxCPUPercent marks the server degraded above this CPU usage. Zero disables the limit.
+}
+
+// DefaultHealthThresholds are sensible limits for a large workspace.
+var DefaultHealthThresholds = HealthThresholds{
+	Interval:                   30 * time.Second,
+	ProbeTimeout:               5 * time.Second,
+	SlowProbe:                  time.Second,
+	FailuresBeforeUnresponsive: 3,
+	MaxRSSBytes:                4 << 30,
+	MaxCPUPercent:              95,
+}
+
+// withDefaults fills unset probe settings from DefaultHealthThresholds. Resource limits
+// are left alone because zero disables them.
+func (t HealthThresholds) withDefaults() HealthThresholds {
+	if t.Interval <= 0 {
+		t.Interval = DefaultHealthThresholds.Interval
+	}
+	if t.ProbeTimeout <= 0 {
+		t.ProbeTimeout = DefaultHealthThresholds.ProbeTimeout
+	}
+	if t.SlowProbe <= 0 {
+		t.SlowProbe = DefaultHealthThresholds.SlowProbe
+	}
+	if t.FailuresBeforeUnresponsive <= 0 {
+		t.FailuresBeforeUnresponsive = DefaultHealthThresholds.FailuresBeforeUnresponsive
+	}
+	return t
+}
+
+// HealthReport is the outcome of a single watchdog check.
+type HealthReport struct {
+	Status              HealthStatus  // Status is the overall verdict.
+	CheckedAt           time.Time     // CheckedAt is when the check started.
+	Latency             time.Duration // Latency is how long the liveness probe took.
+	ConsecutiveFailures int           // ConsecutiveFailures counts failed probes in a row, including this one.
+	Reasons             []string      // Reasons explains why the status is not healthy.
+	Stats               *ProcessStats // Stats is the resource sample, nil when /proc is unavailable.
+	Err                 error         // Err is the probe error, if any.
+}
+
+// Watchdog periodically probes nxls with nx/workspacePath and samples its process stats.
+type Watchdog struct {
+	client     *Client
+	thresholds HealthThresholds
+	onReport   func(HealthReport)
+
+	mu        sync.Mutex
+	last      HealthReport
+	failures  int
+	prevStats *ProcessStats
+}
+
+// NewWatchdog creates a Watchdog for client. onReport, if not nil, is called after every
+// check from the goroutine running Run; it is where callers restart an unresponsive server.
+func NewWatchdog(client *Client, thresholds HealthThresholds, onReport func(HealthReport)) *Watchdog {
+	return &Watchdog{
+		client:     client,
+		thresholds: thresholds.withDefaults(),
+		onReport:   onReport,
+		last:       HealthReport{Status: HealthStatusUnknown},
+	}
+}
+
+// Thresholds returns the thresholds in effect.
+func (w *Watchdog) Thresholds() HealthThresholds {
+	return w.thresholds
+}
+
+// Report returns the most recent health report.
+func (w *Watchdog) Report() HealthReport {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.last
+}
+
+// Run checks the server every Interval until ctx is done.
+func (w *Watchdog) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.thresholds.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			report := w.Check(ctx)
+			if w.onReport != nil {
+				w.onReport(report)
+			}
+		}
+	}
+}
+
+// Check runs a single liveness probe and resource sample and records the result.
+func (w *Watchdog) Check(ctx context.Context) HealthReport {
+	report := HealthReport{CheckedAt: time.Now()}
+
+	if w.client.Commander == nil {
+		report.Status = HealthStatusUnknown
+		report.Err = errors.New("client is not started")
+		w.record(report)
+		return report
+	}
+
+	probeCtx, cancel := context.WithTimeout(ctx, w.thresholds.ProbeTimeout)
+	_, err := w.client.Commander.SendWorkspacePathRequest(probeCtx)
+	cancel()
+	report.Latency = time.Since(report.CheckedAt)
+	report.Err = err
+
+	if pid := w.client.ServerPID(); pid > 0 {
+		if stats, statsErr := ReadProcessStats(pid); statsErr == nil {
+			w.mu.Lock()
+			stats.Since(w.prevStats)
+			w.prevStats = stats
+			w.mu.Unlock()
+			report.Stats = stats
+		}
+	}
+
+	w.mu.Lock()
+	if err != nil {
+		w.failures++
+	} else {
+		w.failures = 0
+	}
+	report.ConsecutiveFailures = w.failures
+	w.mu.Unlock()
+
+	report.Status, report.Reasons = w.evaluate(report)
+	w.record(report)
+
+	if report.Status != HealthStatusHealthy {
+		w.client.Logger.Warnw("nxls health check", "status", report.Status, "reasons", report.Reasons, "latency", report.Latency)
+	} else {
+		w.client.Logger.Debugw("nxls health check", "status", report.Status, "latency", report.Latency)
+	}
+
+	return report
+}
+
+// evaluate applies the thresholds to a report.
+func (w *Watchdog) evaluate(report HealthReport) (HealthStatus, []string) {
+	var reasons []string
+
+	if report.Err != nil {
+		reasons = append(reasons, fmt.Sprintf("probe failed: %v", report.Err))
+		if report.ConsecutiveFailures >= w.thresholds.FailuresBeforeUnresponsive {
+			return HealthStatusUnresponsive, reasons
+		}
+	} else if report.Latency > w.thresholds.SlowProbe {
+		reasons = append(reasons, fmt.Sprintf("probe took %s", report.Latency.Round(time.Millisecond)))
+	}
+
+	if s := report.Stats; s != nil {
+		if w.thresholds.MaxRSSBytes > 0 && s.RSSBytes > w.thresholds.MaxRSSBytes {
+			reasons = append(reasons, fmt.Sprintf("rss %d MiB above %d MiB", s.RSSBytes>>20, w.thresholds.MaxRSSBytes>>20))
+		}
+		if w.thresholds.MaxCPUPercent > 0 && s.CPUPercent > w.thresholds.MaxCPUPercent {
+			reasons = append(reasons, fmt.Sprintf("cpu %.0f%% above %.0f%%", s.CPUPercent, w.thresholds.MaxCPUPercent))
+		}
+	}
+
+	if len(reasons) > 0 {
+		return HealthStatusDegraded, reasons
+	}
+	return HealthStatusHealthy, nil
+}
+
+func (w *Watchdog) record(report HealthReport) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.last = report
+}
+
+// ServerPID returns the pid nxls reported in its initialize result, falling back to the
+// pid of the spawned node process. It returns 0 when the server is not running.
+func (c *Client) ServerPID() int {
+	if c.serverPid > 0 {
+		return c.serverPid
+	}
+	if c.process != nil && c.process.Process != nil {
+		return c.process.Process.Pid
+	}
+	return 0
+}
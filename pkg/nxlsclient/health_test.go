@@ -0,0 +1,158 @@
+package nxlsclient
+
+import (
+	"context"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/lazyengs/lazynx/pkg/nxlsclient/commands"
+	"github.com/lazyengs/lazynx/pkg/nxlsclient/metrics"
+	"github.com/sourcegraph/jsonrpc2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// newFakeServerClient connects a Client to an in-memory JSON-RPC server whose
+// nx/workspacePath handler sleeps for delay before answering.
+func newFakeServerClient(t *testing.T, delay time.Duration) *Client {
+	t.Helper()
+
+	logger, _ := zap.NewDevelopment()
+	clientSide, serverSide := net.Pipe()
+
+	handler := jsonrpc2.HandlerWithError(func(ctx context.Context, conn *jsonrpc2.Conn, req *jsonrpc2.Request) (interface{}, error) {
+		time.Sleep(delay)
+		return "/workspace", nil
+	})
+	server := jsonrpc2.NewConn(context.Background(), jsonrpc2.NewBufferedStream(serverSide, jsonrpc2.VSCodeObjectCodec{}), jsonrpc2.AsyncHandler(handler))
+	conn := jsonrpc2.NewConn(context.Background(), jsonrpc2.NewBufferedStream(clientSide, jsonrpc2.VSCodeObjectCodec{}), jsonrpc2.HandlerWithError(func(ctx context.Context, conn *jsonrpc2.Conn, req *jsonrpc2.Request) (interface{}, error) {
+		return nil, nil
+	}))
+	t.Cleanup(func() {
+		conn.Close()
+		server.Close()
+	})
+
+	return &Client{
+		Logger:    logger.Sugar(),
+		conn:      conn,
+		Commander: commands.NewCommander(conn, logger.Sugar()),
+		serverPid: os.Getpid(),
+	}
+}
+
+func TestReadProcessStats(t *testing.T) {
+	if _, err := os.Stat("/proc/self/stat"); err != nil {
+		t.Skip("procfs not available")
+	}
+
+	first, err := ReadProcessStats(os.Getpid())
+	require.NoError(t, err)
+	assert.Equal(t, os.Getpid(), first.PID)
+	assert.Greater(t, first.RSSBytes, uint64(0))
+
+	// Burn some CPU so the second sample differs
+	deadline := time.Now().Add(50 * time.Millisecond)
+	for time.Now().Before(deadline) {
+	}
+
+	second, err := ReadProcessStats(os.Getpid())
+	require.NoError(t, err)
+	second.Since(first)
+	assert.GreaterOrEqual(t, second.CPUPercent, 0.0)
+	assert.GreaterOrEqual(t, second.CPUTime, first.CPUTime)
+}
+
+func TestWatchdogCheck(t *testing.T) {
+	t.Run("Healthy", func(t *testing.T) {
+		client := newFakeServerClient(t, 0)
+		watchdog := NewWatchdog(client, HealthThresholds{ProbeTimeout: time.Second}, nil)
+
+		report := watchdog.Check(context.Background())
+		assert.Equal(t, HealthStatusHealthy, report.Status, report.Reasons)
+		assert.NoError(t, report.Err)
+		assert.Equal(t, report, watchdog.Report())
+	})
+
+	t.Run("SlowProbeIsDegraded", func(t *testing.T) {
+		client := newFakeServerClient(t, 50*time.Millisecond)
+		watchdog := NewWatchdog(client, HealthThresholds{ProbeTimeout: time.Second, SlowProbe: 10 * time.Millisecond}, nil)
+
+		report := watchdog.Check(context.Background())
+		assert.Equal(t, HealthStatusDegraded, report.Status)
+		assert.NoError(t, report.Err)
+	})
+
+	t.Run("RSSLimitIsDegraded", func(t *testing.T) {
+		if _, err := os.Stat("/proc/self/stat"); err != nil {
+			t.Skip("procfs not available")
+		}
+		client := newFakeServerClient(t, 0)
+		watchdog := NewWatchdog(client, HealthThresholds{ProbeTimeout: time.Second, MaxRSSBytes: 1}, nil)
+
+		report := watchdog.Check(context.Background())
+		assert.Equal(t, HealthStatusDegraded, report.Status)
+		require.NotNil(t, report.Stats)
+	})
+
+	t.Run("HungServerBecomesUnresponsive", func(t *testing.T) {
+		client := newFakeServerClient(t, time.Hour)
+		watchdog := NewWatchdog(client, HealthThresholds{ProbeTimeout: 20 * time.Millisecond, FailuresBeforeUnresponsive: 2}, nil)
+
+		first := watchdog.Check(context.Background())
+		assert.Equal(t, HealthStatusDegraded, first.Status)
+		assert.Error(t, first.Err)
+		assert.Equal(t, 1, first.ConsecutiveFailures)
+
+		second := watchdog.Check(context.Background())
+		assert.Equal(t, HealthStatusUnresponsive, second.Status)
+		assert.Equal(t, 2, second.ConsecutiveFailures)
+	})
+
+	t.Run("NotStarted", func(t *testing.T) {
+		logger, _ := zap.NewDevelopment()
+		watchdog := NewWatchdog(&Client{Logger: logger.Sugar()}, HealthThresholds{}, nil)
+
+		report := watchdog.Check(context.Background())
+		assert.Equal(t, HealthStatusUnknown, report.Status)
+	})
+}
+
+func TestWatchdogRun(t *testing.T) {
+	client := newFakeServerClient(t, 0)
+
+	reports := make(chan HealthReport, 10)
+	watchdog := NewWatchdog(client, HealthThresholds{Interval: 10 * time.Millisecond, ProbeTimeout: time.Second}, func(r HealthReport) {
+		reports <- r
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go watchdog.Run(ctx)
+
+	select {
+	case report := <-reports:
+		assert.Equal(t, HealthStatusHealthy, report.Status, report.Reasons)
+	case <-time.After(2 * time.Second):
+		t.Fatal("Watchdog did not report")
+	}
+}
+
+func TestCommanderReportsMetrics(t *testing.T) {
+	client := newFakeServerClient(t, 0)
+	registry := metrics.NewRegistry()
+	client.Commander.SetObserver(registry)
+
+	_, err := client.Commander.SendWorkspacePathRequest(context.Background())
+	require.NoError(t, err)
+
+	snap := registry.Snapshot()
+	require.Len(t, snap.Requests, 1)
+	assert.Equal(t, "nx/workspacePath", snap.Requests[0].Method)
+	assert.Equal(t, uint64(1), snap.Requests[0].Count)
+	assert.Equal(t, int64(0), snap.Requests[0].InFlight)
+	assert.Equal(t, float64(len(`"/workspace"`)), snap.Requests[0].ResponseBytes.Sum)
+}
@@ -0,0 +1,70 @@
+/*
+Package inputs expands the inputs of nx targets into the files, environment variables,
+runtime commands and packages that decide whether a cached result can be reused.
+
+Target inputs are written in terms of named inputs ("production", "^production"),
+filesets ("{projectRoot}/src/**", "!{projectRoot}/jest.config.ts"), and runtime, env,
+externalDependencies and dependentTasksOutputFiles entries. A Resolver expands them
+with the named inputs of nx.json and each project, and matches the filesets against the
+project file map of an nx/workspace response.
+
+# Usage
+
+	workspace, err := client.Commander.SendWorkspaceRequest(ctx, &commands.WorkspaceRequestParams{})
+	if err != nil {
+		// Handle error
+	}
+
+	resolver, err := inputs.NewResolver(workspace)
+	if err != nil {
+		// Handle error, the workspace has no project file map
+	}
+
+	result, err := resolver.Resolve("app", "build")
+	if err != nil {
+		// Handle error
+	}
+
+	for _, file := range result.Files {
+		fmt.Println(file.Path, file.Project, file.Hash)
+	}
+	fmt.Println(result.Env, result.Runtime, result.ExternalDependencies)
+
+Inputs follow the nx rules: targets without inputs use targetDefaults, then every file of
+the project and its dependencies; "^name" applies name to all projects the target's
+project depends on, directly or not, and adds the npm packages reached on the way;
+negated filesets remove files matched by the other filesets of the same project.
+Filesets under {workspaceRoot} also match Resolver.WorkspaceFiles, the files outside
+every project, which the project file map does not list.
+
+# Cache Misses
+
+A Snapshot records the hashes of the input files, the versions of the input packages and
+a hash of the input env vars, typically after a successful run. Comparing it with a
+later snapshot tells why the target no longer hits the cache:
+
+	store := inputs.NewStore(cacheDir)
+	snap, err := resolver.Snapshot("app", "build")
+	if err != nil {
+		// Handle error
+	}
+	if err := store.Save(workspacePath, snap); err != nil {
+		// Handle error
+	}
+
+	// Later
+	before, err := store.Load(workspacePath, "app", "build")
+	if errors.Is(err, inputs.ErrNoSnapshot) {
+		// Nothing recorded yet, or the snapshot was taken with another Nx version
+	}
+	after, err := resolver.Snapshot("app", "build")
+	diff := inputs.Compare(before, after)
+	fmt.Println(diff.Summary())
+	for _, line := range diff.Lines() {
+		fmt.Println(line)
+	}
+
+Runtime inputs and dependent task outputs are not recorded, so an empty diff does not
+rule them out.
+*/
+package inputs
@@ -0,0 +1,81 @@
+package inputs
+
+import (
+	"path"
+	"strings"
+)
+
+// matchGlob matches a workspace relative file against a fileset pattern with the
+// minimatch features nx filesets use: "**" for any number of directories, "*", "?",
+// character classes and {a,b} alternatives. Dot files match like any other file.
+func matchGlob(pattern, file string) bool {
+	for _, p := range expandBraces(pattern) {
+		if matchSegments(strings.Split(p, "/"), strings.Split(file, "/")) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchSegments(pattern, name []string) bool {
+	if len(pattern) == 0 {
+		return len(name) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(name); i++ {
+			if matchSegments(pattern[1:], name[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(name) == 0 {
+		return false
+	}
+	matched, err := path.Match(pattern[0], name[0])
+	return err == nil && matched && matchSegments(pattern[1:], name[1:])
+}
+
+// expandBraces expands the first {a,b} group of pattern, recursively, into the patterns
+// it stands for.
+func expandBraces(pattern string) []string {
+	open := strings.IndexByte(pattern, '{')
+	if open < 0 {
+		return []string{pattern}
+	}
+
+	depth := 0
+	var alternatives []string
+	start := open + 1
+	for i := open; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '{':
+			depth++
+		case ',':
+			if depth == 1 {
+				alternatives = append(alternatives, pattern[start:i])
+				start = i + 1
+			}
+		case '}':
+			depth--
+			if depth > 0 {
+				continue
+			}
+			if alternatives == nil {
+				// A group without alternatives is literal, such as an unknown token
+				rest := expandBraces(pattern[i+1:])
+				for j, r := range rest {
+					rest[j] = pattern[:i+1] + r
+				}
+				return rest
+			}
+			alternatives = append(alternatives, pattern[start:i])
+			var expanded []string
+			for _, alt := range alternatives {
+				expanded = append(expanded, expandBraces(pattern[:open]+alt+pattern[i+1:])...)
+			}
+			return expanded
+		}
+	}
+	return []string{pattern}
+}
@@ -0,0 +1,449 @@
+package inputs
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/lazyengs/lazynx/pkg/nxlsclient/graph"
+	"github.com/lazyengs/lazynx/pkg/nxlsclient/internal/glob"
+	nxtypes "github.com/lazyengs/lazynx/pkg/nxlsclient/nx-types"
+	"github.com/lazyengs/lazynx/pkg/nxlsclient/targetconfig"
+)
+
+// DefaultNamedInput is the named input every project has, covering all of its files.
+var DefaultNamedInput = []nxtypes.Input{{Definition: &nxtypes.InputDefinition{Fileset: ptr("{projectRoot}/**/*")}}}
+
+// DefaultInputs are the inputs of targets that set none, directly or through
+// targetDefaults: every file of the project and of its dependencies.
+var DefaultInputs = []nxtypes.Input{
+	{Definition: &nxtypes.InputDefinition{Fileset: ptr("{projectRoot}/**/*")}},
+	{Definition: &nxtypes.InputDefinition{Input: ptr("default"), Dependencies: ptr(true)}},
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+// File is a file covered by the inputs of a target.
+type File struct {
+	Path string
+	// Project owns the file, empty for files outside every project.
+	Project string
+	Hash    string
+}
+
+// Result is what the inputs of a target expand to.
+type Result struct {
+	Project string
+	Target  string
+	// Files are sorted by path.
+	Files []File
+	// Env are the environment variables whose values are part of the hash, sorted.
+	Env []string
+	// Runtime are the commands whose output is part of the hash, sorted.
+	Runtime []string
+	// ExternalDependencies are the npm packages, as external node names such as
+	// npm:react, whose versions are part of the hash, sorted.
+	ExternalDependencies []string
+	// DependentOutputs are the dependentTasksOutputFiles patterns, matched against the
+	// outputs of dependency tasks when they run.
+	DependentOutputs []string
+	// Projects are the projects contributing files, sorted.
+	Projects []string
+}
+
+// Resolver expands target inputs for the projects of a workspace.
+type Resolver struct {
+	// WorkspaceFiles are the files outside every project, which {workspaceRoot}
+	// filesets can match besides project files. The project file map does not list them.
+	WorkspaceFiles []string
+	// Root is the workspace directory. When set, snapshots hash the input files the
+	// project file map has no hash for, such as {workspaceRoot} files, from disk.
+	Root string
+	// LookupEnv reads the environment variables recorded in snapshots. Defaults to
+	// os.LookupEnv.
+	LookupEnv func(key string) (string, bool)
+
+	nxJson    nxtypes.NxJsonConfiguration
+	nxVersion string
+	projects  map[string]nxtypes.ProjectConfiguration
+	externals map[string]nxtypes.ProjectGraphExternalNode
+	files     nxtypes.ProjectFileMap
+	graph     *graph.Graph
+	targets   *targetconfig.Resolver
+}
+
+// NewResolver creates a Resolver from an nx/workspace response, which must include the
+// project file map.
+func NewResolver(workspace *nxtypes.NxWorkspace) (*Resolver, error) {
+	if workspace.ProjectFileMap == nil {
+		return nil, fmt.Errorf("the workspace has no project file map")
+	}
+	r := &Resolver{
+		LookupEnv: os.LookupEnv,
+		nxJson:    workspace.NxJson,
+		nxVersion: workspace.NxVersion.Full,
+		projects:  make(map[string]nxtypes.ProjectConfiguration, len(workspace.ProjectGraph.Nodes)),
+		externals: workspace.ProjectGraph.ExternalNodes,
+		files:     *workspace.ProjectFileMap,
+		graph:     graph.New(&workspace.ProjectGraph),
+		targets:   targetconfig.NewResolver(workspace),
+	}
+	for name, node := range workspace.ProjectGraph.Nodes {
+		r.projects[name] = node.Data.ProjectConfiguration
+	}
+	return r, nil
+}
+
+// Resolve expands the inputs of project:target, including those it gets from
+// targetDefaults, or DefaultInputs when it has none.
+func (r *Resolver) Resolve(project, target string) (*Result, error) {
+	t, err := r.targets.Resolve(project, target)
+	if err != nil {
+		return nil, err
+	}
+	inputs := t.Config.Inputs
+	if inputs == nil {
+		inputs = DefaultInputs
+	}
+	result, err := r.ResolveInputs(project, inputs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve the inputs of %s:%s: %w", project, target, err)
+	}
+	result.Target = target
+	return result, nil
+}
+
+// ResolveInputs expands inputs in the context of project.
+func (r *Resolver) ResolveInputs(project string, inputs []nxtypes.Input) (*Result, error) {
+	if _, ok := r.projects[project]; !ok {
+		return nil, fmt.Errorf("project %q not found", project)
+	}
+
+	c := newCollector()
+	self, err := r.expand(project, inputs, nil)
+	if err != nil {
+		return nil, err
+	}
+	c.add(project, self)
+
+	// Like nx, ^name applies name to every project the target's project depends on,
+	// directly or not, and covers the versions of the npm packages reached on the way
+	var deps []string
+	if len(self.deps) > 0 {
+		deps = r.graph.TransitiveDependencies(project)
+	}
+	for _, dep := range self.deps {
+		for _, node := range deps {
+			if node == project {
+				continue
+			}
+			if r.graph.IsExternal(node) {
+				c.external[node] = true
+				continue
+			}
+			expanded, err := r.expandNamed(node, dep, nil)
+			if err != nil {
+				return nil, fmt.Errorf("^%s in %s: %w", dep, node, err)
+			}
+			c.add(node, expanded)
+		}
+	}
+
+	for _, selected := range self.projectInputs {
+		for _, name := range r.selectProjects(project, selected.projects) {
+			expanded, err := r.expandNamed(name, selected.input, nil)
+			if err != nil {
+				return nil, fmt.Errorf("%s in %s: %w", selected.input, name, err)
+			}
+			c.add(name, expanded)
+		}
+	}
+
+	return c.result(r, project), nil
+}
+
+// expansion is a list of inputs with named inputs replaced by what they stand for.
+type expansion struct {
+	filesets      []string
+	deps          []string
+	projectInputs []projectInput
+	env           []string
+	runtime       []string
+	external      []string
+	outputs       []string
+}
+
+// projectInput is a named input of other projects than the one of the target.
+type projectInput struct {
+	projects []string
+	input    string
+}
+
+// namedInputs returns the named inputs of project: default, then nx.json, then the
+// project's own, later ones winning.
+func (r *Resolver) namedInputs(project string) map[string][]nxtypes.Input {
+	named := map[string][]nxtypes.Input{"default": DefaultNamedInput}
+	for name, inputs := range r.nxJson.NamedInputs {
+		named[name] = inputs
+	}
+	for name, inputs := range r.projects[project].NamedInputs {
+		named[name] = inputs
+	}
+	return named
+}
+
+func (r *Resolver) expandNamed(project, name string, stack []string) (expansion, error) {
+	if slices.Contains(stack, name) {
+		return expansion{}, fmt.Errorf("named input %q refers to itself: %s", name, strings.Join(slices.Concat(stack, []string{name}), " → "))
+	}
+	inputs, ok := r.namedInputs(project)[name]
+	if !ok {
+		return expansion{}, fmt.Errorf("%q is not a named input of %s", name, project)
+	}
+	return r.expand(project, inputs, slices.Concat(stack, []string{name}))
+}
+
+func (r *Resolver) expand(project string, inputs []nxtypes.Input, stack []string) (expansion, error) {
+	var e expansion
+	for _, input := range inputs {
+		def := normalize(input)
+		switch {
+		case def.Fileset != nil:
+			e.filesets = append(e.filesets, *def.Fileset)
+		case def.Runtime != nil:
+			e.runtime = append(e.runtime, *def.Runtime)
+		case def.Env != nil:
+			e.env = append(e.env, *def.Env)
+		case def.ExternalDependencies != nil:
+			e.external = append(e.external, def.ExternalDependencies...)
+		case def.DependentTasksOutputFiles != nil:
+			e.outputs = append(e.outputs, *def.DependentTasksOutputFiles)
+		case def.Input != nil && def.Dependencies != nil && *def.Dependencies:
+			e.deps = append(e.deps, *def.Input)
+		case def.Input != nil && def.Projects != nil:
+			e.projectInputs = append(e.projectInputs, projectInput{projects: def.Projects.Patterns, input: *def.Input})
+		case def.Input != nil:
+			named, err := r.expandNamed(project, *def.Input, stack)
+			if err != nil {
+				return expansion{}, err
+			}
+			e.merge(named)
+		}
+	}
+	return e, nil
+}
+
+func (e *expansion) merge(other expansion) {
+	e.filesets = append(e.filesets, other.filesets...)
+	e.deps = append(e.deps, other.deps...)
+	e.projectInputs = append(e.projectInputs, other.projectInputs...)
+	e.env = append(e.env, other.env...)
+	e.runtime = append(e.runtime, other.runtime...)
+	e.external = append(e.external, other.external...)
+	e.outputs = append(e.outputs, other.outputs...)
+}
+
+// normalize turns a string input into its object form: "^name" is the named input of
+// the dependencies, strings starting with "{" or "!" are filesets, and anything else is
+// a named input.
+func normalize(input nxtypes.Input) nxtypes.InputDefinition {
+	if input.Definition != nil {
+		return *input.Definition
+	}
+	s := input.Shorthand
+	switch {
+	case strings.HasPrefix(s, "^"):
+		return nxtypes.InputDefinition{Input: ptr(s[1:]), Dependencies: ptr(true)}
+	case strings.HasPrefix(s, "{"), strings.HasPrefix(s, "!"):
+		return nxtypes.InputDefinition{Fileset: ptr(s)}
+	}
+	return nxtypes.InputDefinition{Input: ptr(s)}
+}
+
+// selectProjects returns the projects matching patterns: names, globs, tag: globs, and
+// the self and dependencies keywords.
+func (r *Resolver) selectProjects(project string, patterns []string) []string {
+	selected := map[string]bool{}
+	for _, pattern := range patterns {
+		switch {
+		case pattern == "self":
+			selected[project] = true
+		case pattern == "dependencies":
+			for _, dep := range r.graph.Dependencies(project) {
+				if !r.graph.IsExternal(dep) {
+					selected[dep] = true
+				}
+			}
+		case strings.HasPrefix(pattern, "tag:"):
+			tag := strings.TrimPrefix(pattern, "tag:")
+			for name, p := range r.projects {
+				if slices.ContainsFunc(p.Tags, func(t string) bool { return glob.Match(tag, t) }) {
+					selected[name] = true
+				}
+			}
+		default:
+			for name := range r.projects {
+				if glob.Match(pattern, name) {
+					selected[name] = true
+				}
+			}
+		}
+	}
+	return sortedKeys(selected)
+}
+
+// collector gathers expansions of several projects into a Result.
+type collector struct {
+	filesets map[string][]string
+	env      map[string]bool
+	runtime  map[string]bool
+	external map[string]bool
+	outputs  map[string]bool
+}
+
+func newCollector() *collector {
+	return &collector{
+		filesets: map[string][]string{},
+		env:      map[string]bool{},
+		runtime:  map[string]bool{},
+		external: map[string]bool{},
+		outputs:  map[string]bool{},
+	}
+}
+
+func (c *collector) add(project string, e expansion) {
+	c.filesets[project] = append(c.filesets[project], e.filesets...)
+	for _, v := range e.env {
+		c.env[v] = true
+	}
+	for _, v := range e.runtime {
+		c.runtime[v] = true
+	}
+	for _, v := range e.external {
+		if !strings.Contains(v, ":") {
+			v = "npm:" + v
+		}
+		c.external[v] = true
+	}
+	for _, v := range e.outputs {
+		c.outputs[v] = true
+	}
+}
+
+func (c *collector) result(r *Resolver, project string) *Result {
+	result := &Result{
+		Project:              project,
+		Env:                  sortedKeys(c.env),
+		Runtime:              sortedKeys(c.runtime),
+		ExternalDependencies: sortedKeys(c.external),
+		DependentOutputs:     sortedKeys(c.outputs),
+	}
+
+	files := map[string]File{}
+	owners := map[string]bool{}
+	for _, name := range sortedKeys(c.filesets) {
+		for _, f := range r.match(name, c.filesets[name]) {
+			if _, ok := files[f.Path]; !ok {
+				files[f.Path] = f
+			}
+			if f.Project != "" {
+				owners[f.Project] = true
+			}
+		}
+	}
+	for _, path := range sortedKeys(files) {
+		result.Files = append(result.Files, files[path])
+	}
+	result.Projects = sortedKeys(owners)
+	return result
+}
+
+// match returns the files the filesets of project cover. Filesets relative to the
+// project root match its files; {workspaceRoot} filesets match every file. Negated
+// filesets remove matches of the same kind, and project filesets that are all negated
+// start from every file of the project, like nx.
+func (r *Resolver) match(project string, filesets []string) []File {
+	root := r.projects[project].Root
+	tokens := targetconfig.Tokens{ProjectRoot: root, ProjectName: project}
+
+	var projectPositive, projectNegative, workspacePositive, workspaceNegative []string
+	for _, fileset := range filesets {
+		negated := strings.HasPrefix(fileset, "!")
+		pattern := strings.TrimPrefix(fileset, "!")
+		workspace := strings.HasPrefix(pattern, "{workspaceRoot}")
+		pattern = cleanPattern(tokens.Interpolate(pattern))
+		switch {
+		case workspace && negated:
+			workspaceNegative = append(workspaceNegative, pattern)
+		case workspace:
+			workspacePositive = append(workspacePositive, pattern)
+		case negated:
+			projectNegative = append(projectNegative, pattern)
+		default:
+			projectPositive = append(projectPositive, pattern)
+		}
+	}
+
+	var files []File
+	if len(projectPositive) > 0 || len(projectNegative) > 0 {
+		for _, f := range r.files[project] {
+			if filter(f.File, projectPositive, projectNegative, true) {
+				files = append(files, File{Path: f.File, Project: project, Hash: f.Hash})
+			}
+		}
+	}
+	if len(workspacePositive) > 0 {
+		for _, name := range sortedKeys(r.files) {
+			for _, f := range r.files[name] {
+				if filter(f.File, workspacePositive, workspaceNegative, false) {
+					files = append(files, File{Path: f.File, Project: name, Hash: f.Hash})
+				}
+			}
+		}
+		for _, file := range r.WorkspaceFiles {
+			if filter(file, workspacePositive, workspaceNegative, false) {
+				files = append(files, File{Path: file})
+			}
+		}
+	}
+	return files
+}
+
+func filter(file string, positive, negative []string, allIfNoPositive bool) bool {
+	matched := allIfNoPositive && len(positive) == 0
+	for _, pattern := range positive {
+		if matchGlob(pattern, file) {
+			matched = true
+			break
+		}
+	}
+	if !matched {
+		return false
+	}
+	for _, pattern := range negative {
+		if matchGlob(pattern, file) {
+			return false
+		}
+	}
+	return true
+}
+
+// cleanPattern makes an interpolated fileset relative to the workspace root, so a
+// project rooted at "." matches "./**/*" as "**/*".
+func cleanPattern(pattern string) string {
+	return strings.TrimPrefix(path.Clean("/"+pattern), "/")
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
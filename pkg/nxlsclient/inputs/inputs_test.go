@@ -0,0 +1,176 @@
+package inputs
+
+import (
+	"encoding/json"
+	"testing"
+
+	nxtypes "github.com/lazyengs/lazynx/pkg/nxlsclient/nx-types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const workspaceJSON = `{
+	"nxJson": {
+		"namedInputs": {
+			"sharedGlobals": ["{workspaceRoot}/babel.config.json", {"runtime": "node -v"}],
+			"production": ["default", "!{projectRoot}/**/*.spec.ts", "!{projectRoot}/jest.config.ts"]
+		},
+		"targetDefaults": {
+			"build": {"inputs": ["production", "^production", {"env": "API_URL"}]},
+			"test": {"inputs": ["default", "^production", {"externalDependencies": ["jest"]}]}
+		}
+	},
+	"projectGraph": {
+		"nodes": {
+			"app": {"type": "app", "name": "app", "data": {"root": "apps/app", "tags": ["scope:web"], "targets": {
+				"build": {}, "test": {}, "lint": {"inputs": ["{projectRoot}/**/*.{ts,json}", "sharedGlobals", {"input": "default", "projects": ["tag:scope:shared"]}]},
+				"serve": {}, "e2e": {"inputs": [{"dependentTasksOutputFiles": "**/*.d.ts"}, "missing"]}
+			}}},
+			"ui": {"type": "lib", "name": "ui", "data": {"root": "libs/ui", "tags": ["scope:shared"],
+				"namedInputs": {"production": ["{projectRoot}/src/**/*"], "loop": ["self"], "self": ["loop"]}, "targets": {}}},
+			"utils": {"type": "lib", "name": "utils", "data": {"root": "libs/utils", "targets": {}}}
+		},
+		"externalNodes": {
+			"npm:react": {"type": "npm", "name": "npm:react", "data": {"version": "18.0.0", "packageName": "react"}}
+		},
+		"dependencies": {
+			"app": [{"source": "app", "target": "ui", "type": "static"}, {"source": "app", "target": "npm:react", "type": "static"}],
+			"ui": [{"source": "ui", "target": "utils", "type": "static"}],
+			"utils": []
+		}
+	},
+	"projectFileMap": {
+		"app": [
+			{"file": "apps/app/src/main.ts", "hash": "a1"},
+			{"file": "apps/app/src/main.spec.ts", "hash": "a2"},
+			{"file": "apps/app/jest.config.ts", "hash": "a3"},
+			{"file": "apps/app/project.json", "hash": "a4"},
+			{"file": "apps/app/README.md", "hash": "a5"}
+		],
+		"ui": [
+			{"file": "libs/ui/src/button.tsx", "hash": "u1"},
+			{"file": "libs/ui/README.md", "hash": "u2"}
+		],
+		"utils": [
+			{"file": "libs/utils/src/index.ts", "hash": "t1"},
+			{"file": "libs/utils/src/index.spec.ts", "hash": "t2"}
+		]
+	}
+}`
+
+func newResolver(t *testing.T) *Resolver {
+	t.Helper()
+	var workspace nxtypes.NxWorkspace
+	require.NoError(t, json.Unmarshal([]byte(workspaceJSON), &workspace))
+	r, err := NewResolver(&workspace)
+	require.NoError(t, err)
+	r.WorkspaceFiles = []string{"babel.config.json", "package.json"}
+	return r
+}
+
+func paths(files []File) []string {
+	out := make([]string, len(files))
+	for i, f := range files {
+		out[i] = f.Path
+	}
+	return out
+}
+
+func TestResolveTargetDefaults(t *testing.T) {
+	r := newResolver(t)
+
+	// production drops the tests; ^production uses each dependency's own production
+	result, err := r.Resolve("app", "build")
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"apps/app/README.md",
+		"apps/app/project.json",
+		"apps/app/src/main.ts",
+		"libs/ui/src/button.tsx",
+		"libs/utils/src/index.ts",
+	}, paths(result.Files))
+	assert.Equal(t, File{Path: "libs/ui/src/button.tsx", Project: "ui", Hash: "u1"}, result.Files[3])
+	assert.Equal(t, []string{"API_URL"}, result.Env)
+	assert.Equal(t, []string{"npm:react"}, result.ExternalDependencies)
+	assert.Equal(t, []string{"app", "ui", "utils"}, result.Projects)
+
+	result, err = r.Resolve("app", "test")
+	require.NoError(t, err)
+	assert.Contains(t, paths(result.Files), "apps/app/src/main.spec.ts")
+	assert.Equal(t, []string{"npm:jest", "npm:react"}, result.ExternalDependencies)
+}
+
+func TestResolveFilesets(t *testing.T) {
+	r := newResolver(t)
+
+	result, err := r.Resolve("app", "lint")
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"apps/app/jest.config.ts",
+		"apps/app/project.json",
+		"apps/app/src/main.spec.ts",
+		"apps/app/src/main.ts",
+		"babel.config.json",
+		"libs/ui/README.md",
+		"libs/ui/src/button.tsx",
+	}, paths(result.Files))
+	assert.Equal(t, []string{"node -v"}, result.Runtime)
+	assert.Empty(t, result.ExternalDependencies)
+
+	// Targets without inputs use every file of the project and its dependencies
+	result, err = r.Resolve("app", "serve")
+	require.NoError(t, err)
+	assert.Len(t, result.Files, 9)
+
+	result, err = r.ResolveInputs("utils", []nxtypes.Input{{Shorthand: "!{projectRoot}/**/*.spec.ts"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"libs/utils/src/index.ts"}, paths(result.Files))
+}
+
+func TestResolveErrors(t *testing.T) {
+	r := newResolver(t)
+
+	_, err := r.Resolve("app", "e2e")
+	assert.EqualError(t, err, `failed to resolve the inputs of app:e2e: "missing" is not a named input of app`)
+
+	_, err = r.ResolveInputs("ui", []nxtypes.Input{{Shorthand: "loop"}})
+	assert.EqualError(t, err, `named input "loop" refers to itself: loop → self → loop`)
+
+	_, err = NewResolver(&nxtypes.NxWorkspace{})
+	assert.EqualError(t, err, "the workspace has no project file map")
+}
+
+func TestSelectProjects(t *testing.T) {
+	r := &Resolver{projects: map[string]nxtypes.ProjectConfiguration{
+		"@org/shop-app": {Tags: []string{"scope:web", "type:app"}},
+		"@org/ui":       {Tags: []string{"scope:shared"}},
+		"tools":         {},
+	}}
+
+	assert.Equal(t, []string{"@org/shop-app", "@org/ui", "tools"}, r.selectProjects("tools", []string{"*"}))
+	assert.Equal(t, []string{"@org/shop-app"}, r.selectProjects("tools", []string{"*-app"}))
+	assert.Equal(t, []string{"@org/shop-app", "@org/ui"}, r.selectProjects("tools", []string{"@org/*"}))
+	assert.Equal(t, []string{"@org/shop-app", "@org/ui"}, r.selectProjects("tools", []string{"tag:scope:*"}))
+	assert.Equal(t, []string{"@org/ui", "tools"}, r.selectProjects("tools", []string{"tag:scope:shared", "self"}))
+	assert.Empty(t, r.selectProjects("tools", []string{"tag:scope"}))
+}
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern, file string
+		want          bool
+	}{
+		{"libs/ui/**/*", "libs/ui/src/a/b.ts", true},
+		{"libs/ui/**/*", "libs/ui/README.md", true},
+		{"libs/ui/**/*", "libs/uix/a.ts", false},
+		{"**/*.spec.ts", "a/b/c.spec.ts", true},
+		{"**/*.{ts,tsx}", "a/b.tsx", true},
+		{"**/*.{ts,tsx}", "a/b.js", false},
+		{"libs/*/src/*.ts", "libs/ui/src/a.ts", true},
+		{"libs/*/src/*.ts", "libs/ui/src/x/a.ts", false},
+		{"**/.eslintrc.json", ".eslintrc.json", true},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, matchGlob(tt.pattern, tt.file), "%s ~ %s", tt.pattern, tt.file)
+	}
+}
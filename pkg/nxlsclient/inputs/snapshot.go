@@ -0,0 +1,202 @@
+package inputs
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Snapshot records the state of the inputs of a target, such as after a successful run,
+// to explain later why the target missed the cache.
+type Snapshot struct {
+	// FormatVersion is set to the FormatVersion of the package by Store.Save.
+	FormatVersion int `json:"formatVersion"`
+	// NxVersion is the Nx version of the workspace the snapshot was taken in.
+	NxVersion string    `json:"nxVersion,omitempty"`
+	Project   string    `json:"project"`
+	Target    string    `json:"target"`
+	TakenAt   time.Time `json:"takenAt"`
+	// Files maps the input files to their hashes.
+	Files map[string]string `json:"files"`
+	// ExternalDependencies maps the input packages to their versions.
+	ExternalDependencies map[string]string `json:"externalDependencies"`
+	// Env maps the input environment variables to a hash of their values, empty when
+	// unset. Values are hashed so snapshots never hold secrets.
+	Env map[string]string `json:"env"`
+}
+
+// Snapshot resolves the inputs of project:target and records their current state.
+func (r *Resolver) Snapshot(project, target string) (*Snapshot, error) {
+	result, err := r.Resolve(project, target)
+	if err != nil {
+		return nil, err
+	}
+	return r.SnapshotResult(result), nil
+}
+
+// SnapshotResult records the current state of resolved inputs.
+func (r *Resolver) SnapshotResult(result *Result) *Snapshot {
+	snap := &Snapshot{
+		NxVersion:            r.nxVersion,
+		Project:              result.Project,
+		Target:               result.Target,
+		TakenAt:              time.Now().UTC(),
+		Files:                make(map[string]string, len(result.Files)),
+		ExternalDependencies: make(map[string]string, len(result.ExternalDependencies)),
+		Env:                  make(map[string]string, len(result.Env)),
+	}
+	for _, f := range result.Files {
+		snap.Files[f.Path] = f.Hash
+		if f.Hash == "" && r.Root != "" {
+			snap.Files[f.Path] = hashFile(filepath.Join(r.Root, filepath.FromSlash(f.Path)))
+		}
+	}
+	for _, name := range result.ExternalDependencies {
+		snap.ExternalDependencies[name] = r.externalVersion(name)
+	}
+	for _, name := range result.Env {
+		snap.Env[name] = ""
+		if value, ok := r.LookupEnv(name); ok {
+			sum := sha256.Sum256([]byte(value))
+			snap.Env[name] = hex.EncodeToString(sum[:])
+		}
+	}
+	return snap
+}
+
+// hashFile returns the sha256 of a file, or an empty hash when it cannot be read.
+func hashFile(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// externalVersion returns the version of an external node, with its hash when nx
+// recorded one, so a reinstall of the same version still shows up.
+func (r *Resolver) externalVersion(name string) string {
+	node, ok := r.externals[name]
+	if !ok {
+		return ""
+	}
+	if node.Data.Hash != nil && *node.Data.Hash != "" && *node.Data.Hash != node.Data.Version {
+		return node.Data.Version + " (" + *node.Data.Hash + ")"
+	}
+	return node.Data.Version
+}
+
+// ChangeKind tells how an input changed between two snapshots.
+type ChangeKind string
+
+const (
+	ChangeAdded    ChangeKind = "added"
+	ChangeRemoved  ChangeKind = "removed"
+	ChangeModified ChangeKind = "modified"
+)
+
+// Change is an input that differs between two snapshots.
+type Change struct {
+	Kind ChangeKind
+	// Name is the file path, package or environment variable.
+	Name   string
+	Before string
+	After  string
+}
+
+// Diff is how the inputs of a target changed between two snapshots.
+type Diff struct {
+	Files                []Change
+	ExternalDependencies []Change
+	Env                  []Change
+}
+
+// Compare returns the changes from before to after. Each list is sorted by name.
+func Compare(before, after *Snapshot) *Diff {
+	return &Diff{
+		Files:                compareMaps(before.Files, after.Files),
+		ExternalDependencies: compareMaps(before.ExternalDependencies, after.ExternalDependencies),
+		Env:                  compareMaps(before.Env, after.Env),
+	}
+}
+
+func compareMaps(before, after map[string]string) []Change {
+	var changes []Change
+	for name, old := range before {
+		current, ok := after[name]
+		switch {
+		case !ok:
+			changes = append(changes, Change{Kind: ChangeRemoved, Name: name, Before: old})
+		case current != old:
+			changes = append(changes, Change{Kind: ChangeModified, Name: name, Before: old, After: current})
+		}
+	}
+	for name, current := range after {
+		if _, ok := before[name]; !ok {
+			changes = append(changes, Change{Kind: ChangeAdded, Name: name, After: current})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Name < changes[j].Name })
+	return changes
+}
+
+// Empty reports whether no input changed. The target can still miss the cache for
+// reasons a snapshot does not record, such as runtime inputs or dependency outputs.
+func (d *Diff) Empty() bool {
+	return len(d.Files) == 0 && len(d.ExternalDependencies) == 0 && len(d.Env) == 0
+}
+
+// Summary describes the diff in one line, such as "3 files, 1 package changed".
+func (d *Diff) Summary() string {
+	if d.Empty() {
+		return "no input changed"
+	}
+	var parts []string
+	add := func(n int, one, many string) {
+		switch {
+		case n == 1:
+			parts = append(parts, "1 "+one)
+		case n > 1:
+			parts = append(parts, fmt.Sprintf("%d %s", n, many))
+		}
+	}
+	add(len(d.Files), "file", "files")
+	add(len(d.ExternalDependencies), "package", "packages")
+	add(len(d.Env), "env var", "env vars")
+	return strings.Join(parts, ", ") + " changed"
+}
+
+// Lines describes every change, files first, then packages and env vars.
+func (d *Diff) Lines() []string {
+	var lines []string
+	for _, c := range d.Files {
+		lines = append(lines, string(c.Kind)+" "+c.Name)
+	}
+	for _, c := range d.ExternalDependencies {
+		switch c.Kind {
+		case ChangeModified:
+			lines = append(lines, fmt.Sprintf("package %s %s → %s", c.Name, c.Before, c.After))
+		default:
+			lines = append(lines, "package "+string(c.Kind)+" "+c.Name)
+		}
+	}
+	for _, c := range d.Env {
+		switch {
+		case c.Kind == ChangeModified && c.Before == "":
+			lines = append(lines, "env "+c.Name+" set")
+		case c.Kind == ChangeModified && c.After == "":
+			lines = append(lines, "env "+c.Name+" unset")
+		case c.Kind == ChangeModified:
+			lines = append(lines, "env "+c.Name+" changed")
+		default:
+			lines = append(lines, "env "+string(c.Kind)+" "+c.Name)
+		}
+	}
+	return lines
+}
@@ -0,0 +1,106 @@
+package inputs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnapshotCompare(t *testing.T) {
+	r := newResolver(t)
+	env := map[string]string{}
+	r.LookupEnv = func(key string) (string, bool) {
+		value, ok := env[key]
+		return value, ok
+	}
+
+	before, err := r.Snapshot("app", "build")
+	require.NoError(t, err)
+	assert.Equal(t, "u1", before.Files["libs/ui/src/button.tsx"])
+	assert.Equal(t, map[string]string{"npm:react": "18.0.0"}, before.ExternalDependencies)
+	assert.Equal(t, map[string]string{"API_URL": ""}, before.Env)
+	assert.True(t, Compare(before, before).Empty())
+
+	// Files outside the project file map are hashed from disk when the root is known
+	r.Root = t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(r.Root, "babel.config.json"), []byte("{}"), 0644))
+	lint, err := r.Snapshot("app", "lint")
+	require.NoError(t, err)
+	assert.Len(t, lint.Files["babel.config.json"], 64)
+	r.Root = ""
+
+	env["API_URL"] = "https://example.com"
+	r.files["ui"][0].Hash = "u3"
+	r.files["app"] = r.files["app"][:4]
+	node := r.externals["npm:react"]
+	node.Data.Version = "18.2.0"
+	r.externals["npm:react"] = node
+
+	after, err := r.Snapshot("app", "build")
+	require.NoError(t, err)
+	assert.NotContains(t, after.Env["API_URL"], "example", "env values are hashed")
+
+	diff := Compare(before, after)
+	assert.Equal(t, []Change{
+		{Kind: ChangeRemoved, Name: "apps/app/README.md", Before: "a5"},
+		{Kind: ChangeModified, Name: "libs/ui/src/button.tsx", Before: "u1", After: "u3"},
+	}, diff.Files)
+	assert.Equal(t, "2 files, 1 package, 1 env var changed", diff.Summary())
+	assert.Equal(t, []string{
+		"removed apps/app/README.md",
+		"modified libs/ui/src/button.tsx",
+		"package npm:react 18.0.0 → 18.2.0",
+		"env API_URL set",
+	}, diff.Lines())
+}
+
+func TestStore(t *testing.T) {
+	store := NewStore(t.TempDir())
+
+	_, err := store.Load("/repo", "app", "build")
+	assert.ErrorIs(t, err, ErrNoSnapshot)
+
+	snap := &Snapshot{Project: "app", Target: "build", Files: map[string]string{"apps/app/src/main.ts": "a1"}}
+	require.NoError(t, store.Save("/repo", snap))
+
+	loaded, err := store.Load("/repo", "app", "build")
+	require.NoError(t, err)
+	assert.Equal(t, snap.Files, loaded.Files)
+
+	// Snapshots are kept per workspace and target
+	_, err = store.Load("/other", "app", "build")
+	assert.ErrorIs(t, err, ErrNoSnapshot)
+	_, err = store.Load("/repo", "app", "test")
+	assert.ErrorIs(t, err, ErrNoSnapshot)
+}
+
+func TestStoreInvalidation(t *testing.T) {
+	store := NewStore(t.TempDir())
+	installed := "20.1.0"
+	store.InstalledNxVersion = func(string) (string, error) { return installed, nil }
+
+	snap := &Snapshot{NxVersion: "20.1.0", Project: "app", Target: "build"}
+	require.NoError(t, store.Save("/repo", snap))
+	_, err := store.Load("/repo", "app", "build")
+	require.NoError(t, err)
+
+	// Another Nx version may hash inputs differently
+	installed = "21.0.0"
+	_, err = store.Load("/repo", "app", "build")
+	var invalidated *InvalidatedError
+	require.ErrorAs(t, err, &invalidated)
+	assert.Equal(t, "taken with Nx 20.1.0, Nx 21.0.0 is installed", invalidated.Reason)
+	assert.ErrorIs(t, err, ErrNoSnapshot)
+	assert.NoFileExists(t, store.Path("/repo", "app", "build"))
+
+	// Files of another format are discarded
+	path := store.Path("/repo", "app", "build")
+	require.NoError(t, os.WriteFile(path, []byte(`{"project": "app", "target": "build"}`), 0o644))
+	_, err = store.Load("/repo", "app", "build")
+	require.ErrorAs(t, err, &invalidated)
+	assert.Equal(t, "format version 0, expected 1", invalidated.Reason)
+	assert.NoFileExists(t, path)
+}
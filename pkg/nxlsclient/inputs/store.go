@@ -0,0 +1,124 @@
+package inputs
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/lazyengs/lazynx/pkg/nxlsclient/internal/atomicfile"
+	"github.com/lazyengs/lazynx/pkg/nxlsclient/snapshot"
+)
+
+// FormatVersion is the version of the input snapshot file format. Snapshots written
+// with another version are discarded.
+const FormatVersion = 1
+
+// ErrNoSnapshot is returned by Store.Load when no snapshot was saved for a target.
+var ErrNoSnapshot = errors.New("no input snapshot")
+
+// InvalidatedError is returned by Store.Load when a snapshot existed but could no longer
+// be used. The snapshot file has been removed. It wraps ErrNoSnapshot.
+type InvalidatedError struct {
+	Reason string
+}
+
+func (e *InvalidatedError) Error() string {
+	return "input snapshot invalidated: " + e.Reason
+}
+
+func (e *InvalidatedError) Unwrap() error {
+	return ErrNoSnapshot
+}
+
+// Store keeps the last snapshot of each target of each workspace in a directory.
+type Store struct {
+	Dir string
+	// InstalledNxVersion returns the Nx version installed in a workspace, used to discard
+	// snapshots taken with another version, which may hash inputs differently. Empty
+	// results skip the check. Defaults to snapshot.InstalledNxVersion.
+	InstalledNxVersion func(workspacePath string) (string, error)
+}
+
+// NewStore creates a store that keeps snapshots in dir.
+func NewStore(dir string) *Store {
+	return &Store{Dir: dir, InstalledNxVersion: snapshot.InstalledNxVersion}
+}
+
+// Path returns the snapshot file of project:target in a workspace.
+func (s *Store) Path(workspacePath, project, target string) string {
+	workspace := workspacePath
+	if abs, err := filepath.Abs(workspacePath); err == nil {
+		workspace = abs
+	}
+	sum := sha256.Sum256([]byte(workspace + "\x00" + project + ":" + target))
+	return filepath.Join(s.Dir, hex.EncodeToString(sum[:8])+".json")
+}
+
+// Save replaces the snapshot of its target. The file is written atomically.
+func (s *Store) Save(workspacePath string, snap *Snapshot) error {
+	saved := *snap
+	saved.FormatVersion = FormatVersion
+	data, err := json.Marshal(saved)
+	if err != nil {
+		return fmt.Errorf("failed to encode input snapshot: %w", err)
+	}
+
+	if err := atomicfile.WriteFile(s.Path(workspacePath, snap.Project, snap.Target), data); err != nil {
+		return fmt.Errorf("failed to save input snapshot: %w", err)
+	}
+	return nil
+}
+
+// Load returns the snapshot of project:target. It returns ErrNoSnapshot when there is
+// none, and an *InvalidatedError, removing the file, when the snapshot is unreadable,
+// has another format version or was taken with another Nx version than the one
+// installed.
+func (s *Store) Load(workspacePath, project, target string) (*Snapshot, error) {
+	path := s.Path(workspacePath, project, target)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNoSnapshot
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read input snapshot: %w", err)
+	}
+
+	snap, reason := s.check(workspacePath, data)
+	if reason != "" {
+		_ = os.Remove(path)
+		return nil, &InvalidatedError{Reason: reason}
+	}
+	return snap, nil
+}
+
+// check decodes and validates a snapshot, returning why it cannot be used.
+func (s *Store) check(workspacePath string, data []byte) (*Snapshot, string) {
+	// Decode the version first, later formats may not decode as a Snapshot
+	var header struct {
+		FormatVersion int `json:"formatVersion"`
+	}
+	if err := json.Unmarshal(data, &header); err != nil {
+		return nil, "unreadable: " + err.Error()
+	}
+	if header.FormatVersion != FormatVersion {
+		return nil, fmt.Sprintf("format version %d, expected %d", header.FormatVersion, FormatVersion)
+	}
+
+	var snap Snapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return nil, "unreadable: " + err.Error()
+	}
+
+	if s.InstalledNxVersion != nil && snap.NxVersion != "" {
+		installed, err := s.InstalledNxVersion(workspacePath)
+		if err == nil && installed != "" && installed != snap.NxVersion {
+			return nil, fmt.Sprintf("taken with Nx %s, Nx %s is installed", snap.NxVersion, installed)
+		}
+	}
+
+	return &snap, ""
+}
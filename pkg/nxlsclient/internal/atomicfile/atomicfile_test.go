@@ -0,0 +1,34 @@
+package atomicfile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteFile(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "cache")
+	path := filepath.Join(dir, "snapshot.json")
+
+	require.NoError(t, WriteFile(path, []byte("first")))
+	require.NoError(t, WriteFile(path, []byte("second")))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "second", string(data))
+
+	// The temporary files are gone
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+
+	// A directory in the way fails the rename and leaves it alone
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "taken", "child"), 0o755))
+	assert.Error(t, WriteFile(filepath.Join(dir, "taken"), []byte("data")))
+	entries, err = os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 2)
+}
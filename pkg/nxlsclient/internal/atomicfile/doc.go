@@ -0,0 +1,12 @@
+/*
+Package atomicfile writes files that other processes may read at any time, such as the
+workspace and input snapshots.
+
+WriteFile writes to a temporary file next to the target and renames it over the target,
+so readers see the old or the new content, and a crash never leaves a truncated file:
+
+	if err := atomicfile.WriteFile(path, data); err != nil {
+		return fmt.Errorf("failed to save snapshot: %w", err)
+	}
+*/
+package atomicfile
@@ -0,0 +1,17 @@
+/*
+Package glob matches project, target and tag names against the patterns nx accepts in
+project selectors, dependsOn and targetDefaults keys.
+
+"*" matches any run of characters and "?" any single character. Unlike path.Match, both
+cross "/", which npm scoped project names such as "@org/ui" contain. "[...]" matches a
+character class, negated with "!" or "^".
+
+# Usage
+
+	glob.Match("*-app", "@org/shop-app") // true
+	glob.Match("tag:scope:*", "tag:scope:web") // true
+	glob.Match("build", "build:types") // false, no wildcard
+
+File patterns with "**" are matched by the inputs package instead.
+*/
+package glob
@@ -0,0 +1,90 @@
+package glob
+
+import "strings"
+
+// IsPattern reports whether pattern has wildcards.
+func IsPattern(pattern string) bool {
+	return strings.ContainsAny(pattern, "*?[")
+}
+
+// Match reports whether name matches pattern. A pattern without wildcards must equal
+// name. Malformed classes match nothing.
+func Match(pattern, name string) bool {
+	if !IsPattern(pattern) {
+		return pattern == name
+	}
+	return match([]rune(pattern), []rune(name))
+}
+
+// match walks pattern and name together, going back to the last star when they stop
+// matching so it can swallow one more character.
+func match(pattern, name []rune) bool {
+	p, n := 0, 0
+	star, starName := -1, 0
+	for n < len(name) {
+		if p < len(pattern) {
+			switch pattern[p] {
+			case '*':
+				star, starName = p, n
+				p++
+				continue
+			case '?':
+				p++
+				n++
+				continue
+			case '[':
+				matched, end, ok := matchClass(pattern[p:], name[n])
+				if !ok {
+					return false
+				}
+				if matched {
+					p += end
+					n++
+					continue
+				}
+			default:
+				if pattern[p] == name[n] {
+					p++
+					n++
+					continue
+				}
+			}
+		}
+		if star < 0 {
+			return false
+		}
+		starName++
+		p, n = star+1, starName
+	}
+
+	for p < len(pattern) && pattern[p] == '*' {
+		p++
+	}
+	return p == len(pattern)
+}
+
+// matchClass matches c against the class at the start of pattern, such as "[a-z]" or
+// "[!0-9]". It returns the length of the class, and ok false when it is not closed.
+func matchClass(pattern []rune, c rune) (matched bool, end int, ok bool) {
+	i := 1
+	negate := i < len(pattern) && (pattern[i] == '!' || pattern[i] == '^')
+	if negate {
+		i++
+	}
+	for first := true; i < len(pattern); first = false {
+		if pattern[i] == ']' && !first {
+			return matched != negate, i + 1, true
+		}
+		lo := pattern[i]
+		i++
+		hi := lo
+		if i+1 < len(pattern) && pattern[i] == '-' && pattern[i+1] != ']' {
+			hi = pattern[i+1]
+			i += 2
+		}
+		if lo <= c && c <= hi {
+			matched = true
+		}
+	}
+	return false, 0, false
+}
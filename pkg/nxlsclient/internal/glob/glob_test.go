@@ -0,0 +1,36 @@
+package glob
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		pattern, name string
+		want          bool
+	}{
+		{"build", "build", true},
+		{"build", "build:types", false},
+		{"e2e-ci*", "e2e-ci--smoke", true},
+		{"*", "@org/ui", true},
+		{"*", "", true},
+		{"*-app", "@org/foo-app", true},
+		{"*-app", "@org/foo-api", false},
+		{"@org/*", "@org/ui", true},
+		{"@org/*", "@other/ui", false},
+		{"*/ui", "@org/ui", true},
+		{"a*b*c", "axxbyyc", true},
+		{"a*b*c", "axxbyy", false},
+		{"scope:?eb", "scope:web", true},
+		{"scope:[a-w]eb", "scope:web", true},
+		{"scope:[!w]eb", "scope:web", false},
+		{"[]]", "]", true},
+		{"lib[", "lib[", false},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, Match(tt.pattern, tt.name), "%s ~ %s", tt.pattern, tt.name)
+	}
+}
@@ -0,0 +1,13 @@
+/*
+Package proc holds the process helpers shared by the nxls client and the task runner.
+
+Both start their children in a process group of their own, so signals reach everything
+the child spawned, such as the nx daemon or the processes of a task:
+
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	if err := cmd.Start(); err != nil {
+		// Handle error
+	}
+	_ = proc.SignalGroup(cmd.Process.Pid, syscall.SIGTERM)
+*/
+package proc
@@ -0,0 +1,24 @@
+package proc
+
+import (
+	"os/exec"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignalGroup(t *testing.T) {
+	// The child of the shell is in the same group and must be signaled too
+	cmd := exec.Command("sh", "-c", "sleep 30 & wait")
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	require.NoError(t, cmd.Start())
+
+	require.NoError(t, SignalGroup(cmd.Process.Pid, syscall.SIGKILL))
+	assert.Error(t, cmd.Wait())
+
+	// The group is gone
+	assert.NoError(t, SignalGroup(cmd.Process.Pid, syscall.SIGKILL))
+	assert.EqualError(t, SignalGroup(0, syscall.SIGTERM), "invalid pid 0")
+}
@@ -0,0 +1,32 @@
+/*
+Package metrics provides an in-process registry of nxls request and notification metrics.
+
+The Registry records, per JSON-RPC method:
+
+  - Request latency histograms
+  - Request and error counts
+  - Requests currently in flight
+  - Notification counts and rates
+  - Response and notification payload sizes
+
+# Usage
+
+Attach a Registry to a client before starting it:
+
+	registry := metrics.NewRegistry()
+	client := nxlsclient.NewClient(workspacePath, false)
+	client.Metrics = registry
+
+	// Expose the metrics as an expvar under /debug/vars
+	registry.PublishExpvar("nxls")
+
+	// Or serve them in the Prometheus text format
+	http.Handle("/metrics", registry.Handler())
+
+Snapshot returns a point-in-time copy that is safe to render, for example in a debug panel:
+
+	for _, m := range registry.Snapshot().Requests {
+		fmt.Printf("%s: %d requests, p95 %s\n", m.Method, m.Count, m.Quantile(0.95))
+	}
+*/
+package metrics
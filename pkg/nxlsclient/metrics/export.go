@@ -0,0 +1,99 @@
+package metrics
+
+import (
+	"bufio"
+	"expvar"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// prometheusContentType is the content type of the Prometheus text exposition format.
+const prometheusContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// WritePrometheus writes the metrics in the Prometheus text exposition format.
+func (r *Registry) WritePrometheus(w io.Writer) error {
+	snap := r.Snapshot()
+	bw := bufio.NewWriter(w)
+
+	writeHeader(bw, "nxls_requests_total", "counter", "Number of completed nxls requests.")
+	for _, m := range snap.Requests {
+		fmt.Fprintf(bw, "nxls_requests_total{method=%s} %d\n", quoteLabel(m.Method), m.Count)
+	}
+
+	writeHeader(bw, "nxls_request_errors_total", "counter", "Number of failed nxls requests.")
+	for _, m := range snap.Requests {
+		fmt.Fprintf(bw, "nxls_request_errors_total{method=%s} %d\n", quoteLabel(m.Method), m.Errors)
+	}
+
+	writeHeader(bw, "nxls_requests_in_flight", "gauge", "Number of nxls requests waiting for a response.")
+	for _, m := range snap.Requests {
+		fmt.Fprintf(bw, "nxls_requests_in_flight{method=%s} %d\n", quoteLabel(m.Method), m.InFlight)
+	}
+
+	writeHeader(bw, "nxls_request_duration_seconds", "histogram", "Latency of nxls requests.")
+	for _, m := range snap.Requests {
+		writeHistogram(bw, "nxls_request_duration_seconds", m.Method, m.Latency)
+	}
+
+	writeHeader(bw, "nxls_response_size_bytes", "histogram", "Size of successful nxls responses.")
+	for _, m := range snap.Requests {
+		writeHistogram(bw, "nxls_response_size_bytes", m.Method, m.ResponseBytes)
+	}
+
+	writeHeader(bw, "nxls_notifications_total", "counter", "Number of notifications received from nxls.")
+	for _, m := range snap.Notifications {
+		fmt.Fprintf(bw, "nxls_notifications_total{method=%s} %d\n", quoteLabel(m.Method), m.Count)
+	}
+
+	writeHeader(bw, "nxls_notification_size_bytes", "histogram", "Size of notifications received from nxls.")
+	for _, m := range snap.Notifications {
+		writeHistogram(bw, "nxls_notification_size_bytes", m.Method, m.Bytes)
+	}
+
+	return bw.Flush()
+}
+
+func writeHeader(w io.Writer, name, kind, help string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+func writeHistogram(w io.Writer, name, method string, h Histogram) {
+	label := quoteLabel(method)
+	var cumulative uint64
+	for i, bound := range h.Bounds {
+		cumulative += h.Counts[i]
+		fmt.Fprintf(w, "%s_bucket{method=%s,le=%q} %d\n", name, label, strconv.FormatFloat(bound, 'g', -1, 64), cumulative)
+	}
+	fmt.Fprintf(w, "%s_bucket{method=%s,le=\"+Inf\"} %d\n", name, label, h.Count)
+	fmt.Fprintf(w, "%s_sum{method=%s} %s\n", name, label, strconv.FormatFloat(h.Sum, 'g', -1, 64))
+	fmt.Fprintf(w, "%s_count{method=%s} %d\n", name, label, h.Count)
+}
+
+// labelEscaper escapes label values as required by the text exposition format.
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func quoteLabel(v string) string {
+	return `"` + labelEscaper.Replace(v) + `"`
+}
+
+// Handler serves the metrics in the Prometheus text exposition format.
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", prometheusContentType)
+		if err := r.WritePrometheus(w); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
+
+// PublishExpvar exposes the registry snapshot as the expvar name, served under
+// /debug/vars by the expvar handler. Like expvar.Publish, it panics when name is
+// already taken.
+func (r *Registry) PublishExpvar(name string) {
+	expvar.Publish(name, expvar.Func(func() any {
+		return r.Snapshot()
+	}))
+}
@@ -0,0 +1,77 @@
+package metrics
+
+import "sort"
+
+// histogram counts observations into fixed buckets. It is not safe for concurrent use;
+// the Registry guards it.
+type histogram struct {
+	bounds []float64
+	counts []uint64 // counts[i] holds observations <= bounds[i]; the last entry is +Inf
+	sum    float64
+	count  uint64
+}
+
+func newHistogram(bounds []float64) *histogram {
+	return &histogram{bounds: bounds, counts: make([]uint64, len(bounds)+1)}
+}
+
+func (h *histogram) observe(v float64) {
+	i := sort.SearchFloat64s(h.bounds, v)
+	h.counts[i]++
+	h.sum += v
+	h.count++
+}
+
+func (h *histogram) snapshot() Histogram {
+	counts := make([]uint64, len(h.counts))
+	copy(counts, h.counts)
+	return Histogram{Bounds: h.bounds, Counts: counts, Sum: h.sum, Count: h.count}
+}
+
+// Histogram is a copy of a bucketed distribution.
+type Histogram struct {
+	// Bounds are the inclusive upper bounds of the buckets.
+	Bounds []float64 `json:"bounds"`
+	// Counts holds one entry per bound plus a final +Inf bucket. Counts are not cumulative.
+	Counts []uint64 `json:"counts"`
+	Sum    float64  `json:"sum"`
+	Count  uint64   `json:"count"`
+}
+
+// Mean returns the average observation, or 0 when there is none.
+func (h Histogram) Mean() float64 {
+	if h.Count == 0 {
+		return 0
+	}
+	return h.Sum / float64(h.Count)
+}
+
+// Quantile estimates the q-quantile by linear interpolation inside the bucket that
+// contains it. Observations in the +Inf bucket are reported as the largest bound.
+func (h Histogram) Quantile(q float64) float64 {
+	if h.Count == 0 {
+		return 0
+	}
+	q = min(max(q, 0), 1)
+
+	rank := q * float64(h.Count)
+	var seen float64
+	for i, c := range h.Counts {
+		if c == 0 {
+			continue
+		}
+		if seen+float64(c) < rank {
+			seen += float64(c)
+			continue
+		}
+		if i == len(h.Bounds) {
+			return h.Bounds[len(h.Bounds)-1]
+		}
+		lower := 0.0
+		if i > 0 {
+			lower = h.Bounds[i-1]
+		}
+		return lower + (h.Bounds[i]-lower)*(rank-seen)/float64(c)
+	}
+	return h.Bounds[len(h.Bounds)-1]
+}
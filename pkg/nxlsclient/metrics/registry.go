@@ -0,0 +1,189 @@
+package metrics
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// LatencyBuckets are the upper bounds, in seconds, of the request latency histograms.
+var LatencyBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+
+// SizeBuckets are the upper bounds, in bytes, of the payload size histograms. Workspace
+// payloads of large monorepos easily reach tens of megabytes.
+var SizeBuckets = []float64{1 << 10, 10 << 10, 100 << 10, 1 << 20, 10 << 20, 50 << 20, 100 << 20}
+
+// Registry collects request and notification metrics. It is safe for concurrent use and
+// implements commands.RequestObserver.
+type Registry struct {
+	mu            sync.Mutex
+	started       time.Time
+	requests      map[string]*requestStats
+	notifications map[string]*notificationStats
+	now           func() time.Time
+}
+
+type requestStats struct {
+	count    uint64
+	errors   uint64
+	inFlight int64
+	latency  *histogram
+	size     *histogram
+}
+
+type notificationStats struct {
+	count    uint64
+	size     *histogram
+	lastSeen time.Time
+}
+
+// NewRegistry creates an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		started:       time.Now(),
+		requests:      make(map[string]*requestStats),
+		notifications: make(map[string]*notificationStats),
+		now:           time.Now,
+	}
+}
+
+// request returns the stats of method, creating them if needed. The caller must hold mu.
+func (r *Registry) request(method string) *requestStats {
+	s, ok := r.requests[method]
+	if !ok {
+		s = &requestStats{latency: newHistogram(LatencyBuckets), size: newHistogram(SizeBuckets)}
+		r.requests[method] = s
+	}
+	return s
+}
+
+// RequestStarted records a request that is about to be sent.
+func (r *Registry) RequestStarted(method string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.request(method).inFlight++
+}
+
+// RequestFinished records a completed request. responseBytes is the size of the raw
+// result and is only recorded for successful requests.
+func (r *Registry) RequestFinished(method string, duration time.Duration, responseBytes int, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s := r.request(method)
+	if s.inFlight > 0 {
+		s.inFlight--
+	}
+	s.count++
+	s.latency.observe(duration.Seconds())
+	if err != nil {
+		s.errors++
+		return
+	}
+	s.size.observe(float64(responseBytes))
+}
+
+// NotificationReceived records a notification sent by the server.
+func (r *Registry) NotificationReceived(method string, payloadBytes int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s, ok := r.notifications[method]
+	if !ok {
+		s = &notificationStats{size: newHistogram(SizeBuckets)}
+		r.notifications[method] = s
+	}
+	s.count++
+	s.size.observe(float64(payloadBytes))
+	s.lastSeen = r.now()
+}
+
+// Reset discards everything recorded so far.
+func (r *Registry) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.started = r.now()
+	r.requests = make(map[string]*requestStats)
+	r.notifications = make(map[string]*notificationStats)
+}
+
+// Snapshot is a point-in-time copy of a Registry.
+type Snapshot struct {
+	Since         time.Time             `json:"since"`
+	TakenAt       time.Time             `json:"takenAt"`
+	Requests      []RequestMetrics      `json:"requests"`
+	Notifications []NotificationMetrics `json:"notifications"`
+}
+
+// RequestMetrics are the metrics of a single request method.
+type RequestMetrics struct {
+	Method        string    `json:"method"`
+	Count         uint64    `json:"count"`
+	Errors        uint64    `json:"errors"`
+	InFlight      int64     `json:"inFlight"`
+	Latency       Histogram `json:"latencySeconds"`
+	ResponseBytes Histogram `json:"responseBytes"`
+}
+
+// LatencyQuantile estimates the q-quantile of the request latency.
+func (m RequestMetrics) LatencyQuantile(q float64) time.Duration {
+	return time.Duration(m.Latency.Quantile(q) * float64(time.Second))
+}
+
+// MeanLatency returns the average request latency.
+func (m RequestMetrics) MeanLatency() time.Duration {
+	return time.Duration(m.Latency.Mean() * float64(time.Second))
+}
+
+// NotificationMetrics are the metrics of a single notification method.
+type NotificationMetrics struct {
+	Method   string    `json:"method"`
+	Count    uint64    `json:"count"`
+	Bytes    Histogram `json:"bytes"`
+	LastSeen time.Time `json:"lastSeen"`
+	// RatePerMinute is the average rate since the registry was created or reset.
+	RatePerMinute float64 `json:"ratePerMinute"`
+}
+
+// Snapshot copies the current metrics, sorted by method.
+func (r *Registry) Snapshot() Snapshot {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	snap := Snapshot{
+		Since:         r.started,
+		TakenAt:       r.now(),
+		Requests:      make([]RequestMetrics, 0, len(r.requests)),
+		Notifications: make([]NotificationMetrics, 0, len(r.notifications)),
+	}
+
+	for method, s := range r.requests {
+		snap.Requests = append(snap.Requests, RequestMetrics{
+			Method:        method,
+			Count:         s.count,
+			Errors:        s.errors,
+			InFlight:      s.inFlight,
+			Latency:       s.latency.snapshot(),
+			ResponseBytes: s.size.snapshot(),
+		})
+	}
+
+	elapsed := snap.TakenAt.Sub(snap.Since).Minutes()
+	for method, s := range r.notifications {
+		m := NotificationMetrics{
+			Method:   method,
+			Count:    s.count,
+			Bytes:    s.size.snapshot(),
+			LastSeen: s.lastSeen,
+		}
+		if elapsed > 0 {
+			m.RatePerMinute = float64(s.count) / elapsed
+		}
+		snap.Notifications = append(snap.Notifications, m)
+	}
+
+	sort.Slice(snap.Requests, func(i, j int) bool { return snap.Requests[i].Method < snap.Requests[j].Method })
+	sort.Slice(snap.Notifications, func(i, j int) bool { return snap.Notifications[i].Method < snap.Notifications[j].Method })
+
+	return snap
+}
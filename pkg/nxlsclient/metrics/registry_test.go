@@ -0,0 +1,118 @@
+package metrics
+
+import (
+	"errors"
+	"expvar"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistryRequests(t *testing.T) {
+	r := NewRegistry()
+
+	r.RequestStarted("nx/workspace")
+	r.RequestStarted("nx/workspace")
+	snap := r.Snapshot()
+	require.Len(t, snap.Requests, 1)
+	assert.Equal(t, int64(2), snap.Requests[0].InFlight)
+
+	r.RequestFinished("nx/workspace", 20*time.Millisecond, 2<<20, nil)
+	r.RequestFinished("nx/workspace", 3*time.Second, 0, errors.New("boom"))
+
+	snap = r.Snapshot()
+	m := snap.Requests[0]
+	assert.Equal(t, "nx/workspace", m.Method)
+	assert.Equal(t, uint64(2), m.Count)
+	assert.Equal(t, uint64(1), m.Errors)
+	assert.Equal(t, int64(0), m.InFlight)
+	assert.Equal(t, uint64(2), m.Latency.Count)
+	// Only successful responses are sized
+	assert.Equal(t, uint64(1), m.ResponseBytes.Count)
+	assert.Equal(t, float64(2<<20), m.ResponseBytes.Sum)
+	assert.InDelta(t, 1.51, m.MeanLatency().Seconds(), 0.001)
+}
+
+func TestRegistryNotifications(t *testing.T) {
+	r := NewRegistry()
+	start := time.Now()
+	r.now = func() time.Time { return start.Add(2 * time.Minute) }
+	r.started = start
+
+	for range 4 {
+		r.NotificationReceived("nx/refreshWorkspace", 10)
+	}
+	r.NotificationReceived("window/logMessage", 100)
+
+	snap := r.Snapshot()
+	require.Len(t, snap.Notifications, 2)
+	assert.Equal(t, "nx/refreshWorkspace", snap.Notifications[0].Method)
+	assert.Equal(t, uint64(4), snap.Notifications[0].Count)
+	assert.InDelta(t, 2.0, snap.Notifications[0].RatePerMinute, 0.001)
+	assert.Equal(t, start.Add(2*time.Minute), snap.Notifications[0].LastSeen)
+
+	r.Reset()
+	assert.Empty(t, r.Snapshot().Notifications)
+}
+
+func TestHistogramQuantile(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+		q      float64
+		want   float64
+	}{
+		{"empty", nil, 0.5, 0},
+		{"single bucket", []float64{0.5, 0.5}, 0.5, 0.5},
+		{"upper bucket", []float64{0.5, 1.5, 1.5, 1.5}, 0.5, 1.0 + 1.0/3},
+		{"overflow", []float64{10}, 0.99, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newHistogram([]float64{1, 2})
+			for _, v := range tt.values {
+				h.observe(v)
+			}
+			assert.InDelta(t, tt.want, h.snapshot().Quantile(tt.q), 0.0001)
+		})
+	}
+}
+
+func TestWritePrometheus(t *testing.T) {
+	r := NewRegistry()
+	r.RequestStarted("nx/workspace")
+	r.RequestFinished("nx/workspace", 30*time.Millisecond, 2048, nil)
+	r.NotificationReceived(`odd"method`, 1)
+
+	var out strings.Builder
+	require.NoError(t, r.WritePrometheus(&out))
+	text := out.String()
+
+	assert.Contains(t, text, "# TYPE nxls_request_duration_seconds histogram\n")
+	assert.Contains(t, text, `nxls_requests_total{method="nx/workspace"} 1`)
+	assert.Contains(t, text, `nxls_request_duration_seconds_bucket{method="nx/workspace",le="0.025"} 0`)
+	assert.Contains(t, text, `nxls_request_duration_seconds_bucket{method="nx/workspace",le="0.05"} 1`)
+	assert.Contains(t, text, `nxls_request_duration_seconds_bucket{method="nx/workspace",le="+Inf"} 1`)
+	assert.Contains(t, text, `nxls_response_size_bytes_sum{method="nx/workspace"} 2048`)
+	assert.Contains(t, text, `nxls_notifications_total{method="odd\"method"} 1`)
+
+	rec := httptest.NewRecorder()
+	r.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	assert.Equal(t, prometheusContentType, rec.Header().Get("Content-Type"))
+	assert.Equal(t, text, rec.Body.String())
+}
+
+func TestPublishExpvar(t *testing.T) {
+	r := NewRegistry()
+	r.RequestFinished("nx/workspacePath", time.Millisecond, 10, nil)
+	r.PublishExpvar("nxls_test")
+
+	v := expvar.Get("nxls_test")
+	require.NotNil(t, v)
+	assert.Contains(t, v.String(), `"method":"nx/workspacePath"`)
+}
@@ -63,3 +63,7 @@ type CIPEInfoError struct {
 	Message string        `json:"message"`
 	Type    CIPEErrorType `json:"type"`
 }
+
+func (e *CIPEInfoError) Error() string {
+	return string(e.Type) + " error: " + e.Message
+}
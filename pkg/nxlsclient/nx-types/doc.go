@@ -63,6 +63,16 @@ ProjectConfiguration contains the configuration for a single project:
 		Name        string              `json:"name,omitempty"`
 	}
 
+## Project Details
+
+PDVData holds the Project Details View payloads of a file as serialized JSON. Decode
+turns them into a PDVResult, or a *PDVError for results without project details:
+
+	result, err := data.Decode()
+	for _, project := range result.Projects {
+		fmt.Println(project.Project.Name, len(project.SourceMap))
+	}
+
 ## Generator Schemas
 
 GeneratorSchema represents an Nx generator schema:
@@ -2,7 +2,7 @@ package nxtypes
 
 type NxJsonConfiguration struct {
 	DefaultProject  *string                      `json:"defaultProject,omitempty"`
-	NamedInputs     map[string][]interface{}     `json:"namedInputs,omitempty"`
+	NamedInputs     map[string][]Input           `json:"namedInputs,omitempty"`
 	Installation    *NxInstallationConfiguration `json:"installation,omitempty"`
 	Release         *NxReleaseConfiguration      `json:"release,omitempty"`
 	Affected        *NxAffectedConfig            `json:"affected,omitempty"`
@@ -12,8 +12,8 @@ type NxJsonConfiguration struct {
 		AppsDir *string `json:"appsDir,omitempty"`
 	} `json:"workspaceLayout,omitempty"`
 	TasksRunnerOptions map[string]struct {
-		Runner  *string     `json:"runner,omitempty"`
-		Options interface{} `json:"options,omitempty"`
+		Runner  *string `json:"runner,omitempty"`
+		Options Options `json:"options,omitzero"`
 	} `json:"tasksRunnerOptions,omitempty"`
 	Generators map[string]map[string]interface{} `json:"generators,omitempty"`
 	Cli        *struct {
@@ -52,10 +52,10 @@ type NxReleaseChangelogConfiguration struct {
 }
 
 type NxReleaseConfiguration struct {
-	Projects interface{} `json:"projects,omitempty"` // []string or string
+	Projects *ProjectSelector `json:"projects,omitempty"`
 	Groups   map[string]struct {
 		ProjectsRelationship *string                        `json:"projectsRelationship,omitempty"`
-		Projects             interface{}                    `json:"projects"` // []string or string
+		Projects             ProjectSelector                `json:"projects"`
 		Version              *NxReleaseVersionConfiguration `json:"version,omitempty"`
 		Changelog            interface{}                    `json:"changelog,omitempty"` // bool or NxReleaseChangelogConfiguration
 		ReleaseTagPattern    *string                        `json:"releaseTagPattern,omitempty"`
@@ -117,11 +117,9 @@ type NxAffectedConfig struct {
 
 type TargetDefaults map[string]TargetConfiguration
 
-type PluginConfiguration interface{} // string or ExpandedPluginConfiguration
-
 type ExpandedPluginConfiguration struct {
-	Plugin  string      `json:"plugin"`
-	Options interface{} `json:"options,omitempty"`
-	Include []string    `json:"include,omitempty"`
-	Exclude []string    `json:"exclude,omitempty"`
+	Plugin  string   `json:"plugin"`
+	Options Options  `json:"options,omitzero"`
+	Include []string `json:"include,omitempty"`
+	Exclude []string `json:"exclude,omitempty"`
 }
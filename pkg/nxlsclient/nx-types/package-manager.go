@@ -1,3 +1,10 @@
 package nxtypes
 
 type PackageManager string
+
+const (
+	PackageManagerNpm  PackageManager = "npm"
+	PackageManagerYarn PackageManager = "yarn"
+	PackageManagerPnpm PackageManager = "pnpm"
+	PackageManagerBun  PackageManager = "bun"
+)
@@ -1,5 +1,12 @@
 package nxtypes
 
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+)
+
 type PDVResultType string
 
 const (
@@ -18,3 +25,118 @@ type PDVData struct {
 	ErrorsSerialized       string            `json:"errorsSerialized,omitempty"`
 	ErrorMessage           string            `json:"errorMessage,omitempty"`
 }
+
+var (
+	// ErrPDVNoGraph is returned when nxls could not compute the project graph.
+	ErrPDVNoGraph = errors.New("no project graph")
+	// ErrPDVOldNxVersion is returned when the workspace Nx version is too old for the
+	// Project Details View.
+	ErrPDVOldNxVersion = errors.New("nx version does not support project details")
+	// ErrPDVFailed is returned when nxls failed to compute the project details.
+	ErrPDVFailed = errors.New("failed to compute project details")
+)
+
+// PDVError is a PDVData result that carries no project details. It unwraps to one of
+// ErrPDVNoGraph, ErrPDVOldNxVersion or ErrPDVFailed.
+type PDVError struct {
+	ResultType PDVResultType
+	Message    string
+	// Errors are the workspace errors nxls reported along with the result.
+	Errors []NxError
+}
+
+func (e *PDVError) Error() string {
+	msg := e.Unwrap().Error()
+	if e.Message != "" {
+		msg += ": " + e.Message
+	}
+	return msg
+}
+
+func (e *PDVError) Unwrap() error {
+	switch e.ResultType {
+	case PDVResultTypeNoGraphError:
+		return ErrPDVNoGraph
+	case PDVResultTypeOldNxVersion:
+		return ErrPDVOldNxVersion
+	}
+	return ErrPDVFailed
+}
+
+// PDVProject is the Project Details View payload of a single project.
+type PDVProject struct {
+	Project ProjectGraphProjectNode `json:"project"`
+	// SourceMap tells which file and plugin set each property of the project, keyed
+	// like the entries of ConfigurationSourceMaps.
+	SourceMap                  map[string]SourceInformation `json:"sourceMap,omitempty"`
+	Errors                     []NxError                    `json:"errors,omitempty"`
+	ConnectedToCloud           *bool                        `json:"connectedToCloud,omitempty"`
+	DisabledTaskSyncGenerators []string                     `json:"disabledTaskSyncGenerators,omitempty"`
+}
+
+// PDVResult is a decoded PDVData.
+type PDVResult struct {
+	GraphBasePath string
+	// Projects has one entry for SUCCESS and one per project, sorted by name, for
+	// SUCCESS_MULTI, where a single file defines several projects.
+	Projects []PDVProject
+	// Errors are workspace errors that did not prevent computing the details.
+	Errors []NxError
+}
+
+// Decode unpacks the serialized payloads of d. Results without project details
+// return a *PDVError.
+func (d *PDVData) Decode() (*PDVResult, error) {
+	errs, err := d.decodeErrors()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &PDVResult{GraphBasePath: d.GraphBasePath, Errors: errs}
+	switch d.ResultType {
+	case PDVResultTypeSuccess:
+		project, err := decodePDVProject(d.PDVDataSerialized)
+		if err != nil {
+			return nil, err
+		}
+		result.Projects = []PDVProject{project}
+	case PDVResultTypeSuccessMulti:
+		names := make([]string, 0, len(d.PDVDataSerializedMulti))
+		for name := range d.PDVDataSerializedMulti {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			project, err := decodePDVProject(d.PDVDataSerializedMulti[name])
+			if err != nil {
+				return nil, fmt.Errorf("project %s: %w", name, err)
+			}
+			result.Projects = append(result.Projects, project)
+		}
+	default:
+		return nil, &PDVError{ResultType: d.ResultType, Message: d.ErrorMessage, Errors: errs}
+	}
+	return result, nil
+}
+
+func (d *PDVData) decodeErrors() ([]NxError, error) {
+	if d.ErrorsSerialized == "" {
+		return nil, nil
+	}
+	var errs []NxError
+	if err := json.Unmarshal([]byte(d.ErrorsSerialized), &errs); err != nil {
+		return nil, fmt.Errorf("failed to decode project details errors: %w", err)
+	}
+	return errs, nil
+}
+
+func decodePDVProject(serialized string) (PDVProject, error) {
+	var project PDVProject
+	if serialized == "" {
+		return project, errors.New("failed to decode project details: empty payload")
+	}
+	if err := json.Unmarshal([]byte(serialized), &project); err != nil {
+		return project, fmt.Errorf("failed to decode project details: %w", err)
+	}
+	return project, nil
+}
@@ -0,0 +1,79 @@
+package nxtypes
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func serialize(t *testing.T, v any) string {
+	t.Helper()
+	data, err := json.Marshal(v)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestPDVDataDecode(t *testing.T) {
+	app := `{"project":{"type":"app","name":"web","data":{"root":"apps/web","targets":{"build":{"executor":"nx:run-commands"}}}},` +
+		`"sourceMap":{"root":["apps/web/project.json","nx/core/project-json"],"targets.build":[null,"@nx/vite/plugin"]},"connectedToCloud":true}`
+	lib := `{"project":{"type":"lib","name":"ui","data":{"root":"libs/ui"}}}`
+	errs := serialize(t, []map[string]string{{"name": "ProjectsWithNoNameError", "message": "missing name", "file": "libs/x/project.json"}})
+
+	t.Run("success", func(t *testing.T) {
+		data := PDVData{ResultType: PDVResultTypeSuccess, GraphBasePath: "/tmp/graph", PDVDataSerialized: app, ErrorsSerialized: errs}
+		result, err := data.Decode()
+		require.NoError(t, err)
+
+		assert.Equal(t, "/tmp/graph", result.GraphBasePath)
+		require.Len(t, result.Projects, 1)
+		project := result.Projects[0]
+		assert.Equal(t, "web", project.Project.Name)
+		assert.Equal(t, "apps/web", project.Project.Data.Root)
+		assert.Contains(t, project.Project.Data.Targets, "build")
+		assert.Equal(t, ptr("apps/web/project.json"), project.SourceMap["root"].File)
+		assert.Nil(t, project.SourceMap["targets.build"].File)
+		assert.Equal(t, "@nx/vite/plugin", project.SourceMap["targets.build"].Plugin)
+		assert.Equal(t, ptr(true), project.ConnectedToCloud)
+		require.Len(t, result.Errors, 1)
+		assert.Equal(t, ptr("missing name"), result.Errors[0].Message)
+	})
+
+	t.Run("success multi", func(t *testing.T) {
+		data := PDVData{ResultType: PDVResultTypeSuccessMulti, PDVDataSerializedMulti: map[string]string{"web": app, "ui": lib}}
+		result, err := data.Decode()
+		require.NoError(t, err)
+		require.Len(t, result.Projects, 2)
+		assert.Equal(t, "ui", result.Projects[0].Project.Name)
+		assert.Equal(t, "web", result.Projects[1].Project.Name)
+	})
+
+	t.Run("malformed payload", func(t *testing.T) {
+		data := PDVData{ResultType: PDVResultTypeSuccessMulti, PDVDataSerializedMulti: map[string]string{"web": "{"}}
+		_, err := data.Decode()
+		assert.ErrorContains(t, err, "project web: failed to decode project details")
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		tests := []struct {
+			resultType PDVResultType
+			want       error
+		}{
+			{PDVResultTypeNoGraphError, ErrPDVNoGraph},
+			{PDVResultTypeOldNxVersion, ErrPDVOldNxVersion},
+			{PDVResultTypeError, ErrPDVFailed},
+		}
+		for _, tt := range tests {
+			data := PDVData{ResultType: tt.resultType, ErrorMessage: "boom", ErrorsSerialized: errs}
+			_, err := data.Decode()
+			assert.True(t, errors.Is(err, tt.want), tt.resultType)
+
+			var pdvErr *PDVError
+			require.True(t, errors.As(err, &pdvErr))
+			assert.Len(t, pdvErr.Errors, 1)
+			assert.Equal(t, tt.want.Error()+": boom", err.Error())
+		}
+	})
+}
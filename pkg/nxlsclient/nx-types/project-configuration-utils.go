@@ -1,5 +1,36 @@
 package nxtypes
 
-type SourceInformation [2]interface{} // [string|null, string]
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// SourceInformation tells which file and plugin set a configuration property. Nx
+// writes it as [file, plugin], where file is null for properties set by a plugin
+// without a backing file.
+type SourceInformation struct {
+	File   *string
+	Plugin string
+}
+
+func (s *SourceInformation) UnmarshalJSON(data []byte) error {
+	var parts []*string
+	if err := json.Unmarshal(data, &parts); err != nil {
+		return fmt.Errorf("source information must be a [file, plugin] pair: %w", err)
+	}
+	if len(parts) != 2 {
+		return fmt.Errorf("source information must have 2 elements, got %d", len(parts))
+	}
+
+	*s = SourceInformation{File: parts[0]}
+	if parts[1] != nil {
+		s.Plugin = *parts[1]
+	}
+	return nil
+}
+
+func (s SourceInformation) MarshalJSON() ([]byte, error) {
+	return json.Marshal([2]any{s.File, s.Plugin})
+}
 
 type ConfigurationSourceMaps map[string]map[string]SourceInformation
@@ -1,7 +1,5 @@
 package nxtypes
 
-type FileDataDependency interface{} // string or [2]string or [3]string
-
 type FileData struct {
 	File string               `json:"file"`
 	Hash string               `json:"hash"`
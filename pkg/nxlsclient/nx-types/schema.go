@@ -1,5 +1,7 @@
 package nxtypes
 
+import "encoding/json"
+
 type GeneratorCollectionInfo struct {
 	Type           string     `json:"type"`
 	Name           string     `json:"name"`
@@ -31,12 +33,12 @@ type Generator struct {
 // Option represents a CLI option with additional metadata
 type Option struct {
 	// Base CLI Option fields
-	Name         string `json:"name"`
-	OriginalName string `json:"originalName,omitempty"`
-	Positional   *int   `json:"positional,omitempty"`
-	Alias        string `json:"alias,omitempty"`
-	Hidden       bool   `json:"hidden,omitempty"`
-	Deprecated   any    `json:"deprecated,omitempty"` // can be bool or string
+	Name         string       `json:"name"`
+	OriginalName string       `json:"originalName,omitempty"`
+	Positional   *int         `json:"positional,omitempty"`
+	Alias        string       `json:"alias,omitempty"`
+	Hidden       bool         `json:"hidden,omitempty"`
+	Deprecated   *Deprecation `json:"deprecated,omitempty"`
 
 	// From Schema PropertyDescription (embedded)
 	PropertyDescription
@@ -44,7 +46,7 @@ type Option struct {
 	// Additional Option fields
 	Tooltip      string            `json:"tooltip,omitempty"`
 	ItemTooltips map[string]string `json:"itemTooltips,omitempty"`
-	Items        any               `json:"items,omitempty"` // can be []string or ItemsWithEnum
+	Items        *OptionItems      `json:"items,omitempty"`
 	Aliases      []string          `json:"aliases"`
 	IsRequired   bool              `json:"isRequired"`
 	XDropdown    string            `json:"x-dropdown,omitempty"`
@@ -60,7 +62,7 @@ type Schema struct {
 	OneOf                []Schema                       `json:"oneOf,omitempty"`
 	Description          string                         `json:"description,omitempty"`
 	Definitions          Properties                     `json:"definitions,omitempty"`
-	AdditionalProperties any                            `json:"additionalProperties,omitempty"` // can be bool or PropertyDescription
+	AdditionalProperties *AdditionalProperties          `json:"additionalProperties,omitempty"`
 	Examples             []SchemaExample                `json:"examples,omitempty"`
 	PatternProperties    map[string]PropertyDescription `json:"patternProperties,omitempty"`
 }
@@ -74,20 +76,89 @@ type SchemaExample struct {
 // Properties is a map of property descriptions
 type Properties map[string]PropertyDescription
 
-// PropertyDescription would need to be defined based on the full schema specification
+// PropertyDescription is the subset of JSON Schema that Nx uses to describe generator and
+// executor options, with the Nx extensions.
 type PropertyDescription struct {
-	// Add fields based on your needs
-	// This would typically include type information, descriptions, etc.
-	Type        string `json:"type,omitempty"`
-	Description string `json:"description,omitempty"`
-	// Add other fields as needed
+	Type        SchemaType `json:"type,omitempty"`
+	Description string     `json:"description,omitempty"`
+	// Default is the JSON encoding of the default value, nil when there is none.
+	Default json.RawMessage `json:"default,omitempty"`
+	// DefaultSource asks Nx to fill the value from the command line or the context.
+	DefaultSource *DefaultSource `json:"$default,omitempty"`
+	// Enum holds the JSON encoding of each allowed value.
+	Enum   []json.RawMessage `json:"enum,omitempty"`
+	Format string            `json:"format,omitempty"`
+	Ref    string            `json:"$ref,omitempty"`
+
+	// Strings
+	Pattern   string `json:"pattern,omitempty"`
+	MinLength *int   `json:"minLength,omitempty"`
+	MaxLength *int   `json:"maxLength,omitempty"`
+
+	// Numbers
+	Minimum          *float64 `json:"minimum,omitempty"`
+	ExclusiveMinimum *float64 `json:"exclusiveMinimum,omitempty"`
+	Maximum          *float64 `json:"maximum,omitempty"`
+	ExclusiveMaximum *float64 `json:"exclusiveMaximum,omitempty"`
+	MultipleOf       *float64 `json:"multipleOf,omitempty"`
+
+	// Arrays. Items is shadowed by Option.Items on options.
+	Items    *PropertyDescription `json:"items,omitempty"`
+	MinItems *int                 `json:"minItems,omitempty"`
+	MaxItems *int                 `json:"maxItems,omitempty"`
+
+	// Objects
+	Properties           Properties                     `json:"properties,omitempty"`
+	Required             []string                       `json:"required,omitempty"`
+	AdditionalProperties *AdditionalProperties          `json:"additionalProperties,omitempty"`
+	PatternProperties    map[string]PropertyDescription `json:"patternProperties,omitempty"`
+
+	// Composition
+	OneOf []PropertyDescription `json:"oneOf,omitempty"`
+	AnyOf []PropertyDescription `json:"anyOf,omitempty"`
+	AllOf []PropertyDescription `json:"allOf,omitempty"`
+
+	// Nx extensions
+	Visible     *bool        `json:"visible,omitempty"`
+	XPrompt     *Prompt      `json:"x-prompt,omitempty"`
+	XDeprecated *Deprecation `json:"x-deprecated,omitempty"`
+}
+
+// DefaultValue decodes the default value. ok is false when there is none.
+func (p PropertyDescription) DefaultValue() (value any, ok bool) {
+	if len(p.Default) == 0 {
+		return nil, false
+	}
+	if err := json.Unmarshal(p.Default, &value); err != nil {
+		return nil, false
+	}
+	return value, true
+}
+
+// EnumValues decodes the allowed values, skipping the ones that fail to decode.
+func (p PropertyDescription) EnumValues() []any {
+	values := make([]any, 0, len(p.Enum))
+	for _, raw := range p.Enum {
+		var value any
+		if err := json.Unmarshal(raw, &value); err == nil {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// DefaultSource is the $default of a property, such as {"$source": "argv", "index": 0}.
+type DefaultSource struct {
+	// Source is "argv", "projectName", "unparsed" or "workingDirectory".
+	Source string `json:"$source"`
+	// Index is the positional argument, for the argv source.
+	Index *int `json:"index,omitempty"`
 }
 
-// ItemsWithEnum represents the structure when Items is not a string array
+// ItemsWithEnum describes the items of an array option that only allows some values.
 type ItemsWithEnum struct {
-	// Add fields based on your needs
+	Type string   `json:"type,omitempty"`
 	Enum []string `json:"enum,omitempty"`
-	// Add other fields as needed
 }
 
 type TaskExecutionSchema struct {
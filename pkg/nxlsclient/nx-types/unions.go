@@ -0,0 +1,530 @@
+package nxtypes
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// isJSONString reports whether data holds a JSON string.
+func isJSONString(data []byte) bool {
+	data = bytes.TrimSpace(data)
+	return len(data) > 0 && data[0] == '"'
+}
+
+// ProjectSelector selects projects by name, glob or tag, written either as a single
+// string or as a list of strings.
+type ProjectSelector struct {
+	Patterns []string
+	// Single records that the selector was written as a single string.
+	Single bool
+}
+
+// NewProjectSelector creates a selector that marshals as a list.
+func NewProjectSelector(patterns ...string) *ProjectSelector {
+	return &ProjectSelector{Patterns: patterns}
+}
+
+func (p *ProjectSelector) UnmarshalJSON(data []byte) error {
+	if isJSONString(data) {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		*p = ProjectSelector{Patterns: []string{s}, Single: true}
+		return nil
+	}
+
+	var patterns []string
+	if err := json.Unmarshal(data, &patterns); err != nil {
+		return fmt.Errorf("projects must be a string or a list of strings: %w", err)
+	}
+	*p = ProjectSelector{Patterns: patterns}
+	return nil
+}
+
+func (p ProjectSelector) MarshalJSON() ([]byte, error) {
+	if p.Single && len(p.Patterns) == 1 {
+		return json.Marshal(p.Patterns[0])
+	}
+	if p.Patterns == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(p.Patterns)
+}
+
+// TargetDependency is an entry of a target's dependsOn, written either as a string
+// shorthand ("build", "^build", "lib:build") or as an object.
+type TargetDependency struct {
+	Target       string
+	Projects     *ProjectSelector
+	Dependencies *bool
+	Params       *string // Params is "forward" or "ignore".
+	// Shorthand is the original string form, empty when the dependency was an object.
+	Shorthand string
+}
+
+// targetDependencyObject is the object form of a TargetDependency.
+type targetDependencyObject struct {
+	Projects     *ProjectSelector `json:"projects,omitempty"`
+	Dependencies *bool            `json:"dependencies,omitempty"`
+	Params       *string          `json:"params,omitempty"`
+	Target       string           `json:"target"`
+}
+
+// ParseTargetDependency expands a dependsOn string shorthand. A shorthand with a colon
+// is kept whole in Target: it can name a target of the owning project, such as
+// "build:types", or a target of another project, such as "lib:build", which only
+// ForProject can tell apart.
+func ParseTargetDependency(s string) TargetDependency {
+	dep := TargetDependency{Shorthand: s, Target: s}
+	if strings.HasPrefix(s, "^") {
+		dependencies := true
+		dep.Target = s[1:]
+		dep.Dependencies = &dependencies
+	}
+	return dep
+}
+
+// ForProject settles a "project:target" shorthand for the project owning the dependsOn,
+// given its targets. As in nx, a target of that project with the full name wins, and the
+// shorthand otherwise names a target of another project.
+func (d TargetDependency) ForProject(targets map[string]TargetConfiguration) TargetDependency {
+	if d.Shorthand == "" || d.Projects != nil || d.OnDependencies() {
+		return d
+	}
+	if _, ok := targets[d.Target]; ok {
+		return d
+	}
+	project, target, ok := strings.Cut(d.Target, ":")
+	if !ok || project == "" || target == "" {
+		return d
+	}
+	d.Target = target
+	d.Projects = &ProjectSelector{Patterns: []string{project}, Single: true}
+	return d
+}
+
+// OnDependencies reports whether the target runs on the project's dependencies ("^build").
+func (d TargetDependency) OnDependencies() bool {
+	return d.Dependencies != nil && *d.Dependencies
+}
+
+func (d *TargetDependency) UnmarshalJSON(data []byte) error {
+	if isJSONString(data) {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		*d = ParseTargetDependency(s)
+		return nil
+	}
+
+	var obj targetDependencyObject
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return fmt.Errorf("dependsOn entries must be a string or an object: %w", err)
+	}
+	*d = TargetDependency{
+		Target:       obj.Target,
+		Projects:     obj.Projects,
+		Dependencies: obj.Dependencies,
+		Params:       obj.Params,
+	}
+	return nil
+}
+
+func (d TargetDependency) MarshalJSON() ([]byte, error) {
+	if d.Shorthand != "" {
+		return json.Marshal(d.Shorthand)
+	}
+	return json.Marshal(targetDependencyObject{
+		Projects:     d.Projects,
+		Dependencies: d.Dependencies,
+		Params:       d.Params,
+		Target:       d.Target,
+	})
+}
+
+// Input is an entry of a target's inputs or of a named input, written either as a
+// string ("default", "^production", "{projectRoot}/**/*") or as an InputDefinition.
+type Input struct {
+	// Shorthand is the original string form, empty when the input was an object.
+	Shorthand  string
+	Definition *InputDefinition
+}
+
+// IsShorthand reports whether the input was written as a string.
+func (i Input) IsShorthand() bool {
+	return i.Definition == nil
+}
+
+func (i *Input) UnmarshalJSON(data []byte) error {
+	if isJSONString(data) {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		*i = Input{Shorthand: s}
+		return nil
+	}
+
+	var def InputDefinition
+	if err := json.Unmarshal(data, &def); err != nil {
+		return fmt.Errorf("inputs must be a string or an object: %w", err)
+	}
+	*i = Input{Definition: &def}
+	return nil
+}
+
+func (i Input) MarshalJSON() ([]byte, error) {
+	if i.Definition != nil {
+		return json.Marshal(i.Definition)
+	}
+	return json.Marshal(i.Shorthand)
+}
+
+// Options holds executor or plugin options. Values are kept as raw JSON so they
+// round-trip without losing precision; use Decode or the typed getters to read them.
+// Fields of this type are tagged omitzero, so nil options are left out of JSON while an
+// explicit "options": {} is kept.
+type Options map[string]json.RawMessage
+
+// Decode unmarshals the option key into v. It reports whether the option was set.
+func (o Options) Decode(key string, v any) (bool, error) {
+	raw, ok := o[key]
+	if !ok {
+		return false, nil
+	}
+	return true, json.Unmarshal(raw, v)
+}
+
+// GetString returns a string option. ok is false when the option is missing or not a string.
+func (o Options) GetString(key string) (value string, ok bool) {
+	found, err := o.Decode(key, &value)
+	return value, found && err == nil
+}
+
+// GetBool returns a boolean option. ok is false when the option is missing or not a boolean.
+func (o Options) GetBool(key string) (value bool, ok bool) {
+	found, err := o.Decode(key, &value)
+	return value, found && err == nil
+}
+
+// PluginConfiguration is an entry of nx.json plugins, written either as the plugin name
+// or as an object with options and include/exclude patterns.
+type PluginConfiguration struct {
+	ExpandedPluginConfiguration
+	// Shorthand records that the plugin was written as a bare string.
+	Shorthand bool
+}
+
+func (p *PluginConfiguration) UnmarshalJSON(data []byte) error {
+	if isJSONString(data) {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		*p = PluginConfiguration{ExpandedPluginConfiguration: ExpandedPluginConfiguration{Plugin: s}, Shorthand: true}
+		return nil
+	}
+
+	var expanded ExpandedPluginConfiguration
+	if err := json.Unmarshal(data, &expanded); err != nil {
+		return fmt.Errorf("plugins must be a string or an object: %w", err)
+	}
+	*p = PluginConfiguration{ExpandedPluginConfiguration: expanded}
+	return nil
+}
+
+func (p PluginConfiguration) MarshalJSON() ([]byte, error) {
+	e := p.ExpandedPluginConfiguration
+	if p.Shorthand && e.Options == nil && e.Include == nil && e.Exclude == nil {
+		return json.Marshal(e.Plugin)
+	}
+	return json.Marshal(e)
+}
+
+// FileDataDependency is a dependency recorded for a file in the project file map. Nx
+// writes it as "target", [target, type] or [source, target, type].
+type FileDataDependency struct {
+	Source string // Source is only set in the three-element form.
+	Target string
+	Type   DependencyType
+	// form is the number of elements it was written with, 1 for the string form.
+	form int
+}
+
+// DependencyType returns the type, defaulting to static for the string form.
+func (d FileDataDependency) DependencyType() DependencyType {
+	if d.Type == "" {
+		return DependencyTypeStatic
+	}
+	return d.Type
+}
+
+func (d *FileDataDependency) UnmarshalJSON(data []byte) error {
+	if isJSONString(data) {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		*d = FileDataDependency{Target: s, form: 1}
+		return nil
+	}
+
+	var parts []string
+	if err := json.Unmarshal(data, &parts); err != nil {
+		return fmt.Errorf("file dependencies must be a string or a list of strings: %w", err)
+	}
+	switch len(parts) {
+	case 2:
+		*d = FileDataDependency{Target: parts[0], Type: DependencyType(parts[1]), form: 2}
+	case 3:
+		*d = FileDataDependency{Source: parts[0], Target: parts[1], Type: DependencyType(parts[2]), form: 3}
+	default:
+		return fmt.Errorf("file dependencies must have 2 or 3 elements, got %d", len(parts))
+	}
+	return nil
+}
+
+func (d FileDataDependency) MarshalJSON() ([]byte, error) {
+	form := d.form
+	if form == 0 {
+		// Pick the shortest form that keeps all information
+		switch {
+		case d.Source != "":
+			form = 3
+		case d.Type != "" && d.Type != DependencyTypeStatic:
+			form = 2
+		default:
+			form = 1
+		}
+	}
+
+	switch form {
+	case 1:
+		return json.Marshal(d.Target)
+	case 2:
+		return json.Marshal([]string{d.Target, string(d.DependencyType())})
+	default:
+		return json.Marshal([]string{d.Source, d.Target, string(d.DependencyType())})
+	}
+}
+
+// SchemaType is the type of a schema property, written either as a single type name or
+// as a list of them.
+type SchemaType []string
+
+// Has reports whether t is one of the types.
+func (s SchemaType) Has(t string) bool {
+	return slices.Contains(s, t)
+}
+
+func (s *SchemaType) UnmarshalJSON(data []byte) error {
+	if isJSONString(data) {
+		var t string
+		if err := json.Unmarshal(data, &t); err != nil {
+			return err
+		}
+		*s = SchemaType{t}
+		return nil
+	}
+
+	var types []string
+	if err := json.Unmarshal(data, &types); err != nil {
+		return fmt.Errorf("type must be a string or a list of strings: %w", err)
+	}
+	*s = types
+	return nil
+}
+
+func (s SchemaType) MarshalJSON() ([]byte, error) {
+	if len(s) == 1 {
+		return json.Marshal(s[0])
+	}
+	return json.Marshal([]string(s))
+}
+
+// Deprecation marks an option as deprecated, written either as true or as the reason.
+type Deprecation struct {
+	Deprecated bool
+	// Message is the reason or the replacement, empty in the boolean form.
+	Message string
+}
+
+func (d *Deprecation) UnmarshalJSON(data []byte) error {
+	if isJSONString(data) {
+		var message string
+		if err := json.Unmarshal(data, &message); err != nil {
+			return err
+		}
+		*d = Deprecation{Deprecated: true, Message: message}
+		return nil
+	}
+
+	var deprecated bool
+	if err := json.Unmarshal(data, &deprecated); err != nil {
+		return fmt.Errorf("deprecated must be a boolean or a string: %w", err)
+	}
+	*d = Deprecation{Deprecated: deprecated}
+	return nil
+}
+
+func (d Deprecation) MarshalJSON() ([]byte, error) {
+	if d.Message != "" {
+		return json.Marshal(d.Message)
+	}
+	return json.Marshal(d.Deprecated)
+}
+
+// Prompt is the x-prompt of a property, written either as the question or as an object
+// describing a list, confirmation or input prompt.
+type Prompt struct {
+	Message string       `json:"message"`
+	Type    string       `json:"type,omitempty"` // "input", "list" or "confirmation"
+	Items   []PromptItem `json:"items,omitempty"`
+	// Multiselect allows choosing several items of a list prompt.
+	Multiselect bool `json:"multiselect,omitempty"`
+	// Shorthand records that the prompt was written as a bare string.
+	Shorthand bool `json:"-"`
+}
+
+func (p *Prompt) UnmarshalJSON(data []byte) error {
+	if isJSONString(data) {
+		var message string
+		if err := json.Unmarshal(data, &message); err != nil {
+			return err
+		}
+		*p = Prompt{Message: message, Shorthand: true}
+		return nil
+	}
+
+	// Decode through an alias type to skip this method
+	type prompt Prompt
+	var expanded prompt
+	if err := json.Unmarshal(data, &expanded); err != nil {
+		return fmt.Errorf("x-prompt must be a string or an object: %w", err)
+	}
+	*p = Prompt(expanded)
+	return nil
+}
+
+func (p Prompt) MarshalJSON() ([]byte, error) {
+	if p.Shorthand && p.Type == "" && p.Items == nil && !p.Multiselect {
+		return json.Marshal(p.Message)
+	}
+	type prompt Prompt
+	return json.Marshal(prompt(p))
+}
+
+// PromptItem is a choice of a list prompt, written either as the value or as an object
+// with a label.
+type PromptItem struct {
+	Value string `json:"value"`
+	Label string `json:"label,omitempty"`
+	// Shorthand records that the item was written as a bare string.
+	Shorthand bool `json:"-"`
+}
+
+func (i *PromptItem) UnmarshalJSON(data []byte) error {
+	if isJSONString(data) {
+		var value string
+		if err := json.Unmarshal(data, &value); err != nil {
+			return err
+		}
+		*i = PromptItem{Value: value, Shorthand: true}
+		return nil
+	}
+
+	type item PromptItem
+	var expanded item
+	if err := json.Unmarshal(data, &expanded); err != nil {
+		return fmt.Errorf("x-prompt items must be strings or objects: %w", err)
+	}
+	*i = PromptItem(expanded)
+	return nil
+}
+
+func (i PromptItem) MarshalJSON() ([]byte, error) {
+	if i.Shorthand && i.Label == "" {
+		return json.Marshal(i.Value)
+	}
+	type item PromptItem
+	return json.Marshal(item(i))
+}
+
+// OptionItems describes the items of an array option, written either as the list of
+// allowed values or as an ItemsWithEnum.
+type OptionItems struct {
+	// Values is set in the list form.
+	Values []string
+	Schema *ItemsWithEnum
+}
+
+// Allowed returns the allowed item values, nil when any value is allowed.
+func (o OptionItems) Allowed() []string {
+	if o.Schema != nil {
+		return o.Schema.Enum
+	}
+	return o.Values
+}
+
+func (o *OptionItems) UnmarshalJSON(data []byte) error {
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		var values []string
+		if err := json.Unmarshal(data, &values); err != nil {
+			return fmt.Errorf("items must be a list of strings or an object: %w", err)
+		}
+		*o = OptionItems{Values: values}
+		return nil
+	}
+
+	var schema ItemsWithEnum
+	if err := json.Unmarshal(data, &schema); err != nil {
+		return fmt.Errorf("items must be a list of strings or an object: %w", err)
+	}
+	*o = OptionItems{Schema: &schema}
+	return nil
+}
+
+func (o OptionItems) MarshalJSON() ([]byte, error) {
+	if o.Schema != nil {
+		return json.Marshal(o.Schema)
+	}
+	if o.Values == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(o.Values)
+}
+
+// AdditionalProperties is the additionalProperties of a schema, written either as a
+// boolean or as the schema extra properties must match.
+type AdditionalProperties struct {
+	Allowed bool
+	// Schema is set in the object form, which allows properties matching it.
+	Schema *PropertyDescription
+}
+
+func (a *AdditionalProperties) UnmarshalJSON(data []byte) error {
+	var allowed bool
+	if err := json.Unmarshal(data, &allowed); err == nil {
+		*a = AdditionalProperties{Allowed: allowed}
+		return nil
+	}
+
+	var schema PropertyDescription
+	if err := json.Unmarshal(data, &schema); err != nil {
+		return fmt.Errorf("additionalProperties must be a boolean or a schema: %w", err)
+	}
+	*a = AdditionalProperties{Allowed: true, Schema: &schema}
+	return nil
+}
+
+func (a AdditionalProperties) MarshalJSON() ([]byte, error) {
+	if a.Schema != nil {
+		return json.Marshal(a.Schema)
+	}
+	return json.Marshal(a.Allowed)
+}
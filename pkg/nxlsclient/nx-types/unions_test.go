@@ -0,0 +1,238 @@
+package nxtypes
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+// roundTrip unmarshals data into a new T, checks it against want and marshals it back.
+func roundTrip[T any](t *testing.T, data string, want T) {
+	t.Helper()
+
+	var got T
+	require.NoError(t, json.Unmarshal([]byte(data), &got))
+	assert.Equal(t, want, got)
+
+	out, err := json.Marshal(got)
+	require.NoError(t, err)
+	assert.JSONEq(t, data, string(out))
+}
+
+func TestTargetDependency(t *testing.T) {
+	tests := []struct {
+		name string
+		json string
+		want TargetDependency
+	}{
+		{"same project", `"build"`, TargetDependency{Target: "build", Shorthand: "build"}},
+		{"dependencies", `"^build"`, TargetDependency{Target: "build", Dependencies: ptr(true), Shorthand: "^build"}},
+		{"colon", `"app:serve"`, TargetDependency{Target: "app:serve", Shorthand: "app:serve"}},
+		{"object", `{"target":"build","dependencies":true}`, TargetDependency{Target: "build", Dependencies: ptr(true)}},
+		{"object with projects", `{"projects":["a","tag:b"],"target":"generate","params":"forward"}`, TargetDependency{Target: "generate", Projects: &ProjectSelector{Patterns: []string{"a", "tag:b"}}, Params: ptr("forward")}},
+		{"explicit false", `{"target":"build","dependencies":false}`, TargetDependency{Target: "build", Dependencies: ptr(false)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			roundTrip(t, tt.json, tt.want)
+		})
+	}
+
+	assert.True(t, ParseTargetDependency("^build").OnDependencies())
+	assert.False(t, ParseTargetDependency("build").OnDependencies())
+
+	// A colon names a target of the owning project before a target of another project
+	targets := map[string]TargetConfiguration{"build:types": {}}
+	assert.Equal(t, TargetDependency{Target: "build:types", Shorthand: "build:types"}, ParseTargetDependency("build:types").ForProject(targets))
+	assert.Equal(t,
+		TargetDependency{Target: "serve", Projects: &ProjectSelector{Patterns: []string{"app"}, Single: true}, Shorthand: "app:serve"},
+		ParseTargetDependency("app:serve").ForProject(targets))
+	assert.Equal(t, ParseTargetDependency("^build:types"), ParseTargetDependency("^build:types").ForProject(nil))
+
+	var invalid TargetDependency
+	assert.Error(t, json.Unmarshal([]byte(`42`), &invalid))
+}
+
+func TestInput(t *testing.T) {
+	tests := []struct {
+		name string
+		json string
+		want Input
+	}{
+		{"named", `"production"`, Input{Shorthand: "production"}},
+		{"dependencies", `"^production"`, Input{Shorthand: "^production"}},
+		{"fileset", `{"fileset":"{projectRoot}/**/*"}`, Input{Definition: &InputDefinition{Fileset: ptr("{projectRoot}/**/*")}}},
+		{"runtime", `{"runtime":"node -v"}`, Input{Definition: &InputDefinition{Runtime: ptr("node -v")}}},
+		{"external", `{"externalDependencies":["typescript"]}`, Input{Definition: &InputDefinition{ExternalDependencies: []string{"typescript"}}}},
+		{"projects", `{"input":"production","projects":"shared"}`, Input{Definition: &InputDefinition{Input: ptr("production"), Projects: &ProjectSelector{Patterns: []string{"shared"}, Single: true}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			roundTrip(t, tt.json, tt.want)
+		})
+	}
+
+	assert.True(t, Input{Shorthand: "default"}.IsShorthand())
+}
+
+func TestPluginConfiguration(t *testing.T) {
+	tests := []struct {
+		name string
+		json string
+		want PluginConfiguration
+	}{
+		{"string", `"@nx/eslint/plugin"`, PluginConfiguration{ExpandedPluginConfiguration: ExpandedPluginConfiguration{Plugin: "@nx/eslint/plugin"}, Shorthand: true}},
+		{"object", `{"plugin":"@nx/vite/plugin"}`, PluginConfiguration{ExpandedPluginConfiguration: ExpandedPluginConfiguration{Plugin: "@nx/vite/plugin"}}},
+		{"options", `{"plugin":"@nx/jest/plugin","options":{"targetName":"test"},"exclude":["e2e/**"]}`, PluginConfiguration{ExpandedPluginConfiguration: ExpandedPluginConfiguration{
+			Plugin:  "@nx/jest/plugin",
+			Options: Options{"targetName": json.RawMessage(`"test"`)},
+			Exclude: []string{"e2e/**"},
+		}}},
+		{"empty options", `{"plugin":"@nx/jest/plugin","options":{}}`, PluginConfiguration{ExpandedPluginConfiguration: ExpandedPluginConfiguration{
+			Plugin:  "@nx/jest/plugin",
+			Options: Options{},
+		}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			roundTrip(t, tt.json, tt.want)
+		})
+	}
+}
+
+func TestFileDataDependency(t *testing.T) {
+	tests := []struct {
+		name     string
+		json     string
+		want     FileDataDependency
+		wantType DependencyType
+	}{
+		{"string", `"npm:react"`, FileDataDependency{Target: "npm:react", form: 1}, DependencyTypeStatic},
+		{"pair", `["utils","dynamic"]`, FileDataDependency{Target: "utils", Type: DependencyTypeDynamic, form: 2}, DependencyTypeDynamic},
+		{"triple", `["ui","utils","static"]`, FileDataDependency{Source: "ui", Target: "utils", Type: DependencyTypeStatic, form: 3}, DependencyTypeStatic},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			roundTrip(t, tt.json, tt.want)
+			assert.Equal(t, tt.wantType, tt.want.DependencyType())
+		})
+	}
+
+	// Values built in Go use the shortest form
+	out, err := json.Marshal([]FileDataDependency{
+		{Target: "npm:react"},
+		{Target: "utils", Type: DependencyTypeDynamic},
+		{Source: "ui", Target: "utils"},
+	})
+	require.NoError(t, err)
+	assert.JSONEq(t, `["npm:react",["utils","dynamic"],["ui","utils","static"]]`, string(out))
+
+	var invalid FileDataDependency
+	assert.Error(t, json.Unmarshal([]byte(`["a"]`), &invalid))
+}
+
+func TestSourceInformation(t *testing.T) {
+	roundTrip(t, `["libs/ui/project.json","nx/core/project-json"]`, SourceInformation{File: ptr("libs/ui/project.json"), Plugin: "nx/core/project-json"})
+	roundTrip(t, `[null,"@nx/eslint/plugin"]`, SourceInformation{Plugin: "@nx/eslint/plugin"})
+
+	var invalid SourceInformation
+	assert.Error(t, json.Unmarshal([]byte(`["only-one"]`), &invalid))
+}
+
+func TestProjectSelector(t *testing.T) {
+	roundTrip(t, `"tag:publishable"`, ProjectSelector{Patterns: []string{"tag:publishable"}, Single: true})
+	roundTrip(t, `["libs/*","!libs/internal-*"]`, ProjectSelector{Patterns: []string{"libs/*", "!libs/internal-*"}})
+
+	out, err := json.Marshal(NewProjectSelector("a"))
+	require.NoError(t, err)
+	assert.JSONEq(t, `["a"]`, string(out))
+}
+
+func TestOptions(t *testing.T) {
+	var options Options
+	require.NoError(t, json.Unmarshal([]byte(`{"passWithNoTests":true,"maxWorkers":2,"config":"jest.config.ts","big":12345678901234567890}`), &options))
+
+	value, ok := options.GetBool("passWithNoTests")
+	assert.True(t, ok)
+	assert.True(t, value)
+
+	config, ok := options.GetString("config")
+	assert.True(t, ok)
+	assert.Equal(t, "jest.config.ts", config)
+
+	_, ok = options.GetString("maxWorkers")
+	assert.False(t, ok)
+	_, ok = options.GetBool("missing")
+	assert.False(t, ok)
+
+	// Large numbers survive the round trip
+	out, err := json.Marshal(options)
+	require.NoError(t, err)
+	assert.Contains(t, string(out), `"big":12345678901234567890`)
+
+	// Explicitly empty options are kept, missing ones stay missing
+	roundTrip(t, `{"executor":"nx:noop","options":{}}`, TargetConfiguration{Executor: ptr("nx:noop"), Options: Options{}})
+	roundTrip(t, `{"executor":"nx:noop"}`, TargetConfiguration{Executor: ptr("nx:noop")})
+}
+
+func TestNxJsonRoundTrip(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "nx.json"))
+	require.NoError(t, err)
+
+	var nxJson NxJsonConfiguration
+	require.NoError(t, json.Unmarshal(data, &nxJson))
+
+	build := nxJson.TargetDefaults["build"]
+	require.Len(t, build.DependsOn, 2)
+	assert.True(t, build.DependsOn[0].OnDependencies())
+	assert.Equal(t, []string{"tag:codegen"}, build.DependsOn[1].Projects.Patterns)
+	assert.Equal(t, "production", *build.Inputs[2].Definition.Input)
+
+	require.Len(t, nxJson.Plugins, 3)
+	assert.Equal(t, "@nx/eslint/plugin", nxJson.Plugins[0].Plugin)
+	targetName, _ := nxJson.Plugins[1].Options.GetString("targetName")
+	assert.Equal(t, "test", targetName)
+
+	assert.Equal(t, []string{"tag:publishable"}, nxJson.Release.Groups["npm"].Projects.Patterns)
+	assert.Equal(t, "NODE_ENV", *nxJson.NamedInputs["sharedGlobals"][2].Definition.Env)
+
+	out, err := json.Marshal(nxJson)
+	require.NoError(t, err)
+	assert.JSONEq(t, string(data), string(out))
+}
+
+func TestWorkspaceDataRoundTrip(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "workspace-data.json"))
+	require.NoError(t, err)
+
+	var workspace struct {
+		ProjectFileMap ProjectFileMap          `json:"projectFileMap"`
+		SourceMaps     ConfigurationSourceMaps `json:"sourceMaps"`
+	}
+	require.NoError(t, json.Unmarshal(data, &workspace))
+
+	deps := workspace.ProjectFileMap["ui"][0].Deps
+	require.Len(t, deps, 3)
+	assert.Equal(t, "npm:react", deps[0].Target)
+	assert.Equal(t, DependencyTypeDynamic, deps[1].DependencyType())
+	assert.Equal(t, "ui", deps[2].Source)
+
+	assert.Nil(t, workspace.SourceMaps["libs/ui"]["targets.lint"].File)
+	assert.Equal(t, "libs/ui/project.json", *workspace.SourceMaps["libs/ui"]["root"].File)
+
+	out, err := json.Marshal(workspace)
+	require.NoError(t, err)
+	assert.JSONEq(t, string(data), string(out))
+}
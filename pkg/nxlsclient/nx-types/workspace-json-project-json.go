@@ -6,7 +6,7 @@ type ProjectConfiguration struct {
 	SourceRoot  *string                           `json:"sourceRoot,omitempty"`
 	ProjectType *ProjectType                      `json:"projectType,omitempty"`
 	Generators  map[string]map[string]interface{} `json:"generators,omitempty"`
-	NamedInputs map[string][]interface{}          `json:"namedInputs,omitempty"`
+	NamedInputs map[string][]Input                `json:"namedInputs,omitempty"`
 	Release     *struct {
 		Version *struct {
 			Generator        *string                `json:"generator,omitempty"`
@@ -20,30 +20,30 @@ type ProjectConfiguration struct {
 }
 
 type InputDefinition struct {
-	Projects                  interface{} `json:"projects,omitempty"`
-	Input                     *string     `json:"input,omitempty"`
-	Dependencies              *bool       `json:"dependencies,omitempty"`
-	Fileset                   *string     `json:"fileset,omitempty"`
-	Runtime                   *string     `json:"runtime,omitempty"`
-	DependentTasksOutputFiles *string     `json:"dependentTasksOutputFiles,omitempty"`
-	Transitive                *bool       `json:"transitive,omitempty"`
-	Env                       *string     `json:"env,omitempty"`
-	ExternalDependencies      []string    `json:"externalDependencies,omitempty"`
+	Projects                  *ProjectSelector `json:"projects,omitempty"`
+	Input                     *string          `json:"input,omitempty"`
+	Dependencies              *bool            `json:"dependencies,omitempty"`
+	Fileset                   *string          `json:"fileset,omitempty"`
+	Runtime                   *string          `json:"runtime,omitempty"`
+	DependentTasksOutputFiles *string          `json:"dependentTasksOutputFiles,omitempty"`
+	Transitive                *bool            `json:"transitive,omitempty"`
+	Env                       *string          `json:"env,omitempty"`
+	ExternalDependencies      []string         `json:"externalDependencies,omitempty"`
 }
 
 type TargetConfiguration struct {
-	Executor             *string                `json:"executor,omitempty"`
-	Command              *string                `json:"command,omitempty"`
-	Outputs              []string               `json:"outputs,omitempty"`
-	DependsOn            []interface{}          `json:"dependsOn,omitempty"` // []TargetDependencyConfig or []string
-	Inputs               []interface{}          `json:"inputs,omitempty"`    // []InputDefinition or []string
-	Options              interface{}            `json:"options,omitempty"`
-	Configurations       map[string]interface{} `json:"configurations,omitempty"`
-	DefaultConfiguration *string                `json:"defaultConfiguration,omitempty"`
-	Cache                *bool                  `json:"cache,omitempty"`
-	Metadata             *TargetMetadata        `json:"metadata,omitempty"`
-	Parallelism          *bool                  `json:"parallelism,omitempty"`
-	SyncGenerators       []string               `json:"syncGenerators,omitempty"`
+	Executor             *string            `json:"executor,omitempty"`
+	Command              *string            `json:"command,omitempty"`
+	Outputs              []string           `json:"outputs,omitempty"`
+	DependsOn            []TargetDependency `json:"dependsOn,omitempty"`
+	Inputs               []Input            `json:"inputs,omitempty"`
+	Options              Options            `json:"options,omitzero"`
+	Configurations       map[string]Options `json:"configurations,omitempty"`
+	DefaultConfiguration *string            `json:"defaultConfiguration,omitempty"`
+	Cache                *bool              `json:"cache,omitempty"`
+	Metadata             *TargetMetadata    `json:"metadata,omitempty"`
+	Parallelism          *bool              `json:"parallelism,omitempty"`
+	SyncGenerators       []string           `json:"syncGenerators,omitempty"`
 }
 
 type TargetMetadata struct {
@@ -60,10 +60,10 @@ type TargetMetadata struct {
 }
 
 type TargetDependencyConfig struct {
-	Projects     interface{} `json:"projects,omitempty"`
-	Dependencies *bool       `json:"dependencies,omitempty"`
-	Params       *string     `json:"params,omitempty"`
-	Target       string      `json:"target"`
+	Projects     *ProjectSelector `json:"projects,omitempty"`
+	Dependencies *bool            `json:"dependencies,omitempty"`
+	Params       *string          `json:"params,omitempty"`
+	Target       string           `json:"target"`
 }
 
 type ProjectMetadata struct {
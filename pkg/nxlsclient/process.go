@@ -0,0 +1,260 @@
+package nxlsclient
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/lazyengs/lazynx/pkg/nxlsclient/internal/proc"
+)
+
+const (
+	// serverTempDirPattern is the os.MkdirTemp pattern used when unpacking the server.
+	serverTempDirPattern = "nxls-server*"
+	// ownerFileName records the pid of the process that unpacked a server directory.
+	ownerFileName = "owner.pid"
+	// staleUnownedDirAge is how old a server directory without owner file must be before it is reaped.
+	staleUnownedDirAge = 24 * time.Hour
+)
+
+// ShutdownTimeouts bounds every step of the nxls teardown sequence.
+type ShutdownTimeouts struct {
+	StopDaemon time.Duration // StopDaemon bounds the nx/stopDaemon request and the npx fallback.
+	Shutdown   time.Duration // Shutdown bounds the LSP shutdown request.
+	Exit       time.Duration // Exit is how long to wait for nxls to exit after the exit notification.
+	Terminate  time.Duration // Terminate is how long to wait after SIGTERM before sending SIGKILL.
+}
+
+// DefaultShutdownTimeouts are used by clients created with NewClient and NewClientWithLogger.
+var DefaultShutdownTimeouts = ShutdownTimeouts{
+	StopDaemon: 5 * time.Second,
+	Shutdown:   3 * time.Second,
+	Exit:       2 * time.Second,
+	Terminate:  3 * time.Second,
+}
+
+// withDefaults fills unset timeouts from DefaultShutdownTimeouts.
+func (t ShutdownTimeouts) withDefaults() ShutdownTimeouts {
+	if t.StopDaemon <= 0 {
+		t.StopDaemon = DefaultShutdownTimeouts.StopDaemon
+	}
+	if t.Shutdown <= 0 {
+		t.Shutdown = DefaultShutdownTimeouts.Shutdown
+	}
+	if t.Exit <= 0 {
+		t.Exit = DefaultShutdownTimeouts.Exit
+	}
+	if t.Terminate <= 0 {
+		t.Terminate = DefaultShutdownTimeouts.Terminate
+	}
+	return t
+}
+
+// stepContext derives a context for a single teardown step. The step still runs when
+// the parent has already been cancelled, so Stop can be called with a dead context.
+func stepContext(parent context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.WithoutCancel(parent), timeout)
+}
+
+// setProcessGroup puts cmd in its own process group and makes context cancellation
+// terminate the whole group instead of only the direct child.
+func setProcessGroup(cmd *exec.Cmd, waitDelay time.Duration) {
+	// Set up process group isolation (prevents signal propagation)
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		Setpgid: true, // Put the child in its own process group
+	}
+	cmd.Cancel = func() error {
+		return proc.SignalGroup(cmd.Process.Pid, syscall.SIGTERM)
+	}
+	// Escalate to SIGKILL if the group ignores SIGTERM
+	cmd.WaitDelay = waitDelay
+}
+
+// processAlive reports whether a process with the given pid exists.
+func processAlive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	err := syscall.Kill(pid, 0)
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
+
+// waitForExit waits until done is closed or the timeout expires.
+func waitForExit(done <-chan struct{}, timeout time.Duration) bool {
+	if done == nil {
+		return true
+	}
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+// terminateNxls makes sure the nxls process group is gone, escalating from waiting for a
+// graceful exit to SIGTERM and finally SIGKILL.
+func (c *Client) terminateNxls() {
+	if c.process == nil || c.process.Process == nil {
+		return
+	}
+	pid := c.process.Process.Pid
+	timeouts := c.ShutdownTimeouts.withDefaults()
+
+	if waitForExit(c.processDone, timeouts.Exit) {
+		c.Logger.Debugw("nxls exited gracefully", "pid", pid)
+		// Children such as plugin workers may outlive the leader
+		_ = proc.SignalGroup(pid, syscall.SIGTERM)
+		return
+	}
+
+	c.Logger.Infow("nxls did not exit, sending SIGTERM to its process group", "pid", pid)
+	if err := proc.SignalGroup(pid, syscall.SIGTERM); err != nil {
+		c.Logger.Warnw("Failed to send SIGTERM to nxls process group", "pid", pid, "error", err.Error())
+	}
+	if waitForExit(c.processDone, timeouts.Terminate) {
+		c.Logger.Debugw("nxls exited after SIGTERM", "pid", pid)
+		return
+	}
+
+	c.Logger.Warnw("nxls ignored SIGTERM, sending SIGKILL to its process group", "pid", pid)
+	if err := proc.SignalGroup(pid, syscall.SIGKILL); err != nil {
+		c.Logger.Errorw("Failed to send SIGKILL to nxls process group", "pid", pid, "error", err.Error())
+	}
+	waitForExit(c.processDone, timeouts.Terminate)
+}
+
+// writeOwnerFile records the current process as owner of the unpacked server directory.
+func writeOwnerFile(tempDir string) error {
+	return os.WriteFile(filepath.Join(tempDir, ownerFileName), []byte(strconv.Itoa(os.Getpid())), 0600)
+}
+
+// readOwnerPid returns the pid recorded in a server directory, or 0 when there is none.
+func readOwnerPid(tempDir string) int {
+	data, err := os.ReadFile(filepath.Join(tempDir, ownerFileName))
+	if err != nil {
+		return 0
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		return 0
+	}
+	return pid
+}
+
+// isStaleServerDir reports whether a server directory was left behind by a crashed run.
+// owned is set when an owner file proves it; only then may the nxls still running from
+// it be killed.
+func isStaleServerDir(dir string, now time.Time) (stale, owned bool) {
+	if owner := readOwnerPid(dir); owner != 0 {
+		return owner != os.Getpid() && !processAlive(owner), true
+	}
+
+	// Directories unpacked before owner files existed are reaped once they are old enough
+	info, err := os.Stat(dir)
+	if err != nil {
+		return false, false
+	}
+	return now.Sub(info.ModTime()) > staleUnownedDirAge, false
+}
+
+// ReapStaleServers kills orphaned nxls processes and removes server directories left in
+// the temp directory by runs that crashed before they could clean up. It is called by
+// Start and returns the directories it removed.
+//
+// Only directories whose owner file names an exited process have their nxls killed.
+// Old directories without owner file may belong to a client that predates owner files
+// and is still running, so they are removed only when no nxls runs from them.
+func (c *Client) ReapStaleServers() []string {
+	tempRoot := os.TempDir()
+	dirs, err := filepath.Glob(filepath.Join(tempRoot, serverTempDirPattern))
+	if err != nil {
+		return nil
+	}
+
+	now := time.Now()
+	running := findNxlsProcesses(tempRoot)
+	var stale []string
+	for _, dir := range dirs {
+		isStale, owned := isStaleServerDir(dir, now)
+		switch {
+		case !isStale:
+			continue
+		case !owned && len(running[dir]) > 0:
+			c.Logger.Debugw("Keeping unowned server directory in use", "dir", dir, "pids", running[dir])
+			continue
+		case owned:
+			for _, pid := range running[dir] {
+				c.killOrphanedNxls(pid)
+			}
+		}
+		stale = append(stale, dir)
+	}
+
+	var removed []string
+	for _, dir := range stale {
+		if err := os.RemoveAll(dir); err != nil {
+			c.Logger.Warnw("Failed to remove stale server directory", "dir", dir, "error", err.Error())
+			continue
+		}
+		c.Logger.Debugw("Removed stale server directory", "dir", dir)
+		removed = append(removed, dir)
+	}
+
+	return removed
+}
+
+// killOrphanedNxls kills the process group of an nxls whose owner exited. Processes
+// that do not lead their group were started by that nxls and go with it.
+func (c *Client) killOrphanedNxls(pid int) {
+	if pgid, err := syscall.Getpgid(pid); err != nil || pgid != pid {
+		return
+	}
+	c.Logger.Infow("Killing orphaned nxls process", "pid", pid)
+	if err := proc.SignalGroup(pid, syscall.SIGKILL); err != nil {
+		c.Logger.Warnw("Failed to kill orphaned nxls process", "pid", pid, "error", err.Error())
+	}
+}
+
+// findNxlsProcesses scans /proc for node processes running an nxls main.js from a
+// server directory under tempRoot and returns their pids by directory. On systems
+// without /proc it returns nothing.
+func findNxlsProcesses(tempRoot string) map[string][]int {
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		return nil
+	}
+
+	prefix := filepath.Join(tempRoot, "nxls-server")
+	pids := make(map[string][]int)
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil || pid == os.Getpid() {
+			continue
+		}
+
+		cmdline, err := os.ReadFile(filepath.Join("/proc", entry.Name(), "cmdline"))
+		if err != nil {
+			continue
+		}
+
+		for _, arg := range strings.Split(string(cmdline), "\x00") {
+			if !strings.HasPrefix(arg, prefix) || !strings.HasSuffix(arg, filepath.Join("server", "nxls", "main.js")) {
+				continue
+			}
+
+			rel, _ := filepath.Rel(tempRoot, arg)
+			dir := filepath.Join(tempRoot, strings.Split(rel, string(filepath.Separator))[0])
+			pids[dir] = append(pids[dir], pid)
+			break
+		}
+	}
+
+	return pids
+}
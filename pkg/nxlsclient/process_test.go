@@ -0,0 +1,141 @@
+package nxlsclient
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// startTestProcess starts a shell script in its own process group the same way startNxls does.
+func startTestProcess(t *testing.T, client *Client, script string) {
+	t.Helper()
+
+	cmd := exec.CommandContext(context.Background(), "sh", "-c", script)
+	setProcessGroup(cmd, time.Second)
+	require.NoError(t, cmd.Start())
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = cmd.Wait()
+	}()
+
+	client.process = cmd
+	client.processDone = done
+}
+
+func TestStepContext(t *testing.T) {
+	parent, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	ctx, stepCancel := stepContext(parent, time.Minute)
+	defer stepCancel()
+
+	assert.NoError(t, ctx.Err(), "Step context should outlive a cancelled parent")
+	_, hasDeadline := ctx.Deadline()
+	assert.True(t, hasDeadline, "Step context should be bounded")
+}
+
+func TestTerminateNxls(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	timeouts := ShutdownTimeouts{
+		StopDaemon: 100 * time.Millisecond,
+		Shutdown:   100 * time.Millisecond,
+		Exit:       100 * time.Millisecond,
+		Terminate:  500 * time.Millisecond,
+	}
+
+	t.Run("SIGTERM", func(t *testing.T) {
+		client := &Client{Logger: logger.Sugar(), ShutdownTimeouts: timeouts}
+		startTestProcess(t, client, "sleep 60")
+
+		client.terminateNxls()
+
+		select {
+		case <-client.processDone:
+		default:
+			t.Fatal("Process should have exited after SIGTERM")
+		}
+	})
+
+	t.Run("SIGKILL", func(t *testing.T) {
+		client := &Client{Logger: logger.Sugar(), ShutdownTimeouts: timeouts}
+		// Ignore SIGTERM so the teardown has to escalate
+		startTestProcess(t, client, "trap '' TERM; while true; do sleep 0.05; done")
+
+		start := time.Now()
+		client.terminateNxls()
+
+		select {
+		case <-client.processDone:
+		default:
+			t.Fatal("Process should have exited after SIGKILL")
+		}
+		assert.GreaterOrEqual(t, time.Since(start), timeouts.Exit+timeouts.Terminate)
+	})
+
+	t.Run("NoProcess", func(t *testing.T) {
+		client := &Client{Logger: logger.Sugar()}
+		// Should not panic or block
+		client.terminateNxls()
+	})
+}
+
+func TestReapStaleServers(t *testing.T) {
+	tempRoot := t.TempDir()
+	t.Setenv("TMPDIR", tempRoot)
+
+	// A directory owned by a process that no longer exists
+	deadOwner := exec.Command("true")
+	require.NoError(t, deadOwner.Run())
+	staleDir := filepath.Join(tempRoot, "nxls-server111")
+	require.NoError(t, os.MkdirAll(staleDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(staleDir, ownerFileName), []byte(strconv.Itoa(deadOwner.Process.Pid)), 0600))
+
+	// A directory owned by this process
+	liveDir := filepath.Join(tempRoot, "nxls-server222")
+	require.NoError(t, os.MkdirAll(liveDir, 0755))
+	require.NoError(t, writeOwnerFile(liveDir))
+
+	// An old directory from a version that did not write owner files
+	oldDir := filepath.Join(tempRoot, "nxls-server333")
+	require.NoError(t, os.MkdirAll(oldDir, 0755))
+	old := time.Now().Add(-2 * staleUnownedDirAge)
+	require.NoError(t, os.Chtimes(oldDir, old, old))
+
+	// A recent directory without owner file
+	recentDir := filepath.Join(tempRoot, "nxls-server444")
+	require.NoError(t, os.MkdirAll(recentDir, 0755))
+
+	// An old directory without owner file that an nxls still runs from
+	usedDir := filepath.Join(tempRoot, "nxls-server555")
+	require.NoError(t, os.MkdirAll(usedDir, 0755))
+	require.NoError(t, os.Chtimes(usedDir, old, old))
+	nxls := exec.CommandContext(context.Background(), "sh", "-c", "sleep 30; :", filepath.Join(usedDir, "server", "nxls", "main.js"))
+	setProcessGroup(nxls, time.Second)
+	require.NoError(t, nxls.Start())
+	t.Cleanup(func() {
+		_ = nxls.Process.Kill()
+		_ = nxls.Wait()
+	})
+
+	logger, _ := zap.NewDevelopment()
+	client := &Client{Logger: logger.Sugar()}
+	removed := client.ReapStaleServers()
+
+	assert.ElementsMatch(t, []string{staleDir, oldDir}, removed)
+	assert.NoDirExists(t, staleDir)
+	assert.NoDirExists(t, oldDir)
+	assert.DirExists(t, liveDir)
+	assert.DirExists(t, recentDir)
+	assert.DirExists(t, usedDir)
+	assert.True(t, processAlive(nxls.Process.Pid), "an nxls of an unowned directory must not be killed")
+}
@@ -0,0 +1,76 @@
+package nxlsclient
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// clockTicksPerSecond is USER_HZ, which is 100 on every Linux architecture Go supports.
+const clockTicksPerSecond = 100
+
+// ProcessStats is a resource usage sample of the nxls server process.
+type ProcessStats struct {
+	PID        int           // PID is the sampled process.
+	RSSBytes   uint64        // RSSBytes is the resident set size.
+	CPUTime    time.Duration // CPUTime is the total user and system CPU time consumed so far.
+	CPUPercent float64       // CPUPercent is the CPU usage since the previous sample, 100 meaning one full core.
+	SampledAt  time.Time     // SampledAt is when the sample was taken.
+}
+
+// ReadProcessStats samples RSS and CPU time for pid from /proc. It returns an error on
+// systems without procfs. CPUPercent is left at zero; use ProcessStats.Since to derive it.
+func ReadProcessStats(pid int) (*ProcessStats, error) {
+	data, err := os.ReadFile(filepath.Join("/proc", strconv.Itoa(pid), "stat"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read process stats: %w", err)
+	}
+
+	// The command name is wrapped in parentheses and may contain spaces, so parse after it
+	stat := string(data)
+	end := strings.LastIndexByte(stat, ')')
+	if end < 0 {
+		return nil, fmt.Errorf("malformed /proc/%d/stat", pid)
+	}
+	fields := strings.Fields(stat[end+1:])
+	// fields[0] is field 3 (state) in proc(5) numbering
+	if len(fields) < 22 {
+		return nil, fmt.Errorf("malformed /proc/%d/stat", pid)
+	}
+
+	utime, err := strconv.ParseUint(fields[11], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("malformed utime in /proc/%d/stat: %w", pid, err)
+	}
+	stime, err := strconv.ParseUint(fields[12], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("malformed stime in /proc/%d/stat: %w", pid, err)
+	}
+	rssPages, err := strconv.ParseUint(fields[21], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("malformed rss in /proc/%d/stat: %w", pid, err)
+	}
+
+	ticks := utime + stime
+	return &ProcessStats{
+		PID:       pid,
+		RSSBytes:  rssPages * uint64(os.Getpagesize()),
+		CPUTime:   time.Duration(ticks) * time.Second / clockTicksPerSecond,
+		SampledAt: time.Now(),
+	}, nil
+}
+
+// Since fills CPUPercent from the CPU time consumed between prev and s.
+func (s *ProcessStats) Since(prev *ProcessStats) {
+	if prev == nil || prev.PID != s.PID {
+		return
+	}
+	wall := s.SampledAt.Sub(prev.SampledAt)
+	if wall <= 0 {
+		return
+	}
+	s.CPUPercent = float64(s.CPUTime-prev.CPUTime) / float64(wall) * 100
+}
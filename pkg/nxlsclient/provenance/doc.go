@@ -0,0 +1,31 @@
+/*
+Package provenance tells which file and plugin defined each property of a project.
+
+Nx merges project configuration from project.json, package.json, the target defaults of
+nx.json and inference plugins such as @nx/vite/plugin, and records the origin of every
+property in the source maps of the nx/workspace response. Those maps are keyed by
+project root and dotted property path; Provenance resolves them by project name and
+property path, falling back to the closest parent when a property has no entry of its
+own, which is how Nx records values set as a whole.
+
+# Usage
+
+	p := provenance.New(workspace)
+
+	source, ok := p.Lookup("app", "targets", "build", "options", "command")
+	if ok {
+		fmt.Println(source) // "apps/app/project.json (nx/core/project-json)"
+	}
+
+	// Every field of a target, with its origin
+	for _, field := range p.Target("app", "build") {
+		fmt.Printf("%s = %s from %s\n", field.Name(), field.Value, field.Source)
+	}
+
+	// Jump to the definition
+	loc, err := provenance.Locate(workspacePath, source, "targets", "build", "options", "command")
+	if err == nil {
+		openEditor(loc.File, loc.Line)
+	}
+*/
+package provenance
@@ -0,0 +1,68 @@
+package provenance
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+// ErrNoFile is returned by Locate for properties a plugin set without a backing file.
+var ErrNoFile = errors.New("the property was not defined in a file")
+
+// Location is a position in a file, for opening it in an editor.
+type Location struct {
+	// File is absolute.
+	File string
+	// Line starts at 1.
+	Line int
+}
+
+// Locate finds the line of the source file of a property that defines it, given the
+// full property path passed to Lookup. The search is textual: it looks for each path
+// segment as an object key, after the line of the previous one, and skips segments it
+// cannot find, such as "targets" in the targetDefaults of nx.json. Inferred targets
+// usually come from a config file that names none of the keys, so Locate falls back to
+// the first line.
+func Locate(workspacePath string, source Source, path ...string) (Location, error) {
+	if source.File == "" {
+		return Location{}, ErrNoFile
+	}
+
+	file := source.File
+	if !filepath.IsAbs(file) {
+		file = filepath.Join(workspacePath, file)
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return Location{}, fmt.Errorf("failed to read %s: %w", source.File, err)
+	}
+
+	return Location{File: file, Line: FindLine(data, path...)}, nil
+}
+
+// FindLine returns the line, starting at 1, of the innermost key of path found in a JSON
+// or JavaScript document, or 1 when none is found.
+func FindLine(data []byte, path ...string) int {
+	var lines []string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), len(data)+1)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+
+	found := 0 // index of the last matched line
+	for _, segment := range path {
+		key := regexp.MustCompile(`(?:^|[\s{,])(?:"` + regexp.QuoteMeta(segment) + `"|'` + regexp.QuoteMeta(segment) + `'|` + regexp.QuoteMeta(segment) + `)\s*:`)
+		for i := found; i < len(lines); i++ {
+			if key.MatchString(lines[i]) {
+				found = i
+				break
+			}
+		}
+	}
+	return found + 1
+}
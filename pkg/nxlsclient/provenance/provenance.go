@@ -0,0 +1,215 @@
+package provenance
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+
+	nxtypes "github.com/lazyengs/lazynx/pkg/nxlsclient/nx-types"
+)
+
+// Source is the file and plugin that defined a project property.
+type Source struct {
+	// Key is the source map entry that recorded the property, such as
+	// "targets.build.options.command".
+	Key string `json:"key"`
+	// File is relative to the workspace root. It is empty when a plugin set the property
+	// without a backing file.
+	File string `json:"file,omitempty"`
+	// Plugin is the plugin that produced the property, such as "nx/core/project-json" or
+	// "@nx/vite/plugin".
+	Plugin string `json:"plugin"`
+	// Inherited is set when the property had no entry of its own and Key is the closest
+	// parent that had one.
+	Inherited bool `json:"inherited,omitempty"`
+}
+
+// configPlugins are the plugins that read configuration written by hand.
+var configPlugins = []string{"nx/core/project-json", "nx/core/package-json", "nx/target-defaults"}
+
+// Inferred reports whether a plugin inferred the property rather than reading it from
+// project.json, package.json or the target defaults of nx.json.
+func (s Source) Inferred() bool {
+	return !slices.Contains(configPlugins, s.Plugin)
+}
+
+func (s Source) String() string {
+	origin := s.Plugin
+	if s.File != "" {
+		origin = s.File + " (" + s.Plugin + ")"
+	}
+	if s.Inherited {
+		origin += " via " + s.Key
+	}
+	return origin
+}
+
+// Field is a configuration value annotated with where it came from.
+type Field struct {
+	// Path is the property path relative to what was annotated, such as
+	// []string{"options", "command"} for a target field.
+	Path []string `json:"path"`
+	// Value is the JSON encoding of the value.
+	Value  string `json:"value"`
+	Source Source `json:"source"`
+	// Known is false when neither the property nor any of its parents is in the source map.
+	Known bool `json:"known"`
+}
+
+// Name joins the path with dots.
+func (f Field) Name() string {
+	return strings.Join(f.Path, ".")
+}
+
+// Provenance answers where the properties of the projects of a workspace were defined,
+// from the nx/workspace source maps.
+type Provenance struct {
+	sourceMaps nxtypes.ConfigurationSourceMaps
+	nodes      map[string]nxtypes.ProjectGraphProjectNode
+}
+
+// New reads the source maps of workspace. Workspaces without source maps yield a
+// Provenance that knows nothing.
+func New(workspace *nxtypes.NxWorkspace) *Provenance {
+	p := &Provenance{
+		sourceMaps: nxtypes.ConfigurationSourceMaps{},
+		nodes:      map[string]nxtypes.ProjectGraphProjectNode{},
+	}
+	if workspace == nil {
+		return p
+	}
+	if workspace.SourceMaps != nil {
+		p.sourceMaps = *workspace.SourceMaps
+	}
+	p.nodes = workspace.ProjectGraph.Nodes
+	return p
+}
+
+// Lookup returns the source of the property at path in project, such as
+// Lookup("app", "targets", "build", "options", "command"). Without an entry for the
+// property, the closest parent with one is returned as Inherited.
+//
+// Path segments are joined with dots to form source map keys, so target names containing
+// dots are passed as a single segment.
+func (p *Provenance) Lookup(project string, path ...string) (Source, bool) {
+	entries := p.entries(project)
+	if entries == nil {
+		return Source{}, false
+	}
+
+	for n := len(path); n > 0; n-- {
+		key := strings.Join(path[:n], ".")
+		if info, ok := entries[key]; ok {
+			return newSource(key, info, n < len(path)), true
+		}
+	}
+	return Source{}, false
+}
+
+// Sources returns every source map entry of project, sorted by key.
+func (p *Provenance) Sources(project string) []Source {
+	entries := p.entries(project)
+	sources := make([]Source, 0, len(entries))
+	for key, info := range entries {
+		sources = append(sources, newSource(key, info, false))
+	}
+	slices.SortFunc(sources, func(a, b Source) int { return strings.Compare(a.Key, b.Key) })
+	return sources
+}
+
+// Files returns the files that contributed to project, sorted, without duplicates.
+func (p *Provenance) Files(project string) []string {
+	var files []string
+	for _, info := range p.entries(project) {
+		if info.File != nil && *info.File != "" {
+			files = append(files, *info.File)
+		}
+	}
+	slices.Sort(files)
+	return slices.Compact(files)
+}
+
+// Target annotates every field of a target of project with its source, sorted by path.
+// Objects are walked down to their leaves; arrays such as dependsOn and inputs are
+// annotated as a whole, the way Nx records them.
+func (p *Provenance) Target(project, target string) []Field {
+	node, ok := p.nodes[project]
+	if !ok {
+		return nil
+	}
+	config, ok := node.Data.Targets[target]
+	if !ok {
+		return nil
+	}
+
+	data, err := json.Marshal(config)
+	if err != nil {
+		return nil
+	}
+	var value map[string]any
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil
+	}
+
+	var fields []Field
+	walk(nil, value, func(path []string, leaf any) {
+		encoded, _ := json.Marshal(leaf)
+		source, known := p.Lookup(project, append([]string{"targets", target}, path...)...)
+		fields = append(fields, Field{
+			Path:   slices.Clone(path),
+			Value:  string(encoded),
+			Source: source,
+			Known:  known,
+		})
+	})
+	return fields
+}
+
+// Targets returns the target names of project, sorted.
+func (p *Provenance) Targets(project string) []string {
+	node, ok := p.nodes[project]
+	if !ok {
+		return nil
+	}
+	targets := make([]string, 0, len(node.Data.Targets))
+	for name := range node.Data.Targets {
+		targets = append(targets, name)
+	}
+	slices.Sort(targets)
+	return targets
+}
+
+// entries returns the source map of project, keyed by its root.
+func (p *Provenance) entries(project string) map[string]nxtypes.SourceInformation {
+	node, ok := p.nodes[project]
+	if !ok {
+		return nil
+	}
+	return p.sourceMaps[node.Data.Root]
+}
+
+func newSource(key string, info nxtypes.SourceInformation, inherited bool) Source {
+	source := Source{Key: key, Plugin: info.Plugin, Inherited: inherited}
+	if info.File != nil {
+		source.File = *info.File
+	}
+	return source
+}
+
+// walk calls fn for every leaf of value. Empty objects are leaves.
+func walk(path []string, value any, fn func(path []string, leaf any)) {
+	object, ok := value.(map[string]any)
+	if !ok || len(object) == 0 {
+		fn(path, value)
+		return
+	}
+
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	for _, key := range keys {
+		walk(append(path, key), object[key], fn)
+	}
+}
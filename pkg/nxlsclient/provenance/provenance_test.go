@@ -0,0 +1,142 @@
+package provenance
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	nxtypes "github.com/lazyengs/lazynx/pkg/nxlsclient/nx-types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestWorkspace builds a workspace with an app whose build target comes from
+// project.json, target defaults and the vite plugin.
+func newTestWorkspace(t *testing.T) *nxtypes.NxWorkspace {
+	t.Helper()
+
+	var workspace nxtypes.NxWorkspace
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"projectGraph": {
+			"nodes": {
+				"app": {
+					"name": "app",
+					"type": "app",
+					"data": {
+						"root": "apps/app",
+						"tags": ["scope:app"],
+						"targets": {
+							"build": {
+								"executor": "nx:run-commands",
+								"options": {"command": "vite build", "cwd": "apps/app"},
+								"dependsOn": ["^build"],
+								"cache": true
+							},
+							"serve": {"command": "vite serve"}
+						}
+					}
+				}
+			},
+			"dependencies": {}
+		},
+		"sourceMaps": {
+			"apps/app": {
+				"root": ["apps/app/project.json", "nx/core/project-json"],
+				"tags": ["apps/app/project.json", "nx/core/project-json"],
+				"targets.build": ["apps/app/vite.config.ts", "@nx/vite/plugin"],
+				"targets.build.options.command": ["apps/app/project.json", "nx/core/project-json"],
+				"targets.build.dependsOn": ["nx.json", "nx/target-defaults"],
+				"targets.serve": [null, "@nx/vite/plugin"]
+			}
+		}
+	}`), &workspace))
+	return &workspace
+}
+
+func TestLookup(t *testing.T) {
+	p := New(newTestWorkspace(t))
+
+	source, ok := p.Lookup("app", "targets", "build", "options", "command")
+	require.True(t, ok)
+	assert.Equal(t, Source{Key: "targets.build.options.command", File: "apps/app/project.json", Plugin: "nx/core/project-json"}, source)
+	assert.False(t, source.Inferred())
+
+	// cwd has no entry, the target was inferred as a whole
+	source, ok = p.Lookup("app", "targets", "build", "options", "cwd")
+	require.True(t, ok)
+	assert.Equal(t, Source{Key: "targets.build", File: "apps/app/vite.config.ts", Plugin: "@nx/vite/plugin", Inherited: true}, source)
+	assert.True(t, source.Inferred())
+	assert.Equal(t, "apps/app/vite.config.ts (@nx/vite/plugin) via targets.build", source.String())
+
+	source, ok = p.Lookup("app", "targets", "serve")
+	require.True(t, ok)
+	assert.Empty(t, source.File)
+	assert.Equal(t, "@nx/vite/plugin", source.String())
+
+	_, ok = p.Lookup("app", "sourceRoot")
+	assert.False(t, ok)
+	_, ok = p.Lookup("missing", "root")
+	assert.False(t, ok)
+
+	assert.Equal(t, []string{"apps/app/project.json", "apps/app/vite.config.ts", "nx.json"}, p.Files("app"))
+	assert.Len(t, p.Sources("app"), 6)
+	assert.Equal(t, []string{"build", "serve"}, p.Targets("app"))
+}
+
+func TestTarget(t *testing.T) {
+	p := New(newTestWorkspace(t))
+
+	var names, origins []string
+	for _, field := range p.Target("app", "build") {
+		names = append(names, field.Name())
+		origins = append(origins, field.Source.Key)
+	}
+
+	assert.Equal(t, []string{"cache", "dependsOn", "executor", "options.command", "options.cwd"}, names)
+	assert.Equal(t, []string{"targets.build", "targets.build.dependsOn", "targets.build", "targets.build.options.command", "targets.build"}, origins)
+	assert.Equal(t, `"vite build"`, p.Target("app", "build")[3].Value)
+
+	assert.Nil(t, p.Target("app", "missing"))
+	assert.Empty(t, New(nil).Target("app", "build"))
+}
+
+func TestLocate(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "apps", "app"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "apps", "app", "project.json"), []byte(`{
+  "name": "app",
+  "targets": {
+    "serve": {
+      "options": { "command": "vite serve" }
+    },
+    "build": {
+      "options": {
+        "command": "vite build"
+      }
+    }
+  }
+}`), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "nx.json"), []byte(`{
+  "targetDefaults": {
+    "build": {
+      "dependsOn": ["^build"]
+    }
+  }
+}`), 0o644))
+
+	loc, err := Locate(dir, Source{File: "apps/app/project.json"}, "targets", "build", "options", "command")
+	require.NoError(t, err)
+	assert.Equal(t, Location{File: filepath.Join(dir, "apps", "app", "project.json"), Line: 9}, loc)
+
+	// "targets" is not in nx.json, the other keys are
+	loc, err = Locate(dir, Source{File: "nx.json"}, "targets", "build", "dependsOn")
+	require.NoError(t, err)
+	assert.Equal(t, 4, loc.Line)
+
+	_, err = Locate(dir, Source{Plugin: "@nx/vite/plugin"}, "targets", "serve")
+	assert.ErrorIs(t, err, ErrNoFile)
+
+	assert.Equal(t, 1, FindLine([]byte("export default defineConfig({});"), "targets", "build"))
+	assert.Equal(t, 4, FindLine([]byte("export default {\n  plugins: [],\n  build: {\n    outDir: 'dist',\n  },\n};"), "build", "outDir"))
+}
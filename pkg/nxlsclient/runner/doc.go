@@ -0,0 +1,57 @@
+/*
+Package runner runs nx commands in a workspace and streams their output.
+
+A Runner starts nx with the package manager of the workspace, detected from the
+packageManager field of package.json or from its lock file, so the nx version installed
+in the workspace is used. On Linux, runs write to a pseudo-terminal so nx and the tools
+it starts keep their colors and progress output. Other platforms, macOS included, use
+pipes, and most tools print plain output there. Every line is passed to
+a callback as it is written, and the last lines are kept in the Scrollback of the run.
+
+Cancelling a run sends SIGINT to its whole process group, like pressing Ctrl+C in a
+terminal, so nx can stop the tasks it started. A group that is still running after the
+grace period is killed.
+
+StartTasks follows nx run-many, nx affected and targets with dependencies in a Tasks,
+which splits the output into a log per task and tracks whether each task succeeded,
+came from the cache or failed. A failed task can be run again into the same Tasks.
+
+# Usage
+
+	r := runner.NewRunner(workspacePath)
+
+	run, err := r.StartTarget(ctx, nxtypes.Target{Project: "app", Target: "build"}, nil, func(line runner.Line) {
+		fmt.Println(line.Text)
+	})
+	if err != nil {
+		return err
+	}
+
+	// Stop a long running target such as serve
+	run.Cancel()
+
+	result, err := run.Wait()
+	fmt.Println(result.ExitCode, result.Duration, result.Canceled)
+
+	for _, line := range run.Output.Lines() {
+		fmt.Println(line.Text)
+	}
+
+	tasks := runner.NewTasks(0)
+	run, err = r.StartTasks(ctx, runner.Many{Affected: true, Targets: []string{"test"}}.NxArgs(), tasks)
+	if err != nil {
+		return err
+	}
+	run.Wait()
+
+	// Rerun the failed tasks one at a time
+	for _, id := range tasks.Failed() {
+		task, _ := tasks.Get(id)
+		tasks.Expect(id)
+		run, err = r.StartTasks(ctx, runner.TargetArgs(task.Target()), tasks)
+		if err == nil {
+			run.Wait()
+		}
+	}
+*/
+package runner
@@ -0,0 +1,62 @@
+package runner
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+
+	nxtypes "github.com/lazyengs/lazynx/pkg/nxlsclient/nx-types"
+)
+
+// lockFiles maps the lock file of every package manager to it, in the order Nx checks them.
+var lockFiles = []struct {
+	name           string
+	packageManager nxtypes.PackageManager
+}{
+	{"bun.lockb", nxtypes.PackageManagerBun},
+	{"bun.lock", nxtypes.PackageManagerBun},
+	{"yarn.lock", nxtypes.PackageManagerYarn},
+	{"pnpm-lock.yaml", nxtypes.PackageManagerPnpm},
+	{"package-lock.json", nxtypes.PackageManagerNpm},
+}
+
+// DetectPackageManager returns the package manager of the workspace at workspacePath. The
+// packageManager field of the root package.json wins, then the lock file found in the
+// workspace root. It falls back to npm.
+func DetectPackageManager(workspacePath string) nxtypes.PackageManager {
+	if data, err := os.ReadFile(filepath.Join(workspacePath, "package.json")); err == nil {
+		var pkg struct {
+			PackageManager string `json:"packageManager"`
+		}
+		// The field is name@version, such as "pnpm@9.1.0"
+		if json.Unmarshal(data, &pkg) == nil && pkg.PackageManager != "" {
+			name, _, _ := strings.Cut(pkg.PackageManager, "@")
+			switch pm := nxtypes.PackageManager(name); pm {
+			case nxtypes.PackageManagerNpm, nxtypes.PackageManagerYarn, nxtypes.PackageManagerPnpm, nxtypes.PackageManagerBun:
+				return pm
+			}
+		}
+	}
+
+	for _, lock := range lockFiles {
+		if _, err := os.Stat(filepath.Join(workspacePath, lock.name)); err == nil {
+			return lock.packageManager
+		}
+	}
+	return nxtypes.PackageManagerNpm
+}
+
+// NxCommand returns the command that runs the nx binary installed in the workspace with pm.
+func NxCommand(pm nxtypes.PackageManager) []string {
+	switch pm {
+	case nxtypes.PackageManagerPnpm:
+		return []string{"pnpm", "exec", "nx"}
+	case nxtypes.PackageManagerYarn:
+		return []string{"yarn", "nx"}
+	case nxtypes.PackageManagerBun:
+		return []string{"bunx", "nx"}
+	default:
+		return []string{"npx", "nx"}
+	}
+}
@@ -0,0 +1,14 @@
+//go:build !linux
+
+package runner
+
+import (
+	"errors"
+	"os"
+)
+
+// openPTY is only implemented on Linux, which opens ptys with plain ioctls. macOS and
+// the BSDs need platform specific calls, so runs there fall back to pipes.
+func openPTY(cols, rows uint16) (*os.File, *os.File, error) {
+	return nil, nil, errors.New("pty is not supported on this platform")
+}
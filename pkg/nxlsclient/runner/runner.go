@@ -0,0 +1,343 @@
+package runner
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/lazyengs/lazynx/pkg/nxlsclient/internal/proc"
+	nxtypes "github.com/lazyengs/lazynx/pkg/nxlsclient/nx-types"
+)
+
+const (
+	// DefaultGracePeriod is how long a cancelled run may take to exit after SIGINT before
+	// its process group is killed.
+	DefaultGracePeriod = 5 * time.Second
+	// maxLineLength splits lines longer than this, such as minified bundles echoed by a tool.
+	maxLineLength = 64 * 1024
+	// drainTimeout is how long output is read after nx exited. Processes nx left behind may
+	// keep the terminal open, so the output never ends on its own.
+	drainTimeout = time.Second
+)
+
+// Runner starts nx commands in a workspace.
+type Runner struct {
+	WorkspacePath string
+	// Command starts nx, detected from the workspace package manager by NewRunner.
+	Command []string
+	// Env is the environment of nx, the current one when nil.
+	Env []string
+	// PTY runs nx in a pseudo-terminal, so it keeps its colors and progress output. Only
+	// Linux has one, runs on other platforms and runs where none can be opened use pipes.
+	PTY bool
+	// Cols and Rows are the size of the pseudo-terminal.
+	Cols, Rows uint16
+	// Scrollback is the number of lines kept per run, DefaultScrollback when unset.
+	Scrollback int
+	// GracePeriod is DefaultGracePeriod when unset.
+	GracePeriod time.Duration
+}
+
+// NewRunner creates a runner for the workspace at workspacePath that runs nx with the
+// workspace package manager in a pseudo-terminal.
+func NewRunner(workspacePath string) *Runner {
+	return &Runner{
+		WorkspacePath: workspacePath,
+		Command:       NxCommand(DetectPackageManager(workspacePath)),
+		PTY:           true,
+		Cols:          120,
+		Rows:          40,
+	}
+}
+
+// TargetArgs returns the nx arguments running target, "run project:target:configuration".
+func TargetArgs(target nxtypes.Target) []string {
+	spec := target.Project + ":" + target.Target
+	if target.Configuration != "" {
+		spec += ":" + target.Configuration
+	}
+	return []string{"run", spec}
+}
+
+// StartTarget runs target followed by args, which are passed to nx run as is.
+func (r *Runner) StartTarget(ctx context.Context, target nxtypes.Target, args []string, onLine func(Line)) (*Run, error) {
+	return r.Start(ctx, append(TargetArgs(target), args...), onLine)
+}
+
+// Start runs nx with args, such as {"run-many", "-t", "lint"}. onLine, when not nil, is
+// called with every line of output as it is written, one line at a time.
+//
+// Cancelling ctx interrupts the run: its process group receives SIGINT, as if Ctrl+C was
+// pressed in a terminal, and SIGKILL once the grace period is over.
+func (r *Runner) Start(ctx context.Context, args []string, onLine func(Line)) (*Run, error) {
+	return r.start(ctx, args, nil, onLine, nil)
+}
+
+// start runs nx with args and env added to its environment. onExit, when not nil, is
+// called with the result before the run is done.
+func (r *Runner) start(ctx context.Context, args, env []string, onLine func(Line), onExit func(Result)) (*Run, error) {
+	if len(r.Command) == 0 {
+		return nil, errors.New("failed to start nx: no nx command")
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	run := &Run{
+		Args:   args,
+		Output: NewScrollback(r.Scrollback),
+		cancel: cancel,
+		onLine: onLine,
+		onExit: onExit,
+		exited: make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+
+	cmd := exec.Command(r.Command[0], append(slices.Clone(r.Command[1:]), args...)...)
+	cmd.Dir = r.WorkspacePath
+	cmd.Env = r.Env
+	if cmd.Env == nil {
+		cmd.Env = os.Environ()
+	}
+	// Nx replaces its output with a full screen task UI when it writes to a terminal
+	cmd.Env = append(cmd.Env, "NX_TUI=false")
+	cmd.Env = append(cmd.Env, env...)
+
+	var outputs []*os.File
+	if r.PTY {
+		master, slave, err := openPTY(r.Cols, r.Rows)
+		if err == nil {
+			defer slave.Close()
+			run.PTY = true
+			cmd.Env = append(cmd.Env, "TERM="+termOrDefault(cmd.Env))
+			cmd.Stdin, cmd.Stdout, cmd.Stderr = slave, slave, slave
+			// The terminal becomes the controlling terminal of a new session, so Ctrl+C
+			// semantics apply to everything nx starts
+			cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true, Setctty: true, Ctty: 0}
+			outputs = append(outputs, master)
+			run.readers.Add(1)
+			go run.read(master, Stdout)
+		}
+	}
+	if !run.PTY {
+		stdout, stdoutW, err := os.Pipe()
+		if err != nil {
+			cancel()
+			return nil, fmt.Errorf("failed to start nx: %w", err)
+		}
+		stderr, stderrW, err := os.Pipe()
+		if err != nil {
+			stdout.Close()
+			stdoutW.Close()
+			cancel()
+			return nil, fmt.Errorf("failed to start nx: %w", err)
+		}
+		defer stdoutW.Close()
+		defer stderrW.Close()
+		cmd.Stdout, cmd.Stderr = stdoutW, stderrW
+		cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+		outputs = append(outputs, stdout, stderr)
+		run.readers.Add(2)
+		go run.read(stdout, Stdout)
+		go run.read(stderr, Stderr)
+	}
+
+	run.StartedAt = time.Now()
+	if err := cmd.Start(); err != nil {
+		for _, output := range outputs {
+			output.Close()
+		}
+		cancel()
+		return nil, fmt.Errorf("failed to start nx: %w", err)
+	}
+	run.PID = cmd.Process.Pid
+
+	gracePeriod := r.GracePeriod
+	if gracePeriod <= 0 {
+		gracePeriod = DefaultGracePeriod
+	}
+	go run.forwardCancel(ctx, gracePeriod)
+	go run.wait(cmd, outputs)
+
+	return run, nil
+}
+
+// termOrDefault returns the TERM of env, or a terminal supporting 256 colors.
+func termOrDefault(env []string) string {
+	for i := len(env) - 1; i >= 0; i-- {
+		if term, ok := strings.CutPrefix(env[i], "TERM="); ok && term != "" && term != "dumb" {
+			return term
+		}
+	}
+	return "xterm-256color"
+}
+
+// Result is the outcome of a run.
+type Result struct {
+	// ExitCode is -1 when nx was killed by a signal.
+	ExitCode int
+	Duration time.Duration
+	// Canceled is set when the run was interrupted by cancellation.
+	Canceled bool
+}
+
+// Success reports whether nx exited with code 0.
+func (r Result) Success() bool {
+	return r.ExitCode == 0
+}
+
+// Run is a started nx command.
+type Run struct {
+	Args      []string
+	PID       int
+	StartedAt time.Time
+	// PTY is set when the run writes to a pseudo-terminal. Stdout and stderr are
+	// indistinguishable then, and all lines are reported as Stdout.
+	PTY bool
+	// Output keeps the last lines of output.
+	Output *Scrollback
+
+	cancel  context.CancelFunc
+	onLine  func(Line)
+	onExit  func(Result)
+	emitMu  sync.Mutex
+	readers sync.WaitGroup
+	exited  chan struct{}
+	done    chan struct{}
+
+	mu       sync.Mutex
+	canceled bool
+	result   Result
+	err      error
+}
+
+// Cancel interrupts the run, like cancelling the context it was started with.
+func (r *Run) Cancel() {
+	r.cancel()
+}
+
+// Done is closed once nx exited and its output was read.
+func (r *Run) Done() <-chan struct{} {
+	return r.done
+}
+
+// Wait waits for the run to finish. The error is set when nx did not exit with code 0.
+func (r *Run) Wait() (Result, error) {
+	<-r.done
+	return r.result, r.err
+}
+
+func (r *Run) read(output *os.File, stream Stream) {
+	defer r.readers.Done()
+
+	scanner := bufio.NewScanner(output)
+	scanner.Buffer(make([]byte, 4096), maxLineLength)
+	scanner.Split(scanLines)
+	// A pseudo-terminal reports EIO once nx exited, which ends the scan like EOF
+	for scanner.Scan() {
+		r.emit(Line{Text: cleanLine(scanner.Bytes()), Stream: stream})
+	}
+}
+
+func (r *Run) emit(line Line) {
+	r.emitMu.Lock()
+	defer r.emitMu.Unlock()
+
+	r.Output.Append(line)
+	if r.onLine != nil {
+		r.onLine(line)
+	}
+}
+
+// forwardCancel interrupts the process group of the run when ctx is done, and kills it
+// when it did not exit within gracePeriod.
+func (r *Run) forwardCancel(ctx context.Context, gracePeriod time.Duration) {
+	select {
+	case <-r.exited:
+		r.cancel()
+		return
+	case <-ctx.Done():
+		select {
+		case <-r.exited:
+			return
+		default:
+		}
+	}
+
+	r.mu.Lock()
+	r.canceled = true
+	r.mu.Unlock()
+
+	_ = proc.SignalGroup(r.PID, syscall.SIGINT)
+	select {
+	case <-r.exited:
+	case <-time.After(gracePeriod):
+		_ = proc.SignalGroup(r.PID, syscall.SIGKILL)
+	}
+}
+
+func (r *Run) wait(cmd *exec.Cmd, outputs []*os.File) {
+	waitErr := cmd.Wait()
+	duration := time.Since(r.StartedAt)
+	close(r.exited)
+
+	readDone := make(chan struct{})
+	go func() {
+		r.readers.Wait()
+		close(readDone)
+	}()
+	select {
+	case <-readDone:
+	case <-time.After(drainTimeout):
+	}
+	// Closing the outputs ends the reads still blocked on processes nx left behind
+	for _, output := range outputs {
+		output.Close()
+	}
+	<-readDone
+
+	r.mu.Lock()
+	r.result = Result{ExitCode: cmd.ProcessState.ExitCode(), Duration: duration, Canceled: r.canceled}
+	r.mu.Unlock()
+
+	var exitErr *exec.ExitError
+	switch {
+	case errors.As(waitErr, &exitErr):
+		r.err = fmt.Errorf("failed to run nx %s: %w", strings.Join(r.Args, " "), waitErr)
+	case waitErr != nil:
+		r.err = fmt.Errorf("failed to wait for nx: %w", waitErr)
+	}
+	if r.onExit != nil {
+		r.onExit(r.result)
+	}
+	close(r.done)
+}
+
+// scanLines is bufio.ScanLines, but it splits lines longer than the scanner buffer
+// instead of failing.
+func scanLines(data []byte, atEOF bool) (int, []byte, error) {
+	if i := bytes.IndexByte(data, '\n'); i >= 0 {
+		return i + 1, data[:i], nil
+	}
+	if len(data) >= maxLineLength || (atEOF && len(data) > 0) {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
+
+// cleanLine drops the line ending and keeps what a terminal would show of a line that
+// was rewritten with carriage returns.
+func cleanLine(line []byte) string {
+	line = bytes.TrimRight(line, "\r")
+	if i := bytes.LastIndexByte(line, '\r'); i >= 0 {
+		line = line[i+1:]
+	}
+	return string(line)
+}
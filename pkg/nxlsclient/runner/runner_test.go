@@ -0,0 +1,172 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	nxtypes "github.com/lazyengs/lazynx/pkg/nxlsclient/nx-types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectPackageManager(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  nxtypes.PackageManager
+	}{
+		{name: "no lock file", want: nxtypes.PackageManagerNpm},
+		{name: "pnpm lock file", files: map[string]string{"pnpm-lock.yaml": ""}, want: nxtypes.PackageManagerPnpm},
+		{name: "yarn lock file", files: map[string]string{"yarn.lock": ""}, want: nxtypes.PackageManagerYarn},
+		{name: "bun lock file", files: map[string]string{"bun.lockb": ""}, want: nxtypes.PackageManagerBun},
+		{
+			name:  "packageManager field",
+			files: map[string]string{"package.json": `{"packageManager": "yarn@4.1.0"}`, "package-lock.json": ""},
+			want:  nxtypes.PackageManagerYarn,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644))
+			}
+			assert.Equal(t, tt.want, DetectPackageManager(dir))
+		})
+	}
+
+	assert.Equal(t, []string{"pnpm", "exec", "nx"}, NxCommand(nxtypes.PackageManagerPnpm))
+}
+
+func TestScrollback(t *testing.T) {
+	s := NewScrollback(3)
+	for _, text := range []string{"a", "b", "c", "d", "e"} {
+		s.Append(Line{Text: text})
+	}
+
+	assert.Equal(t, []Line{{Text: "c"}, {Text: "d"}, {Text: "e"}}, s.Lines())
+	assert.Equal(t, 3, s.Len())
+	assert.Equal(t, 2, s.Dropped())
+
+	// Lines are only allocated as they arrive
+	assert.Zero(t, cap(NewScrollback(0).lines))
+}
+
+func TestCleanLine(t *testing.T) {
+	assert.Equal(t, "\x1b[32mdone\x1b[39m", cleanLine([]byte("\x1b[32mdone\x1b[39m\r")))
+	assert.Equal(t, "[====] 100%", cleanLine([]byte("[=   ] 25%\r[==  ] 50%\r[====] 100%\r")))
+}
+
+// fakeNx writes a shell script standing in for nx and returns a runner using it.
+func fakeNx(t *testing.T, script string) *Runner {
+	t.Helper()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "nx")
+	require.NoError(t, os.WriteFile(path, []byte("#!/bin/sh\n"+script), 0o755))
+
+	r := NewRunner(dir)
+	r.Command = []string{"sh", path}
+	r.GracePeriod = 500 * time.Millisecond
+	return r
+}
+
+func TestStartTarget(t *testing.T) {
+	r := fakeNx(t, `echo "$@"
+echo "to stderr" >&2
+exit 3
+`)
+	r.PTY = false
+
+	var lines []Line
+	run, err := r.StartTarget(context.Background(), nxtypes.Target{Project: "app", Target: "build", Configuration: "production"},
+		[]string{"--skip-nx-cache"}, func(line Line) { lines = append(lines, line) })
+	require.NoError(t, err)
+
+	result, err := run.Wait()
+	assert.ErrorContains(t, err, "failed to run nx run app:build:production --skip-nx-cache")
+	assert.Equal(t, 3, result.ExitCode)
+	assert.False(t, result.Success())
+	assert.False(t, result.Canceled)
+	assert.Positive(t, result.Duration)
+	assert.ElementsMatch(t, []Line{
+		{Text: "run app:build:production --skip-nx-cache", Stream: Stdout},
+		{Text: "to stderr", Stream: Stderr},
+	}, lines)
+	assert.ElementsMatch(t, lines, run.Output.Lines())
+}
+
+func TestStartPTY(t *testing.T) {
+	r := fakeNx(t, `if [ -t 1 ]; then echo "terminal $TERM"; else echo "pipe"; fi
+echo "to stderr" >&2
+`)
+	r.Env = []string{"PATH=" + os.Getenv("PATH")}
+
+	run, err := r.Start(context.Background(), []string{"run", "app:build"}, nil)
+	require.NoError(t, err)
+	result, err := run.Wait()
+	require.NoError(t, err)
+	assert.True(t, result.Success())
+
+	if !run.PTY {
+		t.Skip("no pseudo-terminal available")
+	}
+	assert.Equal(t, []Line{
+		{Text: "terminal xterm-256color", Stream: Stdout},
+		{Text: "to stderr", Stream: Stdout},
+	}, run.Output.Lines())
+}
+
+func TestCancel(t *testing.T) {
+	for _, pty := range []bool{false, true} {
+		t.Run(fmt.Sprintf("interrupt with pty %v", pty), func(t *testing.T) {
+			r := fakeNx(t, `trap 'echo interrupted; exit 130' INT
+echo started
+while true; do sleep 0.05; done
+`)
+			r.PTY = pty
+
+			started := make(chan struct{}, 1)
+			run, err := r.Start(context.Background(), []string{"run", "app:serve"}, func(line Line) {
+				if line.Text == "started" {
+					started <- struct{}{}
+				}
+			})
+			require.NoError(t, err)
+			<-started
+			run.Cancel()
+
+			result, err := run.Wait()
+			assert.Error(t, err)
+			assert.True(t, result.Canceled)
+			assert.Equal(t, 130, result.ExitCode)
+			assert.Equal(t, "interrupted", run.Output.Lines()[1].Text)
+		})
+	}
+
+	t.Run("kill after grace period", func(t *testing.T) {
+		r := fakeNx(t, `trap '' INT
+echo started
+sleep 10
+`)
+		r.PTY = false
+
+		ctx, cancel := context.WithCancel(context.Background())
+		started := make(chan struct{}, 1)
+		run, err := r.Start(ctx, []string{"run", "app:serve"}, func(Line) { started <- struct{}{} })
+		require.NoError(t, err)
+		<-started
+		cancel()
+
+		result, err := run.Wait()
+		assert.Error(t, err)
+		assert.True(t, result.Canceled)
+		assert.Equal(t, -1, result.ExitCode)
+		assert.Less(t, result.Duration, 5*time.Second)
+	})
+}
@@ -0,0 +1,87 @@
+package runner
+
+import (
+	"regexp"
+	"sync"
+)
+
+// DefaultScrollback is the number of lines a run keeps when Runner.Scrollback is unset.
+const DefaultScrollback = 10000
+
+// Stream is the output stream a line was written to.
+type Stream int
+
+const (
+	Stdout Stream = iota
+	Stderr
+)
+
+// Line is a line of output without its line ending. It keeps ANSI escape sequences;
+// a line rewritten with carriage returns, as progress bars do, holds its last state.
+type Line struct {
+	Text   string
+	Stream Stream
+}
+
+var ansiPattern = regexp.MustCompile(`\x1b\[[0-9;?]*[A-Za-z]`)
+
+// StripANSI removes the ANSI escape sequences, such as colors, from the text of a line.
+func StripANSI(text string) string {
+	return ansiPattern.ReplaceAllString(text, "")
+}
+
+// Scrollback keeps the last lines of a run. It is safe for concurrent use.
+type Scrollback struct {
+	mu       sync.Mutex
+	lines    []Line
+	capacity int
+	start    int
+	dropped  int
+}
+
+// NewScrollback creates a scrollback that keeps at most capacity lines, DefaultScrollback
+// when capacity is not positive. It grows as lines arrive, so quiet tasks stay small.
+func NewScrollback(capacity int) *Scrollback {
+	if capacity <= 0 {
+		capacity = DefaultScrollback
+	}
+	return &Scrollback{capacity: capacity}
+}
+
+// Append adds line, dropping the oldest line when the scrollback is full.
+func (s *Scrollback) Append(line Line) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.lines) < s.capacity {
+		s.lines = append(s.lines, line)
+		return
+	}
+	s.lines[s.start] = line
+	s.start = (s.start + 1) % len(s.lines)
+	s.dropped++
+}
+
+// Lines returns a copy of the kept lines, oldest first.
+func (s *Scrollback) Lines() []Line {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	lines := make([]Line, 0, len(s.lines))
+	lines = append(lines, s.lines[s.start:]...)
+	return append(lines, s.lines[:s.start]...)
+}
+
+// Len returns the number of kept lines.
+func (s *Scrollback) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.lines)
+}
+
+// Dropped returns the number of lines dropped because the scrollback was full.
+func (s *Scrollback) Dropped() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.dropped
+}
@@ -0,0 +1,338 @@
+package runner
+
+import (
+	"context"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	nxtypes "github.com/lazyengs/lazynx/pkg/nxlsclient/nx-types"
+)
+
+// Many is an nx run-many or nx affected command.
+type Many struct {
+	// Affected runs the targets of the projects affected by the changes between Base and
+	// Head instead of all projects.
+	Affected bool
+	Targets  []string
+	// Projects limits run-many to these projects, all projects when empty.
+	Projects []string
+	Exclude  []string
+	// Configuration is the target configuration, such as "production".
+	Configuration string
+	Base, Head    string
+	// Parallel is the number of tasks run at once, the nx default when 0.
+	Parallel int
+	// Args are passed to nx as is.
+	Args []string
+}
+
+// NxArgs returns the nx arguments of the command.
+func (m Many) NxArgs() []string {
+	args := []string{"run-many"}
+	if m.Affected {
+		args = []string{"affected"}
+	}
+	args = append(args, "--targets="+strings.Join(m.Targets, ","))
+	if !m.Affected && len(m.Projects) > 0 {
+		args = append(args, "--projects="+strings.Join(m.Projects, ","))
+	}
+	if len(m.Exclude) > 0 {
+		args = append(args, "--exclude="+strings.Join(m.Exclude, ","))
+	}
+	if m.Configuration != "" {
+		args = append(args, "--configuration="+m.Configuration)
+	}
+	if m.Affected && m.Base != "" {
+		args = append(args, "--base="+m.Base)
+	}
+	if m.Affected && m.Head != "" {
+		args = append(args, "--head="+m.Head)
+	}
+	if m.Parallel > 0 {
+		args = append(args, "--parallel="+strconv.Itoa(m.Parallel))
+	}
+	return append(args, m.Args...)
+}
+
+// TaskStatus is where a task stands in a run.
+type TaskStatus string
+
+const (
+	// TaskPending is a task expected in the run that nx did not report yet.
+	TaskPending TaskStatus = "pending"
+	// TaskRunning is a task whose output nx is writing.
+	TaskRunning TaskStatus = "running"
+	// TaskFinished is a task whose output ended before nx reported whether it failed,
+	// which it does in the summary at the end of the run.
+	TaskFinished  TaskStatus = "finished"
+	TaskSucceeded TaskStatus = "success"
+	// TaskCached is a task whose output was restored from the cache.
+	TaskCached TaskStatus = "cached"
+	TaskFailed TaskStatus = "failure"
+	// TaskSkipped is an expected task nx never ran, usually because a task it depends on
+	// failed.
+	TaskSkipped  TaskStatus = "skipped"
+	TaskCanceled TaskStatus = "canceled"
+)
+
+// Done reports whether the task will not change anymore.
+func (s TaskStatus) Done() bool {
+	switch s {
+	case TaskSucceeded, TaskCached, TaskFailed, TaskSkipped, TaskCanceled:
+		return true
+	}
+	return false
+}
+
+// Task is a task of a run and its share of the output.
+type Task struct {
+	// ID is project:target or project:target:configuration, as nx names tasks.
+	ID     string
+	Status TaskStatus
+	// Cache is where a cached result came from, such as "local cache".
+	Cache  string
+	Output *Scrollback
+}
+
+// Target returns the target the task runs.
+func (t Task) Target() nxtypes.Target {
+	parts := strings.SplitN(t.ID, ":", 3)
+	target := nxtypes.Target{Project: parts[0]}
+	if len(parts) > 1 {
+		target.Target = parts[1]
+	}
+	if len(parts) > 2 {
+		target.Configuration = parts[2]
+	}
+	return target
+}
+
+// Tasks splits the output of runs into per-task logs. The same Tasks can follow a run
+// and later reruns of some of its tasks. It is safe for concurrent use.
+type Tasks struct {
+	mu         sync.Mutex
+	scrollback int
+	tasks      []*Task
+	byID       map[string]*Task
+	changed    chan struct{}
+}
+
+// NewTasks creates a task list keeping at most scrollback lines per task,
+// DefaultScrollback when scrollback is not positive.
+func NewTasks(scrollback int) *Tasks {
+	return &Tasks{
+		scrollback: scrollback,
+		byID:       make(map[string]*Task),
+		changed:    make(chan struct{}, 1),
+	}
+}
+
+// Expect adds the tasks of ids as pending, so they are listed before nx reports them.
+// Tasks already known are reset to pending with an empty output, which is how a task is
+// prepared for a rerun.
+func (t *Tasks) Expect(ids ...string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, id := range ids {
+		task := t.task(id)
+		task.Status = TaskPending
+		task.Cache = ""
+		task.Output = NewScrollback(t.scrollback)
+	}
+	t.notify()
+}
+
+// List returns the tasks in the order they were expected or reported.
+func (t *Tasks) List() []Task {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	list := make([]Task, len(t.tasks))
+	for i, task := range t.tasks {
+		list[i] = *task
+	}
+	return list
+}
+
+// Get returns the task id.
+func (t *Tasks) Get(id string) (Task, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	task, ok := t.byID[id]
+	if !ok {
+		return Task{}, false
+	}
+	return *task, true
+}
+
+// Failed returns the ids of the failed tasks.
+func (t *Tasks) Failed() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var ids []string
+	for _, task := range t.tasks {
+		if task.Status == TaskFailed {
+			ids = append(ids, task.ID)
+		}
+	}
+	return ids
+}
+
+// Changed receives a value after tasks were added, changed status or received output.
+// Changes made while nobody receives are coalesced into one.
+func (t *Tasks) Changed() <-chan struct{} {
+	return t.changed
+}
+
+// task returns the task id, adding it when it is unknown. t.mu must be held.
+func (t *Tasks) task(id string) *Task {
+	task, ok := t.byID[id]
+	if !ok {
+		task = &Task{ID: id, Status: TaskPending, Output: NewScrollback(t.scrollback)}
+		t.byID[id] = task
+		t.tasks = append(t.tasks, task)
+	}
+	return task
+}
+
+// notify signals a change without blocking. t.mu must be held.
+func (t *Tasks) notify() {
+	select {
+	case t.changed <- struct{}{}:
+	default:
+	}
+}
+
+// StartTasks runs nx with args, such as the NxArgs of a Many, and follows its output in
+// tasks. Nx writes the output of a task after a "> nx run project:target" header, and
+// the failed tasks in its summary; the dynamic output nx uses in a terminal is turned off
+// so the output keeps that shape.
+func (r *Runner) StartTasks(ctx context.Context, args []string, tasks *Tasks) (*Run, error) {
+	p := &taskParser{tasks: tasks}
+	for _, task := range tasks.List() {
+		if task.Status == TaskPending {
+			p.expected = append(p.expected, task.ID)
+		}
+	}
+	return r.start(ctx, args, []string{"NX_TASKS_RUNNER_DYNAMIC_OUTPUT=false"}, p.write, p.finish)
+}
+
+var (
+	taskHeaderPattern = regexp.MustCompile(`^\s*>\s+nx run (\S+)(.*)$`)
+	cacheStatePattern = regexp.MustCompile(`\[(local cache|remote cache|existing outputs match the cache, left as is)\]`)
+	summaryPattern    = regexp.MustCompile(`^\s*(?:>\s+)?NX\s+(?:Successfully ran|Ran targets?|Running targets? .* failed)`)
+	failedTaskPattern = regexp.MustCompile(`^\s*-\s+(\S+:\S+)\s*$`)
+)
+
+// taskParser attributes the output of one run to its tasks.
+type taskParser struct {
+	tasks *Tasks
+	// expected are the tasks pending when the run started.
+	expected []string
+	// seen are the tasks the run reported, in order.
+	seen    []string
+	current string
+	// summary is set once nx started its summary, failedList while it lists failed tasks.
+	summary, failedList bool
+}
+
+func (p *taskParser) write(line Line) {
+	plain := StripANSI(line.Text)
+
+	p.tasks.mu.Lock()
+	defer p.tasks.mu.Unlock()
+	defer p.tasks.notify()
+
+	if match := taskHeaderPattern.FindStringSubmatch(plain); match != nil && !p.summary {
+		p.endCurrent()
+		task := p.tasks.task(match[1])
+		task.Status = TaskRunning
+		task.Cache = ""
+		if cache := cacheStatePattern.FindStringSubmatch(match[2]); cache != nil {
+			task.Status = TaskCached
+			task.Cache = cache[1]
+		}
+		p.current = task.ID
+		if !slices.Contains(p.seen, task.ID) {
+			p.seen = append(p.seen, task.ID)
+		}
+		return
+	}
+
+	if summaryPattern.MatchString(plain) {
+		p.endCurrent()
+		p.summary = true
+		return
+	}
+
+	if p.summary {
+		trimmed := strings.TrimSpace(plain)
+		switch {
+		case strings.HasPrefix(trimmed, "Failed tasks:") || strings.HasSuffix(trimmed, "including the following:"):
+			p.failedList = true
+		case p.failedList && trimmed == "":
+		case p.failedList:
+			match := failedTaskPattern.FindStringSubmatch(plain)
+			if match == nil {
+				p.failedList = false
+				return
+			}
+			p.tasks.task(match[1]).Status = TaskFailed
+		}
+		return
+	}
+
+	if p.current != "" {
+		p.tasks.byID[p.current].Output.Append(line)
+	}
+}
+
+// endCurrent ends the output of the current task. p.tasks.mu must be held.
+func (p *taskParser) endCurrent() {
+	if p.current == "" {
+		return
+	}
+	if task := p.tasks.byID[p.current]; task.Status == TaskRunning {
+		task.Status = TaskFinished
+	}
+	p.current = ""
+}
+
+// finish settles the tasks of the run once nx exited.
+func (p *taskParser) finish(result Result) {
+	p.tasks.mu.Lock()
+	defer p.tasks.mu.Unlock()
+	defer p.tasks.notify()
+
+	for _, id := range p.seen {
+		task := p.tasks.byID[id]
+		switch {
+		case task.Status != TaskRunning && task.Status != TaskFinished:
+		case result.Canceled:
+			task.Status = TaskCanceled
+		case result.Success() || p.summary && task.Status == TaskFinished:
+			// Nx lists the failed tasks in its summary, so a finished task that is not
+			// listed succeeded. Without a summary, nx gave no verdict.
+			task.Status = TaskSucceeded
+		default:
+			task.Status = TaskFailed
+		}
+	}
+
+	for _, id := range p.expected {
+		task := p.tasks.byID[id]
+		if task == nil || task.Status != TaskPending {
+			continue
+		}
+		task.Status = TaskSkipped
+		if result.Canceled {
+			task.Status = TaskCanceled
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package runner
+
+import (
+	"context"
+	"testing"
+
+	nxtypes "github.com/lazyengs/lazynx/pkg/nxlsclient/nx-types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManyNxArgs(t *testing.T) {
+	assert.Equal(t, []string{"run-many", "--targets=lint,test", "--projects=app,ui", "--parallel=3"},
+		Many{Targets: []string{"lint", "test"}, Projects: []string{"app", "ui"}, Parallel: 3, Base: "main"}.NxArgs())
+	assert.Equal(t, []string{"affected", "--targets=build", "--configuration=production", "--base=main", "--head=HEAD", "--skip-nx-cache"},
+		Many{Affected: true, Targets: []string{"build"}, Projects: []string{"app"}, Configuration: "production",
+			Base: "main", Head: "HEAD", Args: []string{"--skip-nx-cache"}}.NxArgs())
+}
+
+// runManyOutput is shaped like nx run-many with static output, where one of three tasks fails.
+const runManyOutput = `
+ \033[1m>\033[22m  \033[1mNX\033[22m   Running targets lint, test for 2 projects:
+
+    - app
+    - ui
+
+ ——————————————————————————————————————————————
+
+\033[2m>\033[22m nx run ui:lint  \033[2m[local cache]\033[22m
+
+All files pass linting.
+
+\033[2m>\033[22m nx run app:lint
+
+/app/src/main.ts
+  1:1  error  Unexpected console statement  no-console
+
+\033[2m>\033[22m nx run ui:test
+
+PASS src/ui.spec.ts
+
+ ——————————————————————————————————————————————
+
+ >  NX   Ran targets lint, test for 2 projects (3s)
+
+    ✔  2/3 succeeded [1 read from cache]
+
+    ✖  1/3 targets failed, including the following:
+
+       - app:lint
+
+`
+
+func TestStartTasks(t *testing.T) {
+	r := fakeNx(t, `printf "`+runManyOutput+`"
+[ "$NX_TASKS_RUNNER_DYNAMIC_OUTPUT" = "false" ] || echo "dynamic output"
+exit 1
+`)
+	r.PTY = false
+
+	tasks := NewTasks(0)
+	tasks.Expect("app:lint", "ui:lint", "app:test", "ui:test")
+	run, err := r.StartTasks(context.Background(), Many{Targets: []string{"lint", "test"}}.NxArgs(), tasks)
+	require.NoError(t, err)
+	_, err = run.Wait()
+	assert.Error(t, err)
+
+	statuses := make(map[string]TaskStatus)
+	for _, task := range tasks.List() {
+		statuses[task.ID] = task.Status
+	}
+	assert.Equal(t, map[string]TaskStatus{
+		"app:lint": TaskFailed,
+		"ui:lint":  TaskCached,
+		"app:test": TaskSkipped,
+		"ui:test":  TaskSucceeded,
+	}, statuses)
+	assert.Equal(t, []string{"app:lint"}, tasks.Failed())
+
+	task, ok := tasks.Get("app:lint")
+	require.True(t, ok)
+	assert.Equal(t, nxtypes.Target{Project: "app", Target: "lint"}, task.Target())
+	assert.Equal(t, []Line{
+		{Text: ""},
+		{Text: "/app/src/main.ts"},
+		{Text: "  1:1  error  Unexpected console statement  no-console"},
+		{Text: ""},
+	}, task.Output.Lines())
+	cached, _ := tasks.Get("ui:lint")
+	assert.Equal(t, "local cache", cached.Cache)
+
+	// Rerunning the failed task replaces its output
+	r = fakeNx(t, `printf "\n> nx run app:lint\n\nAll files pass linting.\n\n >  NX   Successfully ran target lint for project app\n"`)
+	r.PTY = false
+	tasks.Expect(tasks.Failed()...)
+	run, err = r.StartTasks(context.Background(), TargetArgs(task.Target()), tasks)
+	require.NoError(t, err)
+	_, err = run.Wait()
+	require.NoError(t, err)
+
+	task, _ = tasks.Get("app:lint")
+	assert.Equal(t, TaskSucceeded, task.Status)
+	assert.Equal(t, []Line{{Text: ""}, {Text: "All files pass linting."}, {Text: ""}}, task.Output.Lines())
+	skipped, _ := tasks.Get("app:test")
+	assert.Equal(t, TaskSkipped, skipped.Status)
+	assert.Empty(t, tasks.Failed())
+}
+
+func TestFinishWithoutSummary(t *testing.T) {
+	run := func(result Result) map[string]TaskStatus {
+		tasks := NewTasks(0)
+		tasks.Expect("app:build", "ui:build", "e2e:build")
+		p := &taskParser{tasks: tasks, expected: []string{"app:build", "ui:build", "e2e:build"}}
+		for _, text := range []string{"> nx run app:build", "built", "> nx run ui:build", "building"} {
+			p.write(Line{Text: text})
+		}
+		p.finish(result)
+
+		statuses := make(map[string]TaskStatus)
+		for _, task := range tasks.List() {
+			statuses[task.ID] = task.Status
+		}
+		return statuses
+	}
+
+	// A canceled run settles nothing as succeeded, even tasks whose output ended
+	assert.Equal(t, map[string]TaskStatus{
+		"app:build": TaskCanceled,
+		"ui:build":  TaskCanceled,
+		"e2e:build": TaskCanceled,
+	}, run(Result{ExitCode: -1, Canceled: true}))
+
+	// Nx failed without listing the failed tasks
+	assert.Equal(t, map[string]TaskStatus{
+		"app:build": TaskFailed,
+		"ui:build":  TaskFailed,
+		"e2e:build": TaskSkipped,
+	}, run(Result{ExitCode: 1}))
+}
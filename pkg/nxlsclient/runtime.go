@@ -0,0 +1,340 @@
+package nxlsclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// MinimumNodeVersion is the oldest Node.js release the bundled nxls server runs on.
+// server/nxls/package.json declares no engines, so this follows upstream: nxls is built
+// from nx-console, whose extensions and the Nx releases it supports (17 and later) need
+// Node.js 18. Revisit it when updating the bundled server.
+var MinimumNodeVersion = NodeVersion{Major: 18}
+
+// NodeVersionSource identifies where a Node.js version requirement was read from.
+type NodeVersionSource string
+
+const (
+	NodeVersionSourceNvmrc       NodeVersionSource = ".nvmrc"
+	NodeVersionSourceNodeVersion NodeVersionSource = ".node-version"
+	NodeVersionSourceVolta       NodeVersionSource = "package.json#volta.node"
+	NodeVersionSourceEngines     NodeVersionSource = "package.json#engines.node"
+)
+
+// NodeVersionRequirement is a version constraint declared by the workspace.
+type NodeVersionRequirement struct {
+	Source NodeVersionSource
+	Range  NodeVersionRange
+}
+
+// NodeRuntime describes the Node.js installation used to run nxls and npm.
+type NodeRuntime struct {
+	NodePath     string                   // NodePath is the absolute path of the node binary.
+	NpmPath      string                   // NpmPath is the npm binary that ships with NodePath.
+	NpxPath      string                   // NpxPath is the npx binary that ships with NodePath.
+	Version      NodeVersion              // Version is the version reported by `node --version`.
+	Requirements []NodeVersionRequirement // Requirements are the pins found in the workspace.
+	Warnings     []string                 // Warnings lists workspace pins the runtime does not satisfy.
+}
+
+// NodeRuntimeErrorReason categorises a NodeRuntimeError.
+type NodeRuntimeErrorReason string
+
+const (
+	NodeRuntimeNotFound       NodeRuntimeErrorReason = "not-found"
+	NodeRuntimeTooOld         NodeRuntimeErrorReason = "too-old"
+	NodeRuntimeUnknownVersion NodeRuntimeErrorReason = "unknown-version"
+)
+
+// NodeRuntimeError is returned by Start when no usable Node.js runtime is available.
+type NodeRuntimeError struct {
+	Reason   NodeRuntimeErrorReason
+	NodePath string      // NodePath is the binary that was probed, if any.
+	Version  NodeVersion // Version is the detected version for NodeRuntimeTooOld.
+	Required NodeVersion // Required is the minimum version nxls needs.
+	Err      error
+}
+
+func (e *NodeRuntimeError) Error() string {
+	switch e.Reason {
+	case NodeRuntimeNotFound:
+		return fmt.Sprintf("node.js runtime not found: nxls requires node %s or later", e.Required)
+	case NodeRuntimeTooOld:
+		return fmt.Sprintf("node.js %s at %s is too old: nxls requires node %s or later", e.Version, e.NodePath, e.Required)
+	default:
+		return fmt.Sprintf("unable to determine the version of node at %s: %v", e.NodePath, e.Err)
+	}
+}
+
+func (e *NodeRuntimeError) Unwrap() error {
+	return e.Err
+}
+
+// Runtime returns the Node.js runtime resolved by Start, or nil before it has been resolved.
+func (c *Client) Runtime() *NodeRuntime {
+	return c.runtime
+}
+
+// resolveNodeRuntime discovers the node binary to use for the workspace and validates it.
+func (c *Client) resolveNodeRuntime(ctx context.Context) (*NodeRuntime, error) {
+	requirements := readNodeVersionRequirements(c.NxWorkspacePath)
+	for _, req := range requirements {
+		c.Logger.Debugw("Found node version requirement", "source", req.Source, "range", req.Range.String())
+	}
+
+	nodePath, err := findNode(ctx, requirements)
+	if err != nil {
+		return nil, &NodeRuntimeError{Reason: NodeRuntimeNotFound, Required: MinimumNodeVersion, Err: err}
+	}
+
+	version, err := probeNodeVersion(ctx, nodePath)
+	if err != nil {
+		return nil, &NodeRuntimeError{Reason: NodeRuntimeUnknownVersion, NodePath: nodePath, Required: MinimumNodeVersion, Err: err}
+	}
+	if version.Compare(MinimumNodeVersion) < 0 {
+		return nil, &NodeRuntimeError{Reason: NodeRuntimeTooOld, NodePath: nodePath, Version: version, Required: MinimumNodeVersion}
+	}
+
+	runtime := &NodeRuntime{
+		NodePath:     nodePath,
+		NpmPath:      siblingBinary(nodePath, "npm"),
+		NpxPath:      siblingBinary(nodePath, "npx"),
+		Version:      version,
+		Requirements: requirements,
+	}
+
+	for _, req := range requirements {
+		if !req.Range.Contains(version) {
+			warning := fmt.Sprintf("node %s does not satisfy %q from %s", version, req.Range.String(), req.Source)
+			runtime.Warnings = append(runtime.Warnings, warning)
+			c.Logger.Warnw("Node version does not match workspace requirement", "version", version.String(), "source", req.Source, "range", req.Range.String())
+		}
+	}
+
+	c.Logger.Infow("Resolved node runtime", "node", runtime.NodePath, "version", version.String())
+	return runtime, nil
+}
+
+// readNodeVersionRequirements reads .nvmrc, .node-version and package.json volta/engines pins.
+// Aliases such as "lts/*" or "node" cannot be checked and are ignored.
+func readNodeVersionRequirements(workspacePath string) []NodeVersionRequirement {
+	var requirements []NodeVersionRequirement
+
+	add := func(source NodeVersionSource, value string) {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			return
+		}
+		r, err := ParseNodeVersionRange(value)
+		if err != nil {
+			return
+		}
+		requirements = append(requirements, NodeVersionRequirement{Source: source, Range: r})
+	}
+
+	for _, source := range []NodeVersionSource{NodeVersionSourceNvmrc, NodeVersionSourceNodeVersion} {
+		data, err := os.ReadFile(filepath.Join(workspacePath, string(source)))
+		if err == nil {
+			// Only the first line is meaningful, the rest may hold comments
+			line, _, _ := strings.Cut(string(data), "\n")
+			add(source, line)
+		}
+	}
+
+	data, err := os.ReadFile(filepath.Join(workspacePath, "package.json"))
+	if err == nil {
+		var pkg struct {
+			Volta struct {
+				Node string `json:"node"`
+			} `json:"volta"`
+			Engines struct {
+				Node string `json:"node"`
+			} `json:"engines"`
+		}
+		if json.Unmarshal(data, &pkg) == nil {
+			add(NodeVersionSourceVolta, pkg.Volta.Node)
+			add(NodeVersionSourceEngines, pkg.Engines.Node)
+		}
+	}
+
+	return requirements
+}
+
+// findNode picks the node binary for the workspace. Pins from .nvmrc, .node-version and
+// volta select an nvm or volta install, in that order. Otherwise the node on PATH is used
+// unless it does not satisfy the engines range and an install does.
+func findNode(ctx context.Context, requirements []NodeVersionRequirement) (string, error) {
+	var ranges []NodeVersionRange
+	for _, req := range requirements {
+		if req.Source == NodeVersionSourceEngines {
+			ranges = append(ranges, req.Range)
+			continue
+		}
+		if path := findInstalledNode(req.Range); path != "" {
+			return path, nil
+		}
+	}
+
+	pathNode, err := exec.LookPath("node")
+	if err == nil && satisfiesAll(ctx, pathNode, ranges) {
+		return pathNode, nil
+	}
+	for _, r := range ranges {
+		if path := findInstalledNode(r); path != "" {
+			return path, nil
+		}
+	}
+	return pathNode, err
+}
+
+// satisfiesAll reports whether the node at nodePath is in every range. A node whose
+// version cannot be read is left for the caller to reject.
+func satisfiesAll(ctx context.Context, nodePath string, ranges []NodeVersionRange) bool {
+	if len(ranges) == 0 {
+		return true
+	}
+	version, err := probeNodeVersion(ctx, nodePath)
+	if err != nil {
+		return true
+	}
+	for _, r := range ranges {
+		if !r.Contains(version) {
+			return false
+		}
+	}
+	return true
+}
+
+// findInstalledNode looks for the newest node matching pin in nvm or volta tool
+// directories. It returns an empty string when nothing suitable is installed.
+func findInstalledNode(pin NodeVersionRange) string {
+	for _, dir := range nodeInstallDirs() {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+
+		type candidate struct {
+			version NodeVersion
+			path    string
+		}
+		var candidates []candidate
+		for _, entry := range entries {
+			if !entry.IsDir() {
+				continue
+			}
+			v, err := ParseNodeVersion(entry.Name())
+			if err != nil || !pin.Contains(v) {
+				continue
+			}
+			bin := filepath.Join(dir, entry.Name(), "bin", "node")
+			if _, err := os.Stat(bin); err == nil {
+				candidates = append(candidates, candidate{version: v, path: bin})
+			}
+		}
+
+		if len(candidates) > 0 {
+			sort.Slice(candidates, func(i, j int) bool {
+				return candidates[i].version.Compare(candidates[j].version) > 0
+			})
+			return candidates[0].path
+		}
+	}
+
+	return ""
+}
+
+// nodeInstallDirs returns the version directories used by nvm and volta.
+func nodeInstallDirs() []string {
+	var dirs []string
+
+	home, _ := os.UserHomeDir()
+
+	nvmDir := os.Getenv("NVM_DIR")
+	if nvmDir == "" && home != "" {
+		nvmDir = filepath.Join(home, ".nvm")
+	}
+	if nvmDir != "" {
+		dirs = append(dirs, filepath.Join(nvmDir, "versions", "node"))
+	}
+
+	voltaHome := os.Getenv("VOLTA_HOME")
+	if voltaHome == "" && home != "" {
+		voltaHome = filepath.Join(home, ".volta")
+	}
+	if voltaHome != "" {
+		dirs = append(dirs, filepath.Join(voltaHome, "tools", "image", "node"))
+	}
+
+	return dirs
+}
+
+// probeNodeVersion runs `node --version` and parses its output.
+func probeNodeVersion(ctx context.Context, nodePath string) (NodeVersion, error) {
+	out, err := exec.CommandContext(ctx, nodePath, "--version").Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+			return NodeVersion{}, fmt.Errorf("%w: %s", err, strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return NodeVersion{}, err
+	}
+
+	return ParseNodeVersion(strings.TrimSpace(string(out)))
+}
+
+// siblingBinary returns the named binary next to nodePath, falling back to PATH lookup
+// and finally to the bare name.
+func siblingBinary(nodePath, name string) string {
+	sibling := filepath.Join(filepath.Dir(nodePath), name)
+	if _, err := os.Stat(sibling); err == nil {
+		return sibling
+	}
+	if p, err := exec.LookPath(name); err == nil {
+		return p
+	}
+	return name
+}
+
+// nodeCommand returns the binary to run for name ("node", "npm" or "npx"), preferring
+// the resolved runtime when available.
+func (c *Client) nodeCommand(name string) string {
+	if c.runtime == nil {
+		return name
+	}
+	switch name {
+	case "node":
+		return c.runtime.NodePath
+	case "npm":
+		return c.runtime.NpmPath
+	case "npx":
+		return c.runtime.NpxPath
+	}
+	return name
+}
+
+// nodeEnv returns the environment for child processes with the runtime's bin directory
+// first in PATH, so npm and npx scripts resolve the same node binary.
+func (c *Client) nodeEnv() []string {
+	env := os.Environ()
+	if c.runtime == nil {
+		return env
+	}
+
+	binDir := filepath.Dir(c.runtime.NodePath)
+	for i, kv := range env {
+		if strings.HasPrefix(kv, "PATH=") {
+			env[i] = "PATH=" + binDir + string(os.PathListSeparator) + strings.TrimPrefix(kv, "PATH=")
+			return env
+		}
+	}
+
+	return append(env, "PATH="+binDir)
+}
@@ -0,0 +1,153 @@
+package nxlsclient
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// writeFakeNode creates a node executable in dir that prints the given version.
+func writeFakeNode(t *testing.T, dir, version string) string {
+	t.Helper()
+
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	nodePath := filepath.Join(dir, "node")
+	script := "#!/bin/sh\necho " + version + "\n"
+	require.NoError(t, os.WriteFile(nodePath, []byte(script), 0755))
+
+	return nodePath
+}
+
+func TestReadNodeVersionRequirements(t *testing.T) {
+	workspace := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(workspace, ".nvmrc"), []byte("v20.11.1\n# comment\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(workspace, ".node-version"), []byte("lts/iron"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(workspace, "package.json"), []byte(`{
+		"volta": {"node": "20.11.1"},
+		"engines": {"node": ">=18"}
+	}`), 0644))
+
+	requirements := readNodeVersionRequirements(workspace)
+
+	require.Len(t, requirements, 3, "lts aliases should be ignored")
+	assert.Equal(t, NodeVersionSourceNvmrc, requirements[0].Source)
+	assert.Equal(t, NodeVersionSourceVolta, requirements[1].Source)
+	assert.Equal(t, NodeVersionSourceEngines, requirements[2].Source)
+	assert.Equal(t, ">=18", requirements[2].Range.String())
+}
+
+func TestResolveNodeRuntime(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+
+	t.Run("PinnedNvmVersion", func(t *testing.T) {
+		nvmDir := t.TempDir()
+		t.Setenv("NVM_DIR", nvmDir)
+		t.Setenv("VOLTA_HOME", t.TempDir())
+		writeFakeNode(t, filepath.Join(nvmDir, "versions", "node", "v18.20.0", "bin"), "v18.20.0")
+		pinned := writeFakeNode(t, filepath.Join(nvmDir, "versions", "node", "v20.11.1", "bin"), "v20.11.1")
+
+		workspace := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(workspace, ".nvmrc"), []byte("20"), 0644))
+
+		client := &Client{Logger: logger.Sugar(), NxWorkspacePath: workspace}
+		runtime, err := client.resolveNodeRuntime(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, pinned, runtime.NodePath)
+		assert.Equal(t, NodeVersion{Major: 20, Minor: 11, Patch: 1}, runtime.Version)
+		assert.Empty(t, runtime.Warnings)
+	})
+
+	t.Run("LaterPinInstalled", func(t *testing.T) {
+		nvmDir := t.TempDir()
+		t.Setenv("NVM_DIR", nvmDir)
+		t.Setenv("VOLTA_HOME", t.TempDir())
+		t.Setenv("PATH", t.TempDir())
+		pinned := writeFakeNode(t, filepath.Join(nvmDir, "versions", "node", "v20.11.1", "bin"), "v20.11.1")
+
+		// .nvmrc asks for a release that is not installed, volta for one that is
+		workspace := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(workspace, ".nvmrc"), []byte("22"), 0644))
+		require.NoError(t, os.WriteFile(filepath.Join(workspace, "package.json"), []byte(`{"volta": {"node": "20.11.1"}}`), 0644))
+
+		client := &Client{Logger: logger.Sugar(), NxWorkspacePath: workspace}
+		runtime, err := client.resolveNodeRuntime(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, pinned, runtime.NodePath)
+		assert.Len(t, runtime.Warnings, 1)
+	})
+
+	t.Run("EnginesRangePrefersPath", func(t *testing.T) {
+		nvmDir := t.TempDir()
+		t.Setenv("NVM_DIR", nvmDir)
+		t.Setenv("VOLTA_HOME", t.TempDir())
+		writeFakeNode(t, filepath.Join(nvmDir, "versions", "node", "v22.1.0", "bin"), "v22.1.0")
+		installed := writeFakeNode(t, filepath.Join(nvmDir, "versions", "node", "v20.11.1", "bin"), "v20.11.1")
+		binDir := t.TempDir()
+		onPath := writeFakeNode(t, binDir, "v18.19.0")
+		t.Setenv("PATH", binDir)
+
+		workspace := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(workspace, "package.json"), []byte(`{"engines": {"node": ">=18"}}`), 0644))
+		client := &Client{Logger: logger.Sugar(), NxWorkspacePath: workspace}
+		runtime, err := client.resolveNodeRuntime(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, onPath, runtime.NodePath)
+
+		// An install is only used when the PATH node is outside the range
+		require.NoError(t, os.WriteFile(filepath.Join(workspace, "package.json"), []byte(`{"engines": {"node": "^20"}}`), 0644))
+		runtime, err = client.resolveNodeRuntime(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, installed, runtime.NodePath)
+	})
+
+	t.Run("TooOld", func(t *testing.T) {
+		binDir := t.TempDir()
+		writeFakeNode(t, binDir, "v16.20.2")
+		t.Setenv("PATH", binDir)
+		t.Setenv("NVM_DIR", t.TempDir())
+		t.Setenv("VOLTA_HOME", t.TempDir())
+
+		client := &Client{Logger: logger.Sugar(), NxWorkspacePath: t.TempDir()}
+		_, err := client.resolveNodeRuntime(context.Background())
+
+		var runtimeErr *NodeRuntimeError
+		require.True(t, errors.As(err, &runtimeErr))
+		assert.Equal(t, NodeRuntimeTooOld, runtimeErr.Reason)
+		assert.Equal(t, NodeVersion{Major: 16, Minor: 20, Patch: 2}, runtimeErr.Version)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		t.Setenv("PATH", t.TempDir())
+		t.Setenv("NVM_DIR", t.TempDir())
+		t.Setenv("VOLTA_HOME", t.TempDir())
+
+		client := &Client{Logger: logger.Sugar(), NxWorkspacePath: t.TempDir()}
+		_, err := client.resolveNodeRuntime(context.Background())
+
+		var runtimeErr *NodeRuntimeError
+		require.True(t, errors.As(err, &runtimeErr))
+		assert.Equal(t, NodeRuntimeNotFound, runtimeErr.Reason)
+	})
+
+	t.Run("UnsatisfiedEnginesWarns", func(t *testing.T) {
+		binDir := t.TempDir()
+		writeFakeNode(t, binDir, "v18.19.0")
+		t.Setenv("PATH", binDir)
+		t.Setenv("NVM_DIR", t.TempDir())
+		t.Setenv("VOLTA_HOME", t.TempDir())
+
+		workspace := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(workspace, "package.json"), []byte(`{"engines": {"node": ">=20"}}`), 0644))
+
+		client := &Client{Logger: logger.Sugar(), NxWorkspacePath: workspace}
+		runtime, err := client.resolveNodeRuntime(context.Background())
+		require.NoError(t, err)
+		assert.Len(t, runtime.Warnings, 1)
+	})
+}
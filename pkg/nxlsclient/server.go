@@ -10,7 +10,6 @@ import (
 	"os/exec"
 	"path"
 	"path/filepath"
-	"syscall"
 )
 
 //go:embed server/nxls
@@ -18,11 +17,17 @@ var serverfs embed.FS
 
 // unpackServer unpacks the embedded nxls server to a temporary directory.
 func (c *Client) unpackServer() error {
-	tempDir, err := os.MkdirTemp("", "nxls-server")
+	tempDir, err := os.MkdirTemp("", serverTempDirPattern)
 	if err != nil {
 		return fmt.Errorf("failed to create the temp directory: %w", err)
 	}
 	c.Logger.Debugw("Created temporary directory", "tempDir", tempDir)
+	c.tempDir = tempDir
+
+	err = writeOwnerFile(tempDir)
+	if err != nil {
+		return fmt.Errorf("failed to write the owner file: %w", err)
+	}
 
 	err = os.CopyFS(tempDir, serverfs)
 	if err != nil {
@@ -36,7 +41,7 @@ func (c *Client) unpackServer() error {
 // installDependencies installs npm dependencies in the server folder.
 func (c *Client) installDependencies(ctx context.Context) error {
 	c.Logger.Debugw("Installing dependencies at ", "serverDir", c.serverDir)
-	return c.runOSCommandInServerFolder(ctx, "npm", "install")
+	return c.runOSCommandInServerFolder(ctx, c.nodeCommand("npm"), "install")
 }
 
 // runOSCommandInServerFolder runs an OS command in the server folder and logs the output.
@@ -44,6 +49,7 @@ func (c *Client) runOSCommandInServerFolder(ctx context.Context, name string, ar
 	c.Logger.Debugw("Running command", "serverDir", c.serverDir, "command", name, "args", args)
 	cmd := exec.CommandContext(ctx, name, args...)
 	cmd.Dir = c.serverDir
+	cmd.Env = c.nodeEnv()
 
 	stdout, err := cmd.StdoutPipe()
 	if err != nil {
@@ -81,13 +87,11 @@ func (c *Client) startNxls(ctx context.Context) (rwc *ReadWriteCloser, err error
 
 	c.Logger.Debugw("Starting nxls", "workspace", c.NxWorkspacePath, "serverPath", serverPath)
 
-	cmd := exec.CommandContext(ctx, "node", serverPath, "--stdio")
+	cmd := exec.CommandContext(ctx, c.nodeCommand("node"), serverPath, "--stdio")
 	cmd.Dir = c.NxWorkspacePath
+	cmd.Env = c.nodeEnv()
 
-	// Set up process group isolation (prevents signal propagation)
-	cmd.SysProcAttr = &syscall.SysProcAttr{
-		Setpgid: true, // Put the child in its own process group
-	}
+	setProcessGroup(cmd, c.ShutdownTimeouts.withDefaults().Terminate)
 
 	stdin, err := cmd.StdinPipe()
 	if err != nil {
@@ -107,8 +111,13 @@ func (c *Client) startNxls(ctx context.Context) (rwc *ReadWriteCloser, err error
 		stdout: stdout,
 	}
 
+	done := make(chan struct{})
+	c.process = cmd
+	c.processDone = done
+
 	// Start a goroutine to handle the command's completion
 	go func() {
+		defer close(done)
 		if err := cmd.Wait(); err != nil {
 			c.Logger.Errorw("Command exited with error", "error", err)
 		}
@@ -117,33 +126,50 @@ func (c *Client) startNxls(ctx context.Context) (rwc *ReadWriteCloser, err error
 	return rwc, nil
 }
 
+// stopNxls tears down nxls: it stops the Nx daemon, asks the server to shut down and exit,
+// and then escalates to SIGTERM and SIGKILL on the process group. Every step is bounded
+// by ShutdownTimeouts, even when ctx has already been cancelled.
 func (c *Client) stopNxls(ctx context.Context) error {
 	// Log the start of the stopping process
 	c.Logger.Infow("Stopping nxls server and NX daemon")
 
+	timeouts := c.ShutdownTimeouts.withDefaults()
+	stopDaemon := c.shouldStopDaemon()
 	var daemonStoppedWithLSP bool
 
+	if !stopDaemon {
+		c.Logger.Infow("Leaving NX daemon running", "policy", c.DaemonPolicy, "daemonWasRunning", c.daemonWasRunning)
+	}
+
 	// Try LSP commands to stop everything gracefully if Commander is available
 	if c.Commander != nil && c.conn != nil {
-		c.Logger.Debugw("Attempting to stop NX daemon via LSP protocol")
-
-		// Try to stop the NX daemon via LSP protocol
-		err := c.Commander.SendStopNxDaemonRequest(ctx)
-		if err != nil {
-			c.Logger.Warnw("Failed to stop NX daemon via LSP", "error", err.Error())
-		} else {
-			c.Logger.Infow("Successfully stopped NX daemon via LSP")
-			daemonStoppedWithLSP = true
+		if stopDaemon {
+			c.Logger.Debugw("Attempting to stop NX daemon via LSP protocol")
+
+			// Try to stop the NX daemon via LSP protocol
+			stepCtx, cancel := stepContext(ctx, timeouts.StopDaemon)
+			err := c.Commander.SendStopNxDaemonRequest(stepCtx)
+			cancel()
+			if err != nil {
+				c.Logger.Warnw("Failed to stop NX daemon via LSP", "error", err.Error())
+			} else {
+				c.Logger.Infow("Successfully stopped NX daemon via LSP")
+				daemonStoppedWithLSP = true
+			}
 		}
 
 		c.Logger.Debugw("Sending LSP shutdown request")
-		err = c.Commander.SendShutdownRequest(ctx)
+		stepCtx, cancel := stepContext(ctx, timeouts.Shutdown)
+		err := c.Commander.SendShutdownRequest(stepCtx)
+		cancel()
 		if err != nil {
 			c.Logger.Warnw("Failed to send LSP shutdown request", "error", err.Error())
 		}
 
 		c.Logger.Debugw("Sending LSP exit notification")
-		err = c.Commander.SendExitNotification(ctx)
+		stepCtx, cancel = stepContext(ctx, timeouts.Shutdown)
+		err = c.Commander.SendExitNotification(stepCtx)
+		cancel()
 		if err != nil {
 			c.Logger.Warnw("Failed to send LSP exit notification", "error", err.Error())
 		}
@@ -158,9 +184,11 @@ func (c *Client) stopNxls(ctx context.Context) error {
 	}
 
 	// If we couldn't stop the daemon with LSP, try with npx
-	if !daemonStoppedWithLSP {
+	if stopDaemon && !daemonStoppedWithLSP {
 		c.Logger.Infow("Falling back to npx to stop NX daemon")
-		err := c.killDaemonWithNpx(ctx)
+		stepCtx, cancel := stepContext(ctx, timeouts.StopDaemon)
+		err := c.killDaemonWithNpx(stepCtx)
+		cancel()
 		if err != nil {
 			c.Logger.Errorw("Failed to stop NX daemon with npx", "error", err.Error())
 		} else {
@@ -170,6 +198,10 @@ func (c *Client) stopNxls(ctx context.Context) error {
 
 	// Cleanup actions regardless of daemon stop success
 
+	// Make sure nxls and everything it spawned are gone
+	c.terminateNxls()
+	c.serverPid = 0
+
 	// Clean up the server folder
 	c.Logger.Debugw("Cleaning up server folder")
 	err := c.cleanUpServerFolder()
@@ -186,9 +218,12 @@ func (c *Client) stopNxls(ctx context.Context) error {
 	}
 
 	// Final cleanup status
-	if daemonStoppedWithLSP {
+	switch {
+	case !stopDaemon:
+		c.Logger.Infow("Cleanup completed successfully (daemon left running)")
+	case daemonStoppedWithLSP:
 		c.Logger.Infow("Cleanup completed successfully (daemon stopped via LSP)")
-	} else {
+	default:
 		c.Logger.Infow("Cleanup completed successfully (daemon stopped via npx)")
 	}
 	return nil
@@ -197,8 +232,9 @@ func (c *Client) stopNxls(ctx context.Context) error {
 func (c *Client) killDaemonWithNpx(ctx context.Context) error {
 	c.Logger.Debugw("Attempting to stop NX daemon using npx")
 
-	cmd := exec.CommandContext(ctx, "npx", "nx", "daemon", "--stop")
+	cmd := exec.CommandContext(ctx, c.nodeCommand("npx"), "nx", "daemon", "--stop")
 	cmd.Dir = c.NxWorkspacePath
+	cmd.Env = c.nodeEnv()
 
 	// Get stdout and stderr to log the output
 	stdout, err := cmd.StdoutPipe()
@@ -233,27 +269,33 @@ func (c *Client) killDaemonWithNpx(ctx context.Context) error {
 	return nil
 }
 
-// cleanUpServerFolder removes the temporary server directory.
+// cleanUpServerFolder removes the temporary directory the server was unpacked into.
 func (c *Client) cleanUpServerFolder() error {
-	// Skip if serverDir is empty
-	if c.serverDir == "" {
+	// Remove the whole temp directory, not only the nested server folder
+	dir := c.tempDir
+	if dir == "" {
+		dir = c.serverDir
+	}
+
+	// Skip if no directory was created
+	if dir == "" {
 		c.Logger.Debugw("Server directory not set, skipping cleanup")
 		return nil
 	}
 
 	// Check if directory exists before attempting to remove
-	_, err := os.Stat(c.serverDir)
+	_, err := os.Stat(dir)
 	if os.IsNotExist(err) {
-		c.Logger.Debugw("Server directory doesn't exist, skipping cleanup", "serverDir", c.serverDir)
+		c.Logger.Debugw("Server directory doesn't exist, skipping cleanup", "serverDir", dir)
 		return nil
 	}
 
 	// Remove the directory
-	err = os.RemoveAll(c.serverDir)
+	err = os.RemoveAll(dir)
 	if err != nil {
 		return fmt.Errorf("failed to remove the server directory: %w", err)
 	}
 
-	c.Logger.Debugw("Server directory removed", "serverDir", c.serverDir)
+	c.Logger.Debugw("Server directory removed", "serverDir", dir)
 	return nil
 }
@@ -0,0 +1,33 @@
+/*
+Package snapshot persists nx/workspace responses so a client can show a workspace before
+nxls is ready.
+
+Starting nxls means unpacking the server, installing its dependencies, starting node and
+computing the project graph, which can take tens of seconds in a large repository. A Store
+keeps the last workspace of every workspace path in a directory, keyed by a hash of the
+path, so the next start can render it right away and reconcile once the live response
+arrives.
+
+# Usage
+
+	store := snapshot.NewStore(filepath.Join(home, ".lazynx", "cache", "workspaces"))
+
+	snap, err := store.Load(workspacePath)
+	switch {
+	case err == nil:
+		render(snap.Workspace) // Mark as stale, taken at snap.SavedAt
+	case errors.Is(err, snapshot.ErrNoSnapshot):
+		// First start, or the snapshot was invalidated
+	}
+
+	// Once nxls answers
+	workspace, err := client.Commander.SendWorkspaceRequest(ctx, &commands.WorkspaceRequestParams{})
+	if err == nil {
+		err = store.Save(workspacePath, workspace)
+	}
+
+Snapshots are discarded when their format version differs from FormatVersion, or when
+the Nx version they were taken with differs from the nx package installed in the
+workspace node_modules.
+*/
+package snapshot
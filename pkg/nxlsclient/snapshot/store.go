@@ -0,0 +1,185 @@
+package snapshot
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/lazyengs/lazynx/pkg/nxlsclient/internal/atomicfile"
+	nxtypes "github.com/lazyengs/lazynx/pkg/nxlsclient/nx-types"
+)
+
+// FormatVersion is the version of the snapshot file format. Snapshots written with
+// another version are discarded.
+const FormatVersion = 1
+
+// ErrNoSnapshot is returned by Load when no usable snapshot exists for a workspace.
+var ErrNoSnapshot = errors.New("no workspace snapshot")
+
+// InvalidatedError is returned by Load when a snapshot existed but could no longer be
+// used. The snapshot file has been removed. It wraps ErrNoSnapshot.
+type InvalidatedError struct {
+	Reason string
+}
+
+func (e *InvalidatedError) Error() string {
+	return "workspace snapshot invalidated: " + e.Reason
+}
+
+func (e *InvalidatedError) Unwrap() error {
+	return ErrNoSnapshot
+}
+
+// Snapshot is a saved nx/workspace response.
+type Snapshot struct {
+	FormatVersion int                  `json:"formatVersion"`
+	WorkspacePath string               `json:"workspacePath"`
+	SavedAt       time.Time            `json:"savedAt"`
+	Workspace     *nxtypes.NxWorkspace `json:"workspace"`
+}
+
+// Store keeps one snapshot per workspace in a directory.
+type Store struct {
+	Dir string
+	// InstalledNxVersion returns the Nx version installed in a workspace, used to discard
+	// snapshots taken with another version. Empty results skip the check. Defaults to
+	// InstalledNxVersion.
+	InstalledNxVersion func(workspacePath string) (string, error)
+}
+
+// NewStore creates a store that keeps snapshots in dir.
+func NewStore(dir string) *Store {
+	return &Store{Dir: dir, InstalledNxVersion: InstalledNxVersion}
+}
+
+// Path returns the snapshot file of a workspace.
+func (s *Store) Path(workspacePath string) string {
+	sum := sha256.Sum256([]byte(absPath(workspacePath)))
+	return filepath.Join(s.Dir, hex.EncodeToString(sum[:8])+".json")
+}
+
+func absPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return filepath.Clean(path)
+}
+
+// Save replaces the snapshot of workspacePath. The file is written atomically so a
+// crash never leaves a truncated snapshot behind.
+func (s *Store) Save(workspacePath string, workspace *nxtypes.NxWorkspace) error {
+	if workspace == nil {
+		return fmt.Errorf("failed to save snapshot: no workspace")
+	}
+
+	data, err := json.Marshal(Snapshot{
+		FormatVersion: FormatVersion,
+		WorkspacePath: absPath(workspacePath),
+		SavedAt:       time.Now().UTC(),
+		Workspace:     workspace,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode snapshot: %w", err)
+	}
+
+	if err := atomicfile.WriteFile(s.Path(workspacePath), data); err != nil {
+		return fmt.Errorf("failed to save snapshot: %w", err)
+	}
+	return nil
+}
+
+// SaveSerialized saves the string returned by SendWorkspaceSerializedRequest.
+func (s *Store) SaveSerialized(workspacePath, serialized string) error {
+	var workspace nxtypes.NxWorkspace
+	if err := json.Unmarshal([]byte(serialized), &workspace); err != nil {
+		return fmt.Errorf("failed to decode serialized workspace: %w", err)
+	}
+	return s.Save(workspacePath, &workspace)
+}
+
+// Load returns the snapshot of workspacePath. It returns ErrNoSnapshot when there is
+// none, and an *InvalidatedError, removing the file, when the snapshot is unreadable,
+// has another format version, belongs to another path or was taken with another Nx
+// version than the one installed.
+func (s *Store) Load(workspacePath string) (*Snapshot, error) {
+	path := s.Path(workspacePath)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNoSnapshot
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot: %w", err)
+	}
+
+	snap, reason := s.check(workspacePath, data)
+	if reason != "" {
+		_ = os.Remove(path)
+		return nil, &InvalidatedError{Reason: reason}
+	}
+	return snap, nil
+}
+
+// check decodes and validates a snapshot, returning why it cannot be used.
+func (s *Store) check(workspacePath string, data []byte) (*Snapshot, string) {
+	// Decode the version first, later formats may not decode as a Snapshot
+	var header struct {
+		FormatVersion int `json:"formatVersion"`
+	}
+	if err := json.Unmarshal(data, &header); err != nil {
+		return nil, "unreadable: " + err.Error()
+	}
+	if header.FormatVersion != FormatVersion {
+		return nil, fmt.Sprintf("format version %d, expected %d", header.FormatVersion, FormatVersion)
+	}
+
+	var snap Snapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return nil, "unreadable: " + err.Error()
+	}
+	if snap.Workspace == nil {
+		return nil, "no workspace"
+	}
+	if snap.WorkspacePath != absPath(workspacePath) {
+		return nil, fmt.Sprintf("taken for %s", snap.WorkspacePath)
+	}
+
+	if s.InstalledNxVersion != nil {
+		installed, err := s.InstalledNxVersion(workspacePath)
+		if err == nil && installed != "" && installed != snap.Workspace.NxVersion.Full {
+			return nil, fmt.Sprintf("taken with Nx %s, Nx %s is installed", snap.Workspace.NxVersion.Full, installed)
+		}
+	}
+
+	return &snap, ""
+}
+
+// Remove deletes the snapshot of workspacePath, if any.
+func (s *Store) Remove(workspacePath string) error {
+	err := os.Remove(s.Path(workspacePath))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// InstalledNxVersion reads the version of the nx package installed in the workspace
+// node_modules, without starting node.
+func InstalledNxVersion(workspacePath string) (string, error) {
+	data, err := os.ReadFile(filepath.Join(workspacePath, "node_modules", "nx", "package.json"))
+	if err != nil {
+		return "", err
+	}
+
+	var pkg struct {
+		Version string `json:"version"`
+	}
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		return "", fmt.Errorf("failed to read the nx package version: %w", err)
+	}
+	return pkg.Version, nil
+}
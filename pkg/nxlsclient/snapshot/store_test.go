@@ -0,0 +1,118 @@
+package snapshot
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	nxtypes "github.com/lazyengs/lazynx/pkg/nxlsclient/nx-types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestWorkspace(nxVersion string) *nxtypes.NxWorkspace {
+	return &nxtypes.NxWorkspace{
+		ProjectGraph: nxtypes.ProjectGraph{
+			Nodes: map[string]nxtypes.ProjectGraphProjectNode{"app": {Name: "app", Type: "app"}},
+		},
+		NxVersion: nxtypes.NxVersion{Full: nxVersion},
+	}
+}
+
+// writeNxPackage installs a fake nx package of the given version in workspace.
+func writeNxPackage(t *testing.T, workspace, version string) {
+	t.Helper()
+	dir := filepath.Join(workspace, "node_modules", "nx")
+	require.NoError(t, os.MkdirAll(dir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "package.json"), []byte(`{"name":"nx","version":"`+version+`"}`), 0o644))
+}
+
+func TestSaveAndLoad(t *testing.T) {
+	store := NewStore(t.TempDir())
+	workspace := t.TempDir()
+	writeNxPackage(t, workspace, "20.1.0")
+
+	_, err := store.Load(workspace)
+	assert.ErrorIs(t, err, ErrNoSnapshot)
+
+	require.NoError(t, store.Save(workspace, newTestWorkspace("20.1.0")))
+
+	snap, err := store.Load(workspace)
+	require.NoError(t, err)
+	assert.Equal(t, FormatVersion, snap.FormatVersion)
+	assert.Equal(t, "20.1.0", snap.Workspace.NxVersion.Full)
+	assert.Contains(t, snap.Workspace.ProjectGraph.Nodes, "app")
+	assert.False(t, snap.SavedAt.IsZero())
+
+	// Workspaces do not share snapshots
+	_, err = store.Load(t.TempDir())
+	assert.ErrorIs(t, err, ErrNoSnapshot)
+
+	require.NoError(t, store.Remove(workspace))
+	require.NoError(t, store.Remove(workspace))
+	_, err = store.Load(workspace)
+	assert.ErrorIs(t, err, ErrNoSnapshot)
+}
+
+func TestSaveSerialized(t *testing.T) {
+	store := NewStore(t.TempDir())
+	workspace := t.TempDir()
+
+	require.NoError(t, store.SaveSerialized(workspace, `{"projectGraph":{"nodes":{"lib":{"name":"lib","type":"lib","data":{"root":"libs/lib"}}},"dependencies":{}},"nxVersion":{"full":"19.8.0","major":19,"minor":8}}`))
+
+	// Without node_modules the version check is skipped
+	snap, err := store.Load(workspace)
+	require.NoError(t, err)
+	assert.Equal(t, "libs/lib", snap.Workspace.ProjectGraph.Nodes["lib"].Data.Root)
+
+	assert.Error(t, store.SaveSerialized(workspace, "not json"))
+}
+
+func TestInvalidation(t *testing.T) {
+	store := NewStore(t.TempDir())
+	workspace := t.TempDir()
+	writeNxPackage(t, workspace, "20.1.0")
+
+	tests := []struct {
+		name   string
+		setup  func()
+		reason string
+	}{
+		{
+			name:   "nx upgraded",
+			setup:  func() { require.NoError(t, store.Save(workspace, newTestWorkspace("19.8.0"))) },
+			reason: "taken with Nx 19.8.0, Nx 20.1.0 is installed",
+		},
+		{
+			name: "older format",
+			setup: func() {
+				require.NoError(t, os.WriteFile(store.Path(workspace), []byte(`{"formatVersion":0,"workspace":{}}`), 0o644))
+			},
+			reason: "format version 0, expected 1",
+		},
+		{
+			name: "corrupted",
+			setup: func() {
+				require.NoError(t, os.WriteFile(store.Path(workspace), []byte(`{"formatVersion":1,`), 0o644))
+			},
+			reason: "unreadable: unexpected end of JSON input",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setup()
+
+			_, err := store.Load(workspace)
+			var invalidated *InvalidatedError
+			require.True(t, errors.As(err, &invalidated))
+			assert.Equal(t, tt.reason, invalidated.Reason)
+			assert.ErrorIs(t, err, ErrNoSnapshot)
+
+			// The file is gone after invalidation
+			_, err = os.Stat(store.Path(workspace))
+			assert.True(t, errors.Is(err, os.ErrNotExist))
+		})
+	}
+}
@@ -23,6 +23,14 @@ func (c *Client) connectToLSPServer(ctx context.Context, rwc *ReadWriteCloser) {
 func (c *Client) handleServerRequest(ctx context.Context, conn *jsonrpc2.Conn, req *jsonrpc2.Request) (interface{}, error) {
 	// If notification, pass to registered handlers
 	if req.Notif {
+		if c.Metrics != nil {
+			size := 0
+			if req.Params != nil {
+				size = len(*req.Params)
+			}
+			c.Metrics.NotificationReceived(req.Method, size)
+		}
+
 		// Special case for window/logMessage
 		if req.Method == "window/logMessage" {
 			if c.isVerbose {
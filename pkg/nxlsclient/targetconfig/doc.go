@@ -0,0 +1,32 @@
+/*
+Package targetconfig resolves the effective configuration of project targets.
+
+A target in project.json is only part of what nx runs: nx.json targetDefaults, keyed by
+executor, target name or glob, fill in the options, configurations, inputs, outputs and
+dependsOn the target leaves unset, and {projectRoot}, {projectName} and {workspaceRoot}
+tokens are replaced. A Resolver applies the same rules, so consumers can show what a
+target actually runs with.
+
+# Usage
+
+	workspace, err := client.Commander.SendWorkspaceRequest(ctx, &commands.WorkspaceRequestParams{})
+	if err != nil {
+		// Handle error
+	}
+
+	target, err := targetconfig.NewResolver(workspace).Resolve("app", "build")
+	if err != nil {
+		// Handle error
+	}
+
+	fmt.Println(target.Executor(), target.DefaultsKey, target.Config.DependsOn)
+
+	// Options with the production configuration overlaid, or the default one for ""
+	options, err := target.Options("production")
+
+Options are merged key by key, configurations per configuration name. Inputs, outputs,
+dependsOn and every other property are taken from targetDefaults as a whole when the
+target does not set them. Defaults for another executor, or for nx:run-commands with a
+different command, are not merged.
+*/
+package targetconfig
@@ -0,0 +1,366 @@
+package targetconfig
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/lazyengs/lazynx/pkg/nxlsclient/internal/glob"
+	nxtypes "github.com/lazyengs/lazynx/pkg/nxlsclient/nx-types"
+)
+
+// RunCommandsExecutor is the executor of targets written with a bare command.
+const RunCommandsExecutor = "nx:run-commands"
+
+// Executor returns the executor of target, nx:run-commands for a bare command.
+func Executor(target nxtypes.TargetConfiguration) string {
+	if target.Executor != nil && *target.Executor != "" {
+		return *target.Executor
+	}
+	if target.Command != nil && *target.Command != "" {
+		return RunCommandsExecutor
+	}
+	return ""
+}
+
+// LookupDefaults returns the targetDefaults entry for a target the way nx picks it: by
+// executor, then by target name, then by the longest glob matching the target name.
+func LookupDefaults(defaults nxtypes.TargetDefaults, target, executor string) (nxtypes.TargetConfiguration, string, bool) {
+	if executor != "" {
+		if d, ok := defaults[executor]; ok {
+			return d, executor, true
+		}
+	}
+	if d, ok := defaults[target]; ok {
+		return d, target, true
+	}
+
+	var match string
+	for key := range defaults {
+		if !glob.IsPattern(key) || !glob.Match(key, target) {
+			continue
+		}
+		if len(key) > len(match) || (len(key) == len(match) && key < match) {
+			match = key
+		}
+	}
+	if match == "" {
+		return nxtypes.TargetConfiguration{}, "", false
+	}
+	return defaults[match], match, true
+}
+
+// Compatible reports whether nx merges the defaults base into target: their executors
+// must not differ and, for nx:run-commands, neither must their commands.
+func Compatible(target, base nxtypes.TargetConfiguration) bool {
+	a, b := Executor(target), Executor(base)
+	if a == "" || b == "" {
+		return true
+	}
+	if a != b {
+		return false
+	}
+	if a == RunCommandsExecutor {
+		ca, cb := command(target), command(base)
+		return ca == "" || cb == "" || ca == cb
+	}
+	return true
+}
+
+// command returns the command of a run-commands target, joining the commands option
+// like nx does.
+func command(target nxtypes.TargetConfiguration) string {
+	if target.Command != nil && *target.Command != "" {
+		return *target.Command
+	}
+	if c, ok := target.Options.GetString("command"); ok {
+		return c
+	}
+	var commands []string
+	if ok, err := target.Options.Decode("commands", &commands); ok && err == nil {
+		return strings.Join(commands, " && ")
+	}
+	return ""
+}
+
+// Merge fills the properties target leaves unset from the targetDefaults entry base.
+// Options and configuration options are merged key by key; every other property,
+// inputs and dependsOn included, is taken from base as a whole when target has none.
+func Merge(target, base nxtypes.TargetConfiguration) nxtypes.TargetConfiguration {
+	result := target
+
+	if result.Executor == nil {
+		result.Executor = base.Executor
+	}
+	if result.Command == nil {
+		result.Command = base.Command
+	}
+	if result.Outputs == nil {
+		result.Outputs = base.Outputs
+	}
+	if result.DependsOn == nil {
+		result.DependsOn = base.DependsOn
+	}
+	if result.Inputs == nil {
+		result.Inputs = base.Inputs
+	}
+	if result.DefaultConfiguration == nil {
+		result.DefaultConfiguration = base.DefaultConfiguration
+	}
+	if result.Cache == nil {
+		result.Cache = base.Cache
+	}
+	if result.Metadata == nil {
+		result.Metadata = base.Metadata
+	}
+	if result.Parallelism == nil {
+		result.Parallelism = base.Parallelism
+	}
+	if result.SyncGenerators == nil {
+		result.SyncGenerators = base.SyncGenerators
+	}
+
+	result.Options = mergeOptions(base.Options, target.Options)
+	if base.Configurations != nil {
+		result.Configurations = make(map[string]nxtypes.Options, len(base.Configurations)+len(target.Configurations))
+		for name, options := range base.Configurations {
+			result.Configurations[name] = mergeOptions(options, target.Configurations[name])
+		}
+		for name, options := range target.Configurations {
+			if _, ok := base.Configurations[name]; !ok {
+				result.Configurations[name] = options
+			}
+		}
+	}
+	return result
+}
+
+// mergeOptions returns base overlaid with overlay, nil when both are nil.
+func mergeOptions(base, overlay nxtypes.Options) nxtypes.Options {
+	if base == nil && overlay == nil {
+		return nil
+	}
+	merged := make(nxtypes.Options, len(base)+len(overlay))
+	for key, value := range base {
+		merged[key] = value
+	}
+	for key, value := range overlay {
+		merged[key] = value
+	}
+	return merged
+}
+
+// Target is the effective configuration of a project target.
+type Target struct {
+	Project string
+	Name    string
+	// Config is the target with targetDefaults merged in and tokens interpolated.
+	Config nxtypes.TargetConfiguration
+	// DefaultsKey is the targetDefaults entry merged in, empty when none was.
+	DefaultsKey string
+}
+
+// Executor returns the executor of the target, nx:run-commands for a bare command.
+func (t *Target) Executor() string {
+	return Executor(t.Config)
+}
+
+// DefaultConfiguration returns the configuration used when none is given.
+func (t *Target) DefaultConfiguration() string {
+	if t.Config.DefaultConfiguration == nil {
+		return ""
+	}
+	return *t.Config.DefaultConfiguration
+}
+
+// Configurations returns the configuration names of the target, sorted.
+func (t *Target) Configurations() []string {
+	names := make([]string, 0, len(t.Config.Configurations))
+	for name := range t.Config.Configurations {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Configuration returns the configuration the target runs with when asked for
+// configuration: configuration itself, or the default one when it is empty.
+func (t *Target) Configuration(configuration string) string {
+	if configuration != "" {
+		return configuration
+	}
+	if _, ok := t.Config.Configurations[t.DefaultConfiguration()]; !ok {
+		// nx ignores a defaultConfiguration the target does not define
+		return ""
+	}
+	return t.DefaultConfiguration()
+}
+
+// Options returns the options the target runs with in configuration, which are the
+// target options overlaid with those of the configuration. An empty configuration
+// uses the default one.
+func (t *Target) Options(configuration string) (nxtypes.Options, error) {
+	configuration = t.Configuration(configuration)
+	if configuration == "" {
+		return mergeOptions(t.Config.Options, nil), nil
+	}
+	overlay, ok := t.Config.Configurations[configuration]
+	if !ok {
+		return nil, fmt.Errorf("target %s:%s has no configuration %q", t.Project, t.Name, configuration)
+	}
+	return mergeOptions(t.Config.Options, overlay), nil
+}
+
+// Resolver computes effective target configurations for the projects of a workspace.
+type Resolver struct {
+	// WorkspaceRoot replaces {workspaceRoot}. It is empty by default, which drops
+	// {workspaceRoot}/ like nx does, leaving paths relative to the workspace root.
+	WorkspaceRoot string
+	defaults      nxtypes.TargetDefaults
+	projects      map[string]nxtypes.ProjectConfiguration
+}
+
+// NewResolver creates a Resolver from the project graph and nx.json targetDefaults of
+// workspace.
+func NewResolver(workspace *nxtypes.NxWorkspace) *Resolver {
+	r := &Resolver{
+		defaults: workspace.NxJson.TargetDefaults,
+		projects: make(map[string]nxtypes.ProjectConfiguration, len(workspace.ProjectGraph.Nodes)),
+	}
+	for name, node := range workspace.ProjectGraph.Nodes {
+		r.projects[name] = node.Data.ProjectConfiguration
+	}
+	return r
+}
+
+// Resolve returns the effective configuration of project:target. Targets in the project
+// graph of recent nx versions already have targetDefaults merged in; merging them again
+// changes nothing, since the target's own values win.
+func (r *Resolver) Resolve(project, target string) (*Target, error) {
+	p, ok := r.projects[project]
+	if !ok {
+		return nil, fmt.Errorf("project %q not found", project)
+	}
+	config, ok := p.Targets[target]
+	if !ok {
+		return nil, fmt.Errorf("project %q has no target %q", project, target)
+	}
+
+	result := &Target{Project: project, Name: target, Config: config}
+	if defaults, key, ok := LookupDefaults(r.defaults, target, Executor(config)); ok && Compatible(config, defaults) {
+		result.Config = Merge(config, defaults)
+		result.DefaultsKey = key
+	}
+
+	name := project
+	if p.Name != nil && *p.Name != "" {
+		name = *p.Name
+	}
+	tokens := Tokens{ProjectRoot: p.Root, ProjectName: name, WorkspaceRoot: r.WorkspaceRoot}
+	if err := tokens.interpolateTarget(&result.Config); err != nil {
+		return nil, fmt.Errorf("failed to interpolate %s:%s: %w", project, target, err)
+	}
+	return result, nil
+}
+
+// Tokens are the values of the {projectRoot}, {projectName} and {workspaceRoot} tokens.
+type Tokens struct {
+	ProjectRoot   string
+	ProjectName   string
+	WorkspaceRoot string
+}
+
+// Interpolate replaces the tokens in s.
+func (t Tokens) Interpolate(s string) string {
+	if !strings.Contains(s, "{") {
+		return s
+	}
+	if t.WorkspaceRoot == "" {
+		s = strings.ReplaceAll(s, "{workspaceRoot}/", "")
+	}
+	return strings.NewReplacer(
+		"{projectRoot}", t.ProjectRoot,
+		"{projectName}", t.ProjectName,
+		"{workspaceRoot}", t.WorkspaceRoot,
+	).Replace(s)
+}
+
+// interpolateTarget replaces the tokens in the command, outputs and options of target.
+// Inputs keep their tokens, which tell what the input patterns are relative to.
+func (t Tokens) interpolateTarget(target *nxtypes.TargetConfiguration) error {
+	if target.Command != nil {
+		command := t.Interpolate(*target.Command)
+		target.Command = &command
+	}
+	if target.Outputs != nil {
+		outputs := make([]string, len(target.Outputs))
+		for i, output := range target.Outputs {
+			outputs[i] = t.Interpolate(output)
+		}
+		target.Outputs = outputs
+	}
+
+	var err error
+	if target.Options, err = t.interpolateOptions(target.Options); err != nil {
+		return err
+	}
+	if target.Configurations != nil {
+		configurations := make(map[string]nxtypes.Options, len(target.Configurations))
+		for name, options := range target.Configurations {
+			if configurations[name], err = t.interpolateOptions(options); err != nil {
+				return fmt.Errorf("configuration %s: %w", name, err)
+			}
+		}
+		target.Configurations = configurations
+	}
+	return nil
+}
+
+// interpolateOptions replaces the tokens in every string of options, nested ones
+// included. Options without tokens keep their raw JSON.
+func (t Tokens) interpolateOptions(options nxtypes.Options) (nxtypes.Options, error) {
+	if options == nil {
+		return nil, nil
+	}
+	result := make(nxtypes.Options, len(options))
+	for key, raw := range options {
+		if !bytes.Contains(raw, []byte("{")) {
+			result[key] = raw
+			continue
+		}
+
+		decoder := json.NewDecoder(bytes.NewReader(raw))
+		decoder.UseNumber()
+		var value any
+		if err := decoder.Decode(&value); err != nil {
+			return nil, fmt.Errorf("option %s: %w", key, err)
+		}
+
+		var buf bytes.Buffer
+		encoder := json.NewEncoder(&buf)
+		encoder.SetEscapeHTML(false)
+		if err := encoder.Encode(t.interpolateValue(value)); err != nil {
+			return nil, fmt.Errorf("option %s: %w", key, err)
+		}
+		result[key] = json.RawMessage(bytes.TrimSpace(buf.Bytes()))
+	}
+	return result, nil
+}
+
+func (t Tokens) interpolateValue(value any) any {
+	switch v := value.(type) {
+	case string:
+		return t.Interpolate(v)
+	case []any:
+		for i := range v {
+			v[i] = t.interpolateValue(v[i])
+		}
+	case map[string]any:
+		for key := range v {
+			v[key] = t.interpolateValue(v[key])
+		}
+	}
+	return value
+}
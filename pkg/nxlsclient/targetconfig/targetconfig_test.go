@@ -0,0 +1,161 @@
+package targetconfig
+
+import (
+	"encoding/json"
+	"testing"
+
+	nxtypes "github.com/lazyengs/lazynx/pkg/nxlsclient/nx-types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const workspaceJSON = `{
+	"nxJson": {
+		"targetDefaults": {
+			"build": {
+				"dependsOn": ["^build"],
+				"inputs": ["production", "^production"],
+				"outputs": ["{workspaceRoot}/dist/{projectRoot}"],
+				"cache": true,
+				"options": {"outputPath": "dist/{projectName}", "sourceMap": true},
+				"configurations": {
+					"production": {"sourceMap": false, "optimization": true},
+					"development": {"extractLicenses": false}
+				},
+				"defaultConfiguration": "production"
+			},
+			"@nx/jest:jest": {
+				"inputs": ["default", "^production", "{workspaceRoot}/jest.preset.js"],
+				"options": {"passWithNoTests": true}
+			},
+			"e2e*": {"cache": false},
+			"e2e-ci*": {"cache": true, "dependsOn": ["build"]},
+			"lint": {"executor": "@nx/eslint:lint", "options": {"maxWarnings": 0}}
+		}
+	},
+	"projectGraph": {
+		"nodes": {
+			"app": {"type": "app", "name": "app", "data": {"root": "apps/app", "targets": {
+				"build": {
+					"executor": "@nx/vite:build",
+					"inputs": ["default"],
+					"options": {"sourceMap": "hidden", "assets": [{"input": "{projectRoot}/src", "glob": "*.png"}]},
+					"configurations": {"production": {"mode": "prod"}, "staging": {"mode": "staging"}}
+				},
+				"test": {"executor": "@nx/jest:jest", "options": {"jestConfig": "{projectRoot}/jest.config.ts"}},
+				"e2e-ci--smoke": {"command": "playwright test"},
+				"lint": {"command": "eslint ."}
+			}}},
+			"ui": {"type": "lib", "name": "ui", "data": {"root": "libs/ui", "targets": {
+				"build": {"executor": "nx:run-commands", "options": {"command": "tsc -p {projectRoot}"}, "defaultConfiguration": "missing"}
+			}}}
+		},
+		"dependencies": {}
+	}
+}`
+
+func newResolver(t *testing.T) *Resolver {
+	t.Helper()
+	var workspace nxtypes.NxWorkspace
+	require.NoError(t, json.Unmarshal([]byte(workspaceJSON), &workspace))
+	return NewResolver(&workspace)
+}
+
+func options(t *testing.T, o nxtypes.Options) string {
+	t.Helper()
+	data, err := json.Marshal(o)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestResolve(t *testing.T) {
+	r := newResolver(t)
+
+	build, err := r.Resolve("app", "build")
+	require.NoError(t, err)
+	assert.Equal(t, "build", build.DefaultsKey)
+	assert.Equal(t, "@nx/vite:build", build.Executor())
+	assert.Equal(t, "production", build.DefaultConfiguration())
+	assert.Equal(t, []string{"development", "production", "staging"}, build.Configurations())
+	assert.Equal(t, []string{"dist/apps/app"}, build.Config.Outputs)
+	assert.Equal(t, []string{"^build"}, []string{build.Config.DependsOn[0].Shorthand})
+	// The target's own inputs replace the defaults as a whole
+	assert.Equal(t, []nxtypes.Input{{Shorthand: "default"}}, build.Config.Inputs)
+	assert.Equal(t, true, *build.Config.Cache)
+
+	assert.Equal(t, "production", build.Configuration(""))
+	assert.Equal(t, "staging", build.Configuration("staging"))
+	opts, err := build.Options("")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"outputPath":"dist/app","sourceMap":false,"optimization":true,"mode":"prod","assets":[{"input":"apps/app/src","glob":"*.png"}]}`, options(t, opts))
+
+	opts, err = build.Options("development")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"outputPath":"dist/app","sourceMap":"hidden","extractLicenses":false,"assets":[{"input":"apps/app/src","glob":"*.png"}]}`, options(t, opts))
+
+	_, err = build.Options("qa")
+	assert.EqualError(t, err, `target app:build has no configuration "qa"`)
+
+	test, err := r.Resolve("app", "test")
+	require.NoError(t, err)
+	assert.Equal(t, "@nx/jest:jest", test.DefaultsKey)
+	assert.JSONEq(t, `{"jestConfig":"apps/app/jest.config.ts","passWithNoTests":true}`, options(t, test.Config.Options))
+	// Inputs keep their tokens
+	assert.Equal(t, "{workspaceRoot}/jest.preset.js", test.Config.Inputs[2].Shorthand)
+}
+
+func TestResolveDefaultsSelection(t *testing.T) {
+	r := newResolver(t)
+
+	// The longest matching glob wins
+	e2e, err := r.Resolve("app", "e2e-ci--smoke")
+	require.NoError(t, err)
+	assert.Equal(t, "e2e-ci*", e2e.DefaultsKey)
+	assert.Equal(t, RunCommandsExecutor, e2e.Executor())
+	assert.True(t, *e2e.Config.Cache)
+
+	// Defaults for another executor are not merged
+	lint, err := r.Resolve("app", "lint")
+	require.NoError(t, err)
+	assert.Empty(t, lint.DefaultsKey)
+	assert.Nil(t, lint.Config.Options)
+
+	// The build defaults run a different command, but ui runs its own command
+	build, err := r.Resolve("ui", "build")
+	require.NoError(t, err)
+	assert.Equal(t, "build", build.DefaultsKey)
+	assert.Equal(t, "missing", build.DefaultConfiguration())
+	assert.Empty(t, build.Configuration(""))
+	opts, err := build.Options("")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"command":"tsc -p libs/ui","outputPath":"dist/ui","sourceMap":true}`, options(t, opts))
+
+	_, err = r.Resolve("api", "build")
+	assert.EqualError(t, err, `project "api" not found`)
+	_, err = r.Resolve("ui", "serve")
+	assert.EqualError(t, err, `project "ui" has no target "serve"`)
+}
+
+func TestCompatible(t *testing.T) {
+	run := func(command string) nxtypes.TargetConfiguration {
+		return nxtypes.TargetConfiguration{Command: &command}
+	}
+	executor := func(e string) nxtypes.TargetConfiguration {
+		return nxtypes.TargetConfiguration{Executor: &e}
+	}
+
+	assert.True(t, Compatible(executor("@nx/vite:build"), nxtypes.TargetConfiguration{}))
+	assert.True(t, Compatible(executor("@nx/vite:build"), executor("@nx/vite:build")))
+	assert.False(t, Compatible(executor("@nx/vite:build"), executor("@nx/webpack:webpack")))
+	assert.True(t, Compatible(run("tsc"), executor(RunCommandsExecutor)))
+	assert.True(t, Compatible(run("tsc"), run("tsc")))
+	assert.False(t, Compatible(run("tsc"), run("vite build")))
+}
+
+func TestTokensInterpolate(t *testing.T) {
+	tokens := Tokens{ProjectRoot: "libs/ui", ProjectName: "ui"}
+	assert.Equal(t, "dist/libs/ui/ui", tokens.Interpolate("{workspaceRoot}/dist/{projectRoot}/{projectName}"))
+
+	tokens.WorkspaceRoot = "/repo"
+	assert.Equal(t, "/repo/dist/libs/ui", tokens.Interpolate("{workspaceRoot}/dist/{projectRoot}"))
+}
@@ -0,0 +1,271 @@
+package taskgraph
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/lazyengs/lazynx/pkg/nxlsclient/graph"
+	"github.com/lazyengs/lazynx/pkg/nxlsclient/internal/glob"
+	nxtypes "github.com/lazyengs/lazynx/pkg/nxlsclient/nx-types"
+	"github.com/lazyengs/lazynx/pkg/nxlsclient/targetconfig"
+)
+
+// Builder resolves targets into task graphs for one workspace.
+type Builder struct {
+	projects map[string]nxtypes.ProjectConfiguration
+	names    []string
+	graph    *graph.Graph
+	defaults nxtypes.TargetDefaults
+}
+
+// NewBuilder creates a Builder from the project graph and the nx.json targetDefaults of
+// workspace.
+func NewBuilder(workspace *nxtypes.NxWorkspace) *Builder {
+	b := &Builder{
+		projects: make(map[string]nxtypes.ProjectConfiguration, len(workspace.ProjectGraph.Nodes)),
+		graph:    graph.New(&workspace.ProjectGraph).WithoutExternal(),
+		defaults: workspace.NxJson.TargetDefaults,
+	}
+	for name, node := range workspace.ProjectGraph.Nodes {
+		b.projects[name] = node.Data.ProjectConfiguration
+	}
+	b.names = b.graph.Projects()
+	return b
+}
+
+// Build resolves roots and everything they need into a Graph. A root without a
+// configuration uses the target's defaultConfiguration. Dependency tasks use the
+// configuration of the task needing them when their target has it, and their own
+// defaultConfiguration otherwise.
+func (b *Builder) Build(roots ...TaskID) (*Graph, error) {
+	g := newGraph()
+
+	var queue []TaskID
+	for _, root := range roots {
+		target, err := b.target(root.Project, root.Target)
+		if err != nil {
+			return nil, err
+		}
+		if root.Configuration == "" {
+			root.Configuration = deref(target.DefaultConfiguration)
+		} else if _, ok := target.Configurations[root.Configuration]; !ok {
+			return nil, fmt.Errorf("target %s:%s has no configuration %q", root.Project, root.Target, root.Configuration)
+		}
+
+		g.Roots = append(g.Roots, root)
+		if _, seen := g.tasks[root]; !seen {
+			g.tasks[root] = &Task{ID: root, Target: target}
+			queue = append(queue, root)
+		}
+	}
+
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+
+		rules, source := b.dependsOn(id.Project, id.Target)
+		for _, rule := range rules {
+			for _, e := range b.resolve(id, rule, source) {
+				if e.To == id {
+					continue
+				}
+				if _, seen := g.tasks[e.To]; !seen {
+					g.tasks[e.To] = &Task{ID: e.To, Target: b.projects[e.To.Project].Targets[e.To.Target]}
+					queue = append(queue, e.To)
+				}
+				g.addEdge(e)
+			}
+		}
+	}
+
+	for _, edges := range g.deps {
+		sort.SliceStable(edges, func(i, j int) bool { return edges[i].To.String() < edges[j].To.String() })
+	}
+	for _, edges := range g.dependents {
+		sort.SliceStable(edges, func(i, j int) bool { return edges[i].From.String() < edges[j].From.String() })
+	}
+
+	return g, nil
+}
+
+func (b *Builder) target(project, target string) (nxtypes.TargetConfiguration, error) {
+	config, ok := b.projects[project]
+	if !ok {
+		return nxtypes.TargetConfiguration{}, fmt.Errorf("project %q not found", project)
+	}
+	t, ok := config.Targets[target]
+	if !ok {
+		return nxtypes.TargetConfiguration{}, fmt.Errorf("project %q has no target %q", project, target)
+	}
+	return t, nil
+}
+
+// dependsOn returns the dependsOn rules of a target. The target's own rules win over
+// targetDefaults, which are looked up by executor, then by target name, then by glob.
+// Defaults that nx would not merge into the target are skipped.
+func (b *Builder) dependsOn(project, target string) ([]nxtypes.TargetDependency, RuleSource) {
+	t := b.projects[project].Targets[target]
+	if t.DependsOn != nil {
+		return t.DependsOn, RuleSource{}
+	}
+
+	defaults, key, ok := targetconfig.LookupDefaults(b.defaults, target, targetconfig.Executor(t))
+	if ok && targetconfig.Compatible(t, defaults) {
+		return defaults.DependsOn, RuleSource{TargetDefaults: true, Key: key}
+	}
+
+	return nil, RuleSource{}
+}
+
+// resolve expands one dependsOn rule of the task from into edges.
+func (b *Builder) resolve(from TaskID, rule nxtypes.TargetDependency, source RuleSource) []Edge {
+	rule = rule.ForProject(b.projects[from.Project].Targets)
+
+	var edges []Edge
+	add := func(project string, chain []string) {
+		for _, target := range b.targetsMatching(project, rule.Target) {
+			edges = append(edges, Edge{
+				From:   from,
+				To:     b.taskFor(project, target, from.Configuration),
+				Rule:   rule,
+				Source: source,
+				Path:   chain,
+			})
+		}
+	}
+
+	// Older workspaces write "self" and "dependencies" as projects
+	legacy := ""
+	if rule.Projects != nil && rule.Projects.Single && len(rule.Projects.Patterns) == 1 {
+		legacy = rule.Projects.Patterns[0]
+	}
+
+	switch {
+	case legacy == "self":
+		add(from.Project, nil)
+	case rule.Projects != nil && legacy != "dependencies":
+		for _, project := range b.matchProjects(rule.Projects.Patterns) {
+			add(project, nil)
+		}
+	case rule.OnDependencies() || legacy == "dependencies":
+		for _, dep := range b.dependenciesWithTarget(from.Project, rule.Target) {
+			add(dep[len(dep)-1], dep)
+		}
+	default:
+		add(from.Project, nil)
+	}
+
+	return edges
+}
+
+// taskFor picks the configuration of a dependency task.
+func (b *Builder) taskFor(project, target, configuration string) TaskID {
+	t := b.projects[project].Targets[target]
+	if _, ok := t.Configurations[configuration]; !ok || configuration == "" {
+		configuration = deref(t.DefaultConfiguration)
+	}
+	return TaskID{Project: project, Target: target, Configuration: configuration}
+}
+
+// targetsMatching returns the targets of project named pattern, which may be a glob.
+func (b *Builder) targetsMatching(project, pattern string) []string {
+	targets := b.projects[project].Targets
+	if !glob.IsPattern(pattern) {
+		if _, ok := targets[pattern]; ok {
+			return []string{pattern}
+		}
+		return nil
+	}
+
+	var names []string
+	for name := range targets {
+		if glob.Match(pattern, name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// dependenciesWithTarget returns, as project paths starting at project, the closest
+// dependencies having a target matching pattern. Dependencies without it are skipped
+// over the way nx does, so their own dependencies are considered instead.
+func (b *Builder) dependenciesWithTarget(project, pattern string) [][]string {
+	var paths [][]string
+	visited := map[string]bool{project: true}
+	queue := [][]string{{project}}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, dep := range b.graph.Dependencies(current[len(current)-1]) {
+			if visited[dep] {
+				continue
+			}
+			visited[dep] = true
+			chain := append(append([]string(nil), current...), dep)
+			if len(b.targetsMatching(dep, pattern)) > 0 {
+				paths = append(paths, chain)
+			} else {
+				queue = append(queue, chain)
+			}
+		}
+	}
+
+	sort.Slice(paths, func(i, j int) bool { return paths[i][len(paths[i])-1] < paths[j][len(paths[j])-1] })
+	return paths
+}
+
+// matchProjects returns the projects selected by patterns: names, globs, "tag:" patterns
+// and "!" exclusions. Exclusions alone select every other project.
+func (b *Builder) matchProjects(patterns []string) []string {
+	selected := make(map[string]bool)
+	onlyExclusions := true
+	for _, p := range patterns {
+		if !strings.HasPrefix(p, "!") {
+			onlyExclusions = false
+		}
+	}
+	if onlyExclusions {
+		for _, name := range b.names {
+			selected[name] = true
+		}
+	}
+
+	for _, p := range patterns {
+		exclude := strings.HasPrefix(p, "!")
+		p = strings.TrimPrefix(p, "!")
+		for _, name := range b.names {
+			if b.projectMatches(name, p) {
+				selected[name] = !exclude
+			}
+		}
+	}
+
+	var result []string
+	for _, name := range b.names {
+		if selected[name] {
+			result = append(result, name)
+		}
+	}
+	return result
+}
+
+func (b *Builder) projectMatches(name, pattern string) bool {
+	if tag, ok := strings.CutPrefix(pattern, "tag:"); ok {
+		for _, t := range b.projects[name].Tags {
+			if glob.Match(tag, t) {
+				return true
+			}
+		}
+		return false
+	}
+	return glob.Match(pattern, name)
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -0,0 +1,234 @@
+package taskgraph
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/lazyengs/lazynx/pkg/nxlsclient/graph"
+	nxtypes "github.com/lazyengs/lazynx/pkg/nxlsclient/nx-types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestWorkspace builds app -> feature -> ui -> utils, where feature has no build target,
+// and a codegen project tagged "codegen". nx.json targets are given as JSON so dependsOn
+// goes through the same parsing as real workspaces.
+func newTestWorkspace(t *testing.T) *nxtypes.NxWorkspace {
+	t.Helper()
+
+	projects := map[string]string{
+		"app": `{"root":"apps/app","targets":{
+			"build":{"defaultConfiguration":"production","configurations":{"production":{},"development":{}}},
+			"serve":{"dependsOn":["build"]},
+			"e2e-ci":{}
+		}}`,
+		"feature": `{"root":"libs/feature","targets":{"lint":{}}}`,
+		"ui": `{"root":"libs/ui","targets":{
+			"build":{"configurations":{"production":{}}},
+			"test":{"executor":"@nx/jest:jest"}
+		}}`,
+		"utils": `{"root":"libs/utils","targets":{
+			"build":{},"test":{},"build:types":{"configurations":{"ci":{}}},
+			"typecheck":{"dependsOn":["build:types","app:build"]}
+		}}`,
+		"codegen": `{"root":"tools/codegen","tags":["codegen"],"targets":{"generate":{}}}`,
+	}
+
+	workspace := &nxtypes.NxWorkspace{
+		ProjectGraph: nxtypes.ProjectGraph{
+			Nodes: make(map[string]nxtypes.ProjectGraphProjectNode),
+			Dependencies: map[string][]nxtypes.ProjectGraphDependency{
+				"app":     {{Source: "app", Target: "feature", Type: "static"}, {Source: "app", Target: "npm:react", Type: "static"}},
+				"feature": {{Source: "feature", Target: "ui", Type: "static"}},
+				"ui":      {{Source: "ui", Target: "utils", Type: "static"}},
+			},
+		},
+	}
+	for name, config := range projects {
+		node := nxtypes.ProjectGraphProjectNode{Name: name, Type: "lib"}
+		require.NoError(t, json.Unmarshal([]byte(config), &node.Data.ProjectConfiguration))
+		workspace.ProjectGraph.Nodes[name] = node
+	}
+
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"build": {"dependsOn": ["^build", {"projects": "tag:codegen", "target": "generate"}]},
+		"@nx/jest:jest": {"dependsOn": ["build"]},
+		"e2e-*": {"dependsOn": ["serve"]}
+	}`), &workspace.NxJson.TargetDefaults))
+
+	return workspace
+}
+
+func edgeStrings(edges []Edge) []string {
+	var result []string
+	for _, e := range edges {
+		result = append(result, e.From.String()+" -> "+e.To.String())
+	}
+	return result
+}
+
+func TestParseTaskID(t *testing.T) {
+	id, err := ParseTaskID("app:build:production")
+	require.NoError(t, err)
+	assert.Equal(t, TaskID{Project: "app", Target: "build", Configuration: "production"}, id)
+	assert.Equal(t, "app:build:production", id.String())
+
+	id, err = ParseTaskID("app:build")
+	require.NoError(t, err)
+	assert.Equal(t, "app:build", id.String())
+
+	for _, invalid := range []string{"app", ":build", "app:", "a:b:c:d"} {
+		_, err := ParseTaskID(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestResolveTaskID(t *testing.T) {
+	workspace := newTestWorkspace(t)
+
+	tests := []struct {
+		s    string
+		want TaskID
+	}{
+		{"utils:build:types", TaskID{Project: "utils", Target: "build:types"}},
+		{"utils:build:types:ci", TaskID{Project: "utils", Target: "build:types", Configuration: "ci"}},
+		{"app:build:production", TaskID{Project: "app", Target: "build", Configuration: "production"}},
+		{"missing:build", TaskID{Project: "missing", Target: "build"}},
+	}
+	for _, tt := range tests {
+		id, err := ResolveTaskID(tt.s, workspace)
+		require.NoError(t, err, tt.s)
+		assert.Equal(t, tt.want, id, tt.s)
+		assert.Equal(t, tt.s, id.String())
+	}
+
+	_, err := ResolveTaskID("app", workspace)
+	assert.Error(t, err)
+}
+
+func TestBuildDependencies(t *testing.T) {
+	tasks, err := NewBuilder(newTestWorkspace(t)).Build(TaskID{Project: "app", Target: "build"})
+	require.NoError(t, err)
+
+	// The root uses its default configuration, which is forwarded where it exists
+	assert.Equal(t, []TaskID{{Project: "app", Target: "build", Configuration: "production"}}, tasks.Roots)
+	assert.Equal(t, []string{
+		"app:build:production -> codegen:generate",
+		"app:build:production -> ui:build:production",
+		"ui:build:production -> codegen:generate",
+		"ui:build:production -> utils:build",
+		"utils:build -> codegen:generate",
+	}, edgeStrings(tasks.Edges()))
+
+	// feature has no build target, so app reaches ui through it
+	edge := tasks.Dependencies(tasks.Roots[0])[1]
+	assert.Equal(t, []string{"app", "feature", "ui"}, edge.Path)
+	assert.Equal(t, RuleSource{TargetDefaults: true, Key: "build"}, edge.Source)
+	assert.Equal(t,
+		`app:build:production needs ui:build:production: dependsOn "^build" from targetDefaults["build"], app depends on ui through feature`,
+		edge.Explain())
+
+	codegen := tasks.Dependencies(tasks.Roots[0])[0]
+	assert.Equal(t,
+		`app:build:production needs codegen:generate: dependsOn {"projects":"tag:codegen","target":"generate"} from targetDefaults["build"]`,
+		codegen.Explain())
+
+	order, err := tasks.Order()
+	require.NoError(t, err)
+	assert.Equal(t, []TaskID{
+		{Project: "codegen", Target: "generate"},
+		{Project: "utils", Target: "build"},
+		{Project: "ui", Target: "build", Configuration: "production"},
+		{Project: "app", Target: "build", Configuration: "production"},
+	}, order)
+
+	assert.Equal(t, []string{
+		"app:build:production -> ui:build:production",
+		"ui:build:production -> utils:build",
+	}, edgeStrings(tasks.Why(TaskID{Project: "utils", Target: "build"})))
+	assert.Empty(t, tasks.Why(tasks.Roots[0]))
+}
+
+func TestBuildTargetRules(t *testing.T) {
+	builder := NewBuilder(newTestWorkspace(t))
+
+	// Own dependsOn wins over targetDefaults
+	tasks, err := builder.Build(TaskID{Project: "app", Target: "serve"})
+	require.NoError(t, err)
+	edge := tasks.Dependencies(TaskID{Project: "app", Target: "serve"})[0]
+	assert.Equal(t, TaskID{Project: "app", Target: "build", Configuration: "production"}, edge.To)
+	assert.Equal(t, RuleSource{}, edge.Source)
+	assert.Equal(t, `app:serve needs app:build:production: dependsOn "build" from the target`, edge.Explain())
+
+	// targetDefaults keyed by executor and by glob
+	tasks, err = builder.Build(TaskID{Project: "ui", Target: "test"})
+	require.NoError(t, err)
+	assert.Equal(t, RuleSource{TargetDefaults: true, Key: "@nx/jest:jest"}, tasks.Dependencies(TaskID{Project: "ui", Target: "test"})[0].Source)
+
+	tasks, err = builder.Build(TaskID{Project: "app", Target: "e2e-ci"})
+	require.NoError(t, err)
+	assert.Equal(t, RuleSource{TargetDefaults: true, Key: "e2e-*"}, tasks.Dependencies(TaskID{Project: "app", Target: "e2e-ci"})[0].Source)
+	assert.Equal(t, 6, tasks.Len())
+
+	// Explicit configurations are only forwarded to targets having them, and several roots
+	// share their dependencies
+	tasks, err = builder.Build(TaskID{Project: "app", Target: "build", Configuration: "development"}, TaskID{Project: "utils", Target: "test"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"app:build:development -> codegen:generate",
+		"app:build:development -> ui:build",
+		"ui:build -> codegen:generate",
+		"ui:build -> utils:build",
+		"utils:build -> codegen:generate",
+	}, edgeStrings(tasks.Edges()))
+	_, ok := tasks.Task(TaskID{Project: "utils", Target: "test"})
+	assert.True(t, ok)
+}
+
+func TestBuildColonTargets(t *testing.T) {
+	tasks, err := NewBuilder(newTestWorkspace(t)).Build(TaskID{Project: "utils", Target: "typecheck"})
+	require.NoError(t, err)
+
+	// "build:types" is a target of utils, "app:build" a target of app
+	deps := tasks.Dependencies(TaskID{Project: "utils", Target: "typecheck"})
+	require.Len(t, deps, 2)
+	assert.Equal(t, TaskID{Project: "app", Target: "build", Configuration: "production"}, deps[0].To)
+	assert.Equal(t, TaskID{Project: "utils", Target: "build:types"}, deps[1].To)
+	assert.Equal(t, "app:build", deps[0].Rule.Shorthand)
+}
+
+func TestBuildErrors(t *testing.T) {
+	builder := NewBuilder(newTestWorkspace(t))
+
+	_, err := builder.Build(TaskID{Project: "missing", Target: "build"})
+	assert.EqualError(t, err, `project "missing" not found`)
+
+	_, err = builder.Build(TaskID{Project: "feature", Target: "build"})
+	assert.EqualError(t, err, `project "feature" has no target "build"`)
+
+	_, err = builder.Build(TaskID{Project: "app", Target: "build", Configuration: "staging"})
+	assert.EqualError(t, err, `target app:build has no configuration "staging"`)
+}
+
+func TestLayersCycle(t *testing.T) {
+	workspace := newTestWorkspace(t)
+	workspace.ProjectGraph.Dependencies["utils"] = []nxtypes.ProjectGraphDependency{{Source: "utils", Target: "ui", Type: "implicit"}}
+
+	tasks, err := NewBuilder(workspace).Build(TaskID{Project: "ui", Target: "build"})
+	require.NoError(t, err)
+
+	_, err = tasks.Layers()
+	var cycleErr *graph.CycleError
+	require.True(t, errors.As(err, &cycleErr))
+	assert.Equal(t, [][]string{{"ui:build", "utils:build", "ui:build"}}, cycleErr.Cycles)
+}
+
+func TestMatchProjects(t *testing.T) {
+	builder := NewBuilder(newTestWorkspace(t))
+
+	assert.Equal(t, []string{"codegen"}, builder.matchProjects([]string{"tag:code*"}))
+	assert.Equal(t, []string{"ui", "utils"}, builder.matchProjects([]string{"u*"}))
+	assert.Equal(t, []string{"app", "codegen", "feature"}, builder.matchProjects([]string{"!u*"}))
+	assert.Equal(t, []string{"utils"}, builder.matchProjects([]string{"*", "!ui", "!app", "!feature", "!codegen"}))
+}
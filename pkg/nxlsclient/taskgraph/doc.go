@@ -0,0 +1,43 @@
+/*
+Package taskgraph resolves nx targets into the graph of tasks that running them implies.
+
+Running build for a project runs more than one task: "^build" in dependsOn expands over the
+project's dependencies, "lib:generate" points at another project, and nx.json targetDefaults
+add dependsOn rules to targets that declare none. A Builder applies those rules to the project
+graph of an nx/workspace response and produces a Graph of project:target:configuration tasks
+in which every edge records the rule that created it.
+
+# Usage
+
+	workspace, err := client.Commander.SendWorkspaceRequest(ctx, &commands.WorkspaceRequestParams{})
+	if err != nil {
+		// Handle error
+	}
+
+	root, err := taskgraph.ParseTaskID("app:build:production")
+	if err != nil {
+		// Handle error
+	}
+
+	tasks, err := taskgraph.NewBuilder(workspace).Build(root)
+	if err != nil {
+		// Handle error
+	}
+
+	// What will run, in order
+	order, err := tasks.Order()
+
+	// Why each edge exists
+	for _, edge := range tasks.Edges() {
+		fmt.Println(edge.Explain())
+		// app:build:production needs lib:build:production: dependsOn "^build" from targetDefaults["build"], app depends on lib
+	}
+
+	// Why a task is part of the graph at all
+	chain := tasks.Why(taskgraph.TaskID{Project: "utils", Target: "build"})
+
+Dependency projects without the requested target are skipped over, the way nx does, so
+"^build" reaches the closest dependencies that can build. Target names in dependsOn and
+targetDefaults keys may be globs.
+*/
+package taskgraph
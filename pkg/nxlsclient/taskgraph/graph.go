@@ -0,0 +1,160 @@
+package taskgraph
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+
+	"github.com/lazyengs/lazynx/pkg/nxlsclient/graph"
+	nxtypes "github.com/lazyengs/lazynx/pkg/nxlsclient/nx-types"
+)
+
+// Graph is a resolved task graph. Tasks run after every task they have an edge to.
+type Graph struct {
+	// Roots are the requested tasks, with their configuration resolved.
+	Roots      []TaskID
+	tasks      map[TaskID]*Task
+	deps       map[TaskID][]Edge
+	dependents map[TaskID][]Edge
+}
+
+func newGraph() *Graph {
+	return &Graph{
+		tasks:      make(map[TaskID]*Task),
+		deps:       make(map[TaskID][]Edge),
+		dependents: make(map[TaskID][]Edge),
+	}
+}
+
+// addEdge records e unless the same pair of tasks is already connected.
+func (g *Graph) addEdge(e Edge) {
+	for _, existing := range g.deps[e.From] {
+		if existing.To == e.To {
+			return
+		}
+	}
+	g.deps[e.From] = append(g.deps[e.From], e)
+	g.dependents[e.To] = append(g.dependents[e.To], e)
+}
+
+func sortIDs(ids []TaskID) {
+	sort.Slice(ids, func(i, j int) bool { return ids[i].String() < ids[j].String() })
+}
+
+// IDs returns the id of every task, sorted.
+func (g *Graph) IDs() []TaskID {
+	ids := make([]TaskID, 0, len(g.tasks))
+	for id := range g.tasks {
+		ids = append(ids, id)
+	}
+	sortIDs(ids)
+	return ids
+}
+
+// Task returns the task with the given id.
+func (g *Graph) Task(id TaskID) (Task, bool) {
+	task, ok := g.tasks[id]
+	if !ok {
+		return Task{}, false
+	}
+	return *task, true
+}
+
+// Len returns the number of tasks.
+func (g *Graph) Len() int {
+	return len(g.tasks)
+}
+
+// Dependencies returns the edges from id to the tasks it needs, sorted by target task.
+func (g *Graph) Dependencies(id TaskID) []Edge {
+	return slices.Clone(g.deps[id])
+}
+
+// Dependents returns the edges from the tasks needing id, sorted by source task.
+func (g *Graph) Dependents(id TaskID) []Edge {
+	return slices.Clone(g.dependents[id])
+}
+
+// Edges returns every edge, sorted by source and target task.
+func (g *Graph) Edges() []Edge {
+	var edges []Edge
+	for _, id := range g.IDs() {
+		edges = append(edges, g.deps[id]...)
+	}
+	return edges
+}
+
+// Why returns the chain of edges through which a root task pulls in id, shortest first.
+// It is empty for roots and for unknown tasks.
+func (g *Graph) Why(id TaskID) []Edge {
+	if _, ok := g.tasks[id]; !ok || slices.Contains(g.Roots, id) {
+		return nil
+	}
+
+	// Walk up the dependents until a root is found
+	previous := map[TaskID]Edge{}
+	queue := []TaskID{id}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, e := range g.dependents[current] {
+			if _, seen := previous[e.From]; seen || e.From == id {
+				continue
+			}
+			previous[e.From] = e
+			if slices.Contains(g.Roots, e.From) {
+				var chain []Edge
+				for n := e.From; n != id; n = previous[n].To {
+					chain = append(chain, previous[n])
+				}
+				return chain
+			}
+			queue = append(queue, e.From)
+		}
+	}
+	return nil
+}
+
+// Layers groups the tasks so that every task only needs tasks in earlier layers; the
+// tasks of a layer can run in parallel. It returns an error wrapping a *graph.CycleError
+// when tasks depend on each other.
+func (g *Graph) Layers() ([][]TaskID, error) {
+	pg := &nxtypes.ProjectGraph{
+		Nodes:        make(map[string]nxtypes.ProjectGraphProjectNode, len(g.tasks)),
+		Dependencies: make(map[string][]nxtypes.ProjectGraphDependency),
+	}
+	byName := make(map[string]TaskID, len(g.tasks))
+	for id := range g.tasks {
+		name := id.String()
+		byName[name] = id
+		pg.Nodes[name] = nxtypes.ProjectGraphProjectNode{Name: name}
+		for _, e := range g.deps[id] {
+			pg.Dependencies[name] = append(pg.Dependencies[name], nxtypes.ProjectGraphDependency{
+				Source: name,
+				Target: e.To.String(),
+			})
+		}
+	}
+
+	names, err := graph.New(pg).Layers()
+	if err != nil {
+		return nil, fmt.Errorf("failed to order tasks: %w", err)
+	}
+
+	layers := make([][]TaskID, len(names))
+	for i, layer := range names {
+		for _, name := range layer {
+			layers[i] = append(layers[i], byName[name])
+		}
+	}
+	return layers, nil
+}
+
+// Order returns every task after the tasks it needs.
+func (g *Graph) Order() ([]TaskID, error) {
+	layers, err := g.Layers()
+	if err != nil {
+		return nil, err
+	}
+	return slices.Concat(layers...), nil
+}
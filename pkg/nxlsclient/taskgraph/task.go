@@ -0,0 +1,117 @@
+package taskgraph
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	nxtypes "github.com/lazyengs/lazynx/pkg/nxlsclient/nx-types"
+)
+
+// TaskID identifies a task: a target of a project, optionally with a configuration.
+type TaskID struct {
+	Project       string `json:"project"`
+	Target        string `json:"target"`
+	Configuration string `json:"configuration,omitempty"`
+}
+
+// ParseTaskID parses a target string such as "app:build" or "app:build:production".
+// Target names with a colon, such as "build:types", read as a configuration; use
+// ResolveTaskID when the workspace is known.
+func ParseTaskID(s string) (TaskID, error) {
+	parts := strings.Split(s, ":")
+	if len(parts) < 2 || len(parts) > 3 || parts[0] == "" || parts[1] == "" {
+		return TaskID{}, fmt.Errorf("invalid target %q, expected project:target[:configuration]", s)
+	}
+
+	id := TaskID{Project: parts[0], Target: parts[1]}
+	if len(parts) == 3 {
+		id.Configuration = parts[2]
+	}
+	return id, nil
+}
+
+// ResolveTaskID parses a target string against the targets of workspace, the way nx run
+// does: "app:build:types" is the target build:types of app when app has it, and the
+// types configuration of app:build otherwise. Unknown projects parse as in ParseTaskID.
+func ResolveTaskID(s string, workspace *nxtypes.NxWorkspace) (TaskID, error) {
+	project, rest, _ := strings.Cut(s, ":")
+	node, ok := workspace.ProjectGraph.Nodes[project]
+	if !ok || rest == "" {
+		return ParseTaskID(s)
+	}
+
+	targets := node.Data.Targets
+	if _, ok := targets[rest]; ok {
+		return TaskID{Project: project, Target: rest}, nil
+	}
+	// The longest target name followed by a configuration
+	for i := strings.LastIndex(rest, ":"); i > 0; i = strings.LastIndex(rest[:i], ":") {
+		if _, ok := targets[rest[:i]]; ok {
+			return TaskID{Project: project, Target: rest[:i], Configuration: rest[i+1:]}, nil
+		}
+	}
+	return ParseTaskID(s)
+}
+
+// String returns the id in the project:target[:configuration] form used by nx run.
+func (id TaskID) String() string {
+	if id.Configuration == "" {
+		return id.Project + ":" + id.Target
+	}
+	return id.Project + ":" + id.Target + ":" + id.Configuration
+}
+
+// Task is a node of the task graph.
+type Task struct {
+	ID TaskID `json:"id"`
+	// Target is the project's target configuration the task runs.
+	Target nxtypes.TargetConfiguration `json:"target"`
+}
+
+// RuleSource tells where the dependsOn rule of an edge was declared.
+type RuleSource struct {
+	// TargetDefaults is true when the rule comes from nx.json targetDefaults rather than
+	// from the project's own target.
+	TargetDefaults bool `json:"targetDefaults,omitempty"`
+	// Key is the targetDefaults key that matched: a target name, an executor or a glob.
+	Key string `json:"key,omitempty"`
+}
+
+func (s RuleSource) String() string {
+	if s.TargetDefaults {
+		return fmt.Sprintf("targetDefaults[%q]", s.Key)
+	}
+	return "the target"
+}
+
+// Edge is a dependency between two tasks, From running after To.
+type Edge struct {
+	From TaskID `json:"from"`
+	To   TaskID `json:"to"`
+	// Rule is the dependsOn entry that created the edge.
+	Rule   nxtypes.TargetDependency `json:"rule"`
+	Source RuleSource               `json:"source"`
+	// Path is the project dependency chain from From.Project to To.Project for rules on
+	// dependencies. It has more than two entries when projects without the target were
+	// skipped.
+	Path []string `json:"path,omitempty"`
+}
+
+// Explain describes why the edge exists, such as
+// `app:build needs lib:build: dependsOn "^build" from targetDefaults["build"], app depends on lib`.
+func (e Edge) Explain() string {
+	rule, err := json.Marshal(e.Rule)
+	if err != nil {
+		rule = []byte(e.Rule.Target)
+	}
+
+	explanation := fmt.Sprintf("%s needs %s: dependsOn %s from %s", e.From, e.To, rule, e.Source)
+	switch {
+	case len(e.Path) == 2:
+		explanation += fmt.Sprintf(", %s depends on %s", e.Path[0], e.Path[1])
+	case len(e.Path) > 2:
+		explanation += fmt.Sprintf(", %s depends on %s through %s", e.Path[0], e.Path[len(e.Path)-1], strings.Join(e.Path[1:len(e.Path)-1], ", "))
+	}
+	return explanation
+}
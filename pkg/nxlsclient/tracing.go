@@ -0,0 +1,42 @@
+package nxlsclient
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TracerName is the instrumentation name of the lifecycle spans created by a Client.
+const TracerName = "github.com/lazyengs/lazynx/pkg/nxlsclient"
+
+// tracerProvider returns the configured provider, falling back to the global one.
+func (c *Client) tracerProvider() trace.TracerProvider {
+	if c.TracerProvider != nil {
+		return c.TracerProvider
+	}
+	return otel.GetTracerProvider()
+}
+
+// startSpan starts a span for a lifecycle phase, as a child of any span in ctx.
+func (c *Client) startSpan(ctx context.Context, name string) (context.Context, trace.Span) {
+	return c.tracerProvider().Tracer(TracerName).Start(ctx, name)
+}
+
+// tracePhase runs a lifecycle phase inside its own span.
+func (c *Client) tracePhase(ctx context.Context, name string, phase func(context.Context) error) error {
+	ctx, span := c.startSpan(ctx, name)
+	err := phase(ctx)
+	endSpan(span, err)
+	return err
+}
+
+// endSpan records err, if any, and ends the span.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
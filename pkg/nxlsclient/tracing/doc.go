@@ -0,0 +1,26 @@
+/*
+Package tracing provides a local OpenTelemetry exporter that writes spans to a JSONL file.
+
+The nxlsclient Client creates a span for every start-up phase (runtime resolution, unpack,
+npm install, spawn and initialize) and its Commander creates one for every request. Spans
+are children of any span found in the caller's context.Context. Without configuration they
+go to the global OpenTelemetry tracer provider, which discards them.
+
+# Usage
+
+Write the spans to a file that can be attached to a bug report, without running a collector:
+
+	provider, err := tracing.NewFileTracerProvider("/tmp/nxls-trace.jsonl", "my-tool")
+	if err != nil {
+		// Handle error
+	}
+	defer provider.Shutdown(context.Background())
+
+	client := nxlsclient.NewClient(workspacePath, false)
+	client.TracerProvider = provider
+
+Each line of the file is one finished span:
+
+	{"traceId":"…","spanId":"…","parentSpanId":"…","name":"nx/workspace","kind":"client","start":"…","end":"…","durationMs":812.4,"status":"ok",…}
+*/
+package tracing
@@ -0,0 +1,166 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// FileExporter is a span exporter that appends every span as a JSON line to a file.
+type FileExporter struct {
+	mu      sync.Mutex
+	file    *os.File
+	encoder *json.Encoder
+}
+
+var _ sdktrace.SpanExporter = (*FileExporter)(nil)
+
+// NewFileExporter opens path for appending, creating it and its directory if needed.
+func NewFileExporter(path string) (*FileExporter, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create trace directory: %w", err)
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open trace file: %w", err)
+	}
+
+	return &FileExporter{file: file, encoder: json.NewEncoder(file)}, nil
+}
+
+// NewFileTracerProvider creates a tracer provider that writes every span to path as soon
+// as it ends, so the file is complete even when the process crashes. Callers must Shutdown
+// the provider to close the file.
+func NewFileTracerProvider(path string, serviceName string) (*sdktrace.TracerProvider, error) {
+	exporter, err := NewFileExporter(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return sdktrace.NewTracerProvider(
+		sdktrace.WithSyncer(exporter),
+		sdktrace.WithResource(resource.NewSchemaless(attribute.String("service.name", serviceName))),
+	), nil
+}
+
+// spanRecord is the JSON representation of a span.
+type spanRecord struct {
+	TraceID       string         `json:"traceId"`
+	SpanID        string         `json:"spanId"`
+	ParentSpanID  string         `json:"parentSpanId,omitempty"`
+	Name          string         `json:"name"`
+	Kind          string         `json:"kind"`
+	Scope         string         `json:"scope,omitempty"`
+	Start         time.Time      `json:"start"`
+	End           time.Time      `json:"end"`
+	DurationMs    float64        `json:"durationMs"`
+	Status        string         `json:"status"`
+	StatusMessage string         `json:"statusMessage,omitempty"`
+	Attributes    map[string]any `json:"attributes,omitempty"`
+	Events        []eventRecord  `json:"events,omitempty"`
+	Resource      map[string]any `json:"resource,omitempty"`
+}
+
+type eventRecord struct {
+	Name       string         `json:"name"`
+	Time       time.Time      `json:"time"`
+	Attributes map[string]any `json:"attributes,omitempty"`
+}
+
+// ExportSpans writes spans to the file, one JSON object per line.
+func (e *FileExporter) ExportSpans(ctx context.Context, spans []sdktrace.ReadOnlySpan) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.file == nil {
+		return fmt.Errorf("trace file is closed")
+	}
+
+	for _, span := range spans {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := e.encoder.Encode(newSpanRecord(span)); err != nil {
+			return fmt.Errorf("failed to write span: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Shutdown closes the file. Spans exported afterwards are rejected.
+func (e *FileExporter) Shutdown(ctx context.Context) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.file == nil {
+		return nil
+	}
+	err := e.file.Close()
+	e.file = nil
+	return err
+}
+
+func newSpanRecord(span sdktrace.ReadOnlySpan) spanRecord {
+	record := spanRecord{
+		TraceID:       span.SpanContext().TraceID().String(),
+		SpanID:        span.SpanContext().SpanID().String(),
+		Name:          span.Name(),
+		Kind:          span.SpanKind().String(),
+		Scope:         span.InstrumentationScope().Name,
+		Start:         span.StartTime(),
+		End:           span.EndTime(),
+		DurationMs:    float64(span.EndTime().Sub(span.StartTime())) / float64(time.Millisecond),
+		Status:        statusString(span.Status().Code),
+		StatusMessage: span.Status().Description,
+		Attributes:    attributeMap(span.Attributes()),
+	}
+
+	if parent := span.Parent(); parent.IsValid() {
+		record.ParentSpanID = parent.SpanID().String()
+	}
+	if res := span.Resource(); res != nil {
+		record.Resource = attributeMap(res.Attributes())
+	}
+	for _, event := range span.Events() {
+		record.Events = append(record.Events, eventRecord{
+			Name:       event.Name,
+			Time:       event.Time,
+			Attributes: attributeMap(event.Attributes),
+		})
+	}
+
+	return record
+}
+
+func statusString(code codes.Code) string {
+	switch code {
+	case codes.Ok:
+		return "ok"
+	case codes.Error:
+		return "error"
+	default:
+		return "unset"
+	}
+}
+
+func attributeMap(attrs []attribute.KeyValue) map[string]any {
+	if len(attrs) == 0 {
+		return nil
+	}
+	m := make(map[string]any, len(attrs))
+	for _, kv := range attrs {
+		m[string(kv.Key)] = kv.Value.AsInterface()
+	}
+	return m
+}
@@ -0,0 +1,91 @@
+package tracing
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+func readRecords(t *testing.T, path string) []spanRecord {
+	t.Helper()
+
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+
+	var records []spanRecord
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var record spanRecord
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &record))
+		records = append(records, record)
+	}
+	require.NoError(t, scanner.Err())
+	return records
+}
+
+func TestFileTracerProvider(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "traces", "nxls.jsonl")
+	provider, err := NewFileTracerProvider(path, "lazynx")
+	require.NoError(t, err)
+
+	tracer := provider.Tracer("test")
+	ctx, parent := tracer.Start(context.Background(), "nxls.start")
+	_, child := tracer.Start(ctx, "nx/workspace")
+	child.SetAttributes(attribute.Int("rpc.response.size", 42))
+	child.RecordError(errors.New("boom"))
+	child.SetStatus(codes.Error, "boom")
+	child.End()
+	parent.End()
+
+	require.NoError(t, provider.Shutdown(context.Background()))
+
+	records := readRecords(t, path)
+	require.Len(t, records, 2)
+
+	childRecord, parentRecord := records[0], records[1]
+	assert.Equal(t, "nx/workspace", childRecord.Name)
+	assert.Equal(t, parentRecord.TraceID, childRecord.TraceID)
+	assert.Equal(t, parentRecord.SpanID, childRecord.ParentSpanID)
+	assert.Empty(t, parentRecord.ParentSpanID)
+	assert.Equal(t, "error", childRecord.Status)
+	assert.Equal(t, "boom", childRecord.StatusMessage)
+	assert.Equal(t, float64(42), childRecord.Attributes["rpc.response.size"])
+	require.Len(t, childRecord.Events, 1)
+	assert.Equal(t, "exception", childRecord.Events[0].Name)
+	assert.Equal(t, "lazynx", parentRecord.Resource["service.name"])
+	assert.Equal(t, "unset", parentRecord.Status)
+	assert.Equal(t, "test", parentRecord.Scope)
+}
+
+func TestFileExporterAppends(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nxls.jsonl")
+
+	for range 2 {
+		provider, err := NewFileTracerProvider(path, "lazynx")
+		require.NoError(t, err)
+		_, span := provider.Tracer("test").Start(context.Background(), "nxls.stop")
+		span.End()
+		require.NoError(t, provider.Shutdown(context.Background()))
+	}
+
+	assert.Len(t, readRecords(t, path), 2)
+}
+
+func TestFileExporterShutdown(t *testing.T) {
+	exporter, err := NewFileExporter(filepath.Join(t.TempDir(), "nxls.jsonl"))
+	require.NoError(t, err)
+
+	require.NoError(t, exporter.Shutdown(context.Background()))
+	require.NoError(t, exporter.Shutdown(context.Background()))
+	assert.Error(t, exporter.ExportSpans(context.Background(), nil))
+}
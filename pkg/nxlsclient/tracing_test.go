@@ -0,0 +1,50 @@
+package nxlsclient
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestCommanderSpansFollowCallerContext(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	client := newFakeServerClient(t, 0)
+	client.TracerProvider = provider
+	client.Commander.SetTracerProvider(provider)
+
+	ctx, parent := provider.Tracer("test").Start(context.Background(), "caller")
+	_, err := client.Commander.SendWorkspacePathRequest(ctx)
+	require.NoError(t, err)
+	parent.End()
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	request := spans[0]
+	assert.Equal(t, "nx/workspacePath", request.Name())
+	assert.Equal(t, trace.SpanKindClient, request.SpanKind())
+	assert.Equal(t, parent.SpanContext().SpanID(), request.Parent().SpanID())
+	assert.Equal(t, parent.SpanContext().TraceID(), request.SpanContext().TraceID())
+}
+
+func TestTracePhaseRecordsErrors(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	client := &Client{TracerProvider: sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))}
+
+	err := client.tracePhase(context.Background(), "nxls.unpack", func(ctx context.Context) error {
+		assert.True(t, trace.SpanFromContext(ctx).SpanContext().IsValid())
+		return assert.AnError
+	})
+	assert.ErrorIs(t, err, assert.AnError)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "nxls.unpack", spans[0].Name())
+	assert.Equal(t, "Error", spans[0].Status().Code.String())
+}
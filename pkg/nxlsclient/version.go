@@ -0,0 +1,266 @@
+package nxlsclient
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// NodeVersion is a parsed Node.js version such as v20.11.1.
+type NodeVersion struct {
+	Major int
+	Minor int
+	Patch int
+}
+
+// String returns the version formatted the way `node --version` prints it.
+func (v NodeVersion) String() string {
+	return fmt.Sprintf("v%d.%d.%d", v.Major, v.Minor, v.Patch)
+}
+
+// Compare returns -1, 0 or 1 depending on whether v is lower, equal or greater than other.
+func (v NodeVersion) Compare(other NodeVersion) int {
+	switch {
+	case v.Major != other.Major:
+		return compareInt(v.Major, other.Major)
+	case v.Minor != other.Minor:
+		return compareInt(v.Minor, other.Minor)
+	default:
+		return compareInt(v.Patch, other.Patch)
+	}
+}
+
+func compareInt(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// ParseNodeVersion parses a full version string like "v20.11.1" or "18.19.0".
+// Missing minor and patch components default to zero.
+func ParseNodeVersion(s string) (NodeVersion, error) {
+	v, _, err := parsePartialVersion(s)
+	return v, err
+}
+
+// parsePartialVersion parses a version that may omit components or use x wildcards
+// ("20", "20.x", "v18.19"). It returns the number of components that were given
+// explicitly so range operators can widen the match accordingly.
+func parsePartialVersion(s string) (NodeVersion, int, error) {
+	s = strings.TrimSpace(s)
+	s = strings.TrimPrefix(s, "v")
+	s = strings.TrimPrefix(s, "=")
+	// Drop pre-release and build metadata, they are irrelevant for runtime checks
+	if i := strings.IndexAny(s, "-+"); i >= 0 {
+		s = s[:i]
+	}
+	if s == "" || s == "*" || s == "x" || s == "X" {
+		return NodeVersion{}, 0, nil
+	}
+
+	parts := strings.Split(s, ".")
+	if len(parts) > 3 {
+		return NodeVersion{}, 0, fmt.Errorf("invalid node version %q", s)
+	}
+
+	var nums [3]int
+	given := 0
+	for i, part := range parts {
+		if part == "x" || part == "X" || part == "*" {
+			break
+		}
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return NodeVersion{}, 0, fmt.Errorf("invalid node version %q", s)
+		}
+		nums[i] = n
+		given++
+	}
+
+	return NodeVersion{Major: nums[0], Minor: nums[1], Patch: nums[2]}, given, nil
+}
+
+// NodeVersionRange is a parsed npm-style version range as found in package.json
+// engines fields, .nvmrc files and volta pins.
+type NodeVersionRange struct {
+	raw string
+	// sets are OR-ed together; the comparators inside each set are AND-ed.
+	sets [][]versionComparator
+}
+
+type versionComparator struct {
+	op      string
+	version NodeVersion
+}
+
+// ParseNodeVersionRange parses ranges such as ">=18", "^20.11.0", "18.x || 20.x",
+// ">=18.0.0 <21" or a bare version, which matches every release sharing its
+// explicitly given components ("20" matches any v20).
+func ParseNodeVersionRange(s string) (NodeVersionRange, error) {
+	r := NodeVersionRange{raw: strings.TrimSpace(s)}
+
+	for _, alt := range strings.Split(r.raw, "||") {
+		set, err := parseComparatorSet(alt)
+		if err != nil {
+			return NodeVersionRange{}, err
+		}
+		r.sets = append(r.sets, set)
+	}
+
+	return r, nil
+}
+
+func parseComparatorSet(s string) ([]versionComparator, error) {
+	fields := strings.Fields(s)
+
+	// Hyphen ranges: "18 - 20"
+	if len(fields) == 3 && fields[1] == "-" {
+		low, _, err := parsePartialVersion(fields[0])
+		if err != nil {
+			return nil, err
+		}
+		high, given, err := parsePartialVersion(fields[2])
+		if err != nil {
+			return nil, err
+		}
+		return []versionComparator{{op: ">=", version: low}, upperBound(high, given)}, nil
+	}
+
+	var set []versionComparator
+	for i := 0; i < len(fields); i++ {
+		field := fields[i]
+		// Allow a space between the operator and the version (">= 18")
+		if isOperator(field) && i+1 < len(fields) {
+			field += fields[i+1]
+			i++
+		}
+
+		comparators, err := parseComparator(field)
+		if err != nil {
+			return nil, err
+		}
+		set = append(set, comparators...)
+	}
+
+	return set, nil
+}
+
+func isOperator(s string) bool {
+	switch s {
+	case ">", ">=", "<", "<=", "=", "^", "~":
+		return true
+	}
+	return false
+}
+
+func parseComparator(s string) ([]versionComparator, error) {
+	for _, op := range []string{">=", "<=", ">", "<"} {
+		if strings.HasPrefix(s, op) {
+			v, _, err := parsePartialVersion(s[len(op):])
+			if err != nil {
+				return nil, err
+			}
+			return []versionComparator{{op: op, version: v}}, nil
+		}
+	}
+
+	switch {
+	case strings.HasPrefix(s, "^"):
+		v, given, err := parsePartialVersion(s[1:])
+		if err != nil {
+			return nil, err
+		}
+		// ^ allows changes that do not modify the left-most non-zero component
+		var high NodeVersion
+		switch {
+		case v.Major > 0 || given <= 1:
+			high = NodeVersion{Major: v.Major + 1}
+		case v.Minor > 0 || given == 2:
+			high = NodeVersion{Minor: v.Minor + 1}
+		default:
+			high = NodeVersion{Minor: v.Minor, Patch: v.Patch + 1}
+		}
+		return []versionComparator{{op: ">=", version: v}, {op: "<", version: high}}, nil
+	case strings.HasPrefix(s, "~"):
+		v, given, err := parsePartialVersion(s[1:])
+		if err != nil {
+			return nil, err
+		}
+		// ~ allows patch-level changes, or minor-level ones when only the major is given
+		if given > 2 {
+			given = 2
+		}
+		return []versionComparator{{op: ">=", version: v}, upperBound(v, given)}, nil
+	}
+
+	v, given, err := parsePartialVersion(s)
+	if err != nil {
+		return nil, err
+	}
+	if given == 0 {
+		return nil, nil
+	}
+	if given == 3 {
+		return []versionComparator{{op: "=", version: v}}, nil
+	}
+
+	return []versionComparator{{op: ">=", version: v}, upperBound(v, given)}, nil
+}
+
+// upperBound returns the exclusive upper bound for a version given with the
+// specified number of explicit components.
+func upperBound(v NodeVersion, given int) versionComparator {
+	switch given {
+	case 0:
+		return versionComparator{op: ">=", version: NodeVersion{}}
+	case 1:
+		return versionComparator{op: "<", version: NodeVersion{Major: v.Major + 1}}
+	case 2:
+		return versionComparator{op: "<", version: NodeVersion{Major: v.Major, Minor: v.Minor + 1}}
+	default:
+		return versionComparator{op: "<=", version: v}
+	}
+}
+
+// Contains reports whether v satisfies the range.
+func (r NodeVersionRange) Contains(v NodeVersion) bool {
+	for _, set := range r.sets {
+		if setContains(set, v) {
+			return true
+		}
+	}
+	return false
+}
+
+func setContains(set []versionComparator, v NodeVersion) bool {
+	for _, c := range set {
+		cmp := v.Compare(c.version)
+		var ok bool
+		switch c.op {
+		case ">":
+			ok = cmp > 0
+		case ">=":
+			ok = cmp >= 0
+		case "<":
+			ok = cmp < 0
+		case "<=":
+			ok = cmp <= 0
+		default:
+			ok = cmp == 0
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// String returns the range as it was written.
+func (r NodeVersionRange) String() string {
+	return r.raw
+}
@@ -0,0 +1,73 @@
+package nxlsclient
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseNodeVersion(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected NodeVersion
+		wantErr  bool
+	}{
+		{input: "v20.11.1", expected: NodeVersion{Major: 20, Minor: 11, Patch: 1}},
+		{input: "18.19.0", expected: NodeVersion{Major: 18, Minor: 19}},
+		{input: "22", expected: NodeVersion{Major: 22}},
+		{input: "v21.0.0-nightly2023", expected: NodeVersion{Major: 21}},
+		{input: "lts/iron", wantErr: true},
+		{input: "1.2.3.4", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			v, err := ParseNodeVersion(tt.input)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, v)
+		})
+	}
+}
+
+func TestNodeVersionRangeContains(t *testing.T) {
+	tests := []struct {
+		rng      string
+		version  string
+		expected bool
+	}{
+		{rng: ">=18", version: "v18.0.0", expected: true},
+		{rng: ">=18", version: "v16.20.2", expected: false},
+		{rng: ">= 18.12.0", version: "v18.11.9", expected: false},
+		{rng: "^20.11.0", version: "v20.19.5", expected: true},
+		{rng: "^20.11.0", version: "v21.0.0", expected: false},
+		{rng: "^0.2.3", version: "v0.2.9", expected: true},
+		{rng: "^0.2.3", version: "v0.3.0", expected: false},
+		{rng: "~18.2", version: "v18.2.7", expected: true},
+		{rng: "~18.2", version: "v18.3.0", expected: false},
+		{rng: "18.x || 20.x", version: "v20.1.0", expected: true},
+		{rng: "18.x || 20.x", version: "v19.1.0", expected: false},
+		{rng: ">=18.0.0 <21", version: "v20.99.0", expected: true},
+		{rng: ">=18.0.0 <21", version: "v21.0.0", expected: false},
+		{rng: "18 - 20", version: "v20.5.0", expected: true},
+		{rng: "18 - 20", version: "v21.0.0", expected: false},
+		{rng: "20", version: "v20.3.1", expected: true},
+		{rng: "v20.11.1", version: "v20.11.1", expected: true},
+		{rng: "v20.11.1", version: "v20.11.2", expected: false},
+		{rng: "*", version: "v12.0.0", expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.rng+"/"+tt.version, func(t *testing.T) {
+			r, err := ParseNodeVersionRange(tt.rng)
+			require.NoError(t, err)
+			v, err := ParseNodeVersion(tt.version)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, r.Contains(v))
+		})
+	}
+}